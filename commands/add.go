@@ -3,10 +3,12 @@ package commands
 import (
 	"context"
 	"net"
+	"strings"
 	"time"
 
 	samqp "github.com/streadway/amqp"
 
+	"github.com/ipfs-search/ipfs-search/components/queue"
 	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
@@ -14,7 +16,23 @@ import (
 	"github.com/ipfs-search/ipfs-search/utils"
 )
 
-// AddHash queues a single IPFS hash for indexing
+// hashesPublisher returns a publisher for the hashes queue.
+func hashesPublisher(ctx context.Context, cfg *config.Config, dialer *utils.RetryingDialer, i *instr.Instrumentation) (queue.Publisher, error) {
+	amqpConfig := &samqp.Config{
+		Dial: dialer.Dial,
+	}
+
+	f := amqp.PublisherFactory{
+		Config:          cfg.AMQPConfig(),
+		Queue:           "hashes",
+		AMQPConfig:      amqpConfig,
+		Instrumentation: i,
+	}
+
+	return f.NewPublisher(ctx)
+}
+
+// AddHash queues a single IPFS hash or IPNS name (/ipns/<name>) for indexing
 func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler add")
 	if err != nil {
@@ -33,18 +51,7 @@ func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 		Context: ctx,
 	}
 
-	amqpConfig := &samqp.Config{
-		Dial: dialer.Dial,
-	}
-
-	f := amqp.PublisherFactory{
-		Config:          cfg.AMQPConfig(),
-		Queue:           "hashes",
-		AMQPConfig:      amqpConfig,
-		Instrumentation: i,
-	}
-
-	queue, err := f.NewPublisher(ctx)
+	queue, err := hashesPublisher(ctx, cfg, dialer, i)
 	if err != nil {
 		return err
 	}
@@ -54,6 +61,17 @@ func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 		ID:       hash,
 	}
 
+	if strings.HasPrefix(hash, "/ipns/") {
+		// IPNS names are resolved by the crawler.
+		return queue.Publish(ctx, &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       hash,
+			},
+			IPNSName: hash,
+		}, 9)
+	}
+
 	provider := t.Provider{
 		Resource: resource,
 		Date:     time.Now(),
@@ -10,8 +10,8 @@ import (
 	"log"
 )
 
-// Crawl configures and initializes crawling
-func Crawl(ctx context.Context, cfg *config.Config) error {
+// Crawl configures and initializes crawling, running in batch mode when enabled in batchOpts.
+func Crawl(ctx context.Context, cfg *config.Config, batchOpts worker.BatchOptions) error {
 	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler")
 	if err != nil {
 		log.Fatal(err)
@@ -28,10 +28,16 @@ func Crawl(ctx context.Context, cfg *config.Config) error {
 		return err
 	}
 
+	if batchOpts.Enabled() {
+		return c.RunBatch(ctx, batchOpts)
+	}
+
 	c.Start(ctx)
 
 	// Context closure or panic is the only way to stop crawling
 	<-ctx.Done()
 
+	c.Shutdown()
+
 	return ctx.Err()
 }
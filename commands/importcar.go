@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+var (
+	// ErrImport is returned when a CAR file could not be imported into IPFS.
+	ErrImport = errors.New("import failed")
+
+	// ErrCrawl is returned when imported roots could not be queued for crawling.
+	ErrCrawl = errors.New("queueing for crawl failed")
+)
+
+// ImportCAR imports a CAR (Content Addressable aRchive) file into IPFS, pinning its roots, and queues each root for
+// crawling. Import errors wrap ErrImport, errors queueing roots wrap ErrCrawl.
+func ImportCAR(ctx context.Context, cfg *config.Config, file string) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler import-car")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	i := instr.New()
+
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	// Connect to the queue before importing, failing early when it is unavailable.
+	queue, err := hashesPublisher(ctx, cfg, dialer, i)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCrawl, err)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrImport, err)
+	}
+	defer f.Close()
+
+	protocol := ipfs.New(cfg.IPFSConfig(), utils.GetHTTPClient(dialer.DialContext, 1), i)
+	if err := protocol.Ping(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrImport, err)
+	}
+
+	roots, err := protocol.DagImport(ctx, f)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrImport, err)
+	}
+
+	if len(roots) == 0 {
+		return fmt.Errorf("%w: no roots in %s", ErrImport, file)
+	}
+
+	for _, root := range roots {
+		provider := t.Provider{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       root,
+			},
+			Date: time.Now(),
+		}
+
+		// Add with highest priority, as this is known to be available
+		if err := queue.Publish(ctx, provider, 9); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrCrawl, root, err)
+		}
+
+		log.Printf("Imported and queued root %s", root)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/seed"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// ImportCIDs queues the CIDs listed in file, one per line, for crawling. Lines can also be JSON objects with a cid
+// and, optionally, a name and parent. When file is empty or -, CIDs are read from stdin.
+func ImportCIDs(ctx context.Context, cfg *config.Config, file string, opts seed.Options) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler import-cids")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	i := instr.New()
+
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	queue, err := hashesPublisher(ctx, cfg, dialer, i)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+
+	if file != "" && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	progress, err := seed.Seed(ctx, r, queue, opts)
+
+	log.Printf("Imported CIDs: %s", progress)
+
+	return err
+}
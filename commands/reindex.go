@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// Reindex copies documents from one Elasticsearch index to another, e.g. for migrating to a new mapping.
+func Reindex(ctx context.Context, cfg *config.Config, opts *elasticsearch.ReindexOptions) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler reindex")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	i := instr.New()
+
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	esURL, err := cfg.ElasticSearchURL()
+	if err != nil {
+		return err
+	}
+
+	es, err := elastic.NewClient(
+		elastic.SetSniff(false),
+		elastic.SetURL(esURL),
+		elastic.SetHttpClient(utils.GetHTTPClient(dialer.DialContext, 5)),
+	)
+	if err != nil {
+		return err
+	}
+
+	progress, err := elasticsearch.Reindex(ctx, es, opts, i)
+
+	log.Printf("Reindexed %s to %s: %s", opts.Source, opts.Destination, progress)
+
+	return err
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+
+	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// Replay re-publishes messages from the dead-letter queue of queue to the original queue.
+func Replay(ctx context.Context, cfg *config.Config, queue string, opts amqp.ReplayOptions) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler replay")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	i := instr.New()
+
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	amqpConfig := &samqp.Config{
+		Dial: dialer.Dial,
+	}
+
+	conn, err := amqp.NewConnection(ctx, cfg.AMQPConfig(), amqpConfig, i)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	q, err := conn.NewChannelQueue(ctx, queue, 1)
+	if err != nil {
+		return err
+	}
+
+	cnt, err := q.Replay(ctx, opts)
+
+	if opts.DryRun {
+		log.Printf("Found %d messages to replay", cnt)
+	} else {
+		log.Printf("Replayed %d messages", cnt)
+	}
+
+	return err
+}
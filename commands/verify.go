@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"github.com/ipfs-search/ipfs-search/components/verifier"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// verifyBatchSize is the number of document ids fetched per scroll request.
+const verifyBatchSize = 1000
+
+// Verify samples indexed files and directories, marking whether their content is still reachable.
+func Verify(ctx context.Context, cfg *config.Config) error {
+	if s := cfg.CrawlerConfig().DocumentIDs; s != crawler.DocumentIDCID {
+		// Document IDs are retrieved as CIDs.
+		return fmt.Errorf("verifying requires documents keyed by cid, not %s", s)
+	}
+
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler verify")
+	if err != nil {
+		return err
+	}
+	defer instFlusher()
+
+	i := instr.New()
+
+	dialer := &utils.RetryingDialer{
+		Dialer: net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: false,
+		},
+		Context: ctx,
+	}
+
+	esURL, err := cfg.ElasticSearchURL()
+	if err != nil {
+		return err
+	}
+
+	es, err := elastic.NewClient(
+		elastic.SetSniff(false),
+		elastic.SetURL(esURL),
+		elastic.SetHttpClient(utils.GetHTTPClient(dialer.DialContext, 5)),
+	)
+	if err != nil {
+		return err
+	}
+
+	protocol := ipfs.New(cfg.IPFSConfig(), utils.GetHTTPClient(dialer.DialContext, 5), i)
+	if err := protocol.Ping(ctx); err != nil {
+		return err
+	}
+
+	v := verifier.New(cfg.VerifierConfig(), protocol, i)
+
+	for _, name := range []string{cfg.Indexes.Files.Name, cfg.Indexes.Directories.Name} {
+		idxCfg := cfg.ElasticSearchIndexConfig(name)
+		idx := elasticsearch.New(es, idxCfg, i)
+		ids := make(chan string, verifyBatchSize)
+
+		wg, ctx := errgroup.WithContext(ctx)
+
+		wg.Go(func() error {
+			defer close(ids)
+			// Keep the scroll alive while checking a batch at the configured rate.
+			return elasticsearch.ScrollIDs(ctx, es, idxCfg.Sharding.Pattern(name), verifyBatchSize, time.Hour, ids)
+		})
+
+		var counts verifier.Counts
+		wg.Go(func() error {
+			var err error
+			counts, err = v.Verify(ctx, idx, ids)
+			return err
+		})
+
+		err := wg.Wait()
+
+		log.Printf("Verified %s: %s", name, counts)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
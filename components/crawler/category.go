@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// CategoryOther is the category of files of which the MIME type is unknown or not in MimeCategories.
+const CategoryOther = extractor.CategoryOther
+
+// defaultMimeCategories returns a mapping of common MIME types to coarse categories, for faceting search results.
+func defaultMimeCategories() map[string]string {
+	return map[string]string{
+		"application/epub+zip":                            "document",
+		"application/msword":                              "document",
+		"application/pdf":                                 "document",
+		"application/rtf":                                 "document",
+		"application/vnd.ms-powerpoint":                   "document",
+		"application/vnd.oasis.opendocument.presentation": "document",
+		"application/vnd.oasis.opendocument.text":         "document",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation": "document",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   "document",
+		"application/x-mobipocket-ebook":                                            "document",
+		"text/html":                                                                 "document",
+		"text/markdown":                                                             "document",
+		"text/plain":                                                                "document",
+
+		"image/*": "image",
+		"video/*": "video",
+		"audio/*": "audio",
+
+		"application/gzip":             "archive",
+		"application/vnd.rar":          "archive",
+		"application/x-7z-compressed":  "archive",
+		"application/x-bzip2":          "archive",
+		"application/x-gzip":           "archive",
+		"application/x-rar-compressed": "archive",
+		"application/x-tar":            "archive",
+		"application/x-xz":             "archive",
+		"application/zip":              "archive",
+		"application/zstd":             "archive",
+
+		"application/javascript": "code",
+		"application/x-sh":       "code",
+		"text/css":               "code",
+		"text/javascript":        "code",
+		"text/x-c":               "code",
+		"text/x-c++src":          "code",
+		"text/x-csrc":            "code",
+		"text/x-go":              "code",
+		"text/x-java-source":     "code",
+		"text/x-python":          "code",
+		"text/x-rustsrc":         "code",
+		"text/x-sh":              "code",
+
+		"application/json":                                                  "data",
+		"application/vnd.ms-excel":                                          "data",
+		"application/vnd.oasis.opendocument.spreadsheet":                    "data",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": "data",
+		"application/vnd.sqlite3":                                           "data",
+		"application/x-sqlite3":                                             "data",
+		"application/xml":                                                   "data",
+		"application/yaml":                                                  "data",
+		"text/csv":                                                          "data",
+		"text/xml":                                                          "data",
+		"text/yaml":                                                         "data",
+	}
+}
+
+// category returns the category of mimeType, preferring exact matches in MimeCategories over the longest matching
+// glob (e.g. image/*), or CategoryOther.
+func (c *Crawler) category(mimeType string) string {
+	return extractor.Category(mimeType, c.config.MimeCategories)
+}
+
+// setCategory sets the category of f from its MIME type. Files in which a programming language has been detected are
+// code, regardless of their (often generic) MIME type.
+func (c *Crawler) setCategory(f *indexTypes.File) {
+	if f.CodeLanguage != "" {
+		f.Category = extractor.CategoryCode
+		return
+	}
+
+	f.Category = c.category(f.MimeType)
+}
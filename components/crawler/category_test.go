@@ -0,0 +1,62 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestCategory(t *testing.T) {
+	c := &Crawler{config: DefaultConfig()}
+
+	for mimeType, category := range map[string]string{
+		"application/pdf": "document",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": "document",
+		"text/html; charset=utf-8": "document",
+		"image/png":                "image",
+		"image/svg+xml":            "image",
+		"video/mp4":                "video",
+		"audio/mpeg":               "audio",
+		"application/zip":          "archive",
+		"application/x-tar":        "archive",
+		"text/x-go":                "code",
+		"application/javascript":   "code",
+		"application/json":         "data",
+		"text/csv":                 "data",
+		"application/vnd.ms-excel": "data",
+		"application/octet-stream": CategoryOther,
+		"font/woff2":               CategoryOther,
+		"":                         CategoryOther,
+		"not a mime type":          CategoryOther,
+	} {
+		assert.Equal(t, category, c.category(mimeType), mimeType)
+	}
+}
+
+func TestCategoryOverride(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MimeCategories["image/svg+xml"] = "code"
+	cfg.MimeCategories["font/*"] = "font"
+
+	c := &Crawler{config: cfg}
+
+	// Exact matches are preferred over globs.
+	assert.Equal(t, "code", c.category("image/svg+xml"))
+	assert.Equal(t, "image", c.category("image/png"))
+	assert.Equal(t, "font", c.category("font/woff2"))
+}
+
+func TestSetCategoryCode(t *testing.T) {
+	c := &Crawler{config: DefaultConfig()}
+
+	f := &indexTypes.File{MimeType: "text/plain"}
+	c.setCategory(f)
+	assert.Equal(t, "document", f.Category)
+
+	// Source code is often recognized as plain text.
+	f = &indexTypes.File{MimeType: "text/plain", CodeLanguage: "go"}
+	c.setCategory(f)
+	assert.Equal(t, "code", f.Category)
+}
@@ -0,0 +1,15 @@
+package crawler
+
+import (
+	"strings"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// setCharset sets Charset from the Content-Encoding detected by Tika, unless the extractor already set it.
+// Tika transcodes the extracted content to UTF-8 itself.
+func setCharset(f *indexTypes.File) {
+	if f.Charset == "" {
+		f.Charset = strings.ToLower(metadataString(f, "Content-Encoding"))
+	}
+}
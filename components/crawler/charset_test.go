@@ -0,0 +1,34 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestSetCharset(t *testing.T) {
+	f := &indexTypes.File{
+		Metadata: indexTypes.Metadata{
+			"Content-Encoding": []interface{}{"ISO-8859-1"},
+		},
+	}
+
+	setCharset(f)
+
+	assert.Equal(t, "iso-8859-1", f.Charset)
+}
+
+func TestSetCharsetExisting(t *testing.T) {
+	f := &indexTypes.File{
+		Charset: "shift_jis",
+		Metadata: indexTypes.Metadata{
+			"Content-Encoding": []interface{}{"UTF-8"},
+		},
+	}
+
+	setCharset(f)
+
+	assert.Equal(t, "shift_jis", f.Charset)
+}
@@ -0,0 +1,43 @@
+package crawler
+
+import (
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// codecNames overrides names of codecs from cid.CodecToStr which differ from the multicodec table, for versions of
+// go-cid which predate it.
+var codecNames = map[uint64]string{
+	cid.DagProtobuf: "dag-pb",
+	cid.DagCBOR:     "dag-cbor",
+}
+
+// cidProperties returns the names of the codec and multihash type of the CID id, e.g. `dag-pb` and `sha2-256`.
+// Unknown codes are returned in hexadecimal. Returns ErrInvalidResource for invalid CIDs.
+func cidProperties(id string) (codec string, mhType string, err error) {
+	c, err := cid.Decode(id)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	prefix := c.Prefix()
+
+	codec, ok := codecNames[prefix.Codec]
+	if !ok {
+		codec, ok = cid.CodecToStr[prefix.Codec]
+	}
+	if !ok {
+		codec = fmt.Sprintf("0x%x", prefix.Codec)
+	}
+
+	mhType, ok = multihash.Codes[prefix.MhType]
+	if !ok {
+		mhType = fmt.Sprintf("0x%x", prefix.MhType)
+	}
+
+	return codec, mhType, nil
+}
@@ -0,0 +1,34 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIDProperties(t *testing.T) {
+	tests := []struct {
+		cid    string
+		codec  string
+		mhType string
+	}{
+		// CIDv0
+		{"QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", "dag-pb", "sha2-256"},
+		// CIDv1 dag-pb
+		{"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi", "dag-pb", "sha2-256"},
+		// CIDv1 raw
+		{"bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy", "raw", "sha2-256"},
+		// CIDv1 dag-cbor
+		{"bafyreidykglsfhoixmivffc5uwhcgshx4j465xwqntbmu43nb2dzqwfvae", "dag-cbor", "sha2-256"},
+		// CIDv1 raw, identity hash
+		{"bafkqac3imvwgy3zao5xxe3de", "raw", "identity"},
+	}
+
+	for _, test := range tests {
+		codec, mhType, err := cidProperties(test.cid)
+
+		assert.NoError(t, err, test.cid)
+		assert.Equal(t, test.codec, codec, test.cid)
+		assert.Equal(t, test.mhType, mhType, test.cid)
+	}
+}
@@ -1,7 +1,10 @@
 package crawler
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/c2h5oh/datasize"
 )
 
 // Config contains configuration for a Crawler.
@@ -11,6 +14,62 @@ type Config struct {
 	StatTimeout        time.Duration // Timeout for Stat() calls.
 	DirEntryTimeout    time.Duration // Timeout *between* directory entries.
 	MaxDirSize         uint          // Maximum number of directory entries
+	MaxLinks           uint          // Store at most this many links in directory documents; unlimited when 0.
+	LinkDedup          string        // Deduplicate links of directories by LinkDedupHash, LinkDedupName or not (LinkDedupNone).
+	IndexPaths         bool          // Index full paths from known roots.
+	MaxReferences      uint          // Stop adding references to documents with this many references; unlimited when 0.
+	MaxPaths           uint          // Stop adding paths to documents with this many paths; unlimited when 0.
+
+	DenylistFile           string        // File with denied CIDs (badbits format); disabled when empty.
+	DenylistReloadInterval time.Duration // Interval for checking the denylist file for modifications.
+
+	ExtractSubtitles bool              // Index sidecar subtitles (.srt/.vtt) with video files.
+	MaxSubtitleSize  datasize.ByteSize // Maximum size of indexed subtitle text.
+
+	Simhash        bool              // Index a simhash fingerprint of extracted text, for finding near-duplicates.
+	MaxSimhashSize datasize.ByteSize // Maximum amount of text to compute the simhash over.
+
+	SizeBuckets []datasize.ByteSize // Upper bounds of the tiny, small, medium and large size buckets; disabled when empty.
+
+	Source string // Add this crawl source (origin) to the `source` of documents; disabled when empty.
+
+	DagStats           bool    // Index the block count, size and depth of the DAG of documents.
+	DagStatsSampleRate float64 // Fraction of documents to index DAG statistics for, between 0 and 1.
+	DagStatsMaxDepth   uint    // Maximum depth to traverse the DAG to; unlimited when 0.
+	DagStatsMaxBlocks  uint    // Maximum number of blocks to count; unlimited when 0.
+
+	DirectoryTitles  bool              // Index the title of the index.html of directories as their title.
+	MaxIndexPageSize datasize.ByteSize // Skip index pages larger than this.
+	IndexPageNames   []string          // Names of index pages (case-insensitive) marking directories as websites, in order of preference.
+	WebsiteRoots     bool              // Index the nearest ancestor website directory of files as their website root.
+
+	LargeDirThreshold  uint    // Apply LargeDirPolicy to entries of directories beyond this many; disabled when 0.
+	LargeDirPolicy     string  // Queue entries of large directories fully (LargeDirFull), not (LargeDirSkip) or sampled (LargeDirSample).
+	LargeDirSampleRate float64 // Fraction of entries beyond LargeDirThreshold to queue with LargeDirSample, between 0 and 1.
+
+	PrefetchBatchSize uint          // Look up which entries of directories are indexed in batches of this many, with a request per index; disabled when 0.
+	PrefetchTTL       time.Duration // Trust looked up entries to be indexed or not for this long.
+	PrefetchCacheSize int           // Maximum number of looked up entries remembered.
+
+	DocumentIDs       string // Key documents by DocumentIDCID, DocumentIDCIDVersion or DocumentIDCIDName.
+	DocumentIDVersion string // Version appended to CIDs with DocumentIDCIDVersion.
+
+	CompressContent    bool              // Store content over ContentExcerptSize gzipped, indexing an excerpt of it for search.
+	ContentExcerptSize datasize.ByteSize // Size of the content excerpt indexed when compressing content.
+	ContentLanguages   []string          // Languages (e.g. `en`) of which content is also indexed with a language-specific analyzer; disabled when empty.
+
+	MaxExtractionAttempts uint // Index files as unsupported after this many failed extraction attempts; disabled when 0.
+
+	GatewayURL string // Base URL of a public gateway, indexing the `url` of documents on it; disabled when empty.
+
+	OptimisticUpdates  bool // Merge updates of existing documents in the crawler, guarded by their version, rather than by script.
+	MaxUpdateConflicts uint // Re-read and retry optimistic updates up to this many times on concurrent modification.
+
+	DNSLinkTTL time.Duration // Re-resolve and recrawl DNSLink names this long after resolving them; disabled when 0.
+
+	EmptyFiles string // Index zero-byte files as empty without extraction (EmptyFilesIndex) or skip them (EmptyFilesSkip).
+
+	MimeCategories map[string]string // Categories of files by MIME type or glob (e.g. image/*); CategoryOther when not matched.
 }
 
 // DefaultConfig generates a default configuration for a Crawler.
@@ -21,5 +80,92 @@ func DefaultConfig() *Config {
 		StatTimeout:        60 * time.Second,
 		DirEntryTimeout:    60 * time.Second,
 		MaxDirSize:         32768,
+		MaxLinks:           0,
+		LinkDedup:          LinkDedupHash,
+		IndexPaths:         false,
+		MaxReferences:      0,
+		MaxPaths:           0,
+
+		DenylistFile:           "",
+		DenylistReloadInterval: time.Minute,
+		ExtractSubtitles:       false,
+		MaxSubtitleSize:        1024 * 1024, // 1MB
+		Simhash:                false,
+		MaxSimhashSize:         1024 * 1024, // 1MB
+		SizeBuckets: []datasize.ByteSize{
+			16 * datasize.KB,
+			datasize.MB,
+			100 * datasize.MB,
+			datasize.GB,
+		},
+		Source: "",
+
+		DagStats:           false,
+		DagStatsSampleRate: 0.1,
+		DagStatsMaxDepth:   32,
+		DagStatsMaxBlocks:  10000,
+
+		DirectoryTitles:  false,
+		MaxIndexPageSize: 1024 * 1024, // 1MB
+		IndexPageNames:   []string{"index.html", "index.htm"},
+		WebsiteRoots:     false,
+
+		LargeDirThreshold:  0,
+		LargeDirPolicy:     LargeDirFull,
+		LargeDirSampleRate: 0.01,
+
+		PrefetchBatchSize: 0,
+		PrefetchTTL:       10 * time.Minute,
+		PrefetchCacheSize: 100000,
+
+		DocumentIDs:       DocumentIDCID,
+		DocumentIDVersion: "",
+
+		CompressContent:    false,
+		ContentExcerptSize: 64 * 1024, // 64KB
+		ContentLanguages:   []string{},
+
+		MaxExtractionAttempts: 0,
+
+		GatewayURL: "",
+
+		OptimisticUpdates:  false,
+		MaxUpdateConflicts: 5,
+
+		DNSLinkTTL: 0,
+
+		EmptyFiles: EmptyFilesIndex,
+
+		MimeCategories: defaultMimeCategories(),
+	}
+}
+
+// Validate returns an error when LinkDedup, LargeDirPolicy, DocumentIDs or EmptyFiles is not a known mode, when
+// LargeDirSampleRate is not a fraction, when DocumentIDCIDVersion lacks a version or when GatewayURL is invalid.
+func (c *Config) Validate() error {
+	if _, ok := linkKeys[c.LinkDedup]; !ok {
+		return fmt.Errorf("unknown link_dedup mode '%s'", c.LinkDedup)
 	}
+
+	if _, ok := largeDirPolicies[c.LargeDirPolicy]; !ok {
+		return fmt.Errorf("unknown large_dir_policy '%s'", c.LargeDirPolicy)
+	}
+
+	if c.LargeDirSampleRate < 0 || c.LargeDirSampleRate > 1 {
+		return fmt.Errorf("large_dir_sample_rate %v not between 0 and 1", c.LargeDirSampleRate)
+	}
+
+	if _, ok := emptyFilePolicies[c.EmptyFiles]; !ok {
+		return fmt.Errorf("unknown empty_files policy '%s'", c.EmptyFiles)
+	}
+
+	if _, ok := documentIDs[c.DocumentIDs]; !ok {
+		return fmt.Errorf("unknown document_ids strategy '%s'", c.DocumentIDs)
+	}
+
+	if c.DocumentIDs == DocumentIDCIDVersion && c.DocumentIDVersion == "" {
+		return fmt.Errorf("document_ids strategy '%s' requires document_id_version", c.DocumentIDs)
+	}
+
+	return validateGatewayURL(c.GatewayURL)
 }
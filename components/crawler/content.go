@@ -0,0 +1,33 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// compressContent stores content over ContentExcerptSize gzipped in ContentCompressed, keeping an excerpt of it in
+// Content for searching.
+func (c *Crawler) compressContent(f *indexTypes.File) {
+	maxSize := int(c.config.ContentExcerptSize)
+	if !c.config.CompressContent || len(f.Content) <= maxSize {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		// Errors here are programming errors.
+		panic(err)
+	}
+
+	// Writing to a bytes.Buffer does not fail.
+	w.Write([]byte(f.Content))
+	w.Close()
+
+	f.ContentCompressed = buf.Bytes()
+	f.Content = utils.Truncate(f.Content, maxSize)
+}
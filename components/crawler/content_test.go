@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestCompressContent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CompressContent = true
+	cfg.ContentExcerptSize = 16
+
+	c := &Crawler{config: cfg}
+
+	content := strings.Repeat("compressible ", 100)
+	f := &indexTypes.File{Content: content}
+
+	c.compressContent(f)
+
+	assert.Equal(t, content[:16], f.Content)
+
+	r, err := gzip.NewReader(bytes.NewReader(f.ContentCompressed))
+	assert.NoError(t, err)
+
+	full, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(full))
+}
+
+func TestCompressContentSmall(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CompressContent = true
+
+	c := &Crawler{config: cfg}
+
+	f := &indexTypes.File{Content: "small"}
+
+	c.compressContent(f)
+
+	assert.Equal(t, "small", f.Content)
+	assert.Nil(t, f.ContentCompressed)
+}
+
+func TestCompressContentDisabled(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ContentExcerptSize = 1
+
+	c := &Crawler{config: cfg}
+
+	f := &indexTypes.File{Content: "uncompressed"}
+
+	c.compressContent(f)
+
+	assert.Equal(t, "uncompressed", f.Content)
+	assert.Nil(t, f.ContentCompressed)
+}
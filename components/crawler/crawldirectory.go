@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"golang.org/x/sync/errgroup"
-	"log"
 	"math/rand"
+	"path"
 
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
@@ -28,18 +28,29 @@ func (c *Crawler) crawlDir(ctx context.Context, r *t.AnnotatedResource, properti
 
 	entries := make(chan *t.AnnotatedResource, c.config.DirEntryBufferSize)
 
-	wg, ctx := errgroup.WithContext(ctx)
+	var indexPage *t.AnnotatedResource
+
+	wg, wgCtx := errgroup.WithContext(ctx)
 
 	wg.Go(func() error {
-		return c.processDirEntries(ctx, entries, properties)
+		var err error
+		indexPage, err = c.processDirEntries(wgCtx, entries, r, properties)
+		return err
 	})
 
 	wg.Go(func() error {
 		defer close(entries)
-		return c.protocol.Ls(ctx, r, entries)
+		return c.protocol.Ls(wgCtx, r, entries)
 	})
 
-	return wg.Wait()
+	if err := wg.Wait(); err != nil {
+		return err
+	}
+
+	properties.IsWebsite = indexPage != nil
+	c.setDirectoryTitle(ctx, indexPage, properties)
+
+	return nil
 }
 
 func resourceToLinkType(r *t.AnnotatedResource) indexTypes.LinkType {
@@ -57,24 +68,90 @@ func resourceToLinkType(r *t.AnnotatedResource) indexTypes.LinkType {
 	}
 }
 
-func addLink(e *t.AnnotatedResource, properties *indexTypes.Directory) {
+// addLink counts the link to e, adding it to the links of properties up to maxLinks, or unlimited when 0.
+// It returns false when the link was not added.
+func addLink(e *t.AnnotatedResource, properties *indexTypes.Directory, maxLinks uint) bool {
+	properties.LinkCount++
+
+	if maxLinks > 0 && uint(len(properties.Links)) >= maxLinks {
+		return false
+	}
+
 	properties.Links = append(properties.Links, indexTypes.Link{
 		Hash: e.ID,
 		Name: e.Reference.Name,
 		Size: e.Size,
 		Type: resourceToLinkType(e),
 	})
+
+	return true
 }
 
-func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.AnnotatedResource, properties *indexTypes.Directory) error {
+// processDirEntries adds entries of the directory r to properties and queues them, returning the index page of the
+// directory, if any.
+//
+// With WebsiteRoots, entries are held back until the website root for them is known: the directory itself once an
+// index page is found, that of the directory otherwise. Only up to MaxDirSize entries are held back; later entries
+// of large directories are queued with the website root of the directory.
+func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.AnnotatedResource, r *t.AnnotatedResource, properties *indexTypes.Directory) (*t.AnnotatedResource, error) {
 	ctx, span := c.Tracer.Start(ctx, "crawler.processDirEntries")
 	defer span.End()
 
 	var (
-		dirCnt  uint = 0
-		isLarge bool = false
+		dirCnt      uint = 0
+		isLarge     bool = false
+		isTruncated bool = false
+		indexPage   *t.AnnotatedResource
+		dedup       = newLinkDeduper(c.config.LinkDedup)
+		dirPath     = resourcePath(r)
+
+		websiteRoot = r.Reference.WebsiteRoot
+		rootKnown   = !c.config.WebsiteRoots
+		pending     []*t.AnnotatedResource // Entries held back until the website root is known.
+		batch       []*t.AnnotatedResource // Entries to look up before queueing, with PrefetchBatchSize.
 	)
 
+	queueBatch := func(ctx context.Context) error {
+		c.prefetch(ctx, batch)
+
+		for _, e := range batch {
+			if err := c.queueDirEntry(ctx, e); err != nil {
+				return err
+			}
+		}
+
+		batch = nil
+
+		return nil
+	}
+
+	queueEntry := func(ctx context.Context, e *t.AnnotatedResource) error {
+		e.Reference.WebsiteRoot = websiteRoot
+
+		if c.config.PrefetchBatchSize == 0 {
+			return c.queueDirEntry(ctx, e)
+		}
+
+		batch = append(batch, e)
+		if uint(len(batch)) < c.config.PrefetchBatchSize {
+			return nil
+		}
+
+		return queueBatch(ctx)
+	}
+
+	queuePending := func(ctx context.Context) error {
+		for _, e := range pending {
+			if err := queueEntry(ctx, e); err != nil {
+				return err
+			}
+		}
+
+		pending = nil
+
+		return nil
+	}
+
 	// Question: do we need a maximum entry cutoff point? E.g. 10^6 entries or something?
 	processNextDirEntry := func() error {
 		// Create (and cancel!) a new timeout context for every entry.
@@ -90,22 +167,59 @@ func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.Annot
 			}
 
 			if dirCnt > 0 && dirCnt%1024 == 0 {
-				log.Printf("Processed %d directory entries in %v.", dirCnt, entry.Parent)
-				log.Printf("Latest entry: %v", entry)
+				logger.Debugf("Processed %d directory entries in %v.", dirCnt, entry.Parent)
+				logger.Debugf("Latest entry: %v", entry)
 			}
 
 			// Only add to properties up to limit (preventing oversized directory entries) - but queue entries nonetheless.
 			if dirCnt == c.config.MaxDirSize {
 				span.AddEvent(ctx, "large-directory")
-				log.Printf("Directory %v is large, crawling entries but not directory itself.", entry.Parent)
+				logger.Infof("Directory %v is large, crawling entries but not directory itself.", entry.Parent)
 				isLarge = true
 			}
 
 			if !isLarge {
-				addLink(entry, properties)
+				if dedup.isDuplicate(entry) {
+					// Duplicates are queued nonetheless, adding their references.
+					properties.DuplicateLinkCount++
+				} else if !addLink(entry, properties, c.config.MaxLinks) && !isTruncated {
+					// The listing remains available through the references of the entries.
+					span.AddEvent(ctx, "links-truncated")
+					isTruncated = true
+				}
+			}
+
+			// Carry the accumulated path along to the entry.
+			entry.Reference.Path = path.Join(dirPath, entry.Reference.Name)
+
+			if c.isPreferredIndexPage(entry, indexPage) {
+				// Copy, as queueing might modify the entry.
+				page := *entry
+				indexPage = &page
+			}
+
+			if c.skipLargeDirEntry(ctx, dirCnt, properties) {
+				return nil
+			}
+
+			if !rootKnown && (indexPage != nil || isLarge) {
+				if indexPage != nil {
+					websiteRoot = r.ID
+				}
+
+				rootKnown = true
+
+				if err := queuePending(ctx); err != nil {
+					return err
+				}
+			}
+
+			if !rootKnown {
+				pending = append(pending, entry)
+				return nil
 			}
 
-			return c.queueDirEntry(ctx, entry)
+			return queueEntry(ctx, entry)
 		}
 	}
 
@@ -117,24 +231,40 @@ func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.Annot
 		dirCnt++
 	}
 
+	// Not a website; queue held back and batched entries, also when listing failed, as when queueing them right away.
+	qErr := queuePending(ctx)
+	if qErr == nil && len(batch) > 0 {
+		qErr = queueBatch(ctx)
+	}
+
+	if qErr != nil && errors.Is(err, errEndOfLs) {
+		err = qErr
+	}
+
 	if errors.Is(err, errEndOfLs) {
 		// Normal exit of loop, reset error condition
 		err = nil
 
+		if properties.DuplicateLinkCount > 0 {
+			logger.Debugf("Left out %d duplicate links (by %s) of %s.", properties.DuplicateLinkCount, c.config.LinkDedup, dirPath)
+			span.AddEvent(ctx, "duplicate-links")
+		}
+
 		if isLarge {
 			err = ErrDirectoryTooLarge
 		}
 	} else {
 		// Unknown error situation: fail hard
 		// Prefer less over incomplete or inconsistent data.
-		log.Printf("Unexpected error processing directory entries: %v", err)
+		logger.Errorf("Unexpected error processing directory entries: %v", err)
 	}
 
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
 	}
 
-	return err
+	return indexPage, nil
 }
 
 func (c *Crawler) queueDirEntry(ctx context.Context, r *t.AnnotatedResource) error {
@@ -145,6 +275,11 @@ func (c *Crawler) queueDirEntry(ctx context.Context, r *t.AnnotatedResource) err
 	// consistent overall indexing load.
 	priority := uint8(1 + rand.Intn(7))
 
+	if c.denylist.Contains(r.ID) {
+		// Don't bother queueing denied entries.
+		return nil
+	}
+
 	switch r.Type {
 	case t.UndefinedType:
 		return c.queues.Hashes.Publish(ctx, r, priority)
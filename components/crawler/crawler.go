@@ -4,26 +4,41 @@ package crawler
 import (
 	"context"
 	"errors"
-	"log"
 
+	"go.opentelemetry.io/otel/api/metric"
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/label"
 
+	"github.com/ipfs-search/ipfs-search/components/denylist"
 	"github.com/ipfs-search/ipfs-search/components/extractor"
 	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/transform"
+	"github.com/ipfs-search/ipfs-search/components/webhook"
 
 	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
+var logger = logging.New("crawler")
+
 // Crawler allows crawling of resources.
 type Crawler struct {
-	config    *Config
-	indexes   *Indexes
-	queues    *Queues
-	protocol  protocol.Protocol
-	extractor extractor.Extractor
+	config      *Config
+	indexes     *Indexes
+	queues      *Queues
+	protocol    protocol.Protocol
+	extractor   extractor.Extractor
+	denylist    *denylist.Denylist
+	notifier    *webhook.Notifier
+	transform   transform.Chain
+	largeDirs   metric.Int64Counter
+	unindexable *unindexableCounter
+	ids         DocumentIDs
+	prefetched  *prefetchCache
+	fullRefs    *fullReferences
+	scheduled   *scheduledResolves
 
 	*instr.Instrumentation
 }
@@ -38,14 +53,12 @@ func isSupportedType(rType t.ResourceType) bool {
 }
 
 // Crawl updates existing or crawls new resources, extracting metadata where applicable.
-func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
+func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) (err error) {
 	ctx, span := c.Tracer.Start(ctx, "crawler.Crawl",
 		trace.WithAttributes(label.String("cid", r.ID)),
 	)
 	defer span.End()
 
-	var err error
-
 	if r.Protocol == t.InvalidProtocol {
 		// Sending items with an invalid protocol to Crawl() is a programming error and
 		// should never happen.
@@ -57,6 +70,36 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 		panic("invalid type for crawler")
 	}
 
+	if r.IPNSName != "" {
+		// (Re)resolve IPNS names to their current CID.
+		if err := c.resolve(ctx, r); err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return err
+		}
+
+		// Re-resolve only after successfully crawling, so failed crawls are not rescheduled on top of their retries.
+		defer func() {
+			if err == nil {
+				c.scheduleResolve(ctx, r)
+			}
+		}()
+	}
+
+	if c.denylist.Contains(r.ID) {
+		logger.Infof("Skipping denied resource %v", r)
+		span.AddEvent(ctx, "denied")
+		return t.ErrDenied
+	}
+
+	if isEmptyFileCID(r.ID) {
+		// Known without a round-trip to IPFS.
+		r.Stat = t.Stat{Type: t.FileType}
+	}
+
+	if c.skipEmptyFile(ctx, r) {
+		return nil
+	}
+
 	exists, err := c.updateMaybeExisting(ctx, r)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
@@ -64,7 +107,7 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 	}
 
 	if exists {
-		log.Printf("Not updating existing resource %v", r)
+		logger.Debugf("Not updating existing resource %v", r)
 		span.AddEvent(ctx, "Not updating existing resource")
 		return nil
 	}
@@ -72,7 +115,7 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 	if err := c.ensureType(ctx, r); err != nil {
 		if errors.Is(err, t.ErrInvalidResource) {
 			// Resource is invalid, index as such, throwing away ErrInvalidResource in favor of the result of indexing operation.
-			log.Printf("Indexing invalid resource %v", r)
+			logger.Debugf("Indexing invalid resource %v", r)
 			span.AddEvent(ctx, "Indexing invalid resource")
 
 			err = c.indexInvalid(ctx, r, err)
@@ -85,7 +128,11 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 		return err
 	}
 
-	log.Printf("Indexing new item %v", r)
+	if c.skipEmptyFile(ctx, r) {
+		return nil
+	}
+
+	logger.Debugf("Indexing new item %v", r)
 	err = c.index(ctx, r)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
@@ -93,18 +140,46 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 	return err
 }
 
-// New instantiates a Crawler.
-func New(config *Config, indexes *Indexes, queues *Queues, protocol protocol.Protocol, extractor extractor.Extractor, i *instr.Instrumentation) *Crawler {
+// New instantiates a Crawler. A nil extractor indexes files without extracting their metadata, a nil denylist
+// denies nothing, a nil transform chain leaves metadata as is.
+func New(config *Config, indexes *Indexes, queues *Queues, protocol protocol.Protocol, extractor extractor.Extractor, denylist *denylist.Denylist, notifier *webhook.Notifier, transform transform.Chain, i *instr.Instrumentation) *Crawler {
 	return &Crawler{
 		config,
 		indexes,
 		queues,
 		protocol,
 		extractor,
+		denylist,
+		notifier,
+		transform,
+		newLargeDirCounter(i.Meter),
+		newUnindexableCounter(i.Meter),
+		documentIDs[config.DocumentIDs](config),
+		newPrefetchCache(config, i.Meter),
+		newFullReferences(),
+		newScheduledResolves(),
 		i,
 	}
 }
 
+func (c *Crawler) resolve(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := c.Tracer.Start(ctx, "crawler.resolve",
+		trace.WithAttributes(label.String("ipns_name", r.IPNSName)),
+	)
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.StatTimeout)
+	defer cancel()
+
+	err := c.protocol.Resolve(ctx, r)
+	if err != nil {
+		logger.Infof("Error resolving '%s': %v", r.IPNSName, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
 func (c *Crawler) ensureType(ctx context.Context, r *t.AnnotatedResource) error {
 	ctx, span := c.Tracer.Start(ctx, "crawler.ensureType")
 	defer span.End()
@@ -6,14 +6,20 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/ipfs-search/ipfs-search/components/denylist"
 	"github.com/ipfs-search/ipfs-search/components/extractor"
 	"github.com/ipfs-search/ipfs-search/components/index"
 	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
 	"github.com/ipfs-search/ipfs-search/components/protocol"
 	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/transform"
 
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
@@ -67,7 +73,7 @@ func (s *CrawlerTestSuite) SetupTest() {
 
 	s.cfg = DefaultConfig()
 
-	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, s.instr)
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, nil, s.instr)
 }
 
 func (s *CrawlerTestSuite) assertExpectations() {
@@ -85,17 +91,17 @@ func (s *CrawlerTestSuite) assertExpectations() {
 
 func (s *CrawlerTestSuite) assertNotExists(rID string) {
 	s.fileIdx.
-		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
 		Return(false, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
 		Return(false, nil).
 		Once()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
 		Return(false, nil).
 		Once()
 }
@@ -132,6 +138,7 @@ func (s *CrawlerTestSuite) TestCrawlUndefinedType() {
 			r := args.Get(1).(*t.AnnotatedResource)
 			r.Stat = t.Stat{
 				Type: t.FileType,
+				Size: 400,
 			}
 		}).
 		Return(nil).
@@ -253,6 +260,101 @@ func (s *CrawlerTestSuite) TestCrawlInvalid() {
 	s.assertExpectations()
 }
 
+func (s *CrawlerTestSuite) TestCrawlInvalidQueue() {
+	invalidQ := &queue.Mock{}
+	s.queues.Invalids = invalidQ
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.UndefinedType,
+		},
+	}
+
+	invalidErr := fmt.Errorf("%w: %s", t.ErrInvalidResource, "test error")
+
+	// Mock assertions
+	s.protocol.
+		On("Stat", mock.Anything, r).
+		Return(invalidErr).
+		Once()
+
+	// Queued with error instead of indexed.
+	invalidQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(i *t.AnnotatedResource) bool {
+			return s.Equal(r.ID, i.ID) && s.Equal(invalidErr.Error(), i.Error)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	invalidQ.AssertExpectations(s.T())
+}
+
+func (s *CrawlerTestSuite) TestIndexInvalid() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Error: "test error",
+	}
+
+	s.invalidIdx.
+		On("Index", mock.Anything, r.Resource.ID, &indexTypes.Invalid{
+			Error: "test error",
+		}).
+		Return(nil).
+		Once()
+
+	err := s.c.IndexInvalid(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlInvalidCID() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmInvalid",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 400,
+		},
+	}
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Mock assertions
+	s.invalidIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
+			return s.Contains(f.Error, t.ErrInvalidResource.Error())
+		})).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Invalid CIDs should index as invalid, without extraction.
+	s.NoError(err)
+	s.assertExpectations()
+	s.extractor.AssertNotCalled(s.T(), "Extract", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func (s *CrawlerTestSuite) TestCrawlPartialType() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -318,7 +420,8 @@ func (s *CrawlerTestSuite) TestCrawlFileType() {
 		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
 			return s.Equal(f.Metadata, testMetadata) &&
 				s.Equal(f.Content, "testContent") &&
-				s.Equal(f.Size, uint64(15))
+				s.Equal(f.Size, uint64(15)) &&
+				s.Equal(f.ExtractorVersion, extractor.Version)
 		})).
 		Return(nil).
 		Once()
@@ -333,29 +436,21 @@ func (s *CrawlerTestSuite) TestCrawlFileType() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlLargeFile() {
+func (s *CrawlerTestSuite) TestCrawlEmptyFileCID() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
-		},
-		Stat: t.Stat{
-			Type: t.FileType,
-			Size: 15,
+			ID:       "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH",
 		},
 	}
 
-	largeFileErr := fmt.Errorf("blabla %w", extractor.ErrFileTooLarge)
-
-	s.extractor.
-		On("Extract", mock.Anything, r, mock.Anything).
-		Return(largeFileErr).
-		Once()
-
-	s.invalidIdx.
-		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
-			return s.Equal("resource invalid: blabla file too large", f.Error)
+	// Mock assertions; no Stat, extraction or DAG statistics.
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.True(f.Empty) &&
+				s.Equal(uint64(0), f.Size) &&
+				s.Zero(f.ExtractorVersion)
 		})).
 		Return(nil).
 		Once()
@@ -370,69 +465,95 @@ func (s *CrawlerTestSuite) TestCrawlLargeFile() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlStatTimeout() {
+func (s *CrawlerTestSuite) TestCrawlEmptyFileSkip() {
+	s.cfg.EmptyFiles = EmptyFilesSkip
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			ID:       "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
 		},
-		Stat: t.Stat{
-			Type: t.UndefinedType,
+	}
+
+	// Crawl; no calls to IPFS or indexes.
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlEmptyFileStatSkip() {
+	s.cfg.EmptyFiles = EmptyFilesSkip
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
 	}
 
+	s.assertNotExists(r.Resource.ID)
+
 	s.protocol.
 		On("Stat", mock.Anything, r).
-		Return(context.DeadlineExceeded).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*t.AnnotatedResource).Stat = t.Stat{
+				Type: t.FileType,
+			}
+		}).
+		Return(nil).
 		Once()
 
-	s.assertNotExists(r.Resource.ID)
-
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
 
-	s.Equal(err, context.DeadlineExceeded)
+	// Test result, side effects
+	s.NoError(err)
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlReferencedFile() {
+func (s *CrawlerTestSuite) TestCrawlFileDocumentIDs() {
+	s.cfg.DocumentIDs = DocumentIDCIDName
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, nil, s.instr)
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			ID:       "QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p",
 		},
 		Reference: t.Reference{
 			Parent: &t.Resource{
 				Protocol: t.IPFSProtocol,
 				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 			},
-			Name: "fileName.pdf",
+			Name: "readme.md",
 		},
 		Stat: t.Stat{
 			Type: t.FileType,
+			Size: 15,
 		},
 	}
 
+	id := "17e611c75a5abe11be47c72480d0ded486dc35a0d56ee3fb891fa11c92f76e49"
+
 	s.extractor.
 		On("Extract", mock.Anything, r, mock.Anything).
 		Return(nil).
 		Once()
 
+	// Looked up and indexed by the same ID, storing the CID and sorting by ID.
 	s.fileIdx.
-		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
-			return s.Equal(f.References, indexTypes.References{
-				indexTypes.Reference{
-					ParentHash: r.Reference.Parent.ID,
-					Name:       r.Reference.Name,
-				},
-			})
+		On("Index", mock.Anything, id, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal(r.ID, f.CID) && s.Equal(id, f.SortKey)
 		})).
 		Return(nil).
 		Once()
 
-	s.assertNotExists(r.Resource.ID)
+	s.assertNotExists(id)
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
@@ -442,7 +563,9 @@ func (s *CrawlerTestSuite) TestCrawlReferencedFile() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlReferencedDirectory() {
+func (s *CrawlerTestSuite) TestCrawlFileWithoutExtractor() {
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, nil, nil, nil, nil, s.instr)
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
@@ -450,31 +573,22 @@ func (s *CrawlerTestSuite) TestCrawlReferencedDirectory() {
 			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
 		Reference: t.Reference{
-			Parent: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
-			},
-			Name: "dirName",
+			Name: "index.html",
 		},
 		Stat: t.Stat{
-			Type: t.DirectoryType,
+			Type: t.FileType,
+			Size: 15,
 		},
 	}
 
-	// Empty dir
-	s.protocol.
-		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
-		Return(nil).
-		Once()
-
-	s.dirIdx.
-		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
-			return s.Equal(f.References, indexTypes.References{
-				indexTypes.Reference{
-					ParentHash: r.Reference.Parent.ID,
-					Name:       r.Reference.Name,
-				},
-			})
+	// Basic metadata is indexed nonetheless.
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal(uint64(15), f.Size) &&
+				s.Equal("text/html", f.MimeType) &&
+				s.Equal("document", f.Category) &&
+				s.Empty(f.Content) &&
+				s.Zero(f.ExtractorVersion)
 		})).
 		Return(nil).
 		Once()
@@ -489,7 +603,10 @@ func (s *CrawlerTestSuite) TestCrawlReferencedDirectory() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlDirectoryType() {
+func (s *CrawlerTestSuite) TestCrawlDagStats() {
+	s.cfg.DagStats = true
+	s.cfg.DagStatsSampleRate = 1
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
@@ -497,144 +614,2003 @@ func (s *CrawlerTestSuite) TestCrawlDirectoryType() {
 			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
 		Stat: t.Stat{
-			Type: t.DirectoryType,
-			Size: 23,
+			Type: t.FileType,
+			Size: 15,
 		},
 	}
 
-	// Mock assertions
-	fileEntry := t.AnnotatedResource{
+	s.protocol.
+		On("DagStat", mock.Anything, r, s.cfg.DagStatsMaxDepth, s.cfg.DagStatsMaxBlocks).
+		Return(&t.DagStat{Blocks: 3, Size: 120, Depth: 1, Truncated: true}, nil).
+		Once()
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal(uint64(3), f.BlockCount) &&
+				s.Equal(uint64(120), f.DagSize) &&
+				s.Equal(uint(1), f.DagDepth) &&
+				s.True(f.DagTruncated)
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlDagStatsError() {
+	s.cfg.DagStats = true
+	s.cfg.DagStatsSampleRate = 1
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
-		},
-		Reference: t.Reference{
-			Parent: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
-			},
-			Name: "fileName.pdf",
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
 		Stat: t.Stat{
 			Type: t.FileType,
-			Size: 3431,
+			Size: 400,
 		},
 	}
 
-	dirEntry := t.AnnotatedResource{
+	// Failing to get DAG statistics does not prevent indexing.
+	s.protocol.
+		On("DagStat", mock.Anything, r, mock.Anything, mock.Anything).
+		Return((*t.DagStat)(nil), errors.New("refs failed")).
+		Once()
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Zero(f.BlockCount)
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlTransform() {
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, transform.Chain{
+		func(m indexTypes.Metadata) {
+			delete(m, "author")
+		},
+	}, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
-		},
-		Reference: t.Reference{
-			Parent: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
-			},
-			Name: "dirName",
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
 		Stat: t.Stat{
-			Type: t.DirectoryType,
-			Size: 4534543,
+			Type: t.FileType,
+			Size: 400,
 		},
 	}
 
-	unsupportedEntry := t.AnnotatedResource{
-		Resource: &t.Resource{
-			Protocol: t.IPFSProtocol,
-			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Run(func(args mock.Arguments) {
+			f := args.Get(2).(*indexTypes.File)
+			f.Metadata = indexTypes.Metadata{
+				"author":       "Jane Doe",
+				"Content-Type": "text/plain",
+			}
+		}).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal(indexTypes.Metadata{"Content-Type": "text/plain"}, f.Metadata)
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlLargeFile() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
 		Stat: t.Stat{
-			Type: t.UnsupportedType,
+			Type: t.FileType,
+			Size: 15,
 		},
 	}
 
-	unknownEntry := t.AnnotatedResource{
+	largeFileErr := fmt.Errorf("blabla %w", extractor.ErrFileTooLarge)
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(largeFileErr).
+		Once()
+
+	s.invalidIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
+			return s.Equal("resource invalid: blabla file too large", f.Error)
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlUnextractableFile() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
 		Stat: t.Stat{
-			Type: t.UndefinedType,
+			Type: t.FileType,
+			Size: 15,
 		},
 	}
 
-	s.protocol.
-		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
-		Run(func(args mock.Arguments) {
-			// Write bogus directory entry
-			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
-			entryChan <- &fileEntry
-			entryChan <- &dirEntry
-			entryChan <- &unsupportedEntry
-			entryChan <- &unknownEntry
-		}).
-		Return(nil).
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(fmt.Errorf("%w: status 422", extractor.ErrUnextractable)).
 		Once()
 
-	s.dirIdx.
-		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
-			return s.Equal(f.Size, r.Size) &&
-				s.Equal(f.Links, indexTypes.Links{
-					indexTypes.Link{
-						Hash: fileEntry.ID,
-						Name: fileEntry.Reference.Name,
-						Size: fileEntry.Size,
-						Type: indexTypes.FileLinkType,
-					},
-					indexTypes.Link{
-						Hash: dirEntry.ID,
-						Name: dirEntry.Reference.Name,
-						Size: dirEntry.Size,
-						Type: indexTypes.DirectoryLinkType,
-					},
-					indexTypes.Link{
-						Hash: unsupportedEntry.ID,
-						Name: unsupportedEntry.Reference.Name,
-						Size: unsupportedEntry.Size,
-						Type: indexTypes.UnsupportedLinkType,
-					},
-					indexTypes.Link{
-						Hash: unknownEntry.ID,
-						Name: unknownEntry.Reference.Name,
-						Size: unknownEntry.Size,
-						Type: indexTypes.UnknownLinkType,
-					},
-				})
+	s.invalidIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
+			return s.Equal("unsupported type: unexpected response from backend: unextractable content: status 422", f.Error)
 		})).
 		Return(nil).
 		Once()
 
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlExtractionFailed() {
+	s.cfg.MaxExtractionAttempts = 3
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 15,
+		},
+		Attempts: 1,
+	}
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(extractor.ErrRequest).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Transient failures are retried, without indexing.
+	s.True(errors.Is(err, ErrExtractionFailed))
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlMaxExtractionAttempts() {
+	s.cfg.MaxExtractionAttempts = 3
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 15,
+		},
+		Attempts: 2,
+	}
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(extractor.ErrRequest).
+		Once()
+
 	s.invalidIdx.
-		On("Index", mock.Anything, unsupportedEntry.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
-			return s.Equal("unsupported type", f.Error)
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
+			return s.Equal("unsupported type after 3 attempts: request error", f.Error)
 		})).
 		Return(nil).
 		Once()
 
-	s.fileQ.
-		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
-			return s.Equal(fileEntry, *f)
-		}), mock.AnythingOfType("uint8")).
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlStatTimeout() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.UndefinedType,
+		},
+	}
+
+	s.protocol.
+		On("Stat", mock.Anything, r).
+		Return(context.DeadlineExceeded).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	s.Equal(err, context.DeadlineExceeded)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlReferencedFile() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 400,
+		},
+	}
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
 		Return(nil).
 		Once()
 
-	s.dirQ.
-		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
-			return s.Equal(dirEntry, *f)
-		}), mock.AnythingOfType("uint8")).
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal(f.References, indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: r.Reference.Parent.ID,
+					Name:       r.Reference.Name,
+				},
+			})
+		})).
 		Return(nil).
 		Once()
 
-	s.hashQ.
-		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
-			return s.Equal(unknownEntry, *f)
-		}), mock.AnythingOfType("uint8")).
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlVideoSubtitles() {
+	s.cfg.ExtractSubtitles = true
+
+	parent := &t.Resource{
+		Protocol: t.IPFSProtocol,
+		ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+	}
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   "movie.mp4",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 400,
+		},
+	}
+
+	subEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   "movie.en.srt",
+		},
+	}
+
+	otherEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmehHHRh1a7u66r7fugebp6f6wGNMGCa7eho9cgjwhAcm2",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   "other.srt",
+		},
+	}
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Run(func(args mock.Arguments) {
+			f := args.Get(2).(*indexTypes.File)
+			f.Metadata = indexTypes.Metadata{
+				"Content-Type": []interface{}{"video/mp4"},
+			}
+		}).
+		Return(nil).
+		Once()
+
+	s.protocol.
+		On("Ls", mock.Anything, mock.MatchedBy(func(d *t.AnnotatedResource) bool {
+			return d.Resource == parent
+		}), mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- r
+			entryChan <- &subEntry
+			entryChan <- &otherEntry
+		}).
+		Return(nil).
+		Once()
+
+	s.extractor.
+		On("Extract", mock.Anything, &subEntry, mock.Anything).
+		Run(func(args mock.Arguments) {
+			f := args.Get(2).(*indexTypes.File)
+			f.Content = "Hello there."
+		}).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal("Hello there.", f.Subtitles)
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlReferencedDirectory() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "dirName",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	// Empty dir
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Return(nil).
+		Once()
+
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
+			return s.Equal(f.References, indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: r.Reference.Parent.ID,
+					Name:       r.Reference.Name,
+				},
+			})
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryType() {
+	// Entries share a hash; keep all of them, as their types are tested.
+	s.cfg.LinkDedup = LinkDedupNone
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 23,
+		},
+	}
+
+	// Mock assertions
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	dirEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "dirName",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 4534543,
+		},
+	}
+
+	unsupportedEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Stat: t.Stat{
+			Type: t.UnsupportedType,
+		},
+	}
+
+	unknownEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Stat: t.Stat{
+			Type: t.UndefinedType,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			// Write bogus directory entry
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &fileEntry
+			entryChan <- &dirEntry
+			entryChan <- &unsupportedEntry
+			entryChan <- &unknownEntry
+		}).
+		Return(nil).
+		Once()
+
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
+			return s.Equal(f.Size, r.Size) &&
+				s.Equal(uint64(4), f.LinkCount) &&
+				s.Equal(f.Links, indexTypes.Links{
+					indexTypes.Link{
+						Hash: fileEntry.ID,
+						Name: fileEntry.Reference.Name,
+						Size: fileEntry.Size,
+						Type: indexTypes.FileLinkType,
+					},
+					indexTypes.Link{
+						Hash: dirEntry.ID,
+						Name: dirEntry.Reference.Name,
+						Size: dirEntry.Size,
+						Type: indexTypes.DirectoryLinkType,
+					},
+					indexTypes.Link{
+						Hash: unsupportedEntry.ID,
+						Name: unsupportedEntry.Reference.Name,
+						Size: unsupportedEntry.Size,
+						Type: indexTypes.UnsupportedLinkType,
+					},
+					indexTypes.Link{
+						Hash: unknownEntry.ID,
+						Name: unknownEntry.Reference.Name,
+						Size: unknownEntry.Size,
+						Type: indexTypes.UnknownLinkType,
+					},
+				})
+		})).
+		Return(nil).
+		Once()
+
+	s.invalidIdx.
+		On("Index", mock.Anything, unsupportedEntry.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
+			return s.Equal("unsupported type", f.Error)
+		})).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(fileEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.dirQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(dirEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.hashQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(unknownEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryMaxLinks() {
+	s.cfg.MaxLinks = 1
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	parent := &t.Resource{
+		Protocol: t.IPFSProtocol,
+		ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+	}
+
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	dirEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   "dirName",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &fileEntry
+			entryChan <- &dirEntry
+		}).
+		Return(nil).
+		Once()
+
+	// Only the first link is stored, but all are counted.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
+			return s.Equal(uint64(2), f.LinkCount) &&
+				s.Equal(indexTypes.Links{
+					indexTypes.Link{
+						Hash: fileEntry.ID,
+						Name: fileEntry.Reference.Name,
+						Size: fileEntry.Size,
+						Type: indexTypes.FileLinkType,
+					},
+				}, f.Links)
+		})).
+		Return(nil).
+		Once()
+
+	// All entries are crawled nonetheless.
+	s.fileQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(fileEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.dirQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(dirEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryLargeDirSkip() {
+	s.cfg.LargeDirThreshold = 1
+	s.cfg.LargeDirPolicy = LargeDirSkip
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	parent := &t.Resource{
+		Protocol: t.IPFSProtocol,
+		ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+	}
+
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	dirEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   "dirName",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &fileEntry
+			entryChan <- &dirEntry
+		}).
+		Return(nil).
+		Once()
+
+	// All links are stored and counted, recording the policy.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Directory) bool {
+			return s.Equal(uint64(2), f.LinkCount) &&
+				s.Len(f.Links, 2) &&
+				s.Equal(LargeDirSkip, f.LargeDirPolicy)
+		})).
+		Return(nil).
+		Once()
+
+	// Only the entry up to the threshold is crawled.
+	s.fileQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(fileEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	s.dirQ.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryDuplicateLinks() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	parent := &t.Resource{
+		Protocol: t.IPFSProtocol,
+		ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+	}
+
+	// The same file under two names.
+	makeEntry := func(name string) t.AnnotatedResource {
+		return t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+			},
+			Reference: t.Reference{
+				Parent: parent,
+				Name:   name,
+			},
+			Stat: t.Stat{
+				Type: t.FileType,
+				Size: 3431,
+			},
+		}
+	}
+	entry, duplicate := makeEntry("fileName.pdf"), makeEntry("copy.pdf")
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &entry
+			entryChan <- &duplicate
+		}).
+		Return(nil).
+		Once()
+
+	// Only the first link is kept.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(d *indexTypes.Directory) bool {
+			return s.Equal(uint64(1), d.LinkCount) &&
+				s.Equal(uint64(1), d.DuplicateLinkCount) &&
+				s.Equal(indexTypes.Links{
+					indexTypes.Link{
+						Hash: entry.ID,
+						Name: "fileName.pdf",
+						Size: entry.Size,
+						Type: indexTypes.FileLinkType,
+					},
+				}, d.Links)
+		})).
+		Return(nil).
+		Once()
+
+	// Both are crawled, adding both references.
+	s.fileQ.
+		On("Publish", mock.Anything, mock.AnythingOfType("*types.AnnotatedResource"), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Twice()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryTitle() {
+	s.cfg.DirectoryTitles = true
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	parent := &t.Resource{
+		Protocol: t.IPFSProtocol,
+		ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+	}
+
+	pageEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   "index.html",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &pageEntry
+		}).
+		Return(nil).
+		Once()
+
+	s.extractor.
+		On("Extract", mock.Anything, mock.MatchedBy(func(p *t.AnnotatedResource) bool {
+			return p.ID == pageEntry.ID
+		}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			f := args.Get(2).(*indexTypes.File)
+			f.Metadata = indexTypes.Metadata{
+				"title": []interface{}{" My site "},
+			}
+		}).
+		Return(nil).
+		Once()
+
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(d *indexTypes.Directory) bool {
+			return s.Equal("My site", d.Title)
+		})).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(pageEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryTitleTooLarge() {
+	s.cfg.DirectoryTitles = true
+	s.cfg.MaxIndexPageSize = 1024
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	pageEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "index.html",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &pageEntry
+		}).
+		Return(nil).
+		Once()
+
+	// No extraction; no title.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(d *indexTypes.Directory) bool {
+			return s.Empty(d.Title)
+		})).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.Anything, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+// crawlDirectoryWithEntry crawls a directory with a single file entry named name, returning whether it was indexed as
+// a website.
+func (s *CrawlerTestSuite) crawlDirectoryWithEntry(name string) bool {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	entry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   name,
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &entry
+		}).
+		Return(nil).
+		Once()
+
+	var isWebsite bool
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.AnythingOfType("*types.Directory")).
+		Run(func(args mock.Arguments) {
+			isWebsite = args.Get(2).(*indexTypes.Directory).IsWebsite
+		}).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.Anything, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+
+	return isWebsite
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryWebsite() {
+	// Index pages are matched case-insensitively; titles are disabled, so nothing is extracted.
+	s.True(s.crawlDirectoryWithEntry("Index.HTM"))
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryNotWebsite() {
+	s.False(s.crawlDirectoryWithEntry("readme.md"))
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryWebsiteIndexPageNames() {
+	s.cfg.IndexPageNames = []string{"default.htm"}
+
+	s.False(s.crawlDirectoryWithEntry("index.html"))
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryUnexpectedType() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 23,
+		},
+	}
+
+	unexpectedEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Stat: t.Stat{
+			Type: 255,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			// Write bogus directory entry
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &unexpectedEntry
+		}).
+		Return(nil).
+		Once()
+
+	// Unexpected types yield a panic; undefined behaviour
+	s.Panics(func() { _ = s.c.Crawl(s.ctx, r) })
+}
+
+func (s *CrawlerTestSuite) TestCrawlLargeDirectory() {
+	s.cfg = DefaultConfig()
+
+	// Override MaxDirSize
+	s.cfg.MaxDirSize = 3
+
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 23,
+		},
+	}
+
+	// Mock assertions
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			// Write bogus directory entry
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &fileEntry
+			entryChan <- &fileEntry
+			entryChan <- &fileEntry
+			entryChan <- &fileEntry
+			entryChan <- &fileEntry
+		}).
+		Return(nil).
+		Once()
+
+	s.invalidIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
+			return s.Equal("directory too large", f.Error)
+		})).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(fileEntry, *f)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Times(5)
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirEntryTimeout() {
+	s.cfg = DefaultConfig()
+
+	// Override dir entry timeout
+	s.cfg.DirEntryTimeout = 5 * time.Millisecond
+
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, nil, s.instr)
+
+	entryDelay := 2 * s.cfg.DirEntryTimeout
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	dirEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "dirName",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 4534543,
+		},
+	}
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
+			return s.Equal(*f, fileEntry)
+		}), mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &fileEntry
+			time.Sleep(entryDelay)
+			entryChan <- &dirEntry
+		}).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	s.Equal(err, context.DeadlineExceeded)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+	}
+
+	// File is found, last seen 1 hour
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now().Add(-2 * time.Hour)
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return s.Empty(u.References) &&
+				s.WithinDuration(u.LastSeen, time.Now(), time.Second)
+		})).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlNotUpdateInvalid() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+	}
+
+	// File is found, last seen 1 hour
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(true, nil).
+		Maybe()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlAddReference() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	// File is found, very recently, but a new reference is found.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return s.ElementsMatch(u.References, indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "NewReference.pdf",
+				},
+			}) &&
+				s.WithinDuration(u.LastSeen, time.Now(), time.Second)
+		})).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlAppendReference() {
+	// Files index supporting partial updates.
+	fileIdx := &index.AppenderMock{}
+	s.indexes.Files = fileIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	// File is found, very recently, but a new reference is found.
+	fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	// Only the new reference is appended.
+	fileIdx.
+		On("Append", mock.Anything, r.Resource.ID, mock.MatchedBy(func(set map[string]interface{}) bool {
+			lastSeen, ok := set["last-seen"].(time.Time)
+			// Popularity is derived by the index, from the merged references.
+			_, scored := set["popularity"]
+			return ok && s.WithinDuration(lastSeen, time.Now(), time.Second) && !scored
+		}), map[string][]interface{}{
+			"references": {
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "NewReference.pdf",
+				},
+			},
+		}).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects; re-crawling never extracts.
+	s.NoError(err)
+	s.assertExpectations()
+	fileIdx.AssertExpectations(s.T())
+	fileIdx.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+	s.extractor.AssertNotCalled(s.T(), "Extract", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlIndexedConcurrently() {
+	// Files index creating documents only when they don't exist.
+	fileIdx := &index.CreatorMock{}
+	s.indexes.Files = fileIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 15,
+		},
+	}
+
+	fields := []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}
+
+	concurrentRef := indexTypes.Reference{
+		ParentHash: "QmVHxRocoWgUChLEvfEyDuuD6qJ4PhdDL2dTLcpUy3dSC2",
+		Name:       "ConcurrentReference.pdf",
+	}
+	newRef := indexTypes.Reference{
+		ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+		Name:       "NewReference.pdf",
+	}
+
+	// Not indexed when looked up.
+	fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Once()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Once()
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
+
+	// Another worker indexes it in the meantime.
+	fileIdx.
+		On("Create", mock.Anything, r.Resource.ID, mock.Anything).
+		Return(index.ErrConflict).
+		Once()
+
+	fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{concurrentRef}
+		}).
+		Return(true, nil).
+		Once()
+
+	// Updated instead, keeping the concurrently indexed reference.
+	fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return reflect.DeepEqual(indexTypes.References{concurrentRef, newRef}, u.References)
+		})).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	fileIdx.AssertExpectations(s.T())
+	fileIdx.AssertNotCalled(s.T(), "Index", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlOptimisticUpdateConflict() {
+	// Files index supporting optimistic concurrency control.
+	s.cfg.OptimisticUpdates = true
+	fileIdx := &index.VersionedMock{}
+	s.indexes.Files = fileIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	fields := []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}
+
+	existingRef := indexTypes.Reference{
+		ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+		Name:       "ExistingReference.pdf",
+	}
+	concurrentRef := indexTypes.Reference{
+		ParentHash: "QmVHxRocoWgUChLEvfEyDuuD6qJ4PhdDL2dTLcpUy3dSC2",
+		Name:       "ConcurrentReference.pdf",
+	}
+	newRef := indexTypes.Reference{
+		ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+		Name:       "NewReference.pdf",
+	}
+
+	// File is found with an existing reference.
+	fileIdx.
+		On("GetVersioned", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{existingRef}
+		}).
+		Return(&index.Version{SeqNo: 1, PrimaryTerm: 1}, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Maybe()
+
+	// Another worker adds a reference in the meantime.
+	fileIdx.
+		On("UpdateVersioned", mock.Anything, r.Resource.ID, mock.Anything, &index.Version{SeqNo: 1, PrimaryTerm: 1}).
+		Return(index.ErrConflict).
+		Once()
+
+	fileIdx.
+		On("GetVersioned", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{existingRef, concurrentRef}
+		}).
+		Return(&index.Version{SeqNo: 2, PrimaryTerm: 1}, nil).
+		Once()
+
+	// The retried update keeps the concurrently added reference. Not asserting, as the first update is matched too.
+	fileIdx.
+		On("UpdateVersioned", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return reflect.DeepEqual(indexTypes.References{existingRef, concurrentRef, newRef}, u.References)
+		}), &index.Version{SeqNo: 2, PrimaryTerm: 1}).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	fileIdx.AssertExpectations(s.T())
+	fileIdx.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlOptimisticUpdateMaxConflicts() {
+	s.cfg.OptimisticUpdates = true
+	s.cfg.MaxUpdateConflicts = 1
+	fileIdx := &index.VersionedMock{}
+	s.indexes.Files = fileIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, nil, nil, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	fields := []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}
+
+	// Every update conflicts.
+	fileIdx.
+		On("GetVersioned", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*indexTypes.Update).LastSeen = time.Now()
+		}).
+		Return(&index.Version{SeqNo: 1, PrimaryTerm: 1}, nil).
+		Twice()
+
+	fileIdx.
+		On("UpdateVersioned", mock.Anything, r.Resource.ID, mock.Anything, &index.Version{SeqNo: 1, PrimaryTerm: 1}).
+		Return(index.ErrConflict).
+		Twice()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Giving up after the retry.
+	s.True(errors.Is(err, index.ErrConflict))
+	fileIdx.AssertExpectations(s.T())
+}
+
+func (s *CrawlerTestSuite) TestCrawlUpdateGetError() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	testErr := errors.New("test")
+
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, testErr).
+		Maybe()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.Equal(testErr, err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlUpdateUpdateError() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	// File is found, very recently, but a new reference is found.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	testErr := errors.New("test")
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return s.ElementsMatch(u.References, indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "NewReference.pdf",
+				},
+			}) &&
+				s.WithinDuration(u.LastSeen, time.Now(), time.Second)
+		})).
+		Return(testErr).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.Equal(testErr, err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlSameReference() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	// File is found, very recently, but a new reference is found.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "NewReference.pdf",
+				},
+			}
+			u.RefCount = 1
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	// File is found, very recently, but a new reference is found.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "NewName.pdf",
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
+
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return s.ElementsMatch(u.References, indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "NewName.pdf",
+				},
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "NewReference.pdf",
+				},
+			}) &&
+				s.WithinDuration(u.LastSeen, time.Now(), time.Second)
+		})).
 		Return(nil).
 		Once()
 
-	s.assertNotExists(r.Resource.ID)
-
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
 
@@ -643,50 +2619,65 @@ func (s *CrawlerTestSuite) TestCrawlDirectoryType() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlDirectoryUnexpectedType() {
+func (s *CrawlerTestSuite) TestCrawlMaxReferences() {
+	s.cfg.MaxReferences = 1
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
 			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
-		Stat: t.Stat{
-			Type: t.DirectoryType,
-			Size: 23,
-		},
-	}
-
-	unexpectedEntry := t.AnnotatedResource{
-		Resource: &t.Resource{
-			Protocol: t.IPFSProtocol,
-			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
-		},
-		Stat: t.Stat{
-			Type: 255,
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
 		},
 	}
 
-	s.protocol.
-		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+	// File is found, very recently, with the maximum amount of references; not updating.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
 		Run(func(args mock.Arguments) {
-			// Write bogus directory entry
-			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
-			entryChan <- &unexpectedEntry
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+			}
+			u.RefCount = 1
 		}).
-		Return(nil).
+		Return(true, nil).
 		Once()
 
-	// Unexpected types yield a panic; undefined behaviour
-	s.Panics(func() { _ = s.c.Crawl(s.ctx, r) })
-}
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
 
-func (s *CrawlerTestSuite) TestCrawlLargeDirectory() {
-	s.cfg = DefaultConfig()
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
+		Maybe()
 
-	// Override MaxDirSize
-	s.cfg.MaxDirSize = 3
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+
+	// Having reached the maximum, references are no longer fetched.
+	s.True(s.c.fullRefs.contains(r.Resource.ID))
+}
 
-	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, s.instr)
+func (s *CrawlerTestSuite) TestCrawlMaxPaths() {
+	s.cfg.IndexPaths = true
+	s.cfg.MaxPaths = 1
 
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -694,60 +2685,46 @@ func (s *CrawlerTestSuite) TestCrawlLargeDirectory() {
 			Protocol: t.IPFSProtocol,
 			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
-		Stat: t.Stat{
-			Type: t.DirectoryType,
-			Size: 23,
-		},
-	}
-
-	// Mock assertions
-	fileEntry := t.AnnotatedResource{
-		Resource: &t.Resource{
-			Protocol: t.IPFSProtocol,
-			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
-		},
 		Reference: t.Reference{
 			Parent: &t.Resource{
 				Protocol: t.IPFSProtocol,
-				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
 			},
-			Name: "fileName.pdf",
-		},
-		Stat: t.Stat{
-			Type: t.FileType,
-			Size: 3431,
+			Name: "readme.md",
+			Path: "/ipfs/QmRoot/docs/readme.md",
 		},
 	}
 
-	s.protocol.
-		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+	fields := []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}
+
+	// File is found, very recently, with the reference but the maximum amount of paths; not updating.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Run(func(args mock.Arguments) {
-			// Write bogus directory entry
-			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
-			entryChan <- &fileEntry
-			entryChan <- &fileEntry
-			entryChan <- &fileEntry
-			entryChan <- &fileEntry
-			entryChan <- &fileEntry
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "readme.md",
+					Path:       "/ipfs/QmRoot/docs/readme.md",
+				},
+			}
+			u.RefCount = 1
+			u.Paths = []string{"/ipfs/QmOtherRoot/readme.md"}
 		}).
-		Return(nil).
-		Once()
-
-	s.invalidIdx.
-		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.Invalid) bool {
-			return s.Equal("directory too large", f.Error)
-		})).
-		Return(nil).
+		Return(true, nil).
 		Once()
 
-	s.fileQ.
-		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
-			return s.Equal(fileEntry, *f)
-		}), mock.AnythingOfType("uint8")).
-		Return(nil).
-		Times(5)
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Maybe()
 
-	s.assertNotExists(r.Resource.ID)
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Maybe()
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
@@ -755,17 +2732,11 @@ func (s *CrawlerTestSuite) TestCrawlLargeDirectory() {
 	// Test result, side effects
 	s.NoError(err)
 	s.assertExpectations()
+	s.fileIdx.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
 }
 
-func (s *CrawlerTestSuite) TestCrawlDirEntryTimeout() {
-	s.cfg = DefaultConfig()
-
-	// Override dir entry timeout
-	s.cfg.DirEntryTimeout = 5 * time.Millisecond
-
-	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, s.instr)
-
-	entryDelay := 2 * s.cfg.DirEntryTimeout
+func (s *CrawlerTestSuite) TestCrawlAddReferenceCount() {
+	s.cfg.MaxReferences = 1
 
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -773,107 +2744,112 @@ func (s *CrawlerTestSuite) TestCrawlDirEntryTimeout() {
 			Protocol: t.IPFSProtocol,
 			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
-		Stat: t.Stat{
-			Type: t.DirectoryType,
-		},
-	}
-
-	fileEntry := t.AnnotatedResource{
-		Resource: &t.Resource{
-			Protocol: t.IPFSProtocol,
-			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
-		},
-		Reference: t.Reference{
-			Parent: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
-			},
-			Name: "fileName.pdf",
-		},
-		Stat: t.Stat{
-			Type: t.FileType,
-			Size: 3431,
-		},
-	}
-
-	dirEntry := t.AnnotatedResource{
-		Resource: &t.Resource{
-			Protocol: t.IPFSProtocol,
-			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
-		},
 		Reference: t.Reference{
 			Parent: &t.Resource{
 				Protocol: t.IPFSProtocol,
-				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
 			},
-			Name: "dirName",
-		},
-		Stat: t.Stat{
-			Type: t.DirectoryType,
-			Size: 4534543,
+			Name: "NewReference.pdf",
 		},
 	}
 
-	s.fileQ.
-		On("Publish", mock.Anything, mock.MatchedBy(func(f *t.AnnotatedResource) bool {
-			return s.Equal(*f, fileEntry)
-		}), mock.AnythingOfType("uint8")).
-		Return(nil).
-		Once()
+	fields := []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}
 
-	s.protocol.
-		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+	// File is found, very recently, with the maximum amount of references but without their count.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Run(func(args mock.Arguments) {
-			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
-			entryChan <- &fileEntry
-			time.Sleep(entryDelay)
-			entryChan <- &dirEntry
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+			}
 		}).
-		Return(nil).
+		Return(true, nil).
 		Once()
 
-	s.assertNotExists(r.Resource.ID)
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
+		Maybe()
+
+	// The count is stored.
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return len(u.References) == 1 && u.RefCount == 1 && u.Popularity == math.Log1p(1)
+		})).
+		Return(nil).
+		Once()
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
 
-	s.Equal(err, context.DeadlineExceeded)
+	// Test result, side effects
+	s.NoError(err)
 	s.assertExpectations()
+
+	// Only once the count is stored, references are no longer fetched.
+	s.False(s.c.fullRefs.contains(r.Resource.ID))
 }
 
-func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
+func (s *CrawlerTestSuite) TestCrawlFullReferences() {
+	s.cfg.IndexPaths = true
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
 			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "readme.md",
+			Path: "/ipfs/QmRoot/docs/readme.md",
+		},
 	}
 
-	// File is found, last seen 1 hour
+	// File reached the maximum amount of references before.
+	s.c.fullRefs.add(r.Resource.ID)
+
+	fields := []string{"paths", "ipns_names", "dnslink", "last-seen", "reference_count"}
+
+	// References are not fetched, their count is.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
-			u.LastSeen = time.Now().Add(-2 * time.Hour)
+			u.LastSeen = time.Now()
+			u.Paths = []string{"/ipfs/QmOtherRoot/readme.md"}
+			u.RefCount = 3
 		}).
 		Return(true, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Return(false, nil).
 		Maybe()
 
+	// References are left as they are, and so is the popularity.
 	s.fileIdx.
 		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
-			return s.Empty(u.References) &&
-				s.WithinDuration(u.LastSeen, time.Now(), time.Second)
+			return u.References == nil && u.RefCount == 3 && u.Popularity == math.Log1p(3) && len(u.Paths) == 2
 		})).
 		Return(nil).
 		Once()
@@ -886,31 +2862,62 @@ func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlNotUpdateInvalid() {
+func (s *CrawlerTestSuite) TestCrawlAddPath() {
+	s.cfg.IndexPaths = true
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
 			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "readme.md",
+			Path: "/ipfs/QmRoot/docs/readme.md",
+		},
 	}
 
-	// File is found, last seen 1 hour
+	// File is found with same reference, but a new path is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
-		Return(false, nil).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.LastSeen = time.Now()
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "readme.md",
+				},
+			}
+			u.Paths = []string{"/ipfs/QmOtherRoot/readme.md"}
+		}).
+		Return(true, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
-		Return(true, nil).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
+		Return(false, nil).
 		Maybe()
 
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return s.ElementsMatch(u.Paths, []string{
+				"/ipfs/QmOtherRoot/readme.md",
+				"/ipfs/QmRoot/docs/readme.md",
+			})
+		})).
+		Return(nil).
+		Once()
+
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
 
@@ -919,7 +2926,9 @@ func (s *CrawlerTestSuite) TestCrawlNotUpdateInvalid() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlAddReference() {
+func (s *CrawlerTestSuite) TestCrawlReferencePath() {
+	s.cfg.IndexPaths = true
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
@@ -931,20 +2940,23 @@ func (s *CrawlerTestSuite) TestCrawlAddReference() {
 				Protocol: t.IPFSProtocol,
 				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
 			},
-			Name: "NewReference.pdf",
+			Name: "readme.md",
+			Path: "/ipfs/QmRoot/docs/readme.md",
 		},
 	}
 
-	// File is found, very recently, but a new reference is found.
+	// File is found through the same parent from another tree.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			u.LastSeen = time.Now()
 			u.References = indexTypes.References{
 				indexTypes.Reference{
-					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
-					Name:       "ExistingReference.pdf",
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "readme.md",
+					Path:       "/ipfs/QmOtherRoot/docs/readme.md",
+					Root:       "QmOtherRoot",
 				},
 			}
 		}).
@@ -952,28 +2964,31 @@ func (s *CrawlerTestSuite) TestCrawlAddReference() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}).
 		Return(false, nil).
 		Maybe()
 
 	s.fileIdx.
 		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
 			return s.ElementsMatch(u.References, indexTypes.References{
-				indexTypes.Reference{
-					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
-					Name:       "ExistingReference.pdf",
+				{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "readme.md",
+					Path:       "/ipfs/QmOtherRoot/docs/readme.md",
+					Root:       "QmOtherRoot",
 				},
-				indexTypes.Reference{
+				{
 					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-					Name:       "NewReference.pdf",
+					Name:       "readme.md",
+					Path:       "/ipfs/QmRoot/docs/readme.md",
+					Root:       "QmRoot",
 				},
-			}) &&
-				s.WithinDuration(u.LastSeen, time.Now(), time.Second)
+			})
 		})).
 		Return(nil).
 		Once()
@@ -986,157 +3001,242 @@ func (s *CrawlerTestSuite) TestCrawlAddReference() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlUpdateGetError() {
+func (s *CrawlerTestSuite) TestCrawlIPNSName() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
-		Reference: t.Reference{
-			Parent: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-			},
-			Name: "NewReference.pdf",
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 400,
 		},
+		IPNSName: "/ipns/ipfs.io",
 	}
 
-	testErr := errors.New("test")
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*t.AnnotatedResource).ID = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+		}).
+		Return(nil).
+		Once()
 
-	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
-		Return(false, testErr).
-		Maybe()
+	s.assertNotExists("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp")
 
-	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
-		Return(false, nil).
-		Maybe()
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
 
-	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
-		Return(false, nil).
-		Maybe()
+	s.fileIdx.
+		On("Index", mock.Anything, "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal([]string{"/ipns/ipfs.io"}, f.IPNSNames)
+		})).
+		Return(nil).
+		Once()
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
 
 	// Test result, side effects
-	s.Equal(testErr, err)
+	s.NoError(err)
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlUpdateUpdateError() {
+func (s *CrawlerTestSuite) TestCrawlDNSLink() {
+	s.cfg.DNSLinkTTL = time.Hour
+
+	recrawlQ := &queue.Mock{}
+	s.queues.Recrawls = recrawlQ
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
-		Reference: t.Reference{
-			Parent: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-			},
-			Name: "NewReference.pdf",
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 400,
 		},
+		IPNSName: "/ipns/docs.ipfs.io",
 	}
 
-	// File is found, very recently, but a new reference is found.
-	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+	s.protocol.
+		On("Resolve", mock.Anything, r).
 		Run(func(args mock.Arguments) {
-			u := args.Get(2).(*indexTypes.Update)
-			u.LastSeen = time.Now()
-			u.References = indexTypes.References{
-				indexTypes.Reference{
-					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
-					Name:       "ExistingReference.pdf",
-				},
-			}
+			args.Get(1).(*t.AnnotatedResource).ID = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
 		}).
-		Return(true, nil).
+		Return(nil).
 		Once()
 
-	testErr := errors.New("test")
+	recrawlQ.
+		On("PublishDelayed", mock.Anything, &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "/ipns/docs.ipfs.io",
+			},
+			IPNSName: "/ipns/docs.ipfs.io",
+		}, uint8(1), time.Hour).
+		Return(nil).
+		Once()
 
-	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
-		Return(false, nil).
-		Maybe()
+	s.assertNotExists("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp")
 
-	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
-		Return(false, nil).
-		Maybe()
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
 
 	s.fileIdx.
-		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
-			return s.ElementsMatch(u.References, indexTypes.References{
-				indexTypes.Reference{
-					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
-					Name:       "ExistingReference.pdf",
-				},
-				indexTypes.Reference{
-					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-					Name:       "NewReference.pdf",
-				},
-			}) &&
-				s.WithinDuration(u.LastSeen, time.Now(), time.Second)
+		On("Index", mock.Anything, "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal([]string{"docs.ipfs.io"}, f.DNSLinks)
 		})).
-		Return(testErr).
+		Return(nil).
 		Once()
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
 
 	// Test result, side effects
-	s.Equal(testErr, err)
+	s.NoError(err)
 	s.assertExpectations()
+	recrawlQ.AssertExpectations(s.T())
+
+	// Not scheduled again while pending.
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Get", mock.Anything, "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", mock.Anything, mock.Anything).
+		Return(true, nil).
+		Once()
+
+	s.fileIdx.
+		On("Update", mock.Anything, "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", mock.Anything).
+		Return(nil).
+		Maybe()
+
+	err = s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	recrawlQ.AssertNumberOfCalls(s.T(), "PublishDelayed", 1)
 }
 
-func (s *CrawlerTestSuite) TestCrawlSameReference() {
+func (s *CrawlerTestSuite) TestCrawlDNSLinkFailed() {
+	s.cfg.DNSLinkTTL = time.Hour
+
+	recrawlQ := &queue.Mock{}
+	s.queues.Recrawls = recrawlQ
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
-			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
-		Reference: t.Reference{
-			Parent: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-			},
-			Name: "NewReference.pdf",
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 400,
 		},
+		IPNSName: "/ipns/docs.ipfs.io",
 	}
 
-	// File is found, very recently, but a new reference is found.
-	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+	s.protocol.
+		On("Resolve", mock.Anything, r).
 		Run(func(args mock.Arguments) {
-			u := args.Get(2).(*indexTypes.Update)
-			u.LastSeen = time.Now()
-			u.References = indexTypes.References{
-				indexTypes.Reference{
-					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-					Name:       "NewReference.pdf",
-				},
-			}
+			args.Get(1).(*t.AnnotatedResource).ID = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
 		}).
-		Return(true, nil).
+		Return(nil).
+		Once()
+
+	s.assertNotExists("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp")
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Index", mock.Anything, "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", mock.Anything).
+		Return(errors.New("index unavailable")).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.Error(err)
+	s.assertExpectations()
+	recrawlQ.AssertNotCalled(s.T(), "PublishDelayed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlIPNSUnresolvable() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+		},
+		IPNSName: "/ipns/ipfs.io",
+	}
+
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return(t.ErrUnresolvable).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.True(errors.Is(err, t.ErrUnresolvable))
+	s.assertExpectations()
+}
+
+func (s *CrawlerTestSuite) TestCrawlSource() {
+	s.cfg.Source = "pinning"
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 400,
+		},
+	}
+
+	fields := []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count", "source"}
+
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
+		Return(false, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Return(false, nil).
-		Maybe()
+		Once()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Return(false, nil).
-		Maybe()
+		Once()
+
+	s.extractor.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(f *indexTypes.File) bool {
+			return s.Equal([]string{"pinning"}, f.Sources)
+		})).
+		Return(nil).
+		Once()
 
 	// Crawl
 	err := s.c.Crawl(s.ctx, r)
@@ -1146,61 +3246,43 @@ func (s *CrawlerTestSuite) TestCrawlSameReference() {
 	s.assertExpectations()
 }
 
-func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
+func (s *CrawlerTestSuite) TestCrawlAddSource() {
+	s.cfg.Source = "pinning"
+
 	// Prepare resource
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
 			Protocol: t.IPFSProtocol,
 			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
 		},
-		Reference: t.Reference{
-			Parent: &t.Resource{
-				Protocol: t.IPFSProtocol,
-				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-			},
-			Name: "NewReference.pdf",
-		},
 	}
 
-	// File is found, very recently, but a new reference is found.
+	fields := []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count", "source"}
+
+	// File is found recently, but from another source.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			u.LastSeen = time.Now()
-			u.References = indexTypes.References{
-				indexTypes.Reference{
-					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-					Name:       "NewName.pdf",
-				},
-			}
+			u.Sources = []string{"dht"}
 		}).
 		Return(true, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, &indexTypes.Update{}, fields).
 		Return(false, nil).
 		Maybe()
 
 	s.fileIdx.
 		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
-			return s.ElementsMatch(u.References, indexTypes.References{
-				indexTypes.Reference{
-					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-					Name:       "NewName.pdf",
-				},
-				indexTypes.Reference{
-					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
-					Name:       "NewReference.pdf",
-				},
-			}) &&
-				s.WithinDuration(u.LastSeen, time.Now(), time.Second)
+			return s.Equal([]string{"dht", "pinning"}, u.Sources)
 		})).
 		Return(nil).
 		Once()
@@ -1213,6 +3295,36 @@ func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
 	s.assertExpectations()
 }
 
+func (s *CrawlerTestSuite) TestCrawlDenied() {
+	f, err := ioutil.TempFile("", "denylist")
+	s.Require().NoError(err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp\n")
+	s.Require().NoError(err)
+	f.Close()
+
+	deny, err := denylist.New(f.Name())
+	s.Require().NoError(err)
+
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, s.extractor, deny, nil, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+	}
+
+	// Crawl
+	err = s.c.Crawl(s.ctx, r)
+
+	// Test result; nothing is fetched nor indexed.
+	s.True(errors.Is(err, t.ErrDenied))
+	s.assertExpectations()
+}
+
 func TestCrawlerTestSuite(t *testing.T) {
 	suite.Run(t, new(CrawlerTestSuite))
 }
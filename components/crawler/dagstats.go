@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"context"
+	"math/rand"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// setDagStats sets DAG statistics on d for a sample of resources. Errors are logged rather than returned, as the
+// statistics are not essential to the document.
+func (c *Crawler) setDagStats(ctx context.Context, r *t.AnnotatedResource, d *indexTypes.Document) {
+	if !c.config.DagStats || rand.Float64() >= c.config.DagStatsSampleRate {
+		return
+	}
+
+	ctx, span := c.Tracer.Start(ctx, "crawler.setDagStats")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.StatTimeout)
+	defer cancel()
+
+	stat, err := c.protocol.DagStat(ctx, r, c.config.DagStatsMaxDepth, c.config.DagStatsMaxBlocks)
+	if err != nil {
+		logger.Warnf("Error getting DAG statistics for %v: %v", r, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return
+	}
+
+	if stat.Truncated {
+		span.AddEvent(ctx, "dag-truncated")
+	}
+
+	d.BlockCount = stat.Blocks
+	d.DagSize = stat.Size
+	d.DagDepth = stat.Depth
+	d.DagTruncated = stat.Truncated
+}
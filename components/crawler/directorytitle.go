@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// indexPageRank returns the preference of name as an index page, its (case-insensitive) position in IndexPageNames,
+// or -1 when it is not one.
+func (c *Crawler) indexPageRank(name string) int {
+	for i, n := range c.config.IndexPageNames {
+		if strings.EqualFold(name, n) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isPreferredIndexPage returns true when e is an index page preferred over current, which may be nil.
+func (c *Crawler) isPreferredIndexPage(e *t.AnnotatedResource, current *t.AnnotatedResource) bool {
+	rank := c.indexPageRank(e.Reference.Name)
+	if rank < 0 || e.Type == t.DirectoryType {
+		return false
+	}
+
+	return current == nil || rank < c.indexPageRank(current.Reference.Name)
+}
+
+// pageTitle returns the (trimmed) title extracted from an HTML page.
+func pageTitle(f *indexTypes.File) string {
+	title := metadataString(f, "title")
+	if title == "" {
+		title = metadataString(f, "dc:title")
+	}
+
+	return strings.TrimSpace(title)
+}
+
+// setDirectoryTitle sets the title of a directory to the title of its index page, up to MaxIndexPageSize.
+// Errors are logged but not returned; titles are a nice-to-have.
+func (c *Crawler) setDirectoryTitle(ctx context.Context, page *t.AnnotatedResource, d *indexTypes.Directory) {
+	if !c.config.DirectoryTitles || c.extractor == nil || page == nil {
+		return
+	}
+
+	ctx, span := c.Tracer.Start(ctx, "crawler.setDirectoryTitle")
+	defer span.End()
+
+	// Listings don't necessarily include types and sizes.
+	if err := c.ensureType(ctx, page); err != nil {
+		logger.Warnf("Error getting index page %v: %v", page, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return
+	}
+
+	if page.Type != t.FileType {
+		return
+	}
+
+	if page.Size > uint64(c.config.MaxIndexPageSize) {
+		span.AddEvent(ctx, "index-page-too-large")
+		return
+	}
+
+	f := new(indexTypes.File)
+	if err := c.extractor.Extract(ctx, page, f); err != nil {
+		logger.Warnf("Error extracting title from %v: %v", page, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return
+	}
+
+	d.Title = pageTitle(f)
+}
@@ -0,0 +1,107 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// dnslinkDomain returns the domain of a DNSLink name (e.g. `/ipns/docs.ipfs.io`), or an empty string for other IPNS
+// names. Keys (peer IDs and CIDs) never contain dots, whereas domains do.
+func dnslinkDomain(ipnsName string) string {
+	name := strings.TrimPrefix(ipnsName, "/ipns/")
+	if name == ipnsName {
+		return ""
+	}
+
+	// Ignore any path within the name.
+	name = strings.SplitN(name, "/", 2)[0]
+
+	if !strings.Contains(name, ".") {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// scheduledResolves remembers when DNSLink domains are due to be re-resolved, such that retries, redeliveries and
+// rediscoveries of a domain do not schedule it again while a resolution is pending.
+type scheduledResolves struct {
+	mu      sync.Mutex
+	due     map[string]time.Time
+	expired int // Size of due after expired entries were last forgotten.
+}
+
+func newScheduledResolves() *scheduledResolves {
+	return &scheduledResolves{
+		due: make(map[string]time.Time),
+	}
+}
+
+// schedule records domain as due at now + ttl, returning false when it is already pending.
+func (s *scheduledResolves) schedule(domain string, now time.Time, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if due, ok := s.due[domain]; ok && now.Before(due) {
+		return false
+	}
+
+	// Forget domains no longer pending whenever their number has doubled, amortizing the cost over insertions.
+	if len(s.due) >= 2*s.expired {
+		for d, due := range s.due {
+			if !now.Before(due) {
+				delete(s.due, d)
+			}
+		}
+
+		s.expired = len(s.due)
+	}
+
+	s.due[domain] = now.Add(ttl)
+
+	return true
+}
+
+// forget forgets a scheduled resolution of domain, e.g. when scheduling it failed.
+func (s *scheduledResolves) forget(domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.due, domain)
+}
+
+// scheduleResolve queues the DNSLink name of r to be re-resolved and recrawled after DNSLinkTTL, when enabled and
+// not already pending for its domain. Scheduling errors are logged rather than failing the crawl.
+func (c *Crawler) scheduleResolve(ctx context.Context, r *t.AnnotatedResource) {
+	domain := dnslinkDomain(r.IPNSName)
+	if c.config.DNSLinkTTL == 0 || c.queues.Recrawls == nil || domain == "" {
+		return
+	}
+
+	if !c.scheduled.schedule(domain, time.Now(), c.config.DNSLinkTTL) {
+		logger.Debugf("Resolution of '%s' already scheduled", r.IPNSName)
+		return
+	}
+
+	ctx, span := c.Tracer.Start(ctx, "crawler.scheduleResolve")
+	defer span.End()
+
+	next := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: r.Protocol,
+			ID:       r.IPNSName,
+		},
+		IPNSName: r.IPNSName,
+	}
+
+	if err := c.queues.Recrawls.PublishDelayed(ctx, next, 1, c.config.DNSLinkTTL); err != nil {
+		logger.Warnf("Error scheduling resolution of '%s': %v", r.IPNSName, err)
+		span.RecordError(ctx, err)
+
+		c.scheduled.forget(domain)
+	}
+}
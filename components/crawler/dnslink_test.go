@@ -0,0 +1,44 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSLinkDomain(t *testing.T) {
+	assert.Equal(t, "docs.ipfs.io", dnslinkDomain("/ipns/docs.ipfs.io"))
+	assert.Equal(t, "docs.ipfs.io", dnslinkDomain("/ipns/Docs.IPFS.io./concepts"))
+	assert.Equal(t, "", dnslinkDomain("/ipns/k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8"))
+	assert.Equal(t, "", dnslinkDomain("/ipfs/QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"))
+	assert.Equal(t, "", dnslinkDomain(""))
+}
+
+func TestScheduledResolves(t *testing.T) {
+	s := newScheduledResolves()
+	now := time.Now()
+
+	assert.True(t, s.schedule("docs.ipfs.io", now, time.Hour))
+	assert.False(t, s.schedule("docs.ipfs.io", now.Add(time.Minute), time.Hour))
+	assert.True(t, s.schedule("ipfs.io", now, time.Hour))
+
+	// Due again once the scheduled resolution is.
+	assert.True(t, s.schedule("docs.ipfs.io", now.Add(time.Hour), time.Hour))
+
+	s.forget("ipfs.io")
+	assert.True(t, s.schedule("ipfs.io", now, time.Hour))
+}
+
+func TestScheduledResolvesExpire(t *testing.T) {
+	s := newScheduledResolves()
+	now := time.Now()
+
+	s.schedule("a.io", now, time.Minute)
+	s.schedule("b.io", now, time.Minute)
+	s.schedule("c.io", now.Add(time.Hour), time.Minute)
+
+	assert.NotContains(t, s.due, "a.io")
+	assert.NotContains(t, s.due, "b.io")
+	assert.Contains(t, s.due, "c.io")
+}
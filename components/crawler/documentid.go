@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Strategies for deriving the IDs of indexed documents from resources.
+const (
+	DocumentIDCID        = "cid"         // The CID; a single document is shared by all references to content.
+	DocumentIDCIDVersion = "cid_version" // The CID with DocumentIDVersion, keeping the documents of earlier versions.
+	DocumentIDCIDName    = "cid_name"    // A hash of the CID and name, with a document per name content is referenced by.
+)
+
+// DocumentIDs derives the IDs of documents from resources. Existing documents are looked up, indexed and updated by
+// the same ID, so changing strategies amounts to starting a new index.
+type DocumentIDs interface {
+	ID(r *t.AnnotatedResource) string
+}
+
+type cidIDs struct{}
+
+func (cidIDs) ID(r *t.AnnotatedResource) string {
+	return r.ID
+}
+
+type cidVersionIDs struct {
+	version string
+}
+
+func (i cidVersionIDs) ID(r *t.AnnotatedResource) string {
+	return r.ID + "@" + i.version
+}
+
+type cidNameIDs struct{}
+
+// ID returns the CID for unnamed resources (e.g. roots), which can't be told apart by name.
+func (cidNameIDs) ID(r *t.AnnotatedResource) string {
+	if r.Reference.Name == "" {
+		return r.ID
+	}
+
+	sum := sha256.Sum256([]byte(r.ID + "/" + r.Reference.Name))
+	return hex.EncodeToString(sum[:])
+}
+
+// documentIDs returns the DocumentIDs per strategy.
+var documentIDs = map[string]func(c *Config) DocumentIDs{
+	DocumentIDCID:        func(*Config) DocumentIDs { return cidIDs{} },
+	DocumentIDCIDVersion: func(c *Config) DocumentIDs { return cidVersionIDs{c.DocumentIDVersion} },
+	DocumentIDCIDName:    func(*Config) DocumentIDs { return cidNameIDs{} },
+}
+
+// documentCID returns the CID to store in documents of r, as their ID does not reveal it; empty with DocumentIDCID.
+func (c *Crawler) documentCID(r *t.AnnotatedResource) string {
+	if c.config.DocumentIDs == DocumentIDCID {
+		return ""
+	}
+
+	return r.ID
+}
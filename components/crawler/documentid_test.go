@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+func TestDocumentIDs(test *testing.T) {
+	named := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+			},
+			Name: "readme.md",
+		},
+	}
+
+	unnamed := &t.AnnotatedResource{
+		Resource: named.Resource,
+	}
+
+	cfg := DefaultConfig()
+	cfg.DocumentIDVersion = "v2"
+
+	tests := []struct {
+		strategy string
+		named    string
+		unnamed  string
+	}{
+		{DocumentIDCID, named.ID, named.ID},
+		{DocumentIDCIDVersion, named.ID + "@v2", named.ID + "@v2"},
+		{DocumentIDCIDName, "17e611c75a5abe11be47c72480d0ded486dc35a0d56ee3fb891fa11c92f76e49", named.ID},
+	}
+
+	for _, tt := range tests {
+		ids := documentIDs[tt.strategy](cfg)
+
+		assert.Equal(test, tt.named, ids.ID(named), tt.strategy)
+		assert.Equal(test, tt.unnamed, ids.ID(unnamed), tt.strategy)
+	}
+}
+
+func TestValidateDocumentIDs(test *testing.T) {
+	cfg := DefaultConfig()
+	assert.NoError(test, cfg.Validate())
+
+	cfg.DocumentIDs = "unknown"
+	assert.Error(test, cfg.Validate())
+
+	cfg.DocumentIDs = DocumentIDCIDVersion
+	assert.Error(test, cfg.Validate())
+
+	cfg.DocumentIDVersion = "v2"
+	assert.NoError(test, cfg.Validate())
+}
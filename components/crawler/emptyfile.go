@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	"go.opentelemetry.io/otel/api/trace"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Policies for zero-byte files.
+const (
+	EmptyFilesIndex = "index" // Index as `empty`, without extraction.
+	EmptyFilesSkip  = "skip"  // Don't index.
+)
+
+var emptyFilePolicies = map[string]struct{}{
+	EmptyFilesIndex: {},
+	EmptyFilesSkip:  {},
+}
+
+// emptyFileCID is the well-known CID of the zero-byte UnixFS file, as added by default.
+const emptyFileCID = "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH"
+
+// Multihashes of the zero-byte file as a UnixFS (dag-pb) node and as a raw block.
+var emptyDagPBHash, emptyRawHash = emptyFileHashes()
+
+func emptyFileHashes() (multihash.Multihash, multihash.Multihash) {
+	c, err := cid.Decode(emptyFileCID)
+	if err != nil {
+		// Errors here are programming errors.
+		panic(err)
+	}
+
+	raw, err := multihash.Sum(nil, multihash.SHA2_256, -1)
+	if err != nil {
+		// Errors here are programming errors.
+		panic(err)
+	}
+
+	return c.Hash(), raw
+}
+
+// isEmptyFileCID returns true when id is a well-known CID of the zero-byte file, in any CID version or base.
+func isEmptyFileCID(id string) bool {
+	c, err := cid.Decode(id)
+	if err != nil {
+		return false
+	}
+
+	switch c.Type() {
+	case cid.DagProtobuf:
+		return string(c.Hash()) == string(emptyDagPBHash)
+	case cid.Raw:
+		return string(c.Hash()) == string(emptyRawHash)
+	default:
+		return false
+	}
+}
+
+// isEmptyFile returns true when r is known to be a zero-byte file.
+func isEmptyFile(r *t.AnnotatedResource) bool {
+	return r.Type == t.FileType && r.Size == 0
+}
+
+// skipEmptyFile returns true when r is a zero-byte file which should not be indexed.
+func (c *Crawler) skipEmptyFile(ctx context.Context, r *t.AnnotatedResource) bool {
+	if c.config.EmptyFiles != EmptyFilesSkip || !isEmptyFile(r) {
+		return false
+	}
+
+	logger.Debugf("Skipping empty file %v", r)
+	trace.SpanFromContext(ctx).AddEvent(ctx, "empty-file-skipped")
+
+	return true
+}
@@ -0,0 +1,21 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsEmptyFileCID(t *testing.T) {
+	// CIDv0
+	assert.True(t, isEmptyFileCID("QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH"))
+	// CIDv1 dag-pb
+	assert.True(t, isEmptyFileCID("bafybeif7ztnhq65lumvvtr4ekcwd2ifwgm3awq4zfr3srh462rwyinlb4y"))
+	// CIDv1 raw
+	assert.True(t, isEmptyFileCID("bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"))
+
+	// Empty directory
+	assert.False(t, isEmptyFileCID("QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn"))
+	assert.False(t, isEmptyFileCID("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"))
+	assert.False(t, isEmptyFileCID("invalid"))
+}
@@ -0,0 +1,120 @@
+package crawler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestCrawlEndToEnd(t *testing.T) {
+	const (
+		rootID   = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+		readmeID = "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87"
+		docsID   = "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
+		guideID  = "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8"
+	)
+
+	config := DefaultConfig()
+	config.IndexPaths = true
+
+	h := newHarness(context.Background(), config)
+
+	// /readme.txt, /docs/guide.txt
+	h.addFile(readmeID, "Read me first.")
+	h.addFile(guideID, "A guide to the tree.")
+	h.addDirectory(docsID, harnessEntry{"guide.txt", guideID})
+	h.addDirectory(rootID, harnessEntry{"readme.txt", readmeID}, harnessEntry{"docs", docsID})
+
+	assert.NoError(t, h.queue(rootID))
+
+	crawled, err := h.crawl()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, crawled)
+
+	// Only the root is of undefined type.
+	h.protocol.AssertNumberOfCalls(t, "Stat", 1)
+	h.protocol.AssertNumberOfCalls(t, "Ls", 2)
+	h.extractor.AssertNumberOfCalls(t, "Extract", 2)
+
+	assert.Len(t, h.directories.docs, 2)
+	assert.Len(t, h.files.docs, 2)
+	assert.Empty(t, h.invalids.docs)
+
+	root := h.directories.docs[rootID].(*indexTypes.Directory)
+	assert.Equal(t, uint64(2), root.LinkCount)
+	assert.Empty(t, root.References)
+
+	docs := h.directories.docs[docsID].(*indexTypes.Directory)
+	assert.Equal(t, indexTypes.Links{
+		{Hash: guideID, Name: "guide.txt", Size: 20, Type: indexTypes.FileLinkType},
+	}, docs.Links)
+
+	guide := h.files.docs[guideID].(*indexTypes.File)
+	assert.Equal(t, "A guide to the tree.", guide.Content)
+	assert.Equal(t, []string{"/ipfs/" + rootID + "/docs/guide.txt"}, guide.Paths)
+	assert.Equal(t, indexTypes.References{
+		{ParentHash: docsID, Name: "guide.txt", Path: "/ipfs/" + rootID + "/docs/guide.txt", Root: rootID},
+	}, guide.References)
+}
+
+func TestCrawlEndToEndWebsiteRoots(t *testing.T) {
+	const (
+		rootID      = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+		readmeID    = "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87"
+		siteID      = "QmfH6wcHkDAw76erQUmN7S6JaoNUDJZR72jvnR41hdQQZj"
+		aboutID     = "QmZPRLbXS1XeJhuoLp63Tdaja7bPXHnCmMEfPZWDMBN5dJ"
+		indexID     = "QmQCynPW9aur62vxJPsFNLJcNUrWHfSy9epVdhDtVZ5TLH"
+		assetsID    = "QmXm1yNLBFqWjpYr5k6E8V4xQp3oVCNHU7cdM12oboMjd1"
+		styleID     = "Qmc38o4QmmJZhaVpo5B3JDjJ39e96iAus8JSL8F1Dwvsb5"
+		blogID      = "QmdLz7ZQYpMeJUDgDmALhUU721XofWGbtqEpXZRNHCGZxM"
+		postID      = "QmW2CCQUQEWvK668NEGsswvFnK97xccJRXS93ZVHJfukAN"
+		blogIndexID = "QmdKxq6xbcyvfuYhegXGYnkpznCBK1sShQvMmgSRw9Sdfo"
+	)
+
+	config := DefaultConfig()
+	config.WebsiteRoots = true
+
+	h := newHarness(context.Background(), config)
+
+	// /readme.txt, /site/{about.html,index.html,assets/style.css,blog/{post.html,index.html}}
+	h.addFile(readmeID, "Read me first.")
+	h.addFile(aboutID, "<h1>About</h1>")
+	h.addFile(indexID, "<h1>Welcome</h1>")
+	h.addFile(styleID, "h1 { color: red; }")
+	h.addFile(postID, "<h1>First post</h1>")
+	h.addFile(blogIndexID, "<h1>Blog</h1>")
+	h.addDirectory(assetsID, harnessEntry{"style.css", styleID})
+	h.addDirectory(blogID, harnessEntry{"post.html", postID}, harnessEntry{"index.html", blogIndexID})
+	h.addDirectory(siteID,
+		// Entries listed before the index page are only queued once it is found.
+		harnessEntry{"about.html", aboutID},
+		harnessEntry{"assets", assetsID},
+		harnessEntry{"blog", blogID},
+		harnessEntry{"index.html", indexID},
+	)
+	h.addDirectory(rootID, harnessEntry{"readme.txt", readmeID}, harnessEntry{"site", siteID})
+
+	assert.NoError(t, h.queue(rootID))
+
+	crawled, err := h.crawl()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, crawled)
+
+	websiteRoot := func(id string) string {
+		return h.files.docs[id].(*indexTypes.File).WebsiteRoot
+	}
+
+	// Not under any website.
+	assert.Empty(t, websiteRoot(readmeID))
+
+	assert.Equal(t, siteID, websiteRoot(aboutID))
+	assert.Equal(t, siteID, websiteRoot(indexID))
+	assert.Equal(t, siteID, websiteRoot(styleID))
+
+	// Nested websites are their own root.
+	assert.Equal(t, blogID, websiteRoot(postID))
+	assert.Equal(t, blogID, websiteRoot(blogIndexID))
+}
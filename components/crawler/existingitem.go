@@ -9,27 +9,79 @@ import (
 )
 
 type existingItem struct {
+	id string
+
 	*t.AnnotatedResource
 	index.Index
 	*index_types.Update
+
+	version *index.Version // Set with OptimisticUpdates, for indexes implementing index.Versioned.
+
+	refsOmitted bool // References were not fetched, as the item is known to have reached MaxReferences.
 }
 
-func (c *Crawler) getExistingItem(ctx context.Context, r *t.AnnotatedResource) (*existingItem, error) {
-	indexes := []index.Index{c.indexes.Files, c.indexes.Directories, c.indexes.Invalids}
+// existingFields returns the fields of existing items required for updating them. References are left out for items
+// known to have reached MaxReferences, as they're no longer updated; their reference count is stored as well.
+func (c *Crawler) existingFields(omitRefs bool) []string {
+	fields := []string{"paths", "ipns_names", "dnslink", "last-seen", "reference_count"}
+	if !omitRefs {
+		fields = append([]string{"references"}, fields...)
+	}
+
+	if c.config.Source != "" {
+		fields = append(fields, "source")
+	}
+
+	return fields
+}
 
+func (c *Crawler) getExistingItem(ctx context.Context, r *t.AnnotatedResource) (*existingItem, error) {
 	update := new(index_types.Update)
 
-	index, err := index.MultiGet(ctx, indexes, r.ID, update, "references", "last-seen")
+	id := c.ids.ID(r)
+	indexes := c.lookupIndexes(ctx, id, c.existingIndexes())
+	omitRefs := c.fullRefs.contains(id)
+
+	var (
+		i       index.Index
+		version *index.Version
+		err     error
+	)
+
+	if c.config.OptimisticUpdates {
+		i, version, err = index.MultiGetVersioned(ctx, indexes, id, update, c.existingFields(omitRefs)...)
+	} else {
+		i, err = index.MultiGet(ctx, indexes, id, update, c.existingFields(omitRefs)...)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	if index == nil {
+	if i == nil {
 		// Not found
 		return nil, nil
 	}
 
 	return &existingItem{
-		r, index, update,
+		id, r, i, update, version, omitRefs,
 	}, nil
 }
+
+// refresh re-reads the existing item and its version, after a concurrent modification.
+func (c *Crawler) refresh(ctx context.Context, i *existingItem) error {
+	update := new(index_types.Update)
+
+	version, err := i.Index.(index.Versioned).GetVersioned(ctx, i.id, update, c.existingFields(i.refsOmitted)...)
+	if err != nil {
+		return err
+	}
+
+	if version == nil {
+		return index.ErrNotFound
+	}
+
+	i.Update, i.version = update, version
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// ErrExtractionFailed is returned for transient extraction failures of files, which should be retried with an
+// attempt counter until Config.MaxExtractionAttempts.
+var ErrExtractionFailed = errors.New("extraction failed")
+
+// unindexableCounter counts files indexed as unsupported by reason and MIME type, the latter being limited as it
+// comes from content.
+type unindexableCounter struct {
+	counter   metric.Int64Counter
+	mimeTypes *instr.LabelLimit
+}
+
+func newUnindexableCounter(meter metric.Meter) *unindexableCounter {
+	return &unindexableCounter{
+		counter: metric.Must(meter).NewInt64Counter(
+			"ipfs_search.crawler.unindexable_files",
+			metric.WithDescription("Files indexed as unsupported as their content could not be extracted, by reason and MIME type."),
+		),
+		mimeTypes: instr.NewLabelLimit("mimetype"),
+	}
+}
+
+func (u *unindexableCounter) add(ctx context.Context, r *t.AnnotatedResource, reason string) {
+	u.counter.Add(ctx, 1, label.String("reason", reason), u.mimeTypes.String(r.MimeType))
+}
+
+// extractionFailed returns the error for the failed extraction of r with err. Unextractable files, or files failing
+// on their last attempt, are returned as unsupported (hence indexed as invalid) with the last error. Other failures
+// are returned as ErrExtractionFailed with MaxExtractionAttempts set, or as is otherwise.
+func (c *Crawler) extractionFailed(ctx context.Context, r *t.AnnotatedResource, err error) error {
+	if errors.Is(err, extractor.ErrUnextractable) {
+		c.unindexable.add(ctx, r, "unextractable")
+		return fmt.Errorf("%w: %v", t.ErrUnsupportedType, err)
+	}
+
+	max := c.config.MaxExtractionAttempts
+	if max == 0 {
+		return err
+	}
+
+	attempts := r.Attempts + 1
+	if attempts >= max {
+		logger.Infof("Marking '%s' unindexable after %d extraction attempts: %v", r, attempts, err)
+		c.unindexable.add(ctx, r, "attempts")
+		return fmt.Errorf("%w after %d attempts: %v", t.ErrUnsupportedType, attempts, err)
+	}
+
+	return fmt.Errorf("%w (attempt %d of %d): %v", ErrExtractionFailed, attempts, max, err)
+}
@@ -0,0 +1,46 @@
+package crawler
+
+import (
+	"sync"
+)
+
+// fullReferencesSize bounds the number of documents remembered to have reached MaxReferences.
+const fullReferencesSize = 100000
+
+// fullReferences remembers documents which reached MaxReferences, such that their references, which are no longer
+// updated, are no longer fetched either. When full, arbitrary documents are forgotten for new ones; forgotten documents
+// merely have their references fetched again. It is shared by the workers of a crawler.
+type fullReferences struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+func newFullReferences() *fullReferences {
+	return &fullReferences{
+		ids: make(map[string]struct{}),
+	}
+}
+
+// add remembers that the document with id reached MaxReferences.
+func (f *fullReferences) add(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.ids[id]; !ok && len(f.ids) >= fullReferencesSize {
+		for forgotten := range f.ids {
+			delete(f.ids, forgotten)
+			break
+		}
+	}
+
+	f.ids[id] = struct{}{}
+}
+
+// contains returns true when the document with id is known to have reached MaxReferences.
+func (f *fullReferences) contains(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.ids[id]
+	return ok
+}
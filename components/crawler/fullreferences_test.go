@@ -0,0 +1,38 @@
+package crawler
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullReferences(test *testing.T) {
+	f := newFullReferences()
+
+	assert.False(test, f.contains("QmFull"))
+
+	f.add("QmFull")
+	assert.True(test, f.contains("QmFull"))
+	assert.False(test, f.contains("QmOther"))
+}
+
+func TestFullReferencesBounded(test *testing.T) {
+	f := newFullReferences()
+
+	for i := 0; i < fullReferencesSize; i++ {
+		f.ids[strconv.Itoa(i)] = struct{}{}
+	}
+
+	// Another document is forgotten instead.
+	f.add("QmFull")
+	assert.True(test, f.contains("QmFull"))
+	assert.Len(test, f.ids, fullReferencesSize)
+}
+
+func TestExistingFields(test *testing.T) {
+	c := &Crawler{config: DefaultConfig()}
+
+	assert.Equal(test, []string{"references", "paths", "ipns_names", "dnslink", "last-seen", "reference_count"}, c.existingFields(false))
+	assert.Equal(test, []string{"paths", "ipns_names", "dnslink", "last-seen", "reference_count"}, c.existingFields(true))
+}
@@ -0,0 +1,44 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// validateGatewayURL returns an error when gatewayURL is set but not an absolute http(s) URL.
+func validateGatewayURL(gatewayURL string) error {
+	if gatewayURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(gatewayURL)
+	if err != nil {
+		return fmt.Errorf("invalid gateway_url: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("gateway_url '%s' is not an absolute http(s) URL", gatewayURL)
+	}
+
+	return nil
+}
+
+// gatewayURL returns the URL of r on the public gateway, or an empty string without GatewayURL. Named resources are
+// linked through their parent, i.e. `<gateway>/ipfs/<parent>/<name>`, so that the gateway serves them with their
+// name (and hence content type); others as `<gateway>/ipfs/<cid>`.
+func (c *Crawler) gatewayURL(r *t.AnnotatedResource) string {
+	if c.config.GatewayURL == "" {
+		return ""
+	}
+
+	base := strings.TrimSuffix(c.config.GatewayURL, "/")
+
+	if r.Reference.Parent != nil && r.Reference.Name != "" {
+		return base + "/ipfs/" + r.Reference.Parent.ID + "/" + url.PathEscape(r.Reference.Name)
+	}
+
+	return base + "/ipfs/" + r.ID
+}
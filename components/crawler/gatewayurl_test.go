@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+func TestGatewayURL(test *testing.T) {
+	cfg := DefaultConfig()
+	cfg.GatewayURL = "https://ipfs.io/"
+	c := &Crawler{config: cfg}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+	}
+
+	// Without a name, link the CID itself.
+	assert.Equal(test, "https://ipfs.io/ipfs/QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", c.gatewayURL(r))
+
+	r.Reference = t.Reference{
+		Parent: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmVHxRocoWgUChLEvfEyDuuD6qJ4PhdDL2dTLcpUy3dSC2",
+		},
+		Name: "my report #1?.pdf",
+	}
+
+	assert.Equal(test, "https://ipfs.io/ipfs/QmVHxRocoWgUChLEvfEyDuuD6qJ4PhdDL2dTLcpUy3dSC2/my%20report%20%231%3F.pdf", c.gatewayURL(r))
+}
+
+func TestGatewayURLDisabled(test *testing.T) {
+	c := &Crawler{config: DefaultConfig()}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+	}
+
+	assert.Equal(test, "", c.gatewayURL(r))
+}
+
+func TestValidateGatewayURL(test *testing.T) {
+	assert.NoError(test, validateGatewayURL(""))
+	assert.NoError(test, validateGatewayURL("https://ipfs.io"))
+	assert.NoError(test, validateGatewayURL("http://localhost:8080/"))
+	assert.Error(test, validateGatewayURL("ipfs.io"))
+	assert.Error(test, validateGatewayURL("ftp://ipfs.io"))
+	assert.Error(test, validateGatewayURL("https://ipfs.io/%zz"))
+}
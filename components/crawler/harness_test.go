@@ -0,0 +1,318 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/memory"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// countingQueue counts messages published to a Queue, allowing to tell when all of them have been consumed.
+type countingQueue struct {
+	queue.Queue
+	published *int64
+}
+
+// Publish publishes to the underlying Queue, counting successfully published messages.
+func (q *countingQueue) Publish(ctx context.Context, pub interface{}, priority uint8) error {
+	if err := q.Queue.Publish(ctx, pub, priority); err != nil {
+		return err
+	}
+
+	atomic.AddInt64(q.published, 1)
+
+	return nil
+}
+
+// memoryIndex is an in-memory Index, keeping indexed documents and updates in maps.
+type memoryIndex struct {
+	mu      sync.Mutex
+	docs    map[string]interface{}
+	updates map[string][]interface{}
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{
+		docs:    make(map[string]interface{}),
+		updates: make(map[string][]interface{}),
+	}
+}
+
+// Index stores properties as the document with id.
+func (i *memoryIndex) Index(ctx context.Context, id string, properties interface{}) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.docs[id] = properties
+
+	return nil
+}
+
+// Update records properties as an update to the document with id.
+func (i *memoryIndex) Update(ctx context.Context, id string, properties interface{}) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.docs[id]; !ok {
+		return errors.New("updating non-existent document")
+	}
+
+	i.updates[id] = append(i.updates[id], properties)
+
+	return nil
+}
+
+// Get decodes the document with id into dst through JSON, ignoring fields.
+func (i *memoryIndex) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	doc, ok := i.docs[id]
+	if !ok {
+		return false, nil
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(b, dst)
+}
+
+// harnessNode is a file or directory in the synthetic tree served by the harness.
+type harnessNode struct {
+	stat    t.Stat
+	content string
+	entries []harnessEntry
+}
+
+// harnessEntry is a named directory entry.
+type harnessEntry struct {
+	name string
+	id   string
+}
+
+// harness wires a Crawler to mocked protocol and extractor, in-memory queues and in-memory indexes, allowing
+// to crawl a synthetic tree end to end: consuming from the queues, crawling, indexing and queueing children.
+type harness struct {
+	ctx     context.Context
+	config  *Config
+	crawler *Crawler
+	nodes   map[string]*harnessNode
+
+	protocol  *protocol.Mock
+	extractor *extractor.Mock
+
+	files       *memoryIndex
+	directories *memoryIndex
+	invalids    *memoryIndex
+
+	queues    *Queues
+	fileQ     *memory.Queue
+	dirQ      *memory.Queue
+	hashQ     *memory.Queue
+	published int64
+}
+
+func newHarness(ctx context.Context, config *Config) *harness {
+	h := &harness{
+		ctx:    ctx,
+		config: config,
+		nodes:  make(map[string]*harnessNode),
+
+		protocol:  &protocol.Mock{},
+		extractor: &extractor.Mock{},
+
+		files:       newMemoryIndex(),
+		directories: newMemoryIndex(),
+		invalids:    newMemoryIndex(),
+
+		fileQ: memory.New(64),
+		dirQ:  memory.New(64),
+		hashQ: memory.New(64),
+	}
+
+	indexes := &Indexes{
+		Files:       h.files,
+		Directories: h.directories,
+		Invalids:    h.invalids,
+	}
+
+	h.queues = &Queues{
+		Files:       &countingQueue{h.fileQ, &h.published},
+		Directories: &countingQueue{h.dirQ, &h.published},
+		Hashes:      &countingQueue{h.hashQ, &h.published},
+	}
+
+	h.mockProtocol()
+	h.mockExtractor()
+
+	h.crawler = New(config, indexes, h.queues, h.protocol, h.extractor, nil, nil, nil, instr.New())
+
+	return h
+}
+
+// addFile adds a file with content to the synthetic tree.
+func (h *harness) addFile(id string, content string) {
+	h.nodes[id] = &harnessNode{
+		stat: t.Stat{
+			Type: t.FileType,
+			Size: uint64(len(content)),
+		},
+		content: content,
+	}
+}
+
+// addDirectory adds a directory with entries, which should be added to the tree as well, to the synthetic tree.
+func (h *harness) addDirectory(id string, entries ...harnessEntry) {
+	var size uint64
+	for _, e := range entries {
+		if n, ok := h.nodes[e.id]; ok {
+			size += n.stat.Size
+		}
+	}
+
+	h.nodes[id] = &harnessNode{
+		stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: size,
+		},
+		entries: entries,
+	}
+}
+
+func (h *harness) mockProtocol() {
+	h.protocol.
+		On("Stat", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			r := args.Get(1).(*t.AnnotatedResource)
+			if n, ok := h.nodes[r.ID]; ok {
+				r.Stat = n.stat
+			} else {
+				r.Stat = t.Stat{Type: t.UnsupportedType}
+			}
+		}).
+		Return(nil)
+
+	h.protocol.
+		On("Ls", mock.Anything, mock.Anything, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			r := args.Get(1).(*t.AnnotatedResource)
+			entries := args.Get(2).(chan<- *t.AnnotatedResource)
+
+			for _, e := range h.nodes[r.ID].entries {
+				entry := &t.AnnotatedResource{
+					Resource: &t.Resource{
+						Protocol: t.IPFSProtocol,
+						ID:       e.id,
+					},
+					Reference: t.Reference{
+						Parent: r.Resource,
+						Name:   e.name,
+					},
+				}
+
+				if n, ok := h.nodes[e.id]; ok {
+					entry.Stat = n.stat
+				}
+
+				entries <- entry
+			}
+		}).
+		Return(nil)
+}
+
+func (h *harness) mockExtractor() {
+	h.extractor.
+		On("Extract", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			r := args.Get(1).(*t.AnnotatedResource)
+			f := args.Get(2).(*indexTypes.File)
+			f.Content = h.nodes[r.ID].content
+		}).
+		Return(nil)
+}
+
+// queue publishes a resource of undefined type with id to the hashes queue, as the sniffer would.
+func (h *harness) queue(id string) error {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       id,
+		},
+	}
+
+	return h.queues.Hashes.Publish(h.ctx, r, 9)
+}
+
+// crawl consumes and crawls deliveries from all queues until all published messages have been crawled, returning
+// the number of crawled resources.
+func (h *harness) crawl() (int, error) {
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+
+	consumers := make([]<-chan amqp.Delivery, 0, 3)
+	for _, q := range []queue.Queue{h.hashQ, h.dirQ, h.fileQ} {
+		c, err := q.Consume(ctx)
+		if err != nil {
+			return 0, err
+		}
+		consumers = append(consumers, c)
+	}
+
+	crawled := 0
+
+	// Crawling is synchronous, so children have been published once their parent is crawled.
+	for int64(crawled) < atomic.LoadInt64(&h.published) {
+		var d amqp.Delivery
+
+		select {
+		case <-ctx.Done():
+			return crawled, ctx.Err()
+		case d = <-consumers[0]:
+		case d = <-consumers[1]:
+		case d = <-consumers[2]:
+		}
+
+		r := &t.AnnotatedResource{
+			Resource: &t.Resource{},
+		}
+
+		if err := json.Unmarshal(d.Body, r); err != nil {
+			return crawled, err
+		}
+
+		if err := h.crawler.Crawl(h.ctx, r); err != nil {
+			return crawled, err
+		}
+
+		if err := d.Ack(false); err != nil {
+			return crawled, err
+		}
+
+		crawled++
+	}
+
+	return crawled, nil
+}
+
+// Compile-time assurance that implementations satisfy interfaces.
+var (
+	_ queue.Queue = &countingQueue{}
+	_ index.Index = &memoryIndex{}
+)
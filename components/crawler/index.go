@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/label"
 
 	"github.com/ipfs-search/ipfs-search/components/extractor"
@@ -16,7 +16,33 @@ import (
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
-func makeDocument(r *t.AnnotatedResource) indexTypes.Document {
+// makeReference returns the index representation of r, including its path when indexing paths.
+// It returns false when r has no parent.
+func (c *Crawler) makeReference(r *t.Reference) (indexTypes.Reference, bool) {
+	if r.Parent == nil {
+		return indexTypes.Reference{}, false
+	}
+
+	ref := indexTypes.Reference{
+		ParentHash: r.Parent.ID,
+		Name:       r.Name,
+	}
+
+	if c.config.IndexPaths && r.Path != "" {
+		ref.Path = r.Path
+		ref.Root = pathRoot(r.Path)
+	}
+
+	return ref, true
+}
+
+// makeDocument returns common Document properties for r, returning ErrInvalidResource when r has an invalid CID.
+func (c *Crawler) makeDocument(r *t.AnnotatedResource) (indexTypes.Document, error) {
+	codec, mhType, err := cidProperties(r.ID)
+	if err != nil {
+		return indexTypes.Document{}, err
+	}
+
 	now := time.Now().UTC()
 
 	// Strip milliseconds to cater to legacy ES index format.
@@ -24,29 +50,81 @@ func makeDocument(r *t.AnnotatedResource) indexTypes.Document {
 	now = now.Truncate(time.Second)
 
 	var references []indexTypes.Reference
-	if r.Reference.Parent != nil {
-		references = []indexTypes.Reference{
-			{
-				ParentHash: r.Reference.Parent.ID,
-				Name:       r.Reference.Name,
-			},
-		}
+	if ref, ok := c.makeReference(&r.Reference); ok {
+		references = []indexTypes.Reference{ref}
+	}
+
+	var paths []string
+	if c.config.IndexPaths && r.Reference.Path != "" {
+		paths = []string{r.Reference.Path}
+	}
+
+	var ipnsNames []string
+	if r.IPNSName != "" {
+		ipnsNames = []string{r.IPNSName}
+	}
+
+	var dnslinks []string
+	if domain := dnslinkDomain(r.IPNSName); domain != "" {
+		dnslinks = []string{domain}
+	}
+
+	var sources []string
+	if c.config.Source != "" {
+		sources = []string{c.config.Source}
 	}
 
 	// Common Document properties
 	return indexTypes.Document{
-		FirstSeen:  now,
-		LastSeen:   now,
-		References: references,
-		Size:       r.Size,
-	}
+		FirstSeen:    now,
+		LastSeen:     now,
+		References:   references,
+		Paths:        paths,
+		IPNSNames:    ipnsNames,
+		DNSLinks:     dnslinks,
+		Sources:      sources,
+		RefCount:     referenceCount(references),
+		Popularity:   popularity(referenceCount(references), len(ipnsNames)),
+		Size:         r.Size,
+		SizeBucket:   sizeBucket(r.Size, c.config.SizeBuckets),
+		CID:          c.documentCID(r),
+		CIDCodec:     codec,
+		CIDMultihash: mhType,
+		URL:          c.gatewayURL(r),
+		SortKey:      c.ids.ID(r),
+	}, nil
 }
 
+// indexInvalid queues r to be indexed as invalid because of err or, without an invalids queue, indexes it right away.
 func (c *Crawler) indexInvalid(ctx context.Context, r *t.AnnotatedResource, err error) error {
+	invalid := *r
+	invalid.Error = err.Error()
+
+	if c.queues.Invalids != nil {
+		// Keep recording errors off the crawl path.
+		return c.queues.Invalids.Publish(ctx, &invalid, 1)
+	}
+
+	return c.IndexInvalid(ctx, &invalid)
+}
+
+// IndexInvalid indexes a resource as invalid, with the Error it was queued for.
+func (c *Crawler) IndexInvalid(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := c.Tracer.Start(ctx, "crawler.IndexInvalid",
+		trace.WithAttributes(label.String("cid", r.ID)),
+	)
+	defer span.End()
+
 	// Index unsupported items as invalid.
-	return c.indexes.Invalids.Index(ctx, r.ID, &indexTypes.Invalid{
-		Error: err.Error(),
+	err := c.indexes.Invalids.Index(ctx, c.ids.ID(r), &indexTypes.Invalid{
+		CID:   c.documentCID(r),
+		Error: r.Error,
 	})
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
 }
 
 func (c *Crawler) index(ctx context.Context, r *t.AnnotatedResource) error {
@@ -56,21 +134,57 @@ func (c *Crawler) index(ctx context.Context, r *t.AnnotatedResource) error {
 	defer span.End()
 
 	var (
-		err        error
 		index      index.Index
 		properties interface{}
 	)
 
+	document, err := c.makeDocument(r)
+	if err != nil {
+		logger.Debugf("Indexing invalid '%v', err: %v", r, err)
+		span.RecordError(ctx, err)
+		return c.indexInvalid(ctx, r, err)
+	}
+
+	if (r.Type == t.FileType && !isEmptyFile(r)) || r.Type == t.DirectoryType {
+		c.setDagStats(ctx, r, &document)
+	}
+
 	switch r.Type {
 	case t.FileType:
 		f := &indexTypes.File{
-			Document: makeDocument(r),
+			Document:    document,
+			WebsiteRoot: r.Reference.WebsiteRoot,
 		}
-		err = c.extractor.Extract(ctx, r, f)
+
+		if isEmptyFile(r) {
+			// Nothing to extract.
+			f.Empty = true
+			span.AddEvent(ctx, "empty-file")
+		} else if c.extractor != nil {
+			f.ExtractorVersion = extractor.Version
+			err = c.extractor.Extract(ctx, r, f)
+		} else {
+			// Structure-only crawling; index basic metadata, leaving extractor_version unset.
+			span.AddEvent(ctx, "extraction-disabled")
+		}
+
 		if errors.Is(err, extractor.ErrFileTooLarge) {
 			// Interpret files which are too large as invalid resources; prevent repeated attempts.
 			span.RecordError(ctx, err)
 			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		} else if err != nil && !errors.Is(err, t.ErrInvalidResource) {
+			err = c.extractionFailed(ctx, r, err)
+		}
+
+		if err == nil {
+			f.MimeType = extractor.MimeType(r)
+			c.setCategory(f)
+			setCharset(f)
+			c.extractSubtitles(ctx, r, f)
+			c.setSimhash(f)
+			c.transform.Apply(f.Metadata)
+			c.compressContent(f)
+			c.setContentLanguage(f)
 		}
 
 		index = c.indexes.Files
@@ -78,7 +192,7 @@ func (c *Crawler) index(ctx context.Context, r *t.AnnotatedResource) error {
 
 	case t.DirectoryType:
 		d := &indexTypes.Directory{
-			Document: makeDocument(r),
+			Document: document,
 		}
 		err = c.crawlDir(ctx, r, d)
 
@@ -104,7 +218,7 @@ func (c *Crawler) index(ctx context.Context, r *t.AnnotatedResource) error {
 
 	if err != nil {
 		if errors.Is(err, t.ErrInvalidResource) {
-			log.Printf("Indexing invalid '%v', err: %v", r, err)
+			logger.Debugf("Indexing invalid '%v', err: %v", r, err)
 			span.RecordError(ctx, err)
 			return c.indexInvalid(ctx, r, err)
 		}
@@ -113,5 +227,36 @@ func (c *Crawler) index(ctx context.Context, r *t.AnnotatedResource) error {
 	}
 
 	// Index the result
-	return index.Index(ctx, r.ID, properties)
+	created, err := c.create(ctx, index, r, properties)
+	if err != nil || !created {
+		return err
+	}
+
+	c.notifier.Notify(ctx, r)
+
+	return nil
+}
+
+// create indexes properties as the new document for r, returning false when it has been indexed since it was looked
+// up (or prefetched) and was updated instead. Indexes not implementing index.Creator overwrite such documents.
+func (c *Crawler) create(ctx context.Context, i index.Index, r *t.AnnotatedResource, properties interface{}) (bool, error) {
+	creator, ok := i.(index.Creator)
+	if !ok {
+		return true, i.Index(ctx, c.ids.ID(r), properties)
+	}
+
+	err := creator.Create(ctx, c.ids.ID(r), properties)
+	if !errors.Is(err, index.ErrConflict) {
+		return err == nil, err
+	}
+
+	trace.SpanFromContext(ctx).AddEvent(ctx, "indexed-concurrently")
+
+	exists, err := c.updateMaybeExisting(ctx, r)
+	if err != nil || exists {
+		return false, err
+	}
+
+	// Deleted since.
+	return true, i.Index(ctx, c.ids.ID(r), properties)
 }
@@ -0,0 +1,41 @@
+package crawler
+
+import (
+	"strings"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// Confidences of language detection by ipfs-tika considered reliable enough to apply a language-specific analyzer.
+var languageConfidences = map[string]struct{}{
+	"HIGH":   {},
+	"MEDIUM": {},
+}
+
+// contentLanguage returns the detected language of f when it is one of ContentLanguages, or an empty string.
+func (c *Crawler) contentLanguage(f *indexTypes.File) string {
+	if _, ok := languageConfidences[strings.ToUpper(f.Language.Confidence)]; !ok {
+		return ""
+	}
+
+	language := strings.ToLower(f.Language.Language)
+	for _, l := range c.config.ContentLanguages {
+		if strings.ToLower(l) == language {
+			return language
+		}
+	}
+
+	return ""
+}
+
+// setContentLanguage copies content into `content_lang.<language>`, analyzed for its detected language. Content in
+// other languages, or of which the language is uncertain, is only indexed as `content` with the standard analyzer.
+func (c *Crawler) setContentLanguage(f *indexTypes.File) {
+	if f.Content == "" {
+		return
+	}
+
+	if language := c.contentLanguage(f); language != "" {
+		f.ContentLang = map[string]string{language: f.Content}
+	}
+}
@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestSetContentLanguage(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ContentLanguages = []string{"en", "DE"}
+
+	c := &Crawler{config: cfg}
+
+	f := &indexTypes.File{
+		Content:  "Ein Beispiel",
+		Language: indexTypes.Language{Language: "de", Confidence: "HIGH"},
+	}
+
+	c.setContentLanguage(f)
+
+	assert.Equal(t, map[string]string{"de": "Ein Beispiel"}, f.ContentLang)
+}
+
+func TestSetContentLanguageUnsupported(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ContentLanguages = []string{"en"}
+
+	c := &Crawler{config: cfg}
+
+	f := &indexTypes.File{
+		Content:  "Un exemple",
+		Language: indexTypes.Language{Language: "fr", Confidence: "HIGH"},
+	}
+
+	c.setContentLanguage(f)
+
+	assert.Nil(t, f.ContentLang)
+}
+
+func TestSetContentLanguageUncertain(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ContentLanguages = []string{"en"}
+
+	c := &Crawler{config: cfg}
+
+	f := &indexTypes.File{
+		Content:  "An example",
+		Language: indexTypes.Language{Language: "en", Confidence: "LOW"},
+	}
+
+	c.setContentLanguage(f)
+
+	assert.Nil(t, f.ContentLang)
+}
+
+func TestSetContentLanguageDisabled(t *testing.T) {
+	c := &Crawler{config: DefaultConfig()}
+
+	f := &indexTypes.File{
+		Content:  "An example",
+		Language: indexTypes.Language{Language: "en", Confidence: "HIGH"},
+	}
+
+	c.setContentLanguage(f)
+
+	assert.Nil(t, f.ContentLang)
+}
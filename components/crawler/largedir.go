@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"context"
+	"math/rand"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// Policies for queueing the entries of directories beyond Config.LargeDirThreshold.
+const (
+	LargeDirFull   = "full"   // Queue all entries.
+	LargeDirSkip   = "skip"   // Index the directory without queueing further entries.
+	LargeDirSample = "sample" // Queue a random fraction (LargeDirSampleRate) of further entries.
+)
+
+var largeDirPolicies = map[string]struct{}{
+	LargeDirFull:   {},
+	LargeDirSkip:   {},
+	LargeDirSample: {},
+}
+
+func newLargeDirCounter(meter metric.Meter) metric.Int64Counter {
+	return metric.Must(meter).NewInt64Counter(
+		"ipfs_search.crawler.large_directories",
+		metric.WithDescription("Directories exceeding the large directory threshold, by policy."),
+	)
+}
+
+// skipLargeDirEntry returns true when the entry at position n of a directory should not be queued, according to the
+// large directory policy. Reaching the threshold is recorded in properties.
+func (c *Crawler) skipLargeDirEntry(ctx context.Context, n uint, properties *indexTypes.Directory) bool {
+	threshold := c.config.LargeDirThreshold
+	if threshold == 0 || n < threshold {
+		return false
+	}
+
+	policy := c.config.LargeDirPolicy
+
+	if n == threshold {
+		logger.Infof("Directory has over %d entries, applying large directory policy '%s'.", threshold, policy)
+		c.largeDirs.Add(ctx, 1, label.String("policy", policy))
+		properties.LargeDirPolicy = policy
+	}
+
+	switch policy {
+	case LargeDirSkip:
+		return true
+	case LargeDirSample:
+		return rand.Float64() >= c.config.LargeDirSampleRate
+	default:
+		return false
+	}
+}
@@ -0,0 +1,48 @@
+package crawler
+
+import (
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Modes for deduplicating the links of directories.
+const (
+	LinkDedupHash = "hash" // Links to the same hash, keeping the first name.
+	LinkDedupName = "name" // Links with the same name.
+	LinkDedupNone = "none" // Keep all links.
+)
+
+// linkKeys returns the key identifying duplicate links, per mode.
+var linkKeys = map[string]func(e *t.AnnotatedResource) string{
+	LinkDedupHash: func(e *t.AnnotatedResource) string { return e.ID },
+	LinkDedupName: func(e *t.AnnotatedResource) string { return e.Reference.Name },
+	LinkDedupNone: nil,
+}
+
+// linkDeduper tracks the links seen within a directory.
+type linkDeduper struct {
+	key  func(e *t.AnnotatedResource) string
+	seen map[string]struct{}
+}
+
+func newLinkDeduper(mode string) *linkDeduper {
+	return &linkDeduper{
+		key:  linkKeys[mode],
+		seen: make(map[string]struct{}),
+	}
+}
+
+// isDuplicate returns true when a link to e duplicates one seen before, recording it otherwise.
+func (d *linkDeduper) isDuplicate(e *t.AnnotatedResource) bool {
+	if d.key == nil {
+		return false
+	}
+
+	key := d.key(e)
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+
+	d.seen[key] = struct{}{}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// TestCrawlLegacyMessages crawls messages queued by earlier versions, which carried the unused name of the parent
+// directory as ParentName, along with current messages. The field is ignored when decoding, so that messages still
+// in queues are crawled as before.
+func TestCrawlLegacyMessages(test *testing.T) {
+	const (
+		parentID  = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+		legacyID  = "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87"
+		currentID = "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8"
+	)
+
+	h := newHarness(context.Background(), DefaultConfig())
+
+	h.addFile(legacyID, "Legacy message.")
+	h.addFile(currentID, "Current message.")
+
+	publish := func(id, name string, legacy bool) {
+		r := &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       id,
+			},
+			Reference: t.Reference{
+				Parent: &t.Resource{
+					Protocol: t.IPFSProtocol,
+					ID:       parentID,
+				},
+				Name: name,
+			},
+		}
+
+		body, err := json.Marshal(r)
+		require.NoError(test, err)
+
+		if legacy {
+			var message map[string]interface{}
+			require.NoError(test, json.Unmarshal(body, &message))
+
+			message["ParentName"] = "docs"
+
+			body, err = json.Marshal(message)
+			require.NoError(test, err)
+		}
+
+		require.NoError(test, h.queues.Hashes.Publish(h.ctx, json.RawMessage(body), 9))
+	}
+
+	publish(legacyID, "legacy.txt", true)
+	publish(currentID, "current.txt", false)
+
+	crawled, err := h.crawl()
+	assert.NoError(test, err)
+	assert.Equal(test, 2, crawled)
+
+	legacy := h.files.docs[legacyID].(*indexTypes.File)
+	assert.Equal(test, "Legacy message.", legacy.Content)
+	assert.Equal(test, indexTypes.References{
+		{ParentHash: parentID, Name: "legacy.txt"},
+	}, legacy.References)
+
+	current := h.files.docs[currentID].(*indexTypes.File)
+	assert.Equal(test, indexTypes.References{
+		{ParentHash: parentID, Name: "current.txt"},
+	}, current.References)
+}
@@ -0,0 +1,27 @@
+package crawler
+
+import (
+	"path"
+	"strings"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// resourcePath returns the full path of r from a known root, or its root path when no path is known.
+func resourcePath(r *t.AnnotatedResource) string {
+	if r.Reference.Path != "" {
+		return r.Reference.Path
+	}
+
+	return path.Join("/", r.Protocol.String(), r.ID)
+}
+
+// pathRoot returns the root of a full path, e.g. <root> for /ipfs/<root>/docs/readme.md, or an empty string.
+func pathRoot(p string) string {
+	parts := strings.SplitN(strings.TrimPrefix(p, "/"), "/", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return parts[1]
+}
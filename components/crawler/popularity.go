@@ -0,0 +1,24 @@
+package crawler
+
+import (
+	"math"
+
+	index_types "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// referenceCount returns the number of distinct directories referencing a document.
+func referenceCount(refs index_types.References) int {
+	parents := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		parents[ref.ParentHash] = struct{}{}
+	}
+
+	return len(parents)
+}
+
+// popularity returns the popularity score of a document from its reference count and the number of IPNS names
+// resolving to it. The score grows logarithmically, so that widely shared content doesn't dominate rankings; it is 0
+// for unreferenced documents.
+func popularity(refCount, ipnsNames int) float64 {
+	return math.Log1p(float64(refCount + ipnsNames))
+}
@@ -0,0 +1,37 @@
+package crawler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	index_types "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestReferenceCount(t *testing.T) {
+	assert.Equal(t, 0, referenceCount(nil))
+
+	refs := index_types.References{
+		{ParentHash: "QmParent1", Name: "a.txt"},
+		{ParentHash: "QmParent1", Name: "b.txt"}, // Same directory, counted once.
+		{ParentHash: "QmParent2", Name: "a.txt"},
+	}
+	assert.Equal(t, 2, referenceCount(refs))
+}
+
+func TestPopularity(t *testing.T) {
+	assert.Equal(t, 0.0, popularity(0, 0))
+	assert.Equal(t, math.Log1p(2), popularity(2, 0))
+	assert.Equal(t, math.Log1p(3), popularity(2, 1))
+}
+
+func TestPopularityGrows(t *testing.T) {
+	var last float64
+
+	for refCount := 1; refCount <= 3; refCount++ {
+		score := popularity(refCount, 0)
+		assert.Greater(t, score, last)
+		last = score
+	}
+}
@@ -0,0 +1,175 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// prefetched is the index of a directory entry looked up before crawling it, nil when it was not indexed.
+type prefetched struct {
+	index   index.Index
+	expires time.Time
+}
+
+// prefetchCache remembers which directory entries are indexed, as looked up in batches while listing directories,
+// such that crawling them requires no lookup (when new) or only one in their index (when indexed). It is shared by
+// the workers of a crawler.
+type prefetchCache struct {
+	ttl     time.Duration
+	maxSize int
+
+	mu      sync.Mutex
+	entries map[string]prefetched
+
+	batches metric.Int64Counter
+	lookups metric.Int64Counter
+	saved   metric.Int64Counter
+}
+
+func newPrefetchCache(config *Config, meter metric.Meter) *prefetchCache {
+	m := metric.Must(meter)
+
+	return &prefetchCache{
+		ttl:     config.PrefetchTTL,
+		maxSize: config.PrefetchCacheSize,
+		entries: make(map[string]prefetched),
+		batches: m.NewInt64Counter(
+			"ipfs_search.crawler.prefetch.batches",
+			metric.WithDescription("Batches of directory entries looked up, with a multi get request per index."),
+		),
+		lookups: m.NewInt64Counter(
+			"ipfs_search.crawler.prefetch.lookups",
+			metric.WithDescription("Lookups of existing documents, by prefetched result (new, indexed or miss)."),
+		),
+		saved: m.NewInt64Counter(
+			"ipfs_search.crawler.prefetch.gets_saved",
+			metric.WithDescription("Index get requests spared by prefetched results."),
+		),
+	}
+}
+
+// add remembers which of the documents with ids are in which index, until the TTL expires. When the cache is full,
+// expired entries are forgotten first and further documents are not remembered.
+func (p *prefetchCache) add(ids []string, found map[string]index.Index, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expires := now.Add(p.ttl)
+
+	for _, id := range ids {
+		if len(p.entries) >= p.maxSize {
+			p.expire(now)
+
+			if len(p.entries) >= p.maxSize {
+				return
+			}
+		}
+
+		p.entries[id] = prefetched{found[id], expires}
+	}
+}
+
+// expire forgets expired entries; p.mu must be held.
+func (p *prefetchCache) expire(now time.Time) {
+	for id, e := range p.entries {
+		if now.After(e.expires) {
+			delete(p.entries, id)
+		}
+	}
+}
+
+// take returns the index of the document with id, nil when it was not indexed, forgetting it such that further crawls
+// of the document (e.g. of duplicate entries) look it up afresh. It returns false when the document has not been
+// looked up, or too long ago.
+func (p *prefetchCache) take(id string, now time.Time) (index.Index, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	delete(p.entries, id)
+
+	if now.After(e.expires) {
+		return nil, false
+	}
+
+	return e.index, true
+}
+
+// existingIndexes returns the indexes to look up existing documents in, in order.
+func (c *Crawler) existingIndexes() []index.Index {
+	return []index.Index{c.indexes.Files, c.indexes.Directories, c.indexes.Invalids}
+}
+
+// prefetch looks up which of the directory entries are indexed, with a request per index, remembering the results
+// for crawling them. Errors are logged, leaving entries to be looked up when crawled.
+func (c *Crawler) prefetch(ctx context.Context, entries []*t.AnnotatedResource) {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		// Unsupported and denied entries are not crawled.
+		if e.Type != t.UnsupportedType && !c.denylist.Contains(e.ID) {
+			ids = append(ids, c.ids.ID(e))
+		}
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	found, ok, err := index.MultiExists(ctx, c.existingIndexes(), ids)
+	if !ok {
+		logger.Debugf("Not prefetching directory entries: indexes don't support multi get")
+		return
+	}
+
+	if err != nil {
+		logger.Warnf("Error prefetching %d directory entries: %v", len(ids), err)
+		return
+	}
+
+	c.prefetched.batches.Add(ctx, 1)
+	c.prefetched.add(ids, found, time.Now())
+}
+
+// lookupIndexes returns the indexes to look up the document with id in: none when prefetching found it to be new,
+// only its index when found to be indexed, or all of indexes otherwise.
+// Entries may have been indexed since they were found to be new; see create.
+func (c *Crawler) lookupIndexes(ctx context.Context, id string, indexes []index.Index) []index.Index {
+	if c.config.PrefetchBatchSize == 0 {
+		return indexes
+	}
+
+	p := c.prefetched
+
+	i, ok := p.take(id, time.Now())
+	if !ok {
+		p.lookups.Add(ctx, 1, label.String("result", "miss"))
+		return indexes
+	}
+
+	if i == nil {
+		p.lookups.Add(ctx, 1, label.String("result", "new"))
+		p.saved.Add(ctx, int64(len(indexes)))
+		return nil
+	}
+
+	for n, candidate := range indexes {
+		if candidate == i {
+			p.lookups.Add(ctx, 1, label.String("result", "indexed"))
+			p.saved.Add(ctx, int64(n))
+			return indexes[n : n+1]
+		}
+	}
+
+	return indexes
+}
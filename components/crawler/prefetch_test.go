@@ -0,0 +1,147 @@
+package crawler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+func newPrefetchCrawler(cfg *Config, indexes *Indexes) *Crawler {
+	i := instr.New()
+
+	return &Crawler{
+		config:          cfg,
+		indexes:         indexes,
+		ids:             documentIDs[cfg.DocumentIDs](cfg),
+		prefetched:      newPrefetchCache(cfg, i.Meter),
+		Instrumentation: i,
+	}
+}
+
+func TestPrefetchCache(test *testing.T) {
+	cfg := DefaultConfig()
+	files := &index.Mock{}
+
+	p := newPrefetchCache(cfg, instr.New().Meter)
+	now := time.Now()
+
+	p.add([]string{"new", "indexed"}, map[string]index.Index{"indexed": files}, now)
+
+	i, ok := p.take("indexed", now)
+	assert.True(test, ok)
+	assert.Equal(test, files, i)
+
+	i, ok = p.take("new", now)
+	assert.True(test, ok)
+	assert.Nil(test, i)
+
+	// Taken entries are forgotten.
+	_, ok = p.take("new", now)
+	assert.False(test, ok)
+
+	_, ok = p.take("unknown", now)
+	assert.False(test, ok)
+}
+
+func TestPrefetchCacheExpired(test *testing.T) {
+	cfg := DefaultConfig()
+
+	p := newPrefetchCache(cfg, instr.New().Meter)
+	now := time.Now()
+
+	p.add([]string{"new"}, nil, now)
+
+	_, ok := p.take("new", now.Add(cfg.PrefetchTTL+time.Second))
+	assert.False(test, ok)
+}
+
+func TestPrefetchCacheFull(test *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PrefetchCacheSize = 2
+
+	p := newPrefetchCache(cfg, instr.New().Meter)
+	now := time.Now()
+
+	p.add([]string{"a", "b"}, nil, now)
+	p.add([]string{"c"}, nil, now)
+
+	_, ok := p.take("c", now)
+	assert.False(test, ok)
+
+	// Expired entries make room.
+	later := now.Add(cfg.PrefetchTTL + time.Second)
+	p.add([]string{"c"}, nil, later)
+
+	_, ok = p.take("c", later)
+	assert.True(test, ok)
+}
+
+func TestPrefetch(test *testing.T) {
+	ctx := context.Background()
+
+	cfg := DefaultConfig()
+	cfg.PrefetchBatchSize = 2
+
+	files, dirs, invalids := &index.ExisterMock{}, &index.ExisterMock{}, &index.ExisterMock{}
+	files.Test(test)
+	dirs.Test(test)
+	invalids.Test(test)
+
+	c := newPrefetchCrawler(cfg, &Indexes{Files: files, Directories: dirs, Invalids: invalids})
+
+	entries := []*t.AnnotatedResource{
+		{Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmNew"}},
+		{Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmDir"}},
+		{Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmUnsupported"}, Stat: t.Stat{Type: t.UnsupportedType}},
+	}
+
+	files.On("Exists", ctx, []string{"QmNew", "QmDir"}).Return(map[string]bool{}, nil)
+	dirs.On("Exists", ctx, []string{"QmNew", "QmDir"}).Return(map[string]bool{"QmDir": true}, nil)
+	invalids.On("Exists", ctx, []string{"QmNew"}).Return(map[string]bool{}, nil)
+
+	c.prefetch(ctx, entries)
+
+	all := c.existingIndexes()
+
+	assert.Empty(test, c.lookupIndexes(ctx, "QmNew", all))
+	assert.Equal(test, []index.Index{dirs}, c.lookupIndexes(ctx, "QmDir", all))
+	assert.Equal(test, all, c.lookupIndexes(ctx, "QmUnsupported", all))
+
+	files.AssertExpectations(test)
+	dirs.AssertExpectations(test)
+	invalids.AssertExpectations(test)
+}
+
+func TestPrefetchUnsupported(test *testing.T) {
+	ctx := context.Background()
+
+	cfg := DefaultConfig()
+	cfg.PrefetchBatchSize = 2
+
+	c := newPrefetchCrawler(cfg, &Indexes{Files: &index.Mock{}, Directories: &index.Mock{}, Invalids: &index.Mock{}})
+
+	c.prefetch(ctx, []*t.AnnotatedResource{
+		{Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmNew"}},
+	})
+
+	all := c.existingIndexes()
+	assert.Equal(test, all, c.lookupIndexes(ctx, "QmNew", all))
+}
+
+func TestLookupIndexesDisabled(test *testing.T) {
+	ctx := context.Background()
+
+	cfg := DefaultConfig()
+
+	c := newPrefetchCrawler(cfg, &Indexes{Files: &index.Mock{}, Directories: &index.Mock{}, Invalids: &index.Mock{}})
+	c.prefetched.add([]string{"QmNew"}, nil, time.Now())
+
+	all := c.existingIndexes()
+	assert.Equal(test, all, c.lookupIndexes(ctx, "QmNew", all))
+}
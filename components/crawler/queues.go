@@ -9,4 +9,7 @@ type Queues struct {
 	Files       queue.Queue
 	Directories queue.Queue
 	Hashes      queue.Queue
+	Invalids    queue.Queue // Invalid resources are indexed right away when nil.
+
+	Recrawls queue.DelayedPublisher // Publishes DNSLink names to be re-resolved into the hashes queue; disabled when nil.
 }
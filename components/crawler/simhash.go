@@ -0,0 +1,125 @@
+package crawler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+const (
+	// simhashShingleSize is the amount of words hashed together as a feature.
+	simhashShingleSize = 3
+
+	// simhashBands is the amount of bands the simhash is split into; documents within a Hamming distance smaller
+	// than the amount of bands share at least one band.
+	simhashBands = 4
+)
+
+// binaryTypePrefixes are prefixes of Content-Types of which the extracted text is not meaningful for fingerprinting.
+var binaryTypePrefixes = []string{"image/", "video/", "audio/", "application/octet-stream"}
+
+// contentType returns the extracted Content-Type of f, or an empty string.
+func contentType(f *indexTypes.File) string {
+	return metadataString(f, "Content-Type")
+}
+
+// metadataString returns the (first) value for key in the extracted metadata of f, or an empty string.
+func metadataString(f *indexTypes.File, key string) string {
+	switch v := f.Metadata[key].(type) {
+	case string:
+		return v
+	case []interface{}:
+		if len(v) > 0 {
+			s, _ := v[0].(string)
+			return s
+		}
+	}
+
+	return ""
+}
+
+// isBinary returns true when f has a binary Content-Type.
+func isBinary(f *indexTypes.File) bool {
+	ct := contentType(f)
+
+	for _, prefix := range binaryTypePrefixes {
+		if strings.HasPrefix(ct, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// simhash returns a 64-bit simhash over word shingles of text, and false when text has no words.
+func simhash(text string) (uint64, bool) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	n := simhashShingleSize
+	if len(words) < n {
+		n = len(words)
+	}
+
+	var v [64]int
+
+	for i := 0; i+n <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+n], " ")))
+		sum := h.Sum64()
+
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				v[bit]++
+			} else {
+				v[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit := 0; bit < 64; bit++ {
+		if v[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+
+	return hash, true
+}
+
+// simhashBandValues returns the bands of hash as `<band>:<value>`, such that near-duplicates share at least one value.
+func simhashBandValues(hash uint64) []string {
+	const width = 64 / simhashBands
+
+	bands := make([]string, simhashBands)
+	for i := range bands {
+		value := (hash >> uint(i*width)) & (1<<width - 1)
+		bands[i] = fmt.Sprintf("%d:%0*x", i, width/4, value)
+	}
+
+	return bands
+}
+
+// setSimhash sets the simhash fingerprint of the content of f, considering up to MaxSimhashSize bytes of content.
+func (c *Crawler) setSimhash(f *indexTypes.File) {
+	if !c.config.Simhash || isBinary(f) {
+		return
+	}
+
+	hash, ok := simhash(utils.Truncate(f.Content, int(c.config.MaxSimhashSize)))
+	if !ok {
+		return
+	}
+
+	f.Simhash = fmt.Sprintf("%016x", hash)
+	f.SimhashBands = simhashBandValues(hash)
+}
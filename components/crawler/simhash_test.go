@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+const simhashText = `The InterPlanetary File System is a protocol, hypermedia and file sharing peer-to-peer network
+for storing and sharing data in a distributed file system. IPFS uses content-addressing to uniquely identify each file
+in a global namespace connecting all computing devices.`
+
+func TestSimhashEmpty(t *testing.T) {
+	_, ok := simhash(" -- ")
+	assert.False(t, ok)
+}
+
+func TestSimhashNearDuplicate(t *testing.T) {
+	a, ok := simhash(simhashText)
+	assert.True(t, ok)
+
+	// Case and punctuation are ignored.
+	b, _ := simhash("  " + simhashText + "!!")
+	assert.Equal(t, a, b)
+
+	// Small edits result in small distances.
+	c, _ := simhash(simhashText + " Edited.")
+	assert.LessOrEqual(t, bits.OnesCount64(a^c), 8)
+
+	// Unrelated text results in large distances.
+	d, _ := simhash("Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore.")
+	assert.Greater(t, bits.OnesCount64(a^d), 8)
+}
+
+func TestSimhashBandValues(t *testing.T) {
+	assert.Equal(t, []string{"0:4d3c", "1:2b1a", "2:00ff", "3:0001"}, simhashBandValues(0x000100ff2b1a4d3c))
+}
+
+func TestSetSimhash(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Simhash = true
+	cfg.MaxSimhashSize = 32
+
+	c := &Crawler{config: cfg}
+
+	f := &indexTypes.File{
+		Content:  simhashText,
+		Metadata: indexTypes.Metadata{"Content-Type": []interface{}{"text/plain"}},
+	}
+	c.setSimhash(f)
+
+	// Only the first MaxSimhashSize bytes are used.
+	hash, _ := simhash(simhashText[:32])
+	assert.Len(t, f.Simhash, 16)
+	assert.Equal(t, simhashBandValues(hash), f.SimhashBands)
+
+	// Binary types are skipped.
+	f = &indexTypes.File{
+		Content:  simhashText,
+		Metadata: indexTypes.Metadata{"Content-Type": "image/png"},
+	}
+	c.setSimhash(f)
+
+	assert.Empty(t, f.Simhash)
+}
@@ -0,0 +1,23 @@
+package crawler
+
+import (
+	"github.com/c2h5oh/datasize"
+)
+
+// sizeBucketNames are the names of size buckets, from small to large.
+var sizeBucketNames = []string{"tiny", "small", "medium", "large", "huge"}
+
+// sizeBucket returns the name of the bucket for size, given the (ascending) upper bounds of all but the last
+// bucket. Returns an empty string when no thresholds are configured.
+func sizeBucket(size uint64, thresholds []datasize.ByteSize) string {
+	if len(thresholds) == 0 {
+		return ""
+	}
+
+	i := 0
+	for i < len(thresholds) && i < len(sizeBucketNames)-1 && size >= uint64(thresholds[i]) {
+		i++
+	}
+
+	return sizeBucketNames[i]
+}
@@ -0,0 +1,38 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeBucket(t *testing.T) {
+	thresholds := DefaultConfig().SizeBuckets
+
+	cases := map[uint64]string{
+		0:                      "tiny",
+		16*1024 - 1:            "tiny",
+		16 * 1024:              "small",
+		1024*1024 - 1:          "small",
+		1024 * 1024:            "medium",
+		100 * 1024 * 1024:      "large",
+		1024 * 1024 * 1024:     "huge",
+		5 * 1024 * 1024 * 1024: "huge",
+	}
+
+	for size, expected := range cases {
+		assert.Equal(t, expected, sizeBucket(size, thresholds), "size %d", size)
+	}
+}
+
+func TestSizeBucketDisabled(t *testing.T) {
+	assert.Equal(t, "", sizeBucket(1024, nil))
+}
+
+func TestSizeBucketFewThresholds(t *testing.T) {
+	thresholds := []datasize.ByteSize{datasize.KB}
+
+	assert.Equal(t, "tiny", sizeBucket(10, thresholds))
+	assert.Equal(t, "small", sizeBucket(10*1024, thresholds))
+}
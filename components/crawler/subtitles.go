@@ -0,0 +1,130 @@
+package crawler
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/sync/errgroup"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// subtitleExtensions are the (lowercase) extensions of sidecar subtitle files.
+var subtitleExtensions = []string{".srt", ".vtt"}
+
+// isVideo returns true when the extracted Content-Type of f is a video type.
+func isVideo(f *indexTypes.File) bool {
+	return strings.HasPrefix(contentType(f), "video/")
+}
+
+// isSubtitleFor returns true when name is a sidecar subtitle file for a file with the given base name,
+// e.g. `movie.srt` or `movie.en.vtt` for `movie.mp4`.
+func isSubtitleFor(name string, base string) bool {
+	ext := strings.ToLower(path.Ext(name))
+
+	for _, subExt := range subtitleExtensions {
+		if ext == subExt {
+			name = strings.TrimSuffix(name, path.Ext(name))
+			return name == base || strings.HasPrefix(name, base+".")
+		}
+	}
+
+	return false
+}
+
+// findSubtitles lists the parent directory of r, returning entries which are sidecar subtitles for r.
+func (c *Crawler) findSubtitles(ctx context.Context, r *t.AnnotatedResource) ([]*t.AnnotatedResource, error) {
+	parent := &t.AnnotatedResource{
+		Resource: r.Reference.Parent,
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	base := strings.TrimSuffix(r.Reference.Name, path.Ext(r.Reference.Name))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	entries := make(chan *t.AnnotatedResource, c.config.DirEntryBufferSize)
+
+	wg, ctx := errgroup.WithContext(ctx)
+
+	wg.Go(func() error {
+		defer close(entries)
+		return c.protocol.Ls(ctx, parent, entries)
+	})
+
+	var (
+		subtitles []*t.AnnotatedResource
+		cnt       uint
+	)
+
+	for entry := range entries {
+		if cnt++; cnt > c.config.MaxDirSize {
+			// Don't wade through huge directories looking for subtitles.
+			cancel()
+			break
+		}
+
+		if isSubtitleFor(entry.Reference.Name, base) {
+			subtitles = append(subtitles, entry)
+		}
+	}
+
+	// Drain remaining entries so Ls can return.
+	for range entries {
+	}
+
+	if err := wg.Wait(); err != nil && cnt <= c.config.MaxDirSize {
+		return nil, err
+	}
+
+	return subtitles, nil
+}
+
+// extractSubtitles sets Subtitles on video files from sidecar subtitle files in the parent directory.
+// Errors are logged but not returned; subtitles are a nice-to-have.
+func (c *Crawler) extractSubtitles(ctx context.Context, r *t.AnnotatedResource, f *indexTypes.File) {
+	if !c.config.ExtractSubtitles || c.extractor == nil || r.Reference.Parent == nil || !isVideo(f) {
+		return
+	}
+
+	ctx, span := c.Tracer.Start(ctx, "crawler.extractSubtitles")
+	defer span.End()
+
+	subtitles, err := c.findSubtitles(ctx, r)
+	if err != nil {
+		logger.Warnf("Error listing subtitles for %v: %v", r, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return
+	}
+
+	maxSize := int(c.config.MaxSubtitleSize)
+
+	var b strings.Builder
+	for _, s := range subtitles {
+		sub := new(indexTypes.File)
+		if err := c.extractor.Extract(ctx, s, sub); err != nil {
+			logger.Warnf("Error extracting subtitles from %v: %v", s, err)
+			span.RecordError(ctx, err)
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(sub.Content)
+
+		if b.Len() >= maxSize {
+			break
+		}
+	}
+
+	f.Subtitles = utils.Truncate(b.String(), maxSize)
+}
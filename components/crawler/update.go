@@ -2,39 +2,150 @@ package crawler
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.opentelemetry.io/otel/label"
 
+	"github.com/ipfs-search/ipfs-search/components/index"
 	index_types "github.com/ipfs-search/ipfs-search/components/index/types"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
-func appendReference(refs index_types.References, r *t.Reference) (index_types.References, bool) {
-	if r.Parent == nil {
-		// No new reference, not updating
-		return refs, false
-	}
+// appendReference appends ref to refs, returning true when refs were updated.
+// References with the same parent and name are distinguished by their path, if any; existing references without
+// a path get the path of ref.
+func appendReference(refs index_types.References, ref index_types.Reference) (index_types.References, bool) {
+	pathless := -1
+
+	for i, indexedRef := range refs {
+		if indexedRef.ParentHash != ref.ParentHash || indexedRef.Name != ref.Name {
+			continue
+		}
 
-	for _, indexedRef := range refs {
-		if indexedRef.ParentHash == r.Parent.ID && indexedRef.Name == r.Name {
+		if indexedRef.Path == ref.Path || ref.Path == "" {
 			// Existing reference, not updating
 			return refs, false
 		}
+
+		if indexedRef.Path == "" && pathless == -1 {
+			pathless = i
+		}
+	}
+
+	if pathless != -1 {
+		// Add path context to existing reference
+		refs[pathless].Path = ref.Path
+		refs[pathless].Root = ref.Root
+		return refs, true
+	}
+
+	return append(refs, ref), true
+}
+
+// appendUnique appends v to values, returning true when values were updated.
+func appendUnique(values []string, v string) ([]string, bool) {
+	if v == "" {
+		return values, false
+	}
+
+	for _, existing := range values {
+		if existing == v {
+			// Existing value, not updating
+			return values, false
+		}
 	}
 
-	return append(refs, index_types.Reference{
-		ParentHash: r.Parent.ID,
-		Name:       r.Name,
-	}), true
+	return append(values, v), true
 }
 
-// updateExisting updates known existing items.
+// appendUpdate updates last-seen and appends only the newly added (last) values of the updated fields, rather than
+// writing them in full. The reference count and popularity are updated by the index, from the merged references and
+// IPNS names.
+func appendUpdate(ctx context.Context, a index.Appender, id string, lastSeen time.Time,
+	refs index_types.References, paths, ipnsNames, dnslinks, sources []string,
+	refsUpdated, pathsUpdated, ipnsUpdated, dnslinksUpdated, sourcesUpdated bool) error {
+	add := make(map[string][]interface{})
+
+	if refsUpdated {
+		add["references"] = []interface{}{refs[len(refs)-1]}
+	}
+
+	if pathsUpdated {
+		add["paths"] = []interface{}{paths[len(paths)-1]}
+	}
+
+	if ipnsUpdated {
+		add["ipns_names"] = []interface{}{ipnsNames[len(ipnsNames)-1]}
+	}
+
+	if dnslinksUpdated {
+		add["dnslink"] = []interface{}{dnslinks[len(dnslinks)-1]}
+	}
+
+	if sourcesUpdated {
+		add["source"] = []interface{}{sources[len(sources)-1]}
+	}
+
+	set := map[string]interface{}{
+		"last-seen": lastSeen,
+	}
+
+	return a.Append(ctx, id, set, add)
+}
+
+// updateExisting updates known existing items. It only ever updates references and related fields and never
+// fetches or extracts content; items read with a version are updated provided they have not been modified since,
+// otherwise indexes implementing index.Appender are updated with a partial, script-based update.
 func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 	ctx, span := c.Tracer.Start(ctx, "crawler.updateExisting")
 	defer span.End()
 
-	refs, refsUpdated := appendReference(i.References, &i.AnnotatedResource.Reference)
+	var (
+		refs         = i.References
+		refCount     = i.RefCount
+		refsUpdated  bool
+		countUpdated bool
+	)
+	if i.refsOmitted {
+		span.AddEvent(ctx, "max-references")
+	} else {
+		if c.config.MaxReferences == 0 || uint(len(refs)) < c.config.MaxReferences {
+			if ref, ok := c.makeReference(&i.AnnotatedResource.Reference); ok {
+				refs, refsUpdated = appendReference(refs, ref)
+			}
+		} else {
+			// Bound the size of references, as they're fetched in full for every update.
+			span.AddEvent(ctx, "max-references")
+		}
+
+		// Documents indexed before reference counts were stored get theirs.
+		refCount = referenceCount(refs)
+		countUpdated = refCount != i.RefCount
+
+		if c.config.MaxReferences > 0 && uint(len(refs)) >= c.config.MaxReferences && !countUpdated {
+			// No longer updated, so no longer fetched either; the score is derived from the stored count.
+			c.fullRefs.add(i.id)
+		}
+	}
+
+	var (
+		paths        []string
+		pathsUpdated bool
+	)
+	if c.config.IndexPaths {
+		if c.config.MaxPaths == 0 || uint(len(i.Paths)) < c.config.MaxPaths {
+			paths, pathsUpdated = appendUnique(i.Paths, i.AnnotatedResource.Reference.Path)
+		} else {
+			// Bound the size of paths, as they're fetched in full for every update.
+			span.AddEvent(ctx, "max-paths")
+			paths = i.Paths
+		}
+	}
+
+	ipnsNames, ipnsUpdated := appendUnique(i.IPNSNames, i.AnnotatedResource.IPNSName)
+	dnslinks, dnslinksUpdated := appendUnique(i.DNSLinks, dnslinkDomain(i.AnnotatedResource.IPNSName))
+	sources, sourcesUpdated := appendUnique(i.Sources, c.config.Source)
 
 	now := time.Now()
 
@@ -44,7 +155,7 @@ func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 
 	isRecent := now.Sub(i.LastSeen) > c.config.MinUpdateAge
 
-	if refsUpdated || isRecent {
+	if refsUpdated || countUpdated || pathsUpdated || ipnsUpdated || dnslinksUpdated || sourcesUpdated || isRecent {
 		if span.IsRecording() {
 			var reason string
 
@@ -52,6 +163,26 @@ func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 				reason = "reference-added"
 			}
 
+			if countUpdated && !refsUpdated {
+				reason = "reference-count-added"
+			}
+
+			if pathsUpdated {
+				reason = "path-added"
+			}
+
+			if ipnsUpdated {
+				reason = "ipns-name-added"
+			}
+
+			if dnslinksUpdated {
+				reason = "dnslink-added"
+			}
+
+			if sourcesUpdated {
+				reason = "source-added"
+			}
+
 			if isRecent {
 				reason = "is-recent"
 			}
@@ -62,10 +193,32 @@ func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 			)
 		}
 
-		return i.Index.Update(ctx, i.AnnotatedResource.ID, &index_types.Update{
+		// Omitted references are left as they are.
+		update := &index_types.Update{
 			LastSeen:   now,
 			References: refs,
-		})
+			Paths:      paths,
+			IPNSNames:  ipnsNames,
+			DNSLinks:   dnslinks,
+			Sources:    sources,
+			RefCount:   refCount,
+			Popularity: popularity(refCount, len(ipnsNames)),
+		}
+
+		if i.version != nil {
+			// Rejected with index.ErrConflict when the document has been modified since it was read.
+			return i.Index.(index.Versioned).UpdateVersioned(ctx, i.id, update, i.version)
+		}
+
+		// Adding a path to an existing reference can't be expressed as an append.
+		pathAdded := refsUpdated && len(refs) == len(i.References)
+
+		if appender, ok := i.Index.(index.Appender); ok && !pathAdded {
+			return appendUpdate(ctx, appender, i.id, now, refs, paths, ipnsNames, dnslinks, sources,
+				refsUpdated, pathsUpdated, ipnsUpdated, dnslinksUpdated, sourcesUpdated)
+		}
+
+		return i.Index.Update(ctx, i.id, update)
 	} else {
 		span.AddEvent(ctx, "Not updating")
 	}
@@ -95,7 +248,20 @@ func (c *Crawler) updateMaybeExisting(ctx context.Context, r *t.AnnotatedResourc
 		}
 
 		// Update item and we're done.
-		if err = c.updateExisting(ctx, existing); err != nil {
+		err = c.updateExisting(ctx, existing)
+
+		var conflicts uint
+		for errors.Is(err, index.ErrConflict) && existing.version != nil && conflicts < c.config.MaxUpdateConflicts {
+			// Modified concurrently; merge with the current document.
+			conflicts++
+			span.AddEvent(ctx, "version-conflict")
+
+			if err = c.refresh(ctx, existing); err == nil {
+				err = c.updateExisting(ctx, existing)
+			}
+		}
+
+		if err != nil {
 			return true, err
 		}
 
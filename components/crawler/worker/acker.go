@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+)
+
+// acker acknowledges deliveries from a single channel in batches, using a single Ack(multiple=true) once all
+// deliveries up to a delivery tag have been settled. Rejected deliveries are rejected immediately.
+// A nil acker acknowledges every delivery individually.
+type acker struct {
+	batchSize int
+
+	mu           sync.Mutex
+	acknowledger samqp.Acknowledger
+	settled      map[uint64]bool // Settled tags beyond low; true when the delivery is to be acknowledged.
+	low          uint64          // All tags up to and including low have been settled.
+	lastAck      uint64          // Highest tag up to low which is to be acknowledged.
+	pending      int             // Amount of settled, unacknowledged deliveries up to low.
+}
+
+// newAcker returns an acker for batches of batchSize, or nil when batchSize is 1 or less.
+func newAcker(batchSize int) *acker {
+	if batchSize <= 1 {
+		return nil
+	}
+
+	return &acker{
+		batchSize: batchSize,
+		settled:   make(map[uint64]bool),
+	}
+}
+
+// flush acknowledges all pending deliveries; a.mu must be held.
+// Acknowledges up to lastAck rather than low, as the broker closes the channel when acknowledging the tag of a
+// rejected delivery.
+func (a *acker) flush() error {
+	if a.pending == 0 {
+		return nil
+	}
+
+	a.pending = 0
+
+	return a.acknowledger.Ack(a.lastAck, true)
+}
+
+// settle marks the delivery with the given tag as settled, flushing when a full batch is pending.
+func (a *acker) settle(d *samqp.Delivery, ack bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.acknowledger = d.Acknowledger
+	a.settled[d.DeliveryTag] = ack
+
+	// Advance over contiguously settled tags.
+	for {
+		ack, ok := a.settled[a.low+1]
+		if !ok {
+			break
+		}
+
+		delete(a.settled, a.low+1)
+		a.low++
+
+		if ack {
+			a.lastAck = a.low
+			a.pending++
+		}
+	}
+
+	if a.pending >= a.batchSize {
+		return a.flush()
+	}
+
+	return nil
+}
+
+// Ack acknowledges a successfully processed delivery.
+func (a *acker) Ack(d *samqp.Delivery) error {
+	if a == nil {
+		return d.Ack(false)
+	}
+
+	return a.settle(d, true)
+}
+
+// Reject rejects a delivery, requeueing it when requested.
+func (a *acker) Reject(d *samqp.Delivery, requeue bool) error {
+	if err := d.Reject(requeue); err != nil {
+		return err
+	}
+
+	if a == nil {
+		return nil
+	}
+
+	return a.settle(d, false)
+}
+
+// flushPartial flushes pending deliveries, logging errors.
+func (a *acker) flushPartial() {
+	a.mu.Lock()
+	err := a.flush()
+	a.mu.Unlock()
+
+	if err != nil {
+		logger.Errorf("Error acknowledging batch: %v", err)
+	}
+}
+
+// Start periodically flushes partial batches until the context is closed, flushing a final time when it is.
+func (a *acker) Start(ctx context.Context, interval time.Duration) {
+	if a == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.flushPartial()
+			return
+		case <-ticker.C:
+			a.flushPartial()
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"testing"
+
+	samqp "github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAckerBatchEndingInReject(t *testing.T) {
+	assert := assert.New(t)
+
+	ack := new(acknowledgerMock)
+	ack.On("Reject", uint64(3), false).Return(nil).Once()
+	ack.On("Ack", uint64(2), true).Return(nil).Once()
+
+	a := newAcker(2)
+	delivery := func(tag uint64) *samqp.Delivery {
+		return &samqp.Delivery{Acknowledger: ack, DeliveryTag: tag}
+	}
+
+	// Settled out of order, the last contiguously settled delivery being rejected.
+	assert.NoError(a.Ack(delivery(2)))
+	assert.NoError(a.Reject(delivery(3), false))
+	assert.NoError(a.Ack(delivery(1)))
+
+	ack.AssertExpectations(t)
+}
+
+func TestAckerFlushPartialAfterReject(t *testing.T) {
+	ack := new(acknowledgerMock)
+	ack.On("Reject", uint64(2), true).Return(nil).Once()
+	ack.On("Ack", uint64(1), true).Return(nil).Once()
+
+	a := newAcker(10)
+
+	a.Ack(&samqp.Delivery{Acknowledger: ack, DeliveryTag: 1})
+	a.Reject(&samqp.Delivery{Acknowledger: ack, DeliveryTag: 2}, true)
+	a.flushPartial()
+
+	ack.AssertExpectations(t)
+}
+
+func TestAckerFlushPartialOnlyRejected(t *testing.T) {
+	ack := new(acknowledgerMock)
+	ack.On("Reject", uint64(1), false).Return(nil).Once()
+
+	a := newAcker(10)
+
+	a.Reject(&samqp.Delivery{Acknowledger: ack, DeliveryTag: 1}, false)
+	a.flushPartial()
+
+	ack.AssertExpectations(t)
+	ack.AssertNotCalled(t, "Ack", uint64(1), true)
+}
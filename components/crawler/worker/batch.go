@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchOptions configure batch mode, in which the pool stops after processing a number of messages or once the
+// queues are idle, whichever comes first.
+type BatchOptions struct {
+	MaxMessages uint          // Stop after processing this many messages; unlimited when 0.
+	IdleTimeout time.Duration // Stop when no messages have been received for this long; disabled when 0.
+}
+
+// Enabled returns true when batch mode is enabled.
+func (o BatchOptions) Enabled() bool {
+	return o.MaxMessages > 0 || o.IdleTimeout > 0
+}
+
+// batch keeps track of the number of deliveries taken in batch mode. A nil batch is unlimited.
+type batch struct {
+	max   uint64
+	taken uint64 // Accessed atomically.
+
+	activity chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newBatch(opts BatchOptions) *batch {
+	if !opts.Enabled() {
+		return nil
+	}
+
+	b := &batch{
+		max:      uint64(opts.MaxMessages),
+		activity: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	if opts.IdleTimeout > 0 {
+		go b.watchIdle(opts.IdleTimeout)
+	}
+
+	return b
+}
+
+// finish marks the batch as done.
+func (b *batch) finish() {
+	b.once.Do(func() { close(b.done) })
+}
+
+// Done returns a channel which is closed when no more deliveries should be taken; nil for a nil batch.
+func (b *batch) Done() <-chan struct{} {
+	if b == nil {
+		return nil
+	}
+
+	return b.done
+}
+
+// take reserves processing of a delivery, returning false when the batch is done.
+func (b *batch) take() bool {
+	if b == nil {
+		return true
+	}
+
+	select {
+	case <-b.done:
+		return false
+	default:
+	}
+
+	taken := atomic.AddUint64(&b.taken, 1)
+
+	if b.max > 0 {
+		if taken > b.max {
+			return false
+		}
+
+		if taken == b.max {
+			b.finish()
+		}
+	}
+
+	// Signal activity without blocking.
+	select {
+	case b.activity <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// processed returns the number of deliveries taken for processing.
+func (b *batch) processed() uint64 {
+	if b == nil {
+		return 0
+	}
+
+	taken := atomic.LoadUint64(&b.taken)
+	if b.max > 0 && taken > b.max {
+		// Deliveries beyond max were left in the queue.
+		return b.max
+	}
+
+	return taken
+}
+
+// watchIdle finishes the batch when no deliveries have been taken for timeout.
+func (b *batch) watchIdle(timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-b.activity:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(timeout)
+		case <-timer.C:
+			b.finish()
+			return
+		}
+	}
+}
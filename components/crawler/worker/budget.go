@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	samqp "github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/unit"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// budget is a soft limit on the combined size of resources being processed concurrently.
+// A budget with a zero limit allows everything.
+type budget struct {
+	limit uint64
+
+	mu       sync.Mutex
+	inflight uint64
+	freed    chan struct{} // Closed and replaced whenever size is released.
+
+	counter metric.Int64UpDownCounter
+}
+
+func newBudget(limit uint64, meter metric.Meter) *budget {
+	return &budget{
+		limit: limit,
+		freed: make(chan struct{}),
+		counter: metric.Must(meter).NewInt64UpDownCounter(
+			"ipfs_search.crawler.worker.inflight_size",
+			metric.WithDescription("Combined size of resources being processed."),
+			metric.WithUnit(unit.Bytes),
+		),
+	}
+}
+
+// acquire reserves size bytes, waiting until they fit in the budget or the context is done.
+// A resource is always admitted when nothing is in flight, so resources larger than the budget are still processed.
+func (b *budget) acquire(ctx context.Context, size uint64) error {
+	for {
+		b.mu.Lock()
+
+		if b.limit == 0 || b.inflight == 0 || b.inflight+size <= b.limit {
+			b.inflight += size
+			b.mu.Unlock()
+
+			b.counter.Add(ctx, int64(size))
+
+			return nil
+		}
+
+		freed := b.freed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-freed:
+		}
+	}
+}
+
+// release returns size bytes to the budget.
+func (b *budget) release(ctx context.Context, size uint64) {
+	if size == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.inflight -= size
+	b.counter.Add(ctx, -int64(size))
+
+	// Wake up waiting acquirers.
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
+
+// deliverySize returns the size to reserve in the budget for crawling the resource of d; directories and deliveries
+// which can't be decoded (failing when crawled) take none.
+func deliverySize(d *samqp.Delivery) uint64 {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{},
+	}
+
+	if err := json.Unmarshal(d.Body, r); err != nil || r.Type == t.DirectoryType {
+		return 0
+	}
+
+	return r.Size
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+func TestBudgetUnlimited(t *testing.T) {
+	b := newBudget(0, instr.New().Meter)
+	ctx := context.Background()
+
+	assert.NoError(t, b.acquire(ctx, 1000))
+	assert.NoError(t, b.acquire(ctx, 1000))
+}
+
+func TestBudgetOversized(t *testing.T) {
+	b := newBudget(100, instr.New().Meter)
+
+	// Admitted when nothing else is in flight.
+	assert.NoError(t, b.acquire(context.Background(), 1000))
+}
+
+func TestBudgetWait(t *testing.T) {
+	b := newBudget(100, instr.New().Meter)
+	ctx := context.Background()
+
+	assert.NoError(t, b.acquire(ctx, 60))
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- b.acquire(ctx, 60)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired exceeding budget")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	b.release(ctx, 60)
+
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("not acquired after release")
+	}
+}
+
+func TestBudgetCancel(t *testing.T) {
+	b := newBudget(100, instr.New().Meter)
+
+	assert.NoError(t, b.acquire(context.Background(), 60))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, b.acquire(ctx, 60))
+
+	// The cancelled acquisition reserved nothing.
+	b.release(context.Background(), 60)
+	assert.NoError(t, b.acquire(context.Background(), 100))
+}
+
+func TestDeliverySize(t *testing.T) {
+	assert.Equal(t, uint64(400), deliverySize(&samqp.Delivery{Body: []byte(`{"Protocol":1,"ID":"QmA","Type":2,"Size":400}`)}))
+	assert.Equal(t, uint64(0), deliverySize(&samqp.Delivery{Body: []byte(`{"Protocol":1,"ID":"QmA","Type":3,"Size":400}`)}))
+	assert.Equal(t, uint64(0), deliverySize(&samqp.Delivery{Body: []byte(`not json`)}))
+}
@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/metric"
+
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// newCrawlRate returns a token bucket shared by the workers of all pools, allowing MaxCrawlRate crawls per minute with
+// bursts of CrawlBurst, and reports its remaining tokens when limited. Crawls are unlimited when MaxCrawlRate is 0.
+func newCrawlRate(cfg *config.Workers, meter metric.Meter) *utils.RateLimiter {
+	l := utils.NewRateLimiter(cfg.MaxCrawlRate/60, int(cfg.CrawlBurst))
+
+	if cfg.MaxCrawlRate <= 0 {
+		return l
+	}
+
+	metric.Must(meter).NewFloat64ValueObserver(
+		"ipfs_search.crawler.worker.crawl_budget",
+		func(ctx context.Context, result metric.Float64ObserverResult) {
+			result.Observe(l.Tokens())
+		},
+		metric.WithDescription("Crawls allowed before workers pause for max_crawl_rate; negative while workers wait."),
+	)
+
+	return l
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+func TestCrawlRateUnlimited(t *testing.T) {
+	cfg := config.WorkersDefaults()
+	cfg.MaxCrawlRate = 0
+
+	l := newCrawlRate(&cfg, instr.New().Meter)
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, l.Allow())
+	}
+
+	assert.NoError(t, l.Wait(context.Background()))
+}
+
+func TestCrawlRateBurst(t *testing.T) {
+	cfg := config.WorkersDefaults()
+	cfg.MaxCrawlRate = 60 // One per second.
+	cfg.CrawlBurst = 2
+
+	l := newCrawlRate(&cfg, instr.New().Meter)
+
+	assert.True(t, l.Allow())
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+}
+
+func TestCrawlRateWait(t *testing.T) {
+	cfg := config.WorkersDefaults()
+	cfg.MaxCrawlRate = 6000 // One per 10ms.
+	cfg.CrawlBurst = 1
+
+	l := newCrawlRate(&cfg, instr.New().Meter)
+
+	assert.NoError(t, l.Wait(context.Background()))
+
+	start := time.Now()
+	assert.NoError(t, l.Wait(context.Background()))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(5*time.Millisecond))
+}
+
+func TestCrawlRateWaitCancelled(t *testing.T) {
+	cfg := config.WorkersDefaults()
+	cfg.MaxCrawlRate = 60
+	cfg.CrawlBurst = 1
+
+	l := newCrawlRate(&cfg, instr.New().Meter)
+	assert.True(t, l.Allow())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, l.Wait(ctx))
+
+	// The token of the cancelled wait was returned.
+	assert.InDelta(t, 0, l.Tokens(), 0.1)
+}
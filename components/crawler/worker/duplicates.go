@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/config"
+)
+
+// seenTask is a task remembered for detecting duplicates.
+type seenTask struct {
+	key string
+	at  time.Time
+}
+
+// duplicates detects tasks seen before within a TTL, e.g. messages redelivered after a crash or resources
+// re-discovered by the sniffer, remembering a bounded number of tasks.
+type duplicates struct {
+	cfg *config.Workers
+
+	mu    sync.Mutex
+	tasks map[string]*list.Element
+	order *list.List // Of *seenTask, least recently seen first.
+
+	counter metric.Int64Counter
+}
+
+func newDuplicates(cfg *config.Workers, meter metric.Meter) *duplicates {
+	return &duplicates{
+		cfg:   cfg,
+		tasks: make(map[string]*list.Element),
+		order: list.New(),
+		counter: metric.Must(meter).NewInt64Counter(
+			"ipfs_search.crawler.worker.duplicates",
+			metric.WithDescription("Duplicate tasks detected, by window (short or long) and whether they were dropped."),
+		),
+	}
+}
+
+// taskKey returns the key identifying the task of d: its message ID when set by the publisher, or a hash of its body.
+// Redeliveries and re-discoveries through the same reference have the same body, whereas re-published retries
+// have their attempts counted in the body, so they are never considered duplicates.
+func taskKey(d *samqp.Delivery) string {
+	if d.MessageId != "" {
+		return d.MessageId
+	}
+
+	sum := sha256.Sum256(d.Body)
+	return string(sum[:])
+}
+
+// expire forgets tasks seen longer than the TTL before now; s.mu must be held.
+func (s *duplicates) expire(now time.Time) {
+	for e := s.order.Front(); e != nil && now.Sub(e.Value.(*seenTask).at) > s.cfg.DuplicateTTL; e = s.order.Front() {
+		s.order.Remove(e)
+		delete(s.tasks, e.Value.(*seenTask).key)
+	}
+}
+
+// seen returns the time since the task key was last recorded, if it has been recorded within the TTL.
+func (s *duplicates) seen(key string, now time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+
+	if e, ok := s.tasks[key]; ok {
+		return now.Sub(e.Value.(*seenTask).at), true
+	}
+
+	return 0, false
+}
+
+// record remembers the task key as seen at now.
+func (s *duplicates) record(key string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+
+	if e, ok := s.tasks[key]; ok {
+		e.Value.(*seenTask).at = now
+		s.order.MoveToBack(e)
+
+		return
+	}
+
+	s.tasks[key] = s.order.PushBack(&seenTask{key, now})
+
+	if s.order.Len() > s.cfg.DuplicateCacheSize {
+		// Forget the least recently seen task.
+		e := s.order.Front()
+		s.order.Remove(e)
+		delete(s.tasks, e.Value.(*seenTask).key)
+	}
+}
+
+// remember records the task of d as seen. Only tasks which have been processed (and acknowledged) are remembered, so
+// that redeliveries of tasks which failed or were cancelled are not considered duplicates.
+func (s *duplicates) remember(d *samqp.Delivery) {
+	if s.cfg.DuplicateTTL == 0 {
+		return
+	}
+
+	s.record(taskKey(d), time.Now())
+}
+
+// skip returns true when d is a duplicate of a remembered task which should be dropped, as configured.
+func (s *duplicates) skip(ctx context.Context, d *samqp.Delivery) bool {
+	if s.cfg.DuplicateTTL == 0 {
+		return false
+	}
+
+	age, ok := s.seen(taskKey(d), time.Now())
+	if !ok {
+		return false
+	}
+
+	// Duplicates within the short window are likely redeliveries, later ones legitimate re-discoveries.
+	short := age <= s.cfg.DuplicateWindow
+
+	window := "long"
+	if short {
+		window = "short"
+	}
+
+	drop := s.cfg.Duplicates == config.DuplicatesDrop ||
+		(s.cfg.Duplicates == config.DuplicatesDropRedeliveries && short)
+
+	s.counter.Add(ctx, 1, label.String("window", window), label.Bool("dropped", drop))
+	trace.SpanFromContext(ctx).AddEvent(ctx, "duplicate",
+		label.String("window", window),
+		label.Bool("dropped", drop),
+	)
+
+	logger.Debugf("Duplicate task (%s window, last seen %s ago, dropped: %t): %s", window, age, drop, d.Body)
+
+	return drop
+}
@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+func testDuplicates(mode string) *duplicates {
+	cfg := config.WorkersDefaults()
+	cfg.Duplicates = mode
+	cfg.DuplicateTTL = time.Hour
+	cfg.DuplicateWindow = time.Minute
+	cfg.DuplicateCacheSize = 2
+
+	return newDuplicates(&cfg, instr.New().Meter)
+}
+
+func TestDuplicatesSeen(t *testing.T) {
+	s := testDuplicates(config.DuplicatesDrop)
+	now := time.Now()
+
+	_, ok := s.seen("a", now)
+	assert.False(t, ok)
+
+	// Checking does not record.
+	_, ok = s.seen("a", now)
+	assert.False(t, ok)
+
+	s.record("a", now)
+
+	age, ok := s.seen("a", now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, age)
+}
+
+func TestDuplicatesExpiry(t *testing.T) {
+	s := testDuplicates(config.DuplicatesDrop)
+	now := time.Now()
+
+	s.record("a", now)
+
+	_, ok := s.seen("a", now.Add(time.Hour))
+	assert.True(t, ok)
+
+	_, ok = s.seen("a", now.Add(time.Hour+time.Second))
+	assert.False(t, ok)
+	assert.Empty(t, s.tasks)
+}
+
+func TestDuplicatesRecordRefreshes(t *testing.T) {
+	s := testDuplicates(config.DuplicatesDrop)
+	now := time.Now()
+
+	s.record("a", now)
+	s.record("a", now.Add(time.Hour))
+
+	age, ok := s.seen("a", now.Add(time.Hour+time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, age)
+}
+
+func TestDuplicatesEviction(t *testing.T) {
+	s := testDuplicates(config.DuplicatesDrop)
+	now := time.Now()
+
+	s.record("a", now)
+	s.record("b", now)
+	s.record("a", now) // Recently seen again.
+	s.record("c", now) // Evicts the least recently seen task.
+
+	_, ok := s.seen("a", now)
+	assert.True(t, ok)
+
+	_, ok = s.seen("b", now)
+	assert.False(t, ok)
+
+	_, ok = s.seen("c", now)
+	assert.True(t, ok)
+}
+
+func TestTaskKey(t *testing.T) {
+	a := &samqp.Delivery{Body: []byte("a")}
+	assert.Equal(t, taskKey(a), taskKey(&samqp.Delivery{Body: []byte("a")}))
+	assert.NotEqual(t, taskKey(a), taskKey(&samqp.Delivery{Body: []byte("b")}))
+
+	assert.Equal(t, "id", taskKey(&samqp.Delivery{MessageId: "id", Body: []byte("a")}))
+}
+
+func TestSkipUnremembered(t *testing.T) {
+	s := testDuplicates(config.DuplicatesDrop)
+	d := &samqp.Delivery{Body: []byte("a")}
+	ctx := context.Background()
+
+	// Deliveries which were not processed are never duplicates.
+	assert.False(t, s.skip(ctx, d))
+	assert.False(t, s.skip(ctx, d))
+}
+
+func TestSkipDrop(t *testing.T) {
+	s := testDuplicates(config.DuplicatesDrop)
+	d := &samqp.Delivery{Body: []byte("a")}
+	ctx := context.Background()
+
+	s.remember(d)
+	assert.True(t, s.skip(ctx, d))
+
+	// Long window.
+	s.record(taskKey(d), time.Now().Add(-10*time.Minute))
+	assert.True(t, s.skip(ctx, d))
+}
+
+func TestSkipDropRedeliveries(t *testing.T) {
+	s := testDuplicates(config.DuplicatesDropRedeliveries)
+	d := &samqp.Delivery{Body: []byte("a")}
+	ctx := context.Background()
+
+	s.remember(d)
+	assert.True(t, s.skip(ctx, d))
+
+	// Re-discoveries, beyond the short window, are processed.
+	s.record(taskKey(d), time.Now().Add(-10*time.Minute))
+	assert.False(t, s.skip(ctx, d))
+}
+
+func TestSkipProcess(t *testing.T) {
+	s := testDuplicates(config.DuplicatesProcess)
+	d := &samqp.Delivery{Body: []byte("a")}
+
+	s.remember(d)
+	assert.False(t, s.skip(context.Background(), d))
+}
+
+func TestSkipDisabled(t *testing.T) {
+	s := testDuplicates(config.DuplicatesDrop)
+	s.cfg.DuplicateTTL = 0
+	d := &samqp.Delivery{Body: []byte("a")}
+
+	s.remember(d)
+	assert.False(t, s.skip(context.Background(), d))
+	assert.Empty(t, s.tasks)
+}
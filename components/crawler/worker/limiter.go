@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/metric"
+)
+
+// limiter is a semaphore bounding the number of deliveries processed concurrently, across pools.
+// A limiter with a zero limit allows everything; in-flight deliveries are counted regardless.
+type limiter struct {
+	slots chan struct{}
+
+	counter metric.Int64UpDownCounter
+}
+
+func newLimiter(limit uint, meter metric.Meter) *limiter {
+	var slots chan struct{}
+	if limit > 0 {
+		slots = make(chan struct{}, limit)
+	}
+
+	return &limiter{
+		slots: slots,
+		counter: metric.Must(meter).NewInt64UpDownCounter(
+			"ipfs_search.crawler.worker.inflight",
+			metric.WithDescription("Deliveries being processed."),
+		),
+	}
+}
+
+// acquire waits for a free slot, returning the context's error when it is closed first.
+func (l *limiter) acquire(ctx context.Context) error {
+	if l.slots != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case l.slots <- struct{}{}:
+		}
+	}
+
+	l.counter.Add(ctx, 1)
+
+	return nil
+}
+
+// release frees a slot acquired before.
+func (l *limiter) release(ctx context.Context) {
+	if l.slots != nil {
+		<-l.slots
+	}
+
+	l.counter.Add(ctx, -1)
+}
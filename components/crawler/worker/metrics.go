@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/unit"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// poolInstruments are shared by the metrics of all pools, which are told apart by their pool label.
+type poolInstruments struct {
+	busy      metric.Int64UpDownCounter
+	processed metric.Int64Counter
+	duration  metric.Int64ValueRecorder
+	age       metric.Int64ValueRecorder
+
+	pools *instr.LabelLimit
+}
+
+func newPoolInstruments(meter metric.Meter) *poolInstruments {
+	m := metric.Must(meter)
+
+	return &poolInstruments{
+		busy: m.NewInt64UpDownCounter(
+			"ipfs_search.crawler.worker.pool.busy",
+			metric.WithDescription("Workers processing a delivery, by pool."),
+		),
+		processed: m.NewInt64Counter(
+			"ipfs_search.crawler.worker.pool.processed",
+			metric.WithDescription("Deliveries processed, by pool and whether processing succeeded."),
+		),
+		duration: m.NewInt64ValueRecorder(
+			"ipfs_search.crawler.worker.pool.duration",
+			metric.WithDescription("Time taken to process deliveries, by pool."),
+			metric.WithUnit(unit.Milliseconds),
+		),
+		age: m.NewInt64ValueRecorder(
+			"ipfs_search.crawler.worker.pool.message_age",
+			metric.WithDescription("Time between publishing and consuming deliveries, by pool."),
+			metric.WithUnit(unit.Milliseconds),
+		),
+		pools: instr.NewLabelLimit("pool"),
+	}
+}
+
+// poolMetrics records metrics for the workers of a single pool.
+type poolMetrics struct {
+	*poolInstruments
+	pool label.KeyValue
+}
+
+// forPool returns metrics labeled with the name of a pool.
+func (i *poolInstruments) forPool(name string) *poolMetrics {
+	return &poolMetrics{i, i.pools.String(name)}
+}
+
+// start records the start of processing a delivery, returning a function recording its result.
+func (m *poolMetrics) start(ctx context.Context) func(error) {
+	m.busy.Add(ctx, 1, m.pool)
+	start := time.Now()
+
+	return func(err error) {
+		m.busy.Add(ctx, -1, m.pool)
+		m.processed.Add(ctx, 1, m.pool, label.Bool("success", err == nil))
+		m.duration.Record(ctx, time.Since(start).Milliseconds(), m.pool)
+	}
+}
+
+// received records the age of a delivery, when it has been published with a timestamp.
+func (m *poolMetrics) received(ctx context.Context, d *samqp.Delivery) {
+	if d.Timestamp.IsZero() {
+		// Published by an older version or another publisher.
+		return
+	}
+
+	m.age.Record(ctx, time.Since(d.Timestamp).Milliseconds(), m.pool)
+}
+
+// depther is implemented by queues reporting their depth.
+type depther interface {
+	Depth(ctx context.Context) (int, error)
+}
+
+// observeQueueDepths reports the depth of queues, by name, whenever metrics are collected.
+// Queues which can't report their depth are skipped.
+func observeQueueDepths(meter metric.Meter, queues map[string]queue.Queue) {
+	depthers := make(map[string]depther, len(queues))
+	for name, q := range queues {
+		if d, ok := q.(depther); ok {
+			depthers[name] = d
+		}
+	}
+
+	names := instr.NewLabelLimit("queue")
+
+	metric.Must(meter).NewInt64ValueObserver(
+		"ipfs_search.crawler.worker.queue.depth",
+		func(ctx context.Context, result metric.Int64ObserverResult) {
+			for name, d := range depthers {
+				depth, err := d.Depth(ctx)
+				if err != nil {
+					logger.Warnf("Error getting depth of queue %s: %v", name, err)
+					continue
+				}
+
+				result.Observe(int64(depth), names.String(name))
+			}
+		},
+		metric.WithDescription("Messages ready for delivery, by queue."),
+	)
+}
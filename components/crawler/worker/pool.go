@@ -3,29 +3,51 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/olivere/elastic/v7"
 	samqp "github.com/streadway/amqp"
 
+	"go.opentelemetry.io/otel/api/metric"
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
 
 	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/cursor"
+	"github.com/ipfs-search/ipfs-search/components/denylist"
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/extractor/code"
+	"github.com/ipfs-search/ipfs-search/components/extractor/font"
+	"github.com/ipfs-search/ipfs-search/components/extractor/images"
+	"github.com/ipfs-search/ipfs-search/components/extractor/structureddata"
 	"github.com/ipfs-search/ipfs-search/components/extractor/tika"
+	"github.com/ipfs-search/ipfs-search/components/extractor/toc"
+	"github.com/ipfs-search/ipfs-search/components/index"
 	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+	"github.com/ipfs-search/ipfs-search/components/index/mirror"
+	"github.com/ipfs-search/ipfs-search/components/index/ndjson"
 	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"github.com/ipfs-search/ipfs-search/components/queue"
 	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/transform"
+	"github.com/ipfs-search/ipfs-search/components/webhook"
 
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
 	t "github.com/ipfs-search/ipfs-search/types"
 	"github.com/ipfs-search/ipfs-search/utils"
 )
 
+var logger = logging.New("crawler")
+
 // Pool represents a pool of workers.
 type Pool struct {
 	config       *config.Config
@@ -34,89 +56,257 @@ type Pool struct {
 		Files       <-chan samqp.Delivery
 		Directories <-chan samqp.Delivery
 		Hashes      <-chan samqp.Delivery
+		Invalids    <-chan samqp.Delivery
 	}
-	crawler *crawler.Crawler
+	consumeQueues *crawler.Queues
+	retryQueues   map[queue.Queue]delayedRetrier // Delayed queues for retrying deliveries from consumed queues.
+	crawler       *crawler.Crawler
+	getIndex      func(name string) index.Index
+	cursorIndex   index.Index
+	budget        *budget
+	duplicates    *duplicates
+	limiter       *limiter
+	crawlRate     *utils.RateLimiter
+	crawlCounter  metric.Int64Counter
+	instruments   *poolInstruments
+
+	workers  sync.WaitGroup // Running workers.
+	flushers sync.WaitGroup // Running ackers and cursor trackers.
+
+	// Deliveries in progress and flushers outlive the context the pool was started with, until Shutdown.
+	work, flush              context.Context
+	cancelWork, stopFlushers context.CancelFunc
+	requeued                 int64 // Deliveries cancelled and requeued at shutdown.
+	abandoned                int64 // Abandoned processing which has not returned (yet).
 
 	*instr.Instrumentation
 }
 
 func (w *Pool) makeCrawler(ctx context.Context) error {
 	var (
-		queues  *crawler.Queues
-		indexes *crawler.Indexes
-		err     error
+		amqpConnection *amqp.Connection
+		queues         *crawler.Queues
+		indexes        *crawler.Indexes
+		err            error
 	)
 
-	log.Println("Getting publish queues.")
-	if queues, err = w.getQueues(ctx); err != nil {
+	logger.Infof("Getting publish queues.")
+	if amqpConnection, err = w.getAMQPConnection(ctx); err != nil {
+		return err
+	}
+
+	if queues, err = w.getQueues(ctx, amqpConnection); err != nil {
+		return err
+	}
+
+	logger.Infof("Getting indexes from %s sink.", w.config.Indexes.Sink)
+	if w.getIndex, err = w.getIndexFactory(ctx); err != nil {
 		return err
 	}
 
-	log.Println("Getting indexes.")
 	if indexes, err = w.getIndexes(ctx); err != nil {
 		return err
 	}
 
+	w.cursorIndex = w.getIndex(w.config.Indexes.Cursors.Name)
+
 	// Many stat/ls connections
 	ipfsClient := utils.GetHTTPClient(w.dialer.DialContext, 1000)
 	protocol := ipfs.New(w.config.IPFSConfig(), ipfsClient, w.Instrumentation)
 
-	// Limited Tika connections (as resources are generally known to be available by now)
-	tikaClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
-	extractor := tika.New(w.config.TikaConfig(), tikaClient, protocol, w.Instrumentation)
+	logger.Infof("Checking IPFS API.")
+	if err := w.retryStartup(ctx, "IPFS", protocol.Ping); err != nil {
+		return err
+	}
+
+	var extractors []extractor.Extractor
+
+	if cfg := w.config.TikaConfig(); cfg.TikaExtractorURL != "" {
+		// Limited Tika connections (as resources are generally known to be available by now)
+		tikaClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
+		extractors = append(extractors, tika.New(cfg, tikaClient, protocol, w.Instrumentation))
+	} else {
+		logger.Infof("No Tika URL configured, extraction disabled: indexing files without content and metadata.")
+	}
+
+	if cfg := w.config.ImagesConfig(); cfg.Enabled {
+		imagesClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
+		extractors = append(extractors, images.New(cfg, imagesClient, protocol, w.Instrumentation))
+	}
+
+	if cfg := w.config.StructuredDataConfig(); cfg.Enabled {
+		structuredDataClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
+		extractors = append(extractors, structureddata.New(cfg, structuredDataClient, protocol, w.Instrumentation))
+	}
+
+	if cfg := w.config.TOCConfig(); cfg.Enabled {
+		tocClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
+		extractors = append(extractors, toc.New(cfg, tocClient, protocol, w.Instrumentation))
+	}
+
+	if cfg := w.config.FontsConfig(); cfg.Enabled {
+		fontClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
+		extractors = append(extractors, font.New(cfg, fontClient, protocol, w.Instrumentation))
+	}
+
+	if cfg := w.config.CodeConfig(); cfg.Enabled {
+		codeClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
+		extractors = append(extractors, code.New(cfg, codeClient, protocol, w.Instrumentation))
+	}
+
+	var sniffer *extractor.Sniffer
+	if cfg := w.config.ExtractorConfig(); cfg.Sniff {
+		sniffClient := utils.GetHTTPClient(w.dialer.DialContext, 100)
+		sniffer = extractor.NewSniffer(sniffClient, protocol, w.Instrumentation)
+	}
+
+	// Without extractors or sniffer, there's nothing to extract; keep it nil for structure-only crawling.
+	var e extractor.Extractor
+	if len(extractors) > 0 || sniffer != nil {
+		e = extractor.NewMulti(w.config.ExtractorConfig(), sniffer, extractors...)
+	}
+
+	var deny *denylist.Denylist
+	if cfg := w.config.CrawlerConfig(); cfg.DenylistFile != "" {
+		logger.Infof("Loading denylist %s.", cfg.DenylistFile)
+		if deny, err = denylist.New(cfg.DenylistFile); err != nil {
+			return err
+		}
+
+		go deny.Watch(ctx, cfg.DenylistReloadInterval)
+	}
+
+	var notifier *webhook.Notifier
+	if cfg := w.config.WebhookConfig(); cfg.URL != "" {
+		logger.Infof("Notifying webhook %s of indexed resources.", cfg.URL)
+		webhookClient := utils.GetHTTPClient(w.dialer.DialContext, int(cfg.Workers))
+		notifier = webhook.New(cfg, webhookClient, w.Instrumentation)
+		notifier.Start(ctx)
+	}
+
+	transforms, err := transform.New(w.config.TransformConfig())
+	if err != nil {
+		return err
+	}
 
-	w.crawler = crawler.New(w.config.CrawlerConfig(), indexes, queues, protocol, extractor, w.Instrumentation)
+	w.crawler = crawler.New(w.config.CrawlerConfig(), indexes, queues, protocol, e, deny, notifier, transforms, w.Instrumentation)
 
 	return nil
 }
 
-func (w *Pool) getElasticClient() (*elastic.Client, error) {
+// retryStartup retries connecting to the dependency name at startup, as configured for the workers.
+func (w *Pool) retryStartup(ctx context.Context, name string, connect func(context.Context) error) error {
+	return utils.RetryStartup(ctx, name, w.config.Workers.StartupTimeout, w.config.Workers.StartupBackoff, connect)
+}
+
+func (w *Pool) getElasticClient(ctx context.Context, url string) (*elastic.Client, error) {
 	httpClient := utils.GetHTTPClient(w.dialer.DialContext, 5)
 
-	return elastic.NewClient(
-		elastic.SetSniff(false),
-		elastic.SetURL(w.config.ElasticSearch.URL),
-		elastic.SetHttpClient(httpClient),
-	)
+	var client *elastic.Client
+
+	// Creating the client checks whether Elasticsearch is available.
+	err := w.retryStartup(ctx, "Elasticsearch", func(context.Context) (err error) {
+		client, err = elastic.NewClient(
+			elastic.SetSniff(false),
+			elastic.SetURL(url),
+			elastic.SetHttpClient(httpClient),
+		)
+		return
+	})
+
+	return client, err
 }
 
-func (w *Pool) getIndexes(ctx context.Context) (*crawler.Indexes, error) {
-	esClient, err := w.getElasticClient()
-	if err != nil {
-		return nil, err
+// getIndexFactory returns a function returning indexes by name for the configured sink.
+func (w *Pool) getIndexFactory(ctx context.Context) (func(name string) index.Index, error) {
+	switch sink := w.config.Indexes.Sink; sink {
+	case "elasticsearch":
+		esURL, err := w.config.ElasticSearchURL()
+		if err != nil {
+			return nil, err
+		}
+
+		esClient, err := w.getElasticClient(ctx, esURL)
+		if err != nil {
+			return nil, err
+		}
+
+		newIndex := func(client *elastic.Client, name string) index.Index {
+			if bulk := w.config.ElasticSearchBulkConfig(); bulk != nil {
+				return elasticsearch.NewBulk(client, w.config.ElasticSearchIndexConfig(name), bulk, w.Instrumentation)
+			}
+
+			return elasticsearch.New(client, w.config.ElasticSearchIndexConfig(name), w.Instrumentation)
+		}
+
+		if w.config.ElasticSearch.SecondaryURL == "" {
+			return func(name string) index.Index {
+				return newIndex(esClient, name)
+			}, nil
+		}
+
+		logger.Infof("Mirroring writes to secondary Elasticsearch at %s.", w.config.ElasticSearch.SecondaryURL)
+		secondaryClient, err := w.getElasticClient(ctx, w.config.ElasticSearch.SecondaryURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(name string) index.Index {
+			return mirror.New(
+				newIndex(esClient, name),
+				newIndex(secondaryClient, name),
+				w.config.ElasticSearch.MaxSecondaryPending,
+				w.Instrumentation,
+			)
+		}, nil
+
+	case "ndjson":
+		f, err := os.OpenFile(w.config.Indexes.SinkFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		writer := ndjson.NewWriter(f)
+		return func(name string) index.Index {
+			return ndjson.New(writer, &ndjson.Config{Name: name}, w.Instrumentation)
+		}, nil
+
+	case "stdout":
+		writer := ndjson.NewWriter(os.Stdout)
+		return func(name string) index.Index {
+			return ndjson.New(writer, &ndjson.Config{Name: name}, w.Instrumentation)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown index sink '%s'", sink)
 	}
+}
 
+func (w *Pool) getIndexes(ctx context.Context) (*crawler.Indexes, error) {
 	return &crawler.Indexes{
-		Files: elasticsearch.New(
-			esClient,
-			&elasticsearch.Config{Name: w.config.Indexes.Files.Name},
-			w.Instrumentation,
-		),
-		Directories: elasticsearch.New(
-			esClient,
-			&elasticsearch.Config{Name: w.config.Indexes.Directories.Name},
-			w.Instrumentation,
-		),
-		Invalids: elasticsearch.New(
-			esClient,
-			&elasticsearch.Config{Name: w.config.Indexes.Invalids.Name},
-			w.Instrumentation,
-		),
+		Files:       w.getIndex(w.config.Indexes.Files.Name),
+		Directories: w.getIndex(w.config.Indexes.Directories.Name),
+		Invalids:    w.getIndex(w.config.Indexes.Invalids.Name),
 	}, nil
 }
 
-func (w *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
+func (w *Pool) getAMQPConnection(ctx context.Context) (*amqp.Connection, error) {
 	amqpConfig := &samqp.Config{
 		Dial: w.dialer.Dial,
 	}
 
-	log.Println("Connecting to AMQP.")
-	amqpConnection, err := amqp.NewConnection(ctx, w.config.AMQPConfig(), amqpConfig, w.Instrumentation)
-	if err != nil {
-		return nil, err
-	}
+	logger.Infof("Connecting to AMQP.")
+	var amqpConnection *amqp.Connection
+	err := w.retryStartup(ctx, "AMQP", func(ctx context.Context) (err error) {
+		amqpConnection, err = amqp.NewConnection(ctx, w.config.AMQPConfig(), amqpConfig, w.Instrumentation)
+		return
+	})
 
-	log.Println("Creating AMQP channels.")
+	return amqpConnection, err
+}
+
+func (w *Pool) getQueues(ctx context.Context, amqpConnection *amqp.Connection) (*crawler.Queues, error) {
+	logger.Infof("Creating AMQP channels.")
 	fq, err := amqpConnection.NewChannelQueue(ctx, w.config.Queues.Files.Name, w.config.Workers.FileWorkers)
 	if err != nil {
 		return nil, err
@@ -132,17 +322,46 @@ func (w *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
 		return nil, err
 	}
 
-	return &crawler.Queues{
+	queues := &crawler.Queues{
 		Files:       fq,
 		Directories: dq,
 		Hashes:      hq,
-	}, nil
+	}
+
+	if w.config.Workers.InvalidWorkers > 0 {
+		if queues.Invalids, err = amqpConnection.NewChannelQueue(ctx, w.config.Queues.Invalids.Name, w.config.Workers.InvalidWorkers); err != nil {
+			return nil, err
+		}
+	}
+
+	if w.config.CrawlerConfig().DNSLinkTTL > 0 {
+		// Re-resolve DNSLink names through the hashes queue.
+		if queues.Recrawls, err = amqpConnection.NewDelayedChannelQueue(ctx, w.config.Queues.Hashes.Name, 0, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return queues, nil
+}
+
+// getRetryQueues returns delayed queues for retrying deliveries from the given consumed queues.
+func (w *Pool) getRetryQueues(ctx context.Context, amqpConnection *amqp.Connection, queues map[string]queue.Queue) (map[queue.Queue]delayedRetrier, error) {
+	retryQueues := make(map[queue.Queue]delayedRetrier, len(queues))
+
+	for name, q := range queues {
+		dq, err := amqpConnection.NewDelayedChannelQueue(ctx, name, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		retryQueues[q] = dq
+	}
+
+	return retryQueues, nil
 }
 
-func (w *Pool) crawlDelivery(ctx context.Context, d samqp.Delivery) error {
-	// TODO: Get SpanContext from Delivery.
-	// ctx = trace.ContextWithRemoteSpanContext(ctx, p.SpanContext)
-	ctx, span := w.Tracer.Start(ctx, "crawler.worker.crawlDelivery", trace.WithNewRoot())
+func (w *Pool) crawlDelivery(ctx context.Context, d samqp.Delivery, c *cursor.Tracker) error {
+	ctx, span := w.startDeliverySpan(ctx, &d, "crawler.worker.crawlDelivery")
 	defer span.End()
 
 	r := &t.AnnotatedResource{
@@ -154,24 +373,74 @@ func (w *Pool) crawlDelivery(ctx context.Context, d samqp.Delivery) error {
 		return err
 	}
 
-	if !r.IsValid() {
+	// Resources with an IPNS name have their ID resolved during crawling; it may be empty until then.
+	if r.IPNSName == "" && !r.IsValid() {
 		err := fmt.Errorf("Invalid resource: %v", r)
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return err
 	}
 
-	log.Printf("Crawling '%s'", r)
+	if w.duplicates.skip(ctx, &d) {
+		// Acknowledged without crawling.
+		return nil
+	}
+
+	logger.Debugf("Crawling '%s'", r)
 	err := w.crawler.Crawl(ctx, r)
-	log.Printf("Done crawling '%s', result: %v", r, err)
+	logger.Debugf("Done crawling '%s', result: %v", r, err)
+
+	// Allows for measuring first-attempt failures, e.g. to tune the sniffer's first crawl delay.
+	w.crawlCounter.Add(ctx, 1,
+		label.Bool("first_attempt", !d.Redelivered && r.Attempts == 0),
+		label.Bool("success", err == nil),
+	)
 
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	} else {
+		c.Record(r.ID)
 	}
 
 	return err
 }
 
-func (w *Pool) startWorker(ctx context.Context, deliveries <-chan samqp.Delivery, name string) {
+// indexInvalidDelivery indexes a delivery from the invalids queue as invalid.
+func (w *Pool) indexInvalidDelivery(ctx context.Context, d samqp.Delivery, c *cursor.Tracker) error {
+	ctx, span := w.startDeliverySpan(ctx, &d, "crawler.worker.indexInvalidDelivery")
+	defer span.End()
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{},
+	}
+
+	if err := json.Unmarshal(d.Body, r); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	if !r.IsValid() {
+		err := fmt.Errorf("Invalid resource: %v", r)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	logger.Debugf("Indexing invalid '%s', err: %s", r, r.Error)
+	err := w.crawler.IndexInvalid(ctx, r)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	c.Record(r.ID)
+
+	return nil
+}
+
+// startWorker processes deliveries until the context is closed or, in batch mode, the batch is done.
+// Deliveries of crawls are subject to the crawl rate and remembered for detecting duplicates once acknowledged.
+func (w *Pool) startWorker(ctx context.Context, q queue.Queue, deliveries <-chan samqp.Delivery, handle deliveryHandler, crawls bool, name string, m *poolMetrics, c *cursor.Tracker, a *acker, b *batch) {
+	defer w.workers.Done()
+
 	ctx, span := w.Tracer.Start(ctx, "crawler.worker.startWorker")
 	defer span.End()
 
@@ -179,64 +448,255 @@ func (w *Pool) startWorker(ctx context.Context, deliveries <-chan samqp.Delivery
 		select {
 		case <-ctx.Done():
 			return
+		case <-b.Done():
+			return
 		case d, ok := <-deliveries:
 			if !ok {
 				// This is a fatal error; it should never happen - crash the program!
 				panic("unexpected channel close")
 			}
-			if err := w.crawlDelivery(ctx, d); err != nil {
-				// By default, do not retry.
-				shouldRetry := false
+
+			if !b.take() {
+				// Leave unprocessed deliveries in the queue.
+				if err := a.Reject(&d, true); err != nil {
+					span.RecordError(ctx, err)
+				}
+				return
+			}
+
+			if crawls {
+				// Pause until the crawl fits in the rate budget of the process, without holding a slot.
+				if err := w.crawlRate.Wait(ctx); err != nil {
+					// Closing down; leave the delivery in the queue.
+					if err := a.Reject(&d, true); err != nil {
+						span.RecordError(ctx, err)
+					}
+					return
+				}
+			}
+
+			var size uint64
+			if crawls {
+				size = deliverySize(&d)
+			}
+
+			if size > 0 {
+				// Wait for room in the in-flight size budget before taking a slot, so waiting holds none.
+				if err := w.budget.acquire(ctx, size); err != nil {
+					// Closing down; leave the delivery in the queue.
+					if err := a.Reject(&d, true); err != nil {
+						span.RecordError(ctx, err)
+					}
+					return
+				}
+			}
+
+			if err := w.limiter.acquire(ctx); err != nil {
+				w.budget.release(ctx, size)
+
+				// Closing down; leave the delivery in the queue.
+				if err := a.Reject(&d, true); err != nil {
+					span.RecordError(ctx, err)
+				}
+				return
+			}
+
+			m.received(ctx, &d)
+
+			// Finish deliveries in progress when closing down, until cancelled at shutdown.
+			work := shutdownContext{ctx, w.work}
+
+			done := m.start(ctx)
+			err := w.withTimeout(work, func(ctx context.Context) error {
+				return handle(ctx, d, c)
+			}, func() {
+				// Abandoned processing keeps its slot and budget until it returns.
+				w.limiter.release(ctx)
+				w.budget.release(ctx, size)
+			})
+			done(err)
+
+			if err != nil && work.Err() != nil {
+				// Cancelled at shutdown; requeue rather than losing the task.
+				atomic.AddInt64(&w.requeued, 1)
+				logger.Infof("Worker %s: requeueing '%s', cancelled at shutdown", name, d.Body)
+
+				if err := a.Reject(&d, true); err != nil {
+					span.RecordError(ctx, err)
+				}
+
+				continue
+			}
+
+			if errors.Is(err, errProcessingAbandoned) {
+				// Possibly still processing; requeue rather than waiting any longer, freeing the worker.
+				logger.Warnf("Worker %s: abandoning '%s', still processing %s after exceeding %s (%d abandoned outstanding)",
+					name, d.Body, w.config.Workers.ProcessingGrace, w.config.Workers.ProcessingTimeout,
+					atomic.LoadInt64(&w.abandoned))
+
+				if err := a.Reject(&d, true); err != nil {
+					span.RecordError(ctx, err)
+				}
+
+				continue
+			}
+
+			if errors.Is(err, errProcessingTimeout) {
+				logger.Warnf("Worker %s: processing '%s' exceeded %s", name, d.Body, w.config.Workers.ProcessingTimeout)
+			}
+
+			if err != nil && !errors.Is(err, t.ErrDenied) {
+				// Retry when the index is temporarily unavailable, names could not be resolved, extraction failed
+				// transiently or processing timed out, drop otherwise (e.g. on mapping conflicts).
+				shouldRetry := errors.Is(err, index.ErrIndexUnavailable) || errors.Is(err, t.ErrUnresolvable) ||
+					errors.Is(err, crawler.ErrExtractionFailed) || errors.Is(err, errProcessingTimeout)
 
 				span.RecordError(ctx, err)
 
-				if err := d.Reject(shouldRetry); err != nil {
+				if shouldRetry {
+					err = w.retry(ctx, q, &d, a, err)
+				} else {
+					err = a.Reject(&d, false)
+				}
+
+				if err != nil {
 					span.RecordError(ctx, err)
 				}
 			} else {
-				if err := d.Ack(false); err != nil {
+				if err := a.Ack(&d); err != nil {
 					span.RecordError(ctx, err)
+				} else if crawls {
+					w.duplicates.remember(&d)
 				}
 			}
 		}
 	}
 }
 
-func (w *Pool) startPool(ctx context.Context, deliveries <-chan samqp.Delivery, workers int, poolName string) {
+func (w *Pool) startPool(ctx context.Context, q queue.Queue, deliveries <-chan samqp.Delivery, handle deliveryHandler, crawls bool, workers int, poolName string, b *batch) {
 	ctx, span := w.Tracer.Start(ctx, "crawler.worker.startPool")
 	defer span.End()
 
+	// Track progress per pool.
+	c := cursor.New(w.cursorIndex, poolName, w.Instrumentation)
+
+	// Deliveries for a pool come from a single channel, allowing acknowledgements to be batched.
+	a := newAcker(w.config.Workers.AckBatchSize)
+
+	m := w.instruments.forPool(poolName)
+
+	// Flush when all workers have stopped.
+	flushCtx := shutdownContext{ctx, w.flush}
+
+	w.flushers.Add(2)
+	go func() {
+		defer w.flushers.Done()
+		c.Start(flushCtx, w.config.Workers.CursorInterval)
+	}()
+	go func() {
+		defer w.flushers.Done()
+		a.Start(flushCtx, w.config.Workers.AckFlushInterval)
+	}()
+
+	w.workers.Add(workers)
 	for i := 0; i < workers; i++ {
 		name := fmt.Sprintf("%s-%d", poolName, i)
-		go w.startWorker(ctx, deliveries, name)
+		go w.startWorker(ctx, q, deliveries, handle, crawls, name, m, c, a, b)
+	}
+}
+
+func (w *Pool) start(ctx context.Context, b *batch) {
+	w.work, w.cancelWork = context.WithCancel(context.Background())
+	w.flush, w.stopFlushers = context.WithCancel(context.Background())
+
+	logger.Infof("Starting %d workers for files", w.config.Workers.FileWorkers)
+	w.startPool(ctx, w.consumeQueues.Files, w.consumeChans.Files, w.crawlDelivery, true, w.config.Workers.FileWorkers, "files", b)
+
+	logger.Infof("Starting %d workers for hashes", w.config.Workers.HashWorkers)
+	w.startPool(ctx, w.consumeQueues.Hashes, w.consumeChans.Hashes, w.crawlDelivery, true, w.config.Workers.HashWorkers, "hashes", b)
+
+	logger.Infof("Starting %d workers for directories", w.config.Workers.DirectoryWorkers)
+	w.startPool(ctx, w.consumeQueues.Directories, w.consumeChans.Directories, w.crawlDelivery, true, w.config.Workers.DirectoryWorkers, "directories", b)
+
+	if w.consumeQueues.Invalids != nil {
+		logger.Infof("Starting %d workers for invalids", w.config.Workers.InvalidWorkers)
+		w.startPool(ctx, w.consumeQueues.Invalids, w.consumeChans.Invalids, w.indexInvalidDelivery, false, w.config.Workers.InvalidWorkers, "invalids", b)
 	}
 }
 
-// Start launches the workerpool.
+// Start launches the workerpool, which stops taking deliveries when ctx is closed. Call Shutdown to finish or
+// requeue the deliveries in progress.
 func (w *Pool) Start(ctx context.Context) {
 	ctx, span := w.Tracer.Start(ctx, "crawler.worker.Start")
 	defer span.End()
 
-	log.Printf("Starting %d workers for files", w.config.Workers.FileWorkers)
-	w.startPool(ctx, w.consumeChans.Files, w.config.Workers.FileWorkers, "files")
+	w.start(ctx, nil)
+}
+
+// RunBatch runs the workerpool in batch mode, returning once the batch is done and processed messages have been
+// acknowledged. Unprocessed messages are left in the queues.
+func (w *Pool) RunBatch(ctx context.Context, opts BatchOptions) error {
+	ctx, span := w.Tracer.Start(ctx, "crawler.worker.RunBatch")
+	defer span.End()
+
+	b := newBatch(opts)
+
+	w.start(ctx, b)
+
+	select {
+	case <-ctx.Done():
+	case <-b.Done():
+	}
+
+	// Finish in-progress crawls, then flush acknowledgements and cursors.
+	w.Shutdown()
 
-	log.Printf("Starting %d workers for hashes", w.config.Workers.HashWorkers)
-	w.startPool(ctx, w.consumeChans.Hashes, w.config.Workers.HashWorkers, "hashes")
+	logger.Infof("Batch done after %d messages", b.processed())
 
-	log.Printf("Starting %d workers for directories", w.config.Workers.DirectoryWorkers)
-	w.startPool(ctx, w.consumeChans.Directories, w.config.Workers.DirectoryWorkers, "directories")
+	return ctx.Err()
 }
 
 func (w *Pool) makeConsumeChans(ctx context.Context) error {
 	var (
-		queues *crawler.Queues
-		err    error
+		amqpConnection *amqp.Connection
+		queues         *crawler.Queues
+		err            error
 	)
 
-	if queues, err = w.getQueues(ctx); err != nil {
+	if amqpConnection, err = w.getAMQPConnection(ctx); err != nil {
 		return err
 	}
 
+	if queues, err = w.getQueues(ctx, amqpConnection); err != nil {
+		return err
+	}
+
+	// Consumed queues are used for explicit retries.
+	w.consumeQueues = queues
+
+	if w.config.Workers.MaxAttempts > 0 && w.config.Workers.RetryDelay > 0 {
+		// Retry through delayed queues, rather than right away.
+		consumed := map[string]queue.Queue{
+			w.config.Queues.Files.Name:       queues.Files,
+			w.config.Queues.Directories.Name: queues.Directories,
+			w.config.Queues.Hashes.Name:      queues.Hashes,
+		}
+
+		if queues.Invalids != nil {
+			consumed[w.config.Queues.Invalids.Name] = queues.Invalids
+		}
+
+		if w.retryQueues, err = w.getRetryQueues(ctx, amqpConnection, consumed); err != nil {
+			return err
+		}
+	}
+
+	// A growing depth signals the crawler is falling behind.
+	observeQueueDepths(w.Meter, map[string]queue.Queue{
+		w.config.Queues.Hashes.Name: queues.Hashes,
+		w.config.Queues.Files.Name:  queues.Files,
+	})
+
 	if w.consumeChans.Files, err = queues.Files.Consume(ctx); err != nil {
 		return err
 	}
@@ -249,6 +709,12 @@ func (w *Pool) makeConsumeChans(ctx context.Context) error {
 		return err
 	}
 
+	if queues.Invalids != nil {
+		if w.consumeChans.Invalids, err = queues.Invalids.Consume(ctx); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -262,19 +728,32 @@ func (w *Pool) init(ctx context.Context) error {
 		Context: ctx,
 	}
 
-	log.Println("Initializing crawler.")
+	logger.Infof("Initializing crawler.")
 	if err := w.makeCrawler(ctx); err != nil {
 		return err
 	}
 
-	log.Println("Initializing consuming channels.")
+	logger.Infof("Initializing consuming channels.")
 	return w.makeConsumeChans(ctx)
 }
 
+func newCrawlCounter(meter metric.Meter) metric.Int64Counter {
+	return metric.Must(meter).NewInt64Counter(
+		"ipfs_search.crawler.worker.crawls",
+		metric.WithDescription("Crawled resources, by whether it was the first attempt and whether it succeeded."),
+	)
+}
+
 // NewPool initializes and returns a new worker pool.
 func NewPool(ctx context.Context, c *config.Config, i *instr.Instrumentation) (*Pool, error) {
 	w := &Pool{
 		config:          c,
+		budget:          newBudget(uint64(c.Workers.MaxInflightSize), i.Meter),
+		duplicates:      newDuplicates(&c.Workers, i.Meter),
+		limiter:         newLimiter(c.Workers.MaxInflight, i.Meter),
+		crawlRate:       newCrawlRate(&c.Workers, i.Meter),
+		crawlCounter:    newCrawlCounter(i.Meter),
+		instruments:     newPoolInstruments(i.Meter),
 		Instrumentation: i,
 	}
 
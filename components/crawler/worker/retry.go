@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// retrier is implemented by queues supporting explicit retries, like amqp.Queue.
+type retrier interface {
+	Retry(ctx context.Context, d *samqp.Delivery, body []byte, cause error) error
+	DeadLetter(ctx context.Context, d *samqp.Delivery, body []byte, cause error) error
+}
+
+// delayedRetrier is implemented by delayed queues supporting retries, like amqp.DelayedQueue.
+type delayedRetrier interface {
+	RetryDelayed(ctx context.Context, d *samqp.Delivery, body []byte, cause error, delay time.Duration) error
+}
+
+// retryDelay returns the delay before the given (1-based) attempt is retried: RetryDelay, doubling with every
+// attempt up to MaxRetryDelay.
+func (w *Pool) retryDelay(attempt uint) time.Duration {
+	delay, max := w.config.Workers.RetryDelay, w.config.Workers.MaxRetryDelay
+
+	for i := uint(1); i < attempt && (max == 0 || delay < max); i++ {
+		delay *= 2
+	}
+
+	if max > 0 && delay > max {
+		delay = max
+	}
+
+	return delay
+}
+
+// backoff waits for delay, returning early when the context is done.
+func backoff(ctx context.Context, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// retry settles a delivery which failed with a retryable error.
+//
+// With MaxAttempts set, the delivery is re-published with an incremented attempt counter and acknowledged, through
+// the delayed retry queue of q when there is one; after MaxAttempts attempts it is routed to the dead-letter queue,
+// or dropped when there is none. Otherwise, the delivery is rejected for redelivery by the broker after RetryDelay, during which the worker waits; this keeps failing
+// deliveries (e.g. while the index is unavailable) from being redelivered right away, over and over.
+func (w *Pool) retry(ctx context.Context, q queue.Queue, d *samqp.Delivery, a *acker, cause error) error {
+	rq, ok := q.(retrier)
+	if w.config.Workers.MaxAttempts == 0 || !ok {
+		// Requeued right away when closing down.
+		backoff(ctx, w.config.Workers.RetryDelay)
+
+		return a.Reject(d, true)
+	}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{},
+	}
+
+	if err := json.Unmarshal(d.Body, r); err != nil {
+		// This should never happen, as the delivery failed with a retryable error.
+		return a.Reject(d, false)
+	}
+
+	r.Attempts++
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return a.Reject(d, true)
+	}
+
+	if r.Attempts < w.config.Workers.MaxAttempts {
+		if dq, ok := w.retryQueues[q]; ok {
+			err = dq.RetryDelayed(ctx, d, body, cause, w.retryDelay(r.Attempts))
+		} else {
+			err = rq.Retry(ctx, d, body, cause)
+		}
+	} else {
+		logger.Warnf("Giving up on '%s' after %d attempts: %v", r, r.Attempts, cause)
+
+		err = rq.DeadLetter(ctx, d, body, cause)
+		if errors.Is(err, amqp.ErrNoDeadLetter) {
+			return a.Reject(d, false)
+		}
+	}
+
+	if err != nil {
+		// Re-publishing failed; leave it to the broker.
+		return a.Reject(d, true)
+	}
+
+	return a.Ack(d)
+}
@@ -0,0 +1,220 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/config"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type acknowledgerMock struct {
+	mock.Mock
+}
+
+func (m *acknowledgerMock) Ack(tag uint64, multiple bool) error {
+	args := m.Called(tag, multiple)
+	return args.Error(0)
+}
+
+func (m *acknowledgerMock) Nack(tag uint64, multiple bool, requeue bool) error {
+	args := m.Called(tag, multiple, requeue)
+	return args.Error(0)
+}
+
+func (m *acknowledgerMock) Reject(tag uint64, requeue bool) error {
+	args := m.Called(tag, requeue)
+	return args.Error(0)
+}
+
+type queueMock struct {
+	mock.Mock
+}
+
+func (m *queueMock) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	args := m.Called(ctx, params, priority)
+	return args.Error(0)
+}
+
+func (m *queueMock) Consume(ctx context.Context) (<-chan samqp.Delivery, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(<-chan samqp.Delivery), args.Error(1)
+}
+
+type retryQueueMock struct {
+	queueMock
+}
+
+func (m *retryQueueMock) Retry(ctx context.Context, d *samqp.Delivery, body []byte, cause error) error {
+	args := m.Called(ctx, d, body, cause)
+	return args.Error(0)
+}
+
+func (m *retryQueueMock) DeadLetter(ctx context.Context, d *samqp.Delivery, body []byte, cause error) error {
+	args := m.Called(ctx, d, body, cause)
+	return args.Error(0)
+}
+
+func retryPool(maxAttempts uint, delay time.Duration) *Pool {
+	return &Pool{
+		config: &config.Config{
+			Workers: config.Workers{
+				MaxAttempts: maxAttempts,
+				RetryDelay:  delay,
+			},
+		},
+	}
+}
+
+func retryDelivery(test *testing.T, attempts uint) (samqp.Delivery, *acknowledgerMock) {
+	ack := &acknowledgerMock{}
+	ack.Test(test)
+
+	body, err := json.Marshal(&t.AnnotatedResource{
+		Resource: &t.Resource{Protocol: t.IPFSProtocol, ID: "QmYAqhbqNDpTxf3mg5kjwn4aUZk2RKTJoLV5hX5XU4pRs"},
+		Attempts: attempts,
+	})
+	assert.NoError(test, err)
+
+	return samqp.Delivery{Acknowledger: ack, DeliveryTag: 1, Body: body}, ack
+}
+
+func TestRetryRequeueDelay(test *testing.T) {
+	w := retryPool(0, 20*time.Millisecond)
+	d, ack := retryDelivery(test, 0)
+	ack.On("Reject", uint64(1), true).Return(nil).Once()
+
+	start := time.Now()
+	err := w.retry(context.Background(), &queueMock{}, &d, nil, errors.New("unavailable"))
+
+	assert.NoError(test, err)
+	assert.GreaterOrEqual(test, int64(time.Since(start)), int64(20*time.Millisecond))
+	ack.AssertExpectations(test)
+}
+
+func TestRetryRequeueCancelled(test *testing.T) {
+	w := retryPool(0, time.Hour)
+	d, ack := retryDelivery(test, 0)
+	ack.On("Reject", uint64(1), true).Return(nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Requeued right away when closing down.
+	err := w.retry(ctx, &queueMock{}, &d, nil, errors.New("unavailable"))
+
+	assert.NoError(test, err)
+	ack.AssertExpectations(test)
+}
+
+func TestRetryAttempt(test *testing.T) {
+	w := retryPool(3, 0)
+	d, ack := retryDelivery(test, 1)
+	cause := errors.New("unavailable")
+
+	q := &retryQueueMock{}
+	q.Test(test)
+	q.On("Retry", mock.Anything, &d, mock.MatchedBy(func(body []byte) bool {
+		r := &t.AnnotatedResource{Resource: &t.Resource{}}
+		return json.Unmarshal(body, r) == nil && r.Attempts == 2
+	}), cause).Return(nil).Once()
+	ack.On("Ack", uint64(1), false).Return(nil).Once()
+
+	err := w.retry(context.Background(), q, &d, nil, cause)
+
+	assert.NoError(test, err)
+	q.AssertExpectations(test)
+	ack.AssertExpectations(test)
+}
+
+func TestRetryExhausted(test *testing.T) {
+	w := retryPool(3, 0)
+	d, ack := retryDelivery(test, 2)
+	cause := errors.New("unavailable")
+
+	q := &retryQueueMock{}
+	q.Test(test)
+	q.On("DeadLetter", mock.Anything, &d, mock.Anything, cause).Return(nil).Once()
+	ack.On("Ack", uint64(1), false).Return(nil).Once()
+
+	err := w.retry(context.Background(), q, &d, nil, cause)
+
+	assert.NoError(test, err)
+	q.AssertExpectations(test)
+	ack.AssertExpectations(test)
+}
+
+func TestRetryExhaustedNoDeadLetter(test *testing.T) {
+	w := retryPool(3, 0)
+	d, ack := retryDelivery(test, 2)
+	cause := errors.New("unavailable")
+
+	q := &retryQueueMock{}
+	q.Test(test)
+	q.On("DeadLetter", mock.Anything, &d, mock.Anything, cause).Return(amqp.ErrNoDeadLetter).Once()
+	ack.On("Reject", uint64(1), false).Return(nil).Once()
+
+	err := w.retry(context.Background(), q, &d, nil, cause)
+
+	assert.NoError(test, err)
+	q.AssertExpectations(test)
+	ack.AssertExpectations(test)
+}
+
+type delayedRetrierMock struct {
+	mock.Mock
+}
+
+func (m *delayedRetrierMock) RetryDelayed(ctx context.Context, d *samqp.Delivery, body []byte, cause error, delay time.Duration) error {
+	args := m.Called(ctx, d, body, cause, delay)
+	return args.Error(0)
+}
+
+func TestRetryDelayed(test *testing.T) {
+	w := retryPool(5, time.Second)
+	w.config.Workers.MaxRetryDelay = time.Minute
+
+	d, ack := retryDelivery(test, 2)
+	cause := errors.New("unavailable")
+
+	q := &retryQueueMock{}
+	q.Test(test)
+
+	dq := &delayedRetrierMock{}
+	dq.Test(test)
+	w.retryQueues = map[queue.Queue]delayedRetrier{q: dq}
+
+	// Third attempt.
+	dq.On("RetryDelayed", mock.Anything, &d, mock.Anything, cause, 4*time.Second).Return(nil).Once()
+	ack.On("Ack", uint64(1), false).Return(nil).Once()
+
+	err := w.retry(context.Background(), q, &d, nil, cause)
+
+	assert.NoError(test, err)
+	dq.AssertExpectations(test)
+	q.AssertNotCalled(test, "Retry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	ack.AssertExpectations(test)
+}
+
+func TestRetryDelay(test *testing.T) {
+	w := retryPool(0, time.Second)
+	w.config.Workers.MaxRetryDelay = 5 * time.Second
+
+	assert.Equal(test, time.Second, w.retryDelay(1))
+	assert.Equal(test, 2*time.Second, w.retryDelay(2))
+	assert.Equal(test, 4*time.Second, w.retryDelay(3))
+	assert.Equal(test, 5*time.Second, w.retryDelay(4))
+	assert.Equal(test, 5*time.Second, w.retryDelay(1000))
+
+	w.config.Workers.MaxRetryDelay = 0
+	assert.Equal(test, 8*time.Second, w.retryDelay(4))
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// shutdownContext carries the values of a context, e.g. its span, while being cancelled by another, so that work in
+// progress can outlive the context it was started from.
+type shutdownContext struct {
+	context.Context // Values.
+
+	cancel context.Context
+}
+
+func (c shutdownContext) Deadline() (time.Time, bool) { return c.cancel.Deadline() }
+func (c shutdownContext) Done() <-chan struct{}       { return c.cancel.Done() }
+func (c shutdownContext) Err() error                  { return c.cancel.Err() }
+
+// Shutdown waits for the deliveries in progress after the context the pool was started with has been closed. After
+// ShutdownTimeout, remaining deliveries are cancelled and requeued, so that slow tasks are never lost. It returns
+// once all workers have stopped and acknowledgements and progress have been flushed.
+func (w *Pool) Shutdown() {
+	done := make(chan struct{})
+	go func() {
+		w.workers.Wait()
+		close(done)
+	}()
+
+	timeout := w.config.Workers.ShutdownTimeout
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		logger.Warnf("Cancelling deliveries still in progress after %s", timeout)
+		w.cancelWork()
+		<-done
+	}
+
+	w.cancelWork()
+
+	w.stopFlushers()
+	w.flushers.Wait()
+
+	if n := atomic.LoadInt64(&w.abandoned); n > 0 {
+		logger.Warnf("Shutting down with %d abandoned deliveries still processing", n)
+	}
+
+	if n := atomic.LoadInt64(&w.requeued); n > 0 {
+		logger.Warnf("Requeued %d deliveries cancelled at shutdown", n)
+	} else {
+		logger.Infof("Shut down without cancelling deliveries")
+	}
+}
@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	samqp "github.com/streadway/amqp"
+
+	"github.com/ipfs-search/ipfs-search/components/cursor"
+)
+
+var (
+	// errProcessingTimeout is returned when processing a delivery exceeds the processing timeout.
+	errProcessingTimeout = errors.New("processing timeout exceeded")
+
+	// errProcessingAbandoned is returned when processing did not return within the grace period after exceeding the
+	// processing timeout.
+	errProcessingAbandoned = errors.New("processing abandoned")
+)
+
+// deliveryHandler processes a delivery, recording progress in c.
+type deliveryHandler func(ctx context.Context, d samqp.Delivery, c *cursor.Tracker) error
+
+// Processing states, for telling whether processing returned before it was abandoned.
+const (
+	processing int32 = iota
+	processed
+	abandoned
+)
+
+// withTimeout runs process, cancelling it after ProcessingTimeout when set and returning errProcessingTimeout when it
+// failed for exceeding it. The resources held for processing are freed by release, once process has returned.
+//
+// After cancelling, process is waited for during ProcessingGrace: until it returns, it may still be writing to the
+// index or recording progress, so its delivery should not be settled (and redelivered) before. Processing which
+// ignores its context is abandoned after that, returning errProcessingAbandoned, so that it doesn't hold its worker
+// indefinitely; it keeps holding its resources until it returns, if ever.
+func (w *Pool) withTimeout(ctx context.Context, process func(context.Context) error, release func()) error {
+	timeout := w.config.Workers.ProcessingTimeout
+	if timeout == 0 {
+		defer release()
+		return process(ctx)
+	}
+
+	processCtx, cancel := context.WithTimeout(ctx, timeout)
+
+	state := processing
+
+	// Buffered, so that abandoned processing doesn't block on returning.
+	done := make(chan error, 1)
+	go func() {
+		defer cancel()
+
+		err := process(processCtx)
+		release()
+
+		if !atomic.CompareAndSwapInt32(&state, processing, processed) {
+			n := atomic.AddInt64(&w.abandoned, -1)
+			logger.Infof("Abandoned processing returned after all, %d still outstanding: %v", n, err)
+		}
+
+		done <- err
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-processCtx.Done():
+		grace := time.NewTimer(w.config.Workers.ProcessingGrace)
+		defer grace.Stop()
+
+		select {
+		case err = <-done:
+		case <-grace.C:
+			if atomic.CompareAndSwapInt32(&state, processing, abandoned) {
+				atomic.AddInt64(&w.abandoned, 1)
+
+				if ctx.Err() != nil {
+					// Cancelled at shutdown.
+					return ctx.Err()
+				}
+
+				return errProcessingAbandoned
+			}
+
+			// Returned just now.
+			err = <-done
+		}
+	}
+
+	// Cancellation at shutdown is not a timeout.
+	if err != nil && errors.Is(processCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf("%w: %v", errProcessingTimeout, err)
+	}
+
+	return err
+}
@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/config"
+)
+
+func timeoutPool(timeout time.Duration) *Pool {
+	return &Pool{
+		config: &config.Config{
+			Workers: config.Workers{
+				ProcessingTimeout: timeout,
+				ProcessingGrace:   time.Second,
+			},
+		},
+	}
+}
+
+func TestWithTimeoutExceeded(t *testing.T) {
+	w := timeoutPool(10 * time.Millisecond)
+
+	returned := false
+	err := w.withTimeout(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		returned = true
+
+		return ctx.Err()
+	}, func() {})
+
+	assert.True(t, errors.Is(err, errProcessingTimeout))
+	// Processing has stopped before withTimeout returned.
+	assert.True(t, returned)
+}
+
+func TestWithTimeoutAbandoned(t *testing.T) {
+	w := timeoutPool(10 * time.Millisecond)
+	w.config.Workers.ProcessingGrace = 10 * time.Millisecond
+
+	stuck := make(chan struct{})
+	released := make(chan struct{})
+
+	err := w.withTimeout(context.Background(), func(ctx context.Context) error {
+		// Ignores its context.
+		<-stuck
+		return nil
+	}, func() {
+		close(released)
+	})
+
+	assert.True(t, errors.Is(err, errProcessingAbandoned))
+	assert.False(t, errors.Is(err, errProcessingTimeout))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&w.abandoned))
+
+	// Resources are held until processing returns.
+	select {
+	case <-released:
+		t.Fatal("released while processing")
+	default:
+	}
+
+	close(stuck)
+
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("not released after processing returned")
+	}
+
+	// The count is updated right after releasing.
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&w.abandoned) == 0 }, time.Second, time.Millisecond)
+}
+
+func TestWithTimeoutFinished(t *testing.T) {
+	w := timeoutPool(time.Second)
+
+	err := w.withTimeout(context.Background(), func(ctx context.Context) error {
+		return nil
+	}, func() {})
+
+	assert.NoError(t, err)
+}
+
+func TestWithTimeoutError(t *testing.T) {
+	w := timeoutPool(time.Second)
+	processErr := errors.New("failed")
+
+	err := w.withTimeout(context.Background(), func(ctx context.Context) error {
+		return processErr
+	}, func() {})
+
+	assert.Equal(t, processErr, err)
+}
+
+func TestWithTimeoutCancelled(t *testing.T) {
+	w := timeoutPool(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := w.withTimeout(ctx, func(ctx context.Context) error {
+		return ctx.Err()
+	}, func() {})
+
+	// Cancellation at shutdown is not a timeout.
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, errProcessingTimeout))
+}
+
+func TestWithTimeoutDisabled(t *testing.T) {
+	w := timeoutPool(0)
+
+	err := w.withTimeout(context.Background(), func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+
+		return nil
+	}, func() {})
+
+	assert.NoError(t, err)
+}
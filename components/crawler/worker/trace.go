@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+
+	samqp "github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/trace"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// startDeliverySpan starts a span for processing d, continuing the trace of its publisher, as propagated in its
+// headers. Without trace context, e.g. for messages published by older versions, a new trace is started rather than
+// adding to that of the long-running worker.
+func (w *Pool) startDeliverySpan(ctx context.Context, d *samqp.Delivery, name string) (context.Context, trace.Span) {
+	if remoteCtx, ok := queue.ExtractTraceContext(ctx, d); ok {
+		return w.Tracer.Start(remoteCtx, name)
+	}
+
+	return w.Tracer.Start(ctx, name, trace.WithNewRoot())
+}
@@ -0,0 +1,126 @@
+// Package cursor persists crawl progress, recording the last successfully processed resource.
+//
+// Cursors are purely informational for the normal crawling flow; they allow operators to track progress and
+// allow management tools to resume bulk operations after a restart.
+package cursor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
+)
+
+var logger = logging.New("cursor")
+
+// Cursor represents the progress of a named crawl operation.
+type Cursor struct {
+	LastID    string    `json:"last_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Processed uint64    `json:"processed"` // Items processed since the tracker started.
+}
+
+// Tracker records progress in memory and periodically persists it to an Index.
+type Tracker struct {
+	index index.Index
+	name  string
+
+	mu     sync.Mutex
+	cursor Cursor
+	dirty  bool
+
+	*instr.Instrumentation
+}
+
+// New returns a Tracker persisting the cursor called name to index.
+func New(index index.Index, name string, i *instr.Instrumentation) *Tracker {
+	return &Tracker{
+		index:           index,
+		name:            name,
+		Instrumentation: i,
+	}
+}
+
+// Record records id as the last successfully processed item. It does not block on persistence.
+func (t *Tracker) Record(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cursor.LastID = id
+	t.cursor.Timestamp = time.Now().UTC().Truncate(time.Second)
+	t.cursor.Processed++
+	t.dirty = true
+}
+
+// Flush persists the current cursor, if it changed since the last flush.
+func (t *Tracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	cursor := t.cursor
+	t.dirty = false
+	t.mu.Unlock()
+
+	ctx, span := t.Tracer.Start(ctx, "cursor.Flush",
+		trace.WithAttributes(label.String("cursor", t.name)),
+		trace.WithAttributes(label.String("last_id", cursor.LastID)),
+	)
+	defer span.End()
+
+	if err := t.index.Index(ctx, t.name, &cursor); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+
+		// Retry on next flush.
+		t.mu.Lock()
+		t.dirty = true
+		t.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// Start periodically flushes the cursor until the context is done, flushing a final time on exit.
+func (t *Tracker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Flush with a fresh context, as ctx is done.
+			flushCtx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := t.Flush(flushCtx); err != nil {
+				logger.Errorf("Error persisting cursor %s: %v", t.name, err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := t.Flush(ctx); err != nil {
+				logger.Errorf("Error persisting cursor %s: %v", t.name, err)
+			}
+		}
+	}
+}
+
+// Get retrieves the persisted cursor called name from index, returning nil when it does not exist.
+func Get(ctx context.Context, index index.Index, name string) (*Cursor, error) {
+	cursor := new(Cursor)
+
+	found, err := index.Get(ctx, name, cursor)
+	if !found || err != nil {
+		return nil, err
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,78 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type CursorTestSuite struct {
+	suite.Suite
+
+	ctx     context.Context
+	index   *index.Mock
+	tracker *Tracker
+}
+
+func (s *CursorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.index = &index.Mock{}
+	s.index.Test(s.T())
+	s.tracker = New(s.index, "files", instr.New())
+}
+
+func (s *CursorTestSuite) TestFlushClean() {
+	// Nothing recorded, nothing persisted.
+	s.NoError(s.tracker.Flush(s.ctx))
+	s.index.AssertExpectations(s.T())
+}
+
+func (s *CursorTestSuite) TestFlush() {
+	s.tracker.Record("QmA")
+	s.tracker.Record("QmB")
+
+	s.index.
+		On("Index", mock.Anything, "files", mock.MatchedBy(func(c *Cursor) bool {
+			return c.LastID == "QmB" && c.Processed == 2
+		})).
+		Return(nil).
+		Once()
+
+	s.NoError(s.tracker.Flush(s.ctx))
+
+	// Second flush is a no-op.
+	s.NoError(s.tracker.Flush(s.ctx))
+
+	s.index.AssertExpectations(s.T())
+}
+
+func (s *CursorTestSuite) TestFlushErrorRetries() {
+	s.tracker.Record("QmA")
+
+	testErr := errors.New("index error")
+
+	s.index.
+		On("Index", mock.Anything, "files", mock.Anything).
+		Return(testErr).
+		Once()
+
+	s.index.
+		On("Index", mock.Anything, "files", mock.Anything).
+		Return(nil).
+		Once()
+
+	s.Equal(testErr, s.tracker.Flush(s.ctx))
+	s.NoError(s.tracker.Flush(s.ctx))
+
+	s.index.AssertExpectations(s.T())
+}
+
+func TestCursorTestSuite(t *testing.T) {
+	suite.Run(t, new(CursorTestSuite))
+}
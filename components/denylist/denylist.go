@@ -0,0 +1,181 @@
+// Package denylist provides a reloadable list of CIDs which are not to be crawled or indexed.
+//
+// The list format is compatible with the IPFS badbits list (https://badbits.dwebops.pub/): each line contains either
+// a plain CID or a double-hashed entry of the form `//<hex sha256 of "<CIDv1 base32>/">`. Empty lines and lines
+// starting with `#` or `!` are ignored. Path-specific entries are not supported; only entries blocking whole CIDs match.
+//
+// Plain CIDs are matched by multihash, so entries deny the content they refer to regardless of CID version, codec or
+// base.
+package denylist
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/logging"
+)
+
+var logger = logging.New("denylist")
+
+// Denylist is a concurrency-safe set of denied CIDs. A nil Denylist denies nothing.
+type Denylist struct {
+	path string
+
+	mu      sync.RWMutex
+	cids    map[string]bool // Keyed by multihash.
+	hashes  map[string]bool
+	modTime time.Time
+}
+
+// New returns a Denylist loaded from the file at path.
+func New(path string) (*Denylist, error) {
+	d := &Denylist{
+		path: path,
+	}
+
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// read parses denylist entries from r, returning the number of entries skipped as they are not valid CIDs.
+func read(r io.Reader) (map[string]bool, map[string]bool, int, error) {
+	cids, hashes := make(map[string]bool), make(map[string]bool)
+	skipped := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, "!"):
+			continue
+		case strings.HasPrefix(line, "//"):
+			hashes[strings.ToLower(strings.TrimPrefix(line, "//"))] = true
+		default:
+			c, err := cid.Decode(line)
+			if err != nil {
+				logger.Debugf("Skipping denylist entry '%s': %v", line, err)
+				skipped++
+				continue
+			}
+
+			cids[string(c.Hash())] = true
+		}
+	}
+
+	return cids, hashes, skipped, scanner.Err()
+}
+
+// Reload reloads the denylist from disk.
+func (d *Denylist) Reload() error {
+	f, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	cids, hashes, skipped, err := read(f)
+	if err != nil {
+		return err
+	}
+
+	if skipped > 0 {
+		logger.Warnf("Skipped %d unsupported entries in denylist %s", skipped, d.path)
+	}
+
+	d.mu.Lock()
+	d.cids, d.hashes, d.modTime = cids, hashes, stat.ModTime()
+	d.mu.Unlock()
+
+	logger.Infof("Loaded denylist %s with %d CIDs and %d hashes", d.path, len(cids), len(hashes))
+
+	return nil
+}
+
+// isModified returns true when the denylist file has been modified since it was last loaded.
+func (d *Denylist) isModified() (bool, error) {
+	stat, err := os.Stat(d.path)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return !stat.ModTime().Equal(d.modTime), nil
+}
+
+// Watch reloads the denylist whenever the file is modified, checking every interval until the context is done.
+func (d *Denylist) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modified, err := d.isModified()
+			if err == nil && modified {
+				err = d.Reload()
+			}
+
+			if err != nil {
+				// Keep the previous list.
+				logger.Errorf("Error reloading denylist %s: %v", d.path, err)
+			}
+		}
+	}
+}
+
+// doubleHash returns the hex-encoded badbits double hash for c.
+func doubleHash(c cid.Cid) string {
+	if c.Version() == 0 {
+		c = cid.NewCidV1(cid.DagProtobuf, c.Hash())
+	}
+
+	sum := sha256.Sum256([]byte(c.String() + "/"))
+	return hex.EncodeToString(sum[:])
+}
+
+// Contains returns true when id is on the denylist.
+func (d *Denylist) Contains(id string) bool {
+	if d == nil {
+		return false
+	}
+
+	c, err := cid.Decode(id)
+	if err != nil {
+		return false
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.cids[string(c.Hash())] {
+		return true
+	}
+
+	if len(d.hashes) == 0 {
+		return false
+	}
+
+	return d.hashes[doubleHash(c)]
+}
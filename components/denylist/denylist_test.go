@@ -0,0 +1,99 @@
+package denylist
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCID   = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+	otherCID  = "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8"
+	hashedCID = "QmehHHRh1a7u66r7fugebp6f6wGNMGCa7eho9cgjwhAcm2"
+)
+
+func writeList(t *testing.T, lines ...string) string {
+	f, err := ioutil.TempFile("", "denylist")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.WriteString(strings.Join(lines, "\n"))
+	require.NoError(t, err)
+
+	return f.Name()
+}
+
+func TestContains(t *testing.T) {
+	path := writeList(t,
+		"# Comment",
+		testCID,
+		"//"+doubleHash(mustDecode(hashedCID)),
+	)
+	defer os.Remove(path)
+
+	d, err := New(path)
+	require.NoError(t, err)
+
+	assert.True(t, d.Contains(testCID))
+	assert.True(t, d.Contains(hashedCID))
+	assert.False(t, d.Contains(otherCID))
+}
+
+func mustDecode(id string) cid.Cid {
+	c, err := cid.Decode(id)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// cidV1 returns the CIDv1 with codec of the content of CIDv0 id.
+func cidV1(codec uint64, id string) string {
+	return cid.NewCidV1(codec, mustDecode(id).Hash()).String()
+}
+
+func TestContainsNormalised(t *testing.T) {
+	path := writeList(t,
+		testCID,
+		cidV1(cid.DagProtobuf, otherCID),
+		"not-a-cid",
+	)
+	defer os.Remove(path)
+
+	d, err := New(path)
+	require.NoError(t, err)
+
+	// Denied regardless of version and codec.
+	assert.True(t, d.Contains(cidV1(cid.DagProtobuf, testCID)))
+	assert.True(t, d.Contains(cidV1(cid.Raw, testCID)))
+	assert.True(t, d.Contains(otherCID))
+
+	assert.False(t, d.Contains(hashedCID))
+	assert.False(t, d.Contains("not-a-cid"))
+}
+
+func TestNilDenylist(t *testing.T) {
+	var d *Denylist
+	assert.False(t, d.Contains(testCID))
+}
+
+func TestReload(t *testing.T) {
+	path := writeList(t, testCID)
+	defer os.Remove(path)
+
+	d, err := New(path)
+	require.NoError(t, err)
+	assert.False(t, d.Contains(otherCID))
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(otherCID), 0644))
+	require.NoError(t, d.Reload())
+
+	assert.True(t, d.Contains(otherCID))
+	assert.False(t, d.Contains(testCID))
+}
@@ -0,0 +1,41 @@
+package extractor
+
+import (
+	"mime"
+	"path"
+)
+
+// Coarse categories of files, as derived from their MIME type.
+const (
+	CategoryCode  = "code"  // Source code, e.g. for the code extractor.
+	CategoryOther = "other" // MIME type unknown or not categorized.
+)
+
+// Category returns the category of mimeType in categories, which maps MIME types or globs (e.g. image/*) to
+// categories. Exact matches are preferred over the longest matching glob; CategoryOther when nothing matches.
+func Category(mimeType string, categories map[string]string) string {
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		mimeType = mediaType
+	}
+
+	if mimeType == "" {
+		return CategoryOther
+	}
+
+	if category, ok := categories[mimeType]; ok {
+		return category
+	}
+
+	var (
+		category = CategoryOther
+		longest  string
+	)
+
+	for pattern, cat := range categories {
+		if matched, _ := path.Match(pattern, mimeType); matched && len(pattern) > len(longest) {
+			category, longest = cat, pattern
+		}
+	}
+
+	return category
+}
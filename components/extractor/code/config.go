@@ -0,0 +1,27 @@
+package code
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+)
+
+// Config specifies the configuration for the code extractor.
+type Config struct {
+	Enabled        bool              // Detect the programming language of source code and extract top-level symbols.
+	RequestTimeout time.Duration     // Timeout for requests to the gateway.
+	MaxFileSize    datasize.ByteSize // Skip larger files, which are mostly generated or minified.
+	MaxSymbols     int               // Maximum number of symbols extracted per file.
+
+	Categories map[string]string // Categories of MIME types, as the crawler's; files in CategoryCode are considered.
+}
+
+// DefaultConfig returns the default configuration for the code extractor.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:        false,
+		RequestTimeout: 60 * time.Second,
+		MaxFileSize:    1024 * 1024, // 1MB
+		MaxSymbols:     1000,
+	}
+}
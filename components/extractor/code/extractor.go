@@ -0,0 +1,99 @@
+// Package code detects the programming language of source code and extracts its top-level symbols, e.g. function and
+// class names.
+package code
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/trace"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Extractor extracts the language and symbols of source code by fetching it from the gateway.
+type Extractor struct {
+	config  *Config
+	fetcher *extractor.Fetcher
+}
+
+// properties are merged into the extracted metadata.
+type properties struct {
+	CodeLanguage string   `json:"code_language,omitempty"`
+	Symbols      []string `json:"symbols,omitempty"`
+}
+
+// isText returns true when the sniffed mimeType may be source code, which is recognized as text or not at all.
+func isText(mimeType string) bool {
+	return mimeType == "" || mimeType == "application/octet-stream" || strings.HasPrefix(mimeType, "text/")
+}
+
+// isCode returns true when r is source code, by the extension of its name or the category of its MIME type.
+func (e *Extractor) isCode(r *t.AnnotatedResource) bool {
+	mimeType := extractor.MimeType(r)
+	isCodeType := extractor.Category(mimeType, e.config.Categories) == extractor.CategoryCode
+
+	// Content sniffed as anything but text isn't code, e.g. MPEG transport streams named .ts.
+	if !isText(r.MimeType) && !isCodeType {
+		return false
+	}
+
+	return isCodeType || byExtension[strings.ToLower(path.Ext(r.Reference.Name))] != nil
+}
+
+// Applies returns true for source code up to the maximum file size.
+func (e *Extractor) Applies(r *t.AnnotatedResource) bool {
+	return e.isCode(r) && r.Size <= uint64(e.config.MaxFileSize)
+}
+
+// parse detects the language of source code and extracts its symbols, returning no properties for unsupported
+// languages and binary content.
+func (e *Extractor) parse(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error) {
+	buf, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	l := detect(r.Reference.Name, extractor.MimeType(r), buf)
+	if l == nil {
+		trace.SpanFromContext(ctx).AddEvent(ctx, "language-not-detected")
+		return nil, nil
+	}
+
+	return properties{
+		CodeLanguage: l.name,
+		Symbols:      l.findSymbols(string(buf), e.config.MaxSymbols),
+	}, nil
+}
+
+// Extract the language and symbols of source code up to the maximum file size, ignoring other resources and content
+// in unsupported languages.
+func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	if !e.Applies(r) {
+		return nil
+	}
+
+	return e.fetcher.Extract(ctx, r, m, e.config.RequestTimeout, int64(e.config.MaxFileSize), e.parse)
+}
+
+// New returns a new code extractor.
+func New(config *Config, client *http.Client, protocol protocol.Protocol, instr *instr.Instrumentation) extractor.Extractor {
+	return &Extractor{
+		config,
+		extractor.NewFetcher("code", client, protocol, instr),
+	}
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ extractor.Extractor = &Extractor{}
+	_ extractor.Selective = &Extractor{}
+)
@@ -0,0 +1,157 @@
+package code
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/extractor/extractortest"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testGo = `// Package main is an example.
+package main
+
+import "fmt"
+
+type Greeter struct {
+	name string
+}
+
+func (g *Greeter) Greet() {
+	fmt.Println("Hello", g.name)
+}
+
+func main() {
+	g := &Greeter{"world"}
+	g.Greet()
+}
+`
+
+const testPython = `#!/usr/bin/env python3
+import sys
+
+
+class Parser:
+    def parse(self, line):
+        return line.split()
+
+
+async def fetch(url):
+    pass
+
+
+def main():
+    Parser().parse(sys.argv[1])
+`
+
+type CodeTestSuite struct {
+	extractortest.Suite
+
+	e   extractor.Extractor
+	cfg *Config
+}
+
+func (s *CodeTestSuite) SetupTest() {
+	s.Suite.SetupTest()
+
+	s.cfg = DefaultConfig()
+	s.cfg.Categories = map[string]string{
+		"text/x-python": extractor.CategoryCode,
+		"text/*":        "document",
+		"video/*":       "video",
+	}
+
+	s.e = New(s.cfg, http.DefaultClient, s.Protocol, instr.New())
+}
+
+func (s *CodeTestSuite) TestExtractGo() {
+	r := s.Resource("main.go", "text/plain", len(testGo))
+	s.ExpectGet(r, []byte(testGo))
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertFetched()
+
+	s.Equal("go", f.CodeLanguage)
+	s.Equal([]string{"Greeter", "Greet", "main"}, f.Symbols)
+}
+
+func (s *CodeTestSuite) TestExtractPythonByMimeType() {
+	r := s.Resource("", "text/x-python", len(testPython))
+	s.ExpectGet(r, []byte(testPython))
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.Equal("python", f.CodeLanguage)
+	s.Equal([]string{"Parser", "fetch", "main"}, f.Symbols)
+}
+
+func (s *CodeTestSuite) TestExtractMaxSymbols() {
+	s.cfg.MaxSymbols = 1
+
+	r := s.Resource("main.go", "", len(testGo))
+	s.ExpectGet(r, []byte(testGo))
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.Equal([]string{"Greeter"}, f.Symbols)
+}
+
+func (s *CodeTestSuite) TestExtractBinary() {
+	// MPEG transport streams share the .ts extension with TypeScript.
+	body := []byte{0x47, 0x40, 0x00, 0x10, 0x00, 0x00, 0xb0, 0x0d}
+	r := s.Resource("episode.ts", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.Empty(f.CodeLanguage)
+	s.Empty(f.Symbols)
+}
+
+func (s *CodeTestSuite) TestExtractSniffedBinary() {
+	r := s.Resource("episode.ts", "video/mp2t", 100)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+}
+
+func (s *CodeTestSuite) TestExtractUnsupported() {
+	r := s.Resource("README", "text/plain", 100)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+}
+
+func (s *CodeTestSuite) TestExtractTooLarge() {
+	r := s.Resource("main.go", "", int(s.cfg.MaxFileSize)+1)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+}
+
+func TestCodeTestSuite(t *testing.T) {
+	suite.Run(t, new(CodeTestSuite))
+}
@@ -0,0 +1,258 @@
+package code
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// language is a programming language, recognized by extension, shebang interpreter or MIME type.
+type language struct {
+	name         string
+	extensions   []string
+	interpreters []string // Without version, e.g. python for python3.
+	mimeTypes    []string
+	symbols      []*regexp.Regexp // Matching top-level declarations; the first submatch is the symbol.
+	keywords     []string         // Matched by symbols, but never symbols themselves.
+}
+
+// Patterns shared by C and C++; function definitions are (non-prototype) declarations followed by parameters.
+var (
+	cFunction = regexp.MustCompile(`^(?:[A-Za-z_][\w:<>,]*[\s*&]+)+([A-Za-z_][\w:~]*)\s*\([^;]*$`)
+	cType     = regexp.MustCompile(`^(?:typedef\s+)?(?:struct|union|enum|class)\s+([A-Za-z_]\w*)\s*(?:[:{]|$)`)
+)
+
+// cppHeader matches declarations which only occur in C++, to tell C++ headers from C ones.
+var cppHeader = regexp.MustCompile(`(?m)^\s*(?:class\s+\w+|namespace\b|template\s*<)`)
+
+var languages = []*language{
+	{
+		name:       "go",
+		extensions: []string{".go"},
+		mimeTypes:  []string{"text/x-go"},
+		symbols: []*regexp.Regexp{
+			regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`),
+			regexp.MustCompile(`^type\s+([A-Za-z_]\w*)`),
+		},
+	},
+	{
+		name:         "python",
+		extensions:   []string{".py", ".pyw"},
+		interpreters: []string{"python"},
+		mimeTypes:    []string{"text/x-python", "text/x-script.python"},
+		symbols: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z_]\w*)`),
+			regexp.MustCompile(`^class\s+([A-Za-z_]\w*)`),
+		},
+	},
+	{
+		name:         "javascript",
+		extensions:   []string{".js", ".mjs", ".cjs", ".jsx"},
+		interpreters: []string{"node", "nodejs"},
+		mimeTypes:    []string{"application/javascript", "text/javascript", "application/x-javascript"},
+		symbols: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)`),
+			regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?class\s+([A-Za-z_$][\w$]*)`),
+			regexp.MustCompile(`^(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*=>|[A-Za-z_$][\w$]*\s*=>)`),
+		},
+	},
+	{
+		// Not by MIME type; .ts is registered as video/mp2t.
+		name:         "typescript",
+		extensions:   []string{".ts", ".tsx", ".mts", ".cts"},
+		interpreters: []string{"deno", "ts-node"},
+		symbols: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)`),
+			regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?class\s+([A-Za-z_$][\w$]*)`),
+			regexp.MustCompile(`^(?:export\s+)?(?:declare\s+)?(?:const\s+)?(?:interface|type|enum|namespace)\s+([A-Za-z_$][\w$]*)`),
+			regexp.MustCompile(`^(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|[A-Za-z_$][\w$]*\s*=>)`),
+		},
+	},
+	{
+		name:       "rust",
+		extensions: []string{".rs"},
+		mimeTypes:  []string{"text/x-rustsrc", "text/rust"},
+		symbols: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:(?:const|async|unsafe|extern(?:\s+"[^"]*")?)\s+)*fn\s+([A-Za-z_]\w*)`),
+			regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:unsafe\s+)?(?:struct|enum|union|trait|type|mod)\s+([A-Za-z_]\w*)`),
+		},
+	},
+	{
+		// Methods are nested in classes; only top-level types are extracted.
+		name:       "java",
+		extensions: []string{".java"},
+		mimeTypes:  []string{"text/x-java-source", "text/x-java"},
+		symbols: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:(?:public|protected|private|abstract|final|static|sealed|strictfp)\s+)*(?:class|interface|enum|record|@interface)\s+([A-Za-z_]\w*)`),
+		},
+	},
+	{
+		name:       "c",
+		extensions: []string{".c", ".h"},
+		mimeTypes:  []string{"text/x-c", "text/x-csrc", "text/x-chdr"},
+		symbols:    []*regexp.Regexp{cType, cFunction},
+		keywords:   []string{"if", "else", "for", "while", "switch", "return", "sizeof"},
+	},
+	{
+		name:       "cpp",
+		extensions: []string{".cc", ".cpp", ".cxx", ".c++", ".hh", ".hpp", ".hxx"},
+		mimeTypes:  []string{"text/x-c++src", "text/x-c++hdr"},
+		symbols: []*regexp.Regexp{
+			cType,
+			regexp.MustCompile(`^namespace\s+([A-Za-z_][\w:]*)`),
+			cFunction,
+		},
+		keywords: []string{"if", "else", "for", "while", "switch", "return", "sizeof", "catch"},
+	},
+	{
+		name:         "ruby",
+		extensions:   []string{".rb"},
+		interpreters: []string{"ruby"},
+		mimeTypes:    []string{"text/x-ruby", "application/x-ruby"},
+		symbols: []*regexp.Regexp{
+			regexp.MustCompile(`^def\s+(?:self\.)?([A-Za-z_]\w*[?!=]?)`),
+			regexp.MustCompile(`^(?:class|module)\s+([A-Z][\w:]*)`),
+		},
+	},
+	{
+		name:         "shell",
+		extensions:   []string{".sh", ".bash", ".zsh", ".ksh"},
+		interpreters: []string{"sh", "bash", "zsh", "dash", "ksh"},
+		mimeTypes:    []string{"application/x-sh", "text/x-sh", "text/x-shellscript", "application/x-shellscript"},
+		symbols: []*regexp.Regexp{
+			regexp.MustCompile(`^function\s+([A-Za-z_][\w.:-]*)`),
+			regexp.MustCompile(`^([A-Za-z_][\w.:-]*)\s*\(\s*\)`),
+		},
+	},
+}
+
+var (
+	byExtension   = make(map[string]*language)
+	byInterpreter = make(map[string]*language)
+	byMimeType    = make(map[string]*language)
+	byName        = make(map[string]*language)
+)
+
+func init() {
+	for _, l := range languages {
+		byName[l.name] = l
+
+		for _, ext := range l.extensions {
+			byExtension[ext] = l
+		}
+		for _, interpreter := range l.interpreters {
+			byInterpreter[interpreter] = l
+		}
+		for _, mimeType := range l.mimeTypes {
+			byMimeType[mimeType] = l
+		}
+	}
+}
+
+// isBinary returns true when content is not UTF-8 text, e.g. an MPEG transport stream named .ts.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content)
+}
+
+// interpreter returns the language of the interpreter in the shebang line of content (e.g. `#!/usr/bin/env python3`),
+// or nil.
+func interpreter(content []byte) *language {
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return nil
+	}
+
+	line := string(content[2:])
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	name := path.Base(fields[0])
+	if name == "env" {
+		// Skip options to env, e.g. `-S`.
+		name = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				name = field
+				break
+			}
+		}
+	}
+
+	return byInterpreter[strings.TrimRight(name, "0123456789.")]
+}
+
+// detect returns the language of content, by the extension of name, its shebang line or its MIME type, in order of
+// preference. Returns nil for binary content or when no language is recognized.
+func detect(name, mimeType string, content []byte) *language {
+	if isBinary(content) {
+		return nil
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	if l := byExtension[ext]; l != nil {
+		if ext == ".h" && cppHeader.Match(content) {
+			return byName["cpp"]
+		}
+
+		return l
+	}
+
+	if l := interpreter(content); l != nil {
+		return l
+	}
+
+	return byMimeType[mimeType]
+}
+
+// isKeyword returns true when name is a keyword of l.
+func (l *language) isKeyword(name string) bool {
+	for _, keyword := range l.keywords {
+		if name == keyword {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findSymbols returns up to max distinct names declared at the top-level (unindented lines) of content, in order of
+// appearance.
+func (l *language) findSymbols(content string, max int) []string {
+	var symbols []string
+	seen := make(map[string]struct{})
+
+	for _, line := range strings.Split(content, "\n") {
+		if len(symbols) >= max {
+			break
+		}
+
+		line = strings.TrimRight(line, "\r")
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		for _, pattern := range l.symbols {
+			match := pattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+
+			name := match[1]
+			if _, ok := seen[name]; !ok && !l.isKeyword(name) {
+				seen[name] = struct{}{}
+				symbols = append(symbols, name)
+			}
+
+			break
+		}
+	}
+
+	return symbols
+}
@@ -0,0 +1,108 @@
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	for _, test := range []struct {
+		name, mimeType, content, language string
+	}{
+		{"main.go", "", "package main\n", "go"},
+		{"App.JSX", "", "export default App\n", "javascript"},
+		{"util.h", "", "int util(void);\n", "c"},
+		{"util.h", "", "namespace util {\n}\n", "cpp"},
+		{"build", "", "#!/bin/bash\nmake\n", "shell"},
+		{"serve", "", "#!/usr/bin/env -S node --harmony\n", "javascript"},
+		{"tool", "", "#!/usr/bin/python3.9\n", "python"},
+		{"", "text/x-rustsrc", "fn main() {}\n", "rust"},
+		{"notes", "text/plain", "Just text.\n", ""},
+		{"main.go", "", "\x00\x01\x02", ""},
+		{"main.go", "", "\xff\xfe", ""},
+	} {
+		l := detect(test.name, test.mimeType, []byte(test.content))
+
+		if test.language == "" {
+			assert.Nil(t, l, test.name)
+		} else if assert.NotNil(t, l, test.name) {
+			assert.Equal(t, test.language, l.name, test.name)
+		}
+	}
+}
+
+func TestFindSymbols(t *testing.T) {
+	for language, test := range map[string]struct {
+		content string
+		symbols []string
+	}{
+		"javascript": {
+			"import x from 'x'\n" +
+				"export async function load(url) {\n" +
+				"  function nested() {}\n" +
+				"}\n" +
+				"export default class Store {}\n" +
+				"const add = (a, b) => a + b\n" +
+				"const limit = 10\n" +
+				"function* ids() {}\n",
+			[]string{"load", "Store", "add", "ids"},
+		},
+		"typescript": {
+			"export interface Props {}\n" +
+				"export type ID = string\n" +
+				"export const enum Color { Red }\n" +
+				"export abstract class Base {}\n" +
+				"export const fetcher: Fetcher = async (url: string): Promise<Response> => fetch(url)\n",
+			[]string{"Props", "ID", "Color", "Base", "fetcher"},
+		},
+		"rust": {
+			"use std::io;\n" +
+				"pub struct Config {}\n" +
+				"impl Config {\n" +
+				"    pub fn new() -> Self {}\n" +
+				"}\n" +
+				"pub(crate) async fn run() {}\n" +
+				"unsafe extern \"C\" fn callback() {}\n",
+			[]string{"Config", "run", "callback"},
+		},
+		"c": {
+			"#include <stdio.h>\n" +
+				"typedef struct node {\n" +
+				"};\n" +
+				"static const char *name(struct node *n)\n" +
+				"{\n" +
+				"}\n" +
+				"int count(void);\n" +
+				"int main(int argc, char **argv) {\n" +
+				"}\n",
+			[]string{"node", "name", "main"},
+		},
+		"ruby": {
+			"module Shop\n" +
+				"end\n" +
+				"class Cart < Base\n" +
+				"end\n" +
+				"def self.empty?\n" +
+				"end\n",
+			[]string{"Shop", "Cart", "empty?"},
+		},
+		"shell": {
+			"set -e\n" +
+				"usage() {\n" +
+				"}\n" +
+				"function build {\n" +
+				"}\n",
+			[]string{"usage", "build"},
+		},
+	} {
+		assert.Equal(t, test.symbols, byName[language].findSymbols(test.content, 1000), language)
+	}
+}
+
+func TestFindSymbolsDistinct(t *testing.T) {
+	content := "func init() {}\nfunc init() {}\ntype T int\n"
+
+	assert.Equal(t, []string{"init", "T"}, byName["go"].findSymbols(content, 1000))
+	assert.Empty(t, byName["go"].findSymbols(content, 0))
+}
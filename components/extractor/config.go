@@ -0,0 +1,21 @@
+package extractor
+
+import (
+	"time"
+)
+
+// Config specifies the configuration for running multiple extractors.
+type Config struct {
+	Parallel bool          // Run extractors concurrently rather than in order, merging their results.
+	Timeout  time.Duration // Combined deadline for all extractors of a resource; none when 0.
+	Sniff    bool          // Sniff the MIME type from the first bytes of resources, preferring it over their extension.
+}
+
+// DefaultConfig returns the default configuration for running multiple extractors.
+func DefaultConfig() *Config {
+	return &Config{
+		Parallel: false,
+		Timeout:  0,
+		Sniff:    false,
+	}
+}
@@ -2,6 +2,8 @@ package extractor
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 )
 
 var (
@@ -13,4 +15,45 @@ var (
 
 	// ErrRequest is returned on errors in upstream requests.
 	ErrRequest = errors.New("request error")
+
+	// ErrResponseTooLarge is returned when a response from the backend is larger than the configured maximum.
+	ErrResponseTooLarge = fmt.Errorf("%w: response too large", ErrUnexpectedResponse)
+
+	// ErrUnextractable is returned when the backend is unable to extract the content of a file, such that retrying
+	// is pointless (e.g. corrupt or unsupported content), as opposed to transient failures.
+	ErrUnextractable = fmt.Errorf("%w: unextractable content", ErrUnexpectedResponse)
 )
+
+// Errors aggregates errors of multiple extractors. errors.Is and errors.As match any of the errors.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Is returns true when any of the errors matches target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error matching target, setting target to it.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
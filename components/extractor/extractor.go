@@ -11,3 +11,9 @@ import (
 type Extractor interface {
 	Extract(ctx context.Context, resource *t.AnnotatedResource, metadata interface{}) error
 }
+
+// Selective is implemented by extractors which only apply to some resources, e.g. by MIME type.
+// Multi skips them for other resources without dispatching.
+type Selective interface {
+	Applies(resource *t.AnnotatedResource) bool
+}
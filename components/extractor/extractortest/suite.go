@@ -0,0 +1,86 @@
+// Package extractortest provides a test suite for extractors fetching resources from a (mocked) gateway.
+package extractortest
+
+import (
+	"context"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// TestCID is the CID of resources returned by Resource.
+const TestCID = "QmehHHRh1a7u66r7fugebp6f6wGNMGCa7eho9cgjwhAcm2"
+
+// Suite mocks the gateway and the protocol pointing to it, to be embedded in the test suites of extractors.
+// Suites defining SetupTest or TearDownTest should call those of Suite.
+type Suite struct {
+	suite.Suite
+
+	Ctx      context.Context
+	Protocol *protocol.Mock
+
+	MockGWHandler *httpmock.MockHandler
+	MockGWServer  *httpmock.Server
+}
+
+// SetupTest starts the mock gateway.
+func (s *Suite) SetupTest() {
+	s.Ctx = context.Background()
+	s.Protocol = &protocol.Mock{}
+
+	s.MockGWHandler = &httpmock.MockHandler{}
+	s.MockGWServer = httpmock.NewServer(s.MockGWHandler)
+}
+
+// TearDownTest stops the mock gateway.
+func (s *Suite) TearDownTest() {
+	s.MockGWServer.Close()
+}
+
+// Resource returns a resource with the given name, sniffed MIME type and size.
+func (s *Suite) Resource(name, mimeType string, size int) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       TestCID,
+		},
+		Reference: t.Reference{
+			Name: name,
+		},
+		Stat: t.Stat{
+			Size: uint64(size),
+		},
+		MimeType: mimeType,
+	}
+}
+
+// ExpectGet expects r to be fetched from the gateway once, returning body.
+func (s *Suite) ExpectGet(r *t.AnnotatedResource, body []byte) {
+	s.Protocol.
+		On("GatewayURL", r).
+		Return(s.MockGWServer.URL() + "/ipfs/" + TestCID).
+		Once()
+
+	s.MockGWHandler.
+		On("Handle", "GET", "/ipfs/"+TestCID, mock.Anything).
+		Return(httpmock.Response{
+			Body: body,
+		}).
+		Once()
+}
+
+// AssertFetched asserts that all expected resources have been fetched.
+func (s *Suite) AssertFetched() {
+	s.MockGWHandler.AssertExpectations(s.T())
+}
+
+// AssertNotFetched asserts that nothing has been fetched from the gateway.
+func (s *Suite) AssertNotFetched() {
+	s.Protocol.AssertNotCalled(s.T(), "GatewayURL", mock.Anything)
+	s.MockGWHandler.AssertNotCalled(s.T(), "Handle", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,102 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// ParseFunc parses properties from the content of r, to be merged into the extracted metadata; nil properties are
+// skipped. An error means the content can't be parsed.
+type ParseFunc func(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error)
+
+// Fetcher fetches resources from the gateway for extractors parsing their content themselves, leaving them only
+// the parsing.
+type Fetcher struct {
+	name     string
+	client   *http.Client
+	protocol protocol.Protocol
+
+	*instr.Instrumentation
+}
+
+// NewFetcher returns a new Fetcher for the extractor with the given name, used in traces and logs.
+func NewFetcher(name string, client *http.Client, protocol protocol.Protocol, instr *instr.Instrumentation) *Fetcher {
+	return &Fetcher{
+		name,
+		client,
+		protocol,
+		instr,
+	}
+}
+
+// bodyReader records errors reading a response body, telling them apart from content which can't be parsed.
+type bodyReader struct {
+	io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+
+	return n, err
+}
+
+// Extract fetches the first size bytes of r, or all of it when size is 0, within timeout and merges the properties
+// parsed from them into m.
+//
+// Content which can't be parsed is not an extraction failure, as other extractors may still apply; errors fetching
+// it are.
+func (f *Fetcher) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}, timeout time.Duration, size int64, parse ParseFunc) error {
+	ctx, span := f.Tracer.Start(ctx, "extractor."+f.name+".Extract")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := GetRange(ctx, f.client, f.protocol.GatewayURL(r), size)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+	defer resp.Body.Close()
+
+	body := &bodyReader{Reader: resp.Body}
+
+	p, err := parse(ctx, r, body)
+	if body.err != nil {
+		err := fmt.Errorf("%w: %v", ErrRequest, body.err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	if err != nil {
+		logger.Debugf("Unable to parse '%v' (%s): %v", r, f.name, err)
+		span.RecordError(ctx, err)
+		return nil
+	}
+
+	if p == nil {
+		return nil
+	}
+
+	if err := Merge(p, m); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return nil
+}
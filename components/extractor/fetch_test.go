@@ -0,0 +1,149 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor/extractortest"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type FetcherTestSuite struct {
+	extractortest.Suite
+
+	f *Fetcher
+}
+
+func (s *FetcherTestSuite) SetupTest() {
+	s.Suite.SetupTest()
+
+	s.f = NewFetcher("test", http.DefaultClient, s.Protocol, instr.New())
+}
+
+// fetchedProperties hold the content of fetched resources.
+type fetchedProperties struct {
+	Content string `json:"content"`
+}
+
+// parseAll returns the full body as properties.
+func parseAll(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error) {
+	buf, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return fetchedProperties{Content: string(buf)}, nil
+}
+
+func (s *FetcherTestSuite) TestExtract() {
+	r := s.Resource("file.txt", "", 5)
+	s.ExpectGet(r, []byte("hello"))
+
+	m := new(fetchedProperties)
+	err := s.f.Extract(s.Ctx, r, m, time.Second, 0, parseAll)
+
+	s.NoError(err)
+	s.AssertFetched()
+	s.Equal("hello", m.Content)
+}
+
+func (s *FetcherTestSuite) TestExtractSize() {
+	r := s.Resource("file.txt", "", 5)
+	s.ExpectGet(r, []byte("hello"))
+
+	m := new(fetchedProperties)
+	err := s.f.Extract(s.Ctx, r, m, time.Second, 2, parseAll)
+
+	// Gateway ignores the Range header; only the first size bytes are read.
+	s.NoError(err)
+	s.Equal("he", m.Content)
+}
+
+func (s *FetcherTestSuite) TestExtractUnparseable() {
+	r := s.Resource("file.txt", "", 5)
+	s.ExpectGet(r, []byte("hello"))
+
+	parse := func(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error) {
+		return fetchedProperties{Content: "partial"}, errors.New("unparseable")
+	}
+
+	m := new(fetchedProperties)
+	err := s.f.Extract(s.Ctx, r, m, time.Second, 0, parse)
+
+	s.NoError(err)
+	s.Empty(m.Content)
+}
+
+func (s *FetcherTestSuite) TestExtractNoProperties() {
+	r := s.Resource("file.txt", "", 5)
+	s.ExpectGet(r, []byte("hello"))
+
+	parse := func(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error) {
+		return nil, nil
+	}
+
+	m := new(fetchedProperties)
+	err := s.f.Extract(s.Ctx, r, m, time.Second, 0, parse)
+
+	s.NoError(err)
+	s.Empty(m.Content)
+}
+
+func (s *FetcherTestSuite) TestExtractReadError() {
+	r := s.Resource("file.txt", "", 100)
+
+	s.Protocol.
+		On("GatewayURL", r).
+		Return(s.MockGWServer.URL() + "/ipfs/" + extractortest.TestCID).
+		Once()
+
+	// Connection closed before the announced length is sent.
+	s.MockGWHandler.
+		On("Handle", "GET", "/ipfs/"+extractortest.TestCID, mock.Anything).
+		Return(httpmock.Response{
+			Header: http.Header{"Content-Length": []string{"100"}},
+			Body:   []byte("hello"),
+		}).
+		Once()
+
+	m := new(fetchedProperties)
+	err := s.f.Extract(s.Ctx, r, m, time.Second, 0, parseAll)
+
+	s.True(errors.Is(err, ErrRequest))
+	s.Empty(m.Content)
+}
+
+func (s *FetcherTestSuite) TestExtractStatus() {
+	r := s.Resource("file.txt", "", 5)
+
+	s.Protocol.
+		On("GatewayURL", r).
+		Return(s.MockGWServer.URL() + "/ipfs/" + extractortest.TestCID).
+		Once()
+
+	s.MockGWHandler.
+		On("Handle", "GET", "/ipfs/"+extractortest.TestCID, mock.Anything).
+		Return(httpmock.Response{
+			Status: http.StatusNotFound,
+		}).
+		Once()
+
+	err := s.f.Extract(s.Ctx, r, new(fetchedProperties), time.Second, 0, parseAll)
+
+	s.True(errors.Is(err, ErrUnexpectedResponse))
+}
+
+func TestFetcherTestSuite(t *testing.T) {
+	suite.Run(t, new(FetcherTestSuite))
+}
@@ -0,0 +1,23 @@
+package font
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+)
+
+// Config specifies the configuration for the font extractor.
+type Config struct {
+	Enabled        bool              // Extract the family, style and glyph count of TrueType, OpenType and WOFF fonts.
+	RequestTimeout time.Duration     // Timeout for requests to the gateway.
+	MaxFileSize    datasize.ByteSize // Skip larger fonts; tables are located by offset, requiring the whole file.
+}
+
+// DefaultConfig returns the default configuration for the font extractor.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:        false,
+		RequestTimeout: 60 * time.Second,
+		MaxFileSize:    32 * 1024 * 1024, // 32MB, accommodating CJK fonts.
+	}
+}
@@ -0,0 +1,114 @@
+// Package font extracts the family, style and glyph count of TrueType, OpenType and WOFF fonts.
+package font
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// fontTypes are the MIME types of supported fonts, including legacy ones. WOFF2 is not supported, as it's Brotli
+// compressed.
+var fontTypes = map[string]bool{
+	"font/ttf":                    true,
+	"font/otf":                    true,
+	"font/collection":             true,
+	"font/woff":                   true,
+	"font/sfnt":                   true,
+	"application/font-sfnt":       true,
+	"application/font-woff":       true,
+	"application/x-font-ttf":      true,
+	"application/x-font-otf":      true,
+	"application/x-font-truetype": true,
+	"application/x-font-opentype": true,
+	"application/vnd.ms-opentype": true,
+	"application/x-font-woff":     true,
+	"application/x-truetype-font": true,
+	"application/x-opentype-font": true,
+}
+
+// fontExtensions are recognized as well, as fonts are often sniffed as application/octet-stream.
+var fontExtensions = map[string]bool{
+	".ttf":  true,
+	".otf":  true,
+	".ttc":  true,
+	".woff": true,
+}
+
+// Extractor extracts font metadata by fetching fonts from the gateway.
+type Extractor struct {
+	config  *Config
+	fetcher *extractor.Fetcher
+}
+
+// properties are merged into the extracted metadata.
+type properties struct {
+	FontFamily     string `json:"font_family,omitempty"`
+	FontStyle      string `json:"font_style,omitempty"`
+	FontGlyphCount int    `json:"font_glyph_count,omitempty"`
+}
+
+// isFont returns true when r is a supported font, by MIME type or extension.
+func isFont(r *t.AnnotatedResource) bool {
+	if fontExtensions[strings.ToLower(path.Ext(r.Reference.Name))] {
+		return true
+	}
+
+	return fontTypes[extractor.MimeType(r)]
+}
+
+// Applies returns true for fonts up to the maximum file size.
+func (e *Extractor) Applies(r *t.AnnotatedResource) bool {
+	return isFont(r) && r.Size <= uint64(e.config.MaxFileSize)
+}
+
+// parse reads font metadata from body; tables are located by offset, requiring random access.
+func (e *Extractor) parse(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error) {
+	buf, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	font, err := parse(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return properties{
+		FontFamily:     font.Family,
+		FontStyle:      font.Style,
+		FontGlyphCount: font.GlyphCount,
+	}, nil
+}
+
+// Extract the metadata of fonts up to the maximum file size, ignoring other resources and unparseable fonts.
+func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	if !e.Applies(r) {
+		return nil
+	}
+
+	return e.fetcher.Extract(ctx, r, m, e.config.RequestTimeout, int64(e.config.MaxFileSize), e.parse)
+}
+
+// New returns a new font extractor.
+func New(config *Config, client *http.Client, protocol protocol.Protocol, instr *instr.Instrumentation) extractor.Extractor {
+	return &Extractor{
+		config,
+		extractor.NewFetcher("font", client, protocol, instr),
+	}
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ extractor.Extractor = &Extractor{}
+	_ extractor.Selective = &Extractor{}
+)
@@ -0,0 +1,93 @@
+package font
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/extractor/extractortest"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type FontTestSuite struct {
+	extractortest.Suite
+
+	e   extractor.Extractor
+	cfg *Config
+}
+
+func (s *FontTestSuite) SetupTest() {
+	s.Suite.SetupTest()
+
+	s.cfg = DefaultConfig()
+
+	s.e = New(s.cfg, http.DefaultClient, s.Protocol, instr.New())
+}
+
+func (s *FontTestSuite) TestExtractTrueType() {
+	body := buildSFNT(sigTrueType, testFontTables())
+	r := s.Resource("SourceSansPro-SemiboldIt.ttf", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertFetched()
+
+	s.Equal("Source Sans Pro", f.FontFamily)
+	s.Equal("Semibold Italic", f.FontStyle)
+	s.Equal(1894, f.FontGlyphCount)
+}
+
+func (s *FontTestSuite) TestExtractWOFFByMimeType() {
+	body := buildWOFF(testFontTables())
+	r := s.Resource("", "font/woff", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.Equal("Source Sans Pro", f.FontFamily)
+}
+
+func (s *FontTestSuite) TestExtractUnparseable() {
+	body := []byte("wOF2 not supported")
+	r := s.Resource("font.otf", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.Empty(f.FontFamily)
+}
+
+func (s *FontTestSuite) TestExtractUnsupported() {
+	r := s.Resource("index.html", "text/html", 100)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+}
+
+func (s *FontTestSuite) TestExtractTooLarge() {
+	r := s.Resource("font.ttf", "", int(s.cfg.MaxFileSize)+1)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+}
+
+func TestFontTestSuite(t *testing.T) {
+	suite.Run(t, new(FontTestSuite))
+}
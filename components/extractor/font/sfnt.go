@@ -0,0 +1,310 @@
+package font
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf16"
+)
+
+// Signatures of the supported font formats.
+const (
+	sigTrueType   = "\x00\x01\x00\x00"
+	sigTrueTypeOS = "true" // Legacy Apple TrueType.
+	sigOpenType   = "OTTO" // CFF outlines.
+	sigCollection = "ttcf"
+	sigWOFF       = "wOFF"
+	sigWOFF2      = "wOF2"
+)
+
+// IDs of the name table records used.
+const (
+	nameFamily               = 1
+	nameSubfamily            = 2
+	nameTypographicFamily    = 16
+	nameTypographicSubfamily = 17
+)
+
+// maxTableSize bounds the size of decompressed WOFF tables.
+const maxTableSize = 4 * 1024 * 1024
+
+var (
+	errUnsupported = errors.New("unsupported font format")
+	errTruncated   = errors.New("truncated font")
+)
+
+// metadata is read from the name and maxp tables of a font.
+type metadata struct {
+	Family     string
+	Style      string
+	GlyphCount int
+}
+
+// tables returns the name and maxp tables of the font in buf, the first font of collections.
+func tables(buf []byte) (name, maxp []byte, err error) {
+	if len(buf) < 12 {
+		return nil, nil, errTruncated
+	}
+
+	switch string(buf[:4]) {
+	case sigTrueType, sigTrueTypeOS, sigOpenType:
+		return sfntTables(buf, 0)
+
+	case sigCollection:
+		// Header: tag, version, number of fonts and their offsets.
+		if binary.BigEndian.Uint32(buf[8:12]) == 0 || len(buf) < 16 {
+			return nil, nil, errTruncated
+		}
+		return sfntTables(buf, int(binary.BigEndian.Uint32(buf[12:16])))
+
+	case sigWOFF:
+		return woffTables(buf)
+
+	case sigWOFF2:
+		// Brotli compressed, requiring a decoder we don't have.
+		return nil, nil, fmt.Errorf("%w: WOFF2", errUnsupported)
+
+	default:
+		return nil, nil, errUnsupported
+	}
+}
+
+// slice returns length bytes of buf at offset, or an error when out of bounds.
+func slice(buf []byte, offset, length int) ([]byte, error) {
+	if offset < 0 || length < 0 || offset+length > len(buf) || offset+length < offset {
+		return nil, errTruncated
+	}
+
+	return buf[offset : offset+length], nil
+}
+
+// sfntTables returns the name and maxp tables of the TrueType or OpenType font at offset in buf.
+func sfntTables(buf []byte, offset int) (name, maxp []byte, err error) {
+	header, err := slice(buf, offset, 12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	numTables := int(binary.BigEndian.Uint16(header[4:6]))
+
+	// Table records: tag, checksum, offset, length.
+	records, err := slice(buf, offset+12, numTables*16)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := 0; i < numTables; i++ {
+		record := records[i*16 : (i+1)*16]
+
+		var table *[]byte
+		switch string(record[:4]) {
+		case "name":
+			table = &name
+		case "maxp":
+			table = &maxp
+		default:
+			continue
+		}
+
+		// Offsets are from the start of the file, also in collections.
+		*table, err = slice(buf, int(binary.BigEndian.Uint32(record[8:12])), int(binary.BigEndian.Uint32(record[12:16])))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return name, maxp, nil
+}
+
+// woffTables returns the name and maxp tables of the WOFF font in buf, decompressing them when compressed.
+func woffTables(buf []byte) (name, maxp []byte, err error) {
+	header, err := slice(buf, 0, 44)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	numTables := int(binary.BigEndian.Uint16(header[12:14]))
+
+	// Table directory entries: tag, offset, compressed length, original length, checksum.
+	entries, err := slice(buf, 44, numTables*20)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := 0; i < numTables; i++ {
+		entry := entries[i*20 : (i+1)*20]
+
+		var table *[]byte
+		switch string(entry[:4]) {
+		case "name":
+			table = &name
+		case "maxp":
+			table = &maxp
+		default:
+			continue
+		}
+
+		compLength := int(binary.BigEndian.Uint32(entry[8:12]))
+		origLength := int(binary.BigEndian.Uint32(entry[12:16]))
+
+		data, err := slice(buf, int(binary.BigEndian.Uint32(entry[4:8])), compLength)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if compLength < origLength {
+			if data, err = inflate(data, origLength); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		*table = data
+	}
+
+	return name, maxp, nil
+}
+
+// inflate decompresses a zlib compressed WOFF table of length bytes.
+func inflate(data []byte, length int) ([]byte, error) {
+	if length > maxTableSize {
+		return nil, fmt.Errorf("table too large: %d bytes", length)
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(io.LimitReader(r, int64(length)))
+}
+
+// nameRecord is a string of the name table.
+type nameRecord struct {
+	platform, encoding, language, id uint16
+	value                            []byte
+}
+
+// priority returns the preference for a record; lower is better. Windows English names are preferred, as they are
+// required by OpenType, then other Unicode names and finally Macintosh Roman names.
+func (r *nameRecord) priority() int {
+	switch {
+	case r.platform == 3 && r.language == 0x409:
+		return 0
+	case r.platform == 3 || r.platform == 0:
+		return 1
+	case r.platform == 1 && r.encoding == 0:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// decode returns the value of the record as a string.
+func (r *nameRecord) decode() string {
+	if r.platform == 1 {
+		// Macintosh Roman; ASCII for all practical purposes.
+		return strings.ToValidUTF8(string(r.value), "")
+	}
+
+	// UTF-16BE.
+	units := make([]uint16, len(r.value)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(r.value[2*i:])
+	}
+
+	return string(utf16.Decode(units))
+}
+
+// parseNames returns the preferred value of each name ID of interest in the name table.
+func parseNames(table []byte) (map[uint16]string, error) {
+	if len(table) < 6 {
+		return nil, errTruncated
+	}
+
+	count := int(binary.BigEndian.Uint16(table[2:4]))
+	storage := int(binary.BigEndian.Uint16(table[4:6]))
+
+	records, err := slice(table, 6, count*12)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint16]string)
+	priorities := make(map[uint16]int)
+
+	for i := 0; i < count; i++ {
+		b := records[i*12 : (i+1)*12]
+
+		r := nameRecord{
+			platform: binary.BigEndian.Uint16(b[0:2]),
+			encoding: binary.BigEndian.Uint16(b[2:4]),
+			language: binary.BigEndian.Uint16(b[4:6]),
+			id:       binary.BigEndian.Uint16(b[6:8]),
+		}
+
+		switch r.id {
+		case nameFamily, nameSubfamily, nameTypographicFamily, nameTypographicSubfamily:
+		default:
+			continue
+		}
+
+		p := r.priority()
+		if best, ok := priorities[r.id]; p < 0 || (ok && best <= p) {
+			continue
+		}
+
+		value, err := slice(table, storage+int(binary.BigEndian.Uint16(b[10:12])), int(binary.BigEndian.Uint16(b[8:10])))
+		if err != nil {
+			return nil, err
+		}
+		r.value = value
+
+		if s := strings.TrimSpace(r.decode()); s != "" {
+			names[r.id] = s
+			priorities[r.id] = p
+		}
+	}
+
+	return names, nil
+}
+
+// parse returns the metadata of the font in buf.
+func parse(buf []byte) (*metadata, error) {
+	nameTable, maxpTable, err := tables(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(metadata)
+
+	if nameTable != nil {
+		names, err := parseNames(nameTable)
+		if err != nil {
+			return nil, err
+		}
+
+		// Typographic names group more than the four legacy styles per family (e.g. Light or Condensed).
+		m.Family = names[nameTypographicFamily]
+		if m.Family == "" {
+			m.Family = names[nameFamily]
+		}
+
+		m.Style = names[nameTypographicSubfamily]
+		if m.Style == "" {
+			m.Style = names[nameSubfamily]
+		}
+	}
+
+	if len(maxpTable) >= 6 {
+		// Version, followed by the number of glyphs.
+		m.GlyphCount = int(binary.BigEndian.Uint16(maxpTable[4:6]))
+	}
+
+	return m, nil
+}
@@ -0,0 +1,222 @@
+package font
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testName is a name record of a test font.
+type testName struct {
+	platform, encoding, language, id uint16
+	value                            string
+}
+
+// testFontNames are the names of a sample font, as set by font editors: legacy Macintosh names and Windows names,
+// with typographic names for the Semibold style, which is not one of the four legacy styles.
+var testFontNames = []testName{
+	{1, 0, 0, nameFamily, "Source Sans Pro Semibold"},
+	{1, 0, 0, nameSubfamily, "Italic"},
+	{3, 1, 0x409, nameFamily, "Source Sans Pro Semibold"},
+	{3, 1, 0x409, nameSubfamily, "Italic"},
+	{3, 1, 0x407, nameTypographicFamily, "Source Sans Pro (German)"},
+	{3, 1, 0x409, nameTypographicFamily, "Source Sans Pro"},
+	{3, 1, 0x409, nameTypographicSubfamily, "Semibold Italic"},
+}
+
+// buildNameTable returns a name table (format 0) for names.
+func buildNameTable(names []testName) []byte {
+	var records, storage bytes.Buffer
+
+	for _, n := range names {
+		var value []byte
+		if n.platform == 1 {
+			value = []byte(n.value)
+		} else {
+			for _, u := range utf16.Encode([]rune(n.value)) {
+				value = append(value, byte(u>>8), byte(u))
+			}
+		}
+
+		binary.Write(&records, binary.BigEndian, []uint16{
+			n.platform, n.encoding, n.language, n.id, uint16(len(value)), uint16(storage.Len()),
+		})
+		storage.Write(value)
+	}
+
+	var table bytes.Buffer
+	binary.Write(&table, binary.BigEndian, []uint16{0, uint16(len(names)), uint16(6 + records.Len())})
+	table.Write(records.Bytes())
+	table.Write(storage.Bytes())
+
+	return table.Bytes()
+}
+
+// buildMaxpTable returns a maxp table (version 0.5) for glyphs.
+func buildMaxpTable(glyphs uint16) []byte {
+	var table bytes.Buffer
+	binary.Write(&table, binary.BigEndian, uint32(0x00005000))
+	binary.Write(&table, binary.BigEndian, glyphs)
+	return table.Bytes()
+}
+
+// sortedTags returns the tags of tables in order, as in fonts.
+func sortedTags(tables map[string][]byte) []string {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// buildSFNT returns a font with signature and tables.
+func buildSFNT(signature string, tables map[string][]byte) []byte {
+	tags := sortedTags(tables)
+
+	var header, data bytes.Buffer
+	header.WriteString(signature)
+	binary.Write(&header, binary.BigEndian, []uint16{uint16(len(tags)), 0, 0, 0})
+
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		header.WriteString(tag)
+		binary.Write(&header, binary.BigEndian, []uint32{0, uint32(offset + data.Len()), uint32(len(tables[tag]))})
+		data.Write(tables[tag])
+	}
+
+	return append(header.Bytes(), data.Bytes()...)
+}
+
+// buildWOFF returns a WOFF font with tables, compressing them where possible.
+func buildWOFF(tables map[string][]byte) []byte {
+	tags := sortedTags(tables)
+
+	var header, data bytes.Buffer
+	header.WriteString(sigWOFF)
+	header.WriteString(sigTrueType)
+	binary.Write(&header, binary.BigEndian, uint32(0)) // Length, unchecked.
+	binary.Write(&header, binary.BigEndian, []uint16{uint16(len(tags)), 0})
+	header.Write(make([]byte, 44-header.Len()))
+
+	offset := 44 + 20*len(tags)
+	for _, tag := range tags {
+		var compressed bytes.Buffer
+		w := zlib.NewWriter(&compressed)
+		w.Write(tables[tag])
+		w.Close()
+
+		// Tables are stored uncompressed when compression doesn't make them smaller.
+		table := compressed.Bytes()
+		if len(table) >= len(tables[tag]) {
+			table = tables[tag]
+		}
+
+		header.WriteString(tag)
+		binary.Write(&header, binary.BigEndian, []uint32{
+			uint32(offset + data.Len()), uint32(len(table)), uint32(len(tables[tag])), 0,
+		})
+		data.Write(table)
+	}
+
+	return append(header.Bytes(), data.Bytes()...)
+}
+
+func testFontTables() map[string][]byte {
+	return map[string][]byte{
+		"head": make([]byte, 54),
+		"maxp": buildMaxpTable(1894),
+		"name": buildNameTable(testFontNames),
+	}
+}
+
+var testFontMetadata = &metadata{
+	Family:     "Source Sans Pro",
+	Style:      "Semibold Italic",
+	GlyphCount: 1894,
+}
+
+func TestParseTrueType(t *testing.T) {
+	m, err := parse(buildSFNT(sigTrueType, testFontTables()))
+	require.NoError(t, err)
+	assert.Equal(t, testFontMetadata, m)
+}
+
+func TestParseOpenType(t *testing.T) {
+	m, err := parse(buildSFNT(sigOpenType, testFontTables()))
+	require.NoError(t, err)
+	assert.Equal(t, testFontMetadata, m)
+}
+
+func TestParseLegacyNames(t *testing.T) {
+	tables := map[string][]byte{
+		"maxp": buildMaxpTable(229),
+		"name": buildNameTable([]testName{
+			{1, 0, 0, nameFamily, "Chicago"},
+			{1, 0, 0, nameSubfamily, "Regular"},
+		}),
+	}
+
+	m, err := parse(buildSFNT(sigTrueType, tables))
+	require.NoError(t, err)
+	assert.Equal(t, &metadata{Family: "Chicago", Style: "Regular", GlyphCount: 229}, m)
+}
+
+func TestParseCollection(t *testing.T) {
+	font := buildSFNT(sigTrueType, testFontTables())
+
+	// Table offsets are from the start of the file, so the font is shifted by the collection header.
+	var header bytes.Buffer
+	header.WriteString(sigCollection)
+	binary.Write(&header, binary.BigEndian, []uint32{0x00010000, 1, 16})
+
+	collection := append(header.Bytes(), shiftTables(font, 16)...)
+
+	m, err := parse(collection)
+	require.NoError(t, err)
+	assert.Equal(t, testFontMetadata, m)
+}
+
+// shiftTables returns font with its table offsets increased by n, for embedding at offset n.
+func shiftTables(font []byte, n uint32) []byte {
+	shifted := append([]byte(nil), font...)
+
+	numTables := int(binary.BigEndian.Uint16(shifted[4:6]))
+	for i := 0; i < numTables; i++ {
+		offset := shifted[12+i*16+8 : 12+i*16+12]
+		binary.BigEndian.PutUint32(offset, binary.BigEndian.Uint32(offset)+n)
+	}
+
+	return shifted
+}
+
+func TestParseWOFF(t *testing.T) {
+	m, err := parse(buildWOFF(testFontTables()))
+	require.NoError(t, err)
+	assert.Equal(t, testFontMetadata, m)
+}
+
+func TestParseUnsupported(t *testing.T) {
+	_, err := parse([]byte("wOF2\x00\x01\x00\x00 and more"))
+	assert.True(t, errors.Is(err, errUnsupported))
+
+	_, err = parse([]byte("<html>not a font</html>"))
+	assert.True(t, errors.Is(err, errUnsupported))
+}
+
+func TestParseTruncated(t *testing.T) {
+	font := buildSFNT(sigTrueType, testFontTables())
+
+	_, err := parse(font[:len(font)-10])
+	assert.True(t, errors.Is(err, errTruncated))
+
+	_, err = parse(font[:8])
+	assert.True(t, errors.Is(err, errTruncated))
+}
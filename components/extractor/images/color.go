@@ -0,0 +1,51 @@
+package images
+
+import (
+	"fmt"
+	"image"
+)
+
+// maxSamples bounds the amount of pixels sampled for the dominant color.
+const maxSamples = 64 * 64
+
+// dominantColor returns the most common color in img as #rrggbb, quantizing colors to 4 bits per channel.
+// Pixels are sampled at regular intervals, and (mostly) transparent pixels are ignored.
+func dominantColor(img image.Image) string {
+	bounds := img.Bounds()
+
+	step := 1
+	for (bounds.Dx()/step)*(bounds.Dy()/step) > maxSamples {
+		step++
+	}
+
+	var (
+		histogram = make(map[uint16]int)
+		best      uint16
+		bestCnt   int
+	)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+
+			// 16 bit channels to 4 bit buckets.
+			bucket := uint16(r>>12)<<8 | uint16(g>>12)<<4 | uint16(b>>12)
+
+			histogram[bucket]++
+			if cnt := histogram[bucket]; cnt > bestCnt {
+				best, bestCnt = bucket, cnt
+			}
+		}
+	}
+
+	if bestCnt == 0 {
+		return ""
+	}
+
+	// Use the center of the bucket.
+	r, g, b := (best>>8)&0xf, (best>>4)&0xf, best&0xf
+	return fmt.Sprintf("#%02x%02x%02x", r<<4|0x8, g<<4|0x8, b<<4|0x8)
+}
@@ -0,0 +1,31 @@
+package images
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+)
+
+// Config specifies the configuration for the image extractor.
+type Config struct {
+	Enabled        bool              // Extract image dimensions and dominant color.
+	RequestTimeout time.Duration     // Timeout for requests to the gateway.
+	MaxDecodeSize  datasize.ByteSize // Only fully decode images up to this size to compute the dominant color.
+	MaxPixels      int               // Only fully decode images with up to this many pixels (width times height).
+	HeaderSize     datasize.ByteSize // Only fetch this many bytes (using a Range request) when reading headers; 0 fetches all.
+	Thumbnails     bool              // Add thumbnails of fully decoded images to IPFS, indexing their CID.
+	ThumbnailSize  int               // Maximum width and height of thumbnails, in pixels.
+}
+
+// DefaultConfig returns the default configuration for the image extractor.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:        false,
+		RequestTimeout: 60 * time.Second,
+		MaxDecodeSize:  8 * 1024 * 1024,  // 8MB
+		MaxPixels:      25 * 1000 * 1000, // 25 megapixels
+		HeaderSize:     64 * 1024,        // 64KB
+		Thumbnails:     false,
+		ThumbnailSize:  256,
+	}
+}
@@ -0,0 +1,171 @@
+// Package images extracts dimensions and dominant colors from images.
+package images
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	// Register decoders for supported image formats.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+var logger = logging.New("extractor")
+
+// Extractor extracts image properties by fetching images from the gateway.
+type Extractor struct {
+	config   *Config
+	fetcher  *extractor.Fetcher
+	protocol protocol.Protocol
+
+	*instr.Instrumentation
+}
+
+// properties are merged into the extracted metadata.
+type properties struct {
+	Width         int    `json:"image_width"`
+	Height        int    `json:"image_height"`
+	DominantColor string `json:"dominant_color,omitempty"`
+	ThumbnailCID  string `json:"thumbnail_cid,omitempty"`
+}
+
+// decode reads image properties from body, decoding the full image up to maxSize bytes and maxPixels pixels or only
+// reading headers when maxSize is 0 or the image has more pixels. The decoded image is returned as well, or nil when
+// only headers were read.
+func decode(body io.Reader, maxSize datasize.ByteSize, maxPixels int) (*properties, image.Image, error) {
+	if maxSize == 0 {
+		// Only read headers.
+		cfg, _, err := image.DecodeConfig(body)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &properties{Width: cfg.Width, Height: cfg.Height}, nil, nil
+	}
+
+	buf, err := ioutil.ReadAll(io.LimitReader(body, int64(maxSize)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check dimensions first; a small image may claim dimensions requiring gigabytes of memory to decode.
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(buf))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Width*cfg.Height > maxPixels {
+		return &properties{Width: cfg.Width, Height: cfg.Height}, nil, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bounds := img.Bounds()
+
+	return &properties{
+		Width:         bounds.Dx(),
+		Height:        bounds.Dy(),
+		DominantColor: dominantColor(img),
+	}, img, nil
+}
+
+// thumbnailCID returns the CID of a thumbnail for img, adding it to IPFS unless the image is small enough to serve
+// as its own thumbnail. Errors are logged and result in an empty CID; thumbnails are a nice-to-have.
+func (e *Extractor) thumbnailCID(ctx context.Context, r *t.AnnotatedResource, img image.Image) string {
+	if fits(img, e.config.ThumbnailSize) {
+		return r.ID
+	}
+
+	ctx, span := e.Tracer.Start(ctx, "extractor.images.thumbnail")
+	defer span.End()
+
+	buf, err := thumbnail(img, e.config.ThumbnailSize)
+	if err == nil {
+		var cid string
+		if cid, err = e.protocol.Add(ctx, bytes.NewReader(buf)); err == nil {
+			return cid
+		}
+	}
+
+	logger.Debugf("Unable to create thumbnail for '%v': %v", r, err)
+	span.RecordError(ctx, err)
+
+	return ""
+}
+
+// Applies returns true for image resources.
+func (e *Extractor) Applies(r *t.AnnotatedResource) bool {
+	return strings.HasPrefix(extractor.MimeType(r), "image/")
+}
+
+// decodeSize returns the size up to which r is fully decoded, or 0 when only its headers are read.
+func (e *Extractor) decodeSize(r *t.AnnotatedResource) datasize.ByteSize {
+	if r.Size > 0 && r.Size <= uint64(e.config.MaxDecodeSize) {
+		return e.config.MaxDecodeSize
+	}
+
+	return 0
+}
+
+// parse decodes the image in body, adding a thumbnail when it has been fully decoded.
+func (e *Extractor) parse(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error) {
+	p, img, err := decode(body, e.decodeSize(r), e.config.MaxPixels)
+	if err != nil {
+		return nil, err
+	}
+
+	if img != nil && e.config.Thumbnails {
+		p.ThumbnailCID = e.thumbnailCID(ctx, r, img)
+	}
+
+	return p, nil
+}
+
+// Extract image dimensions and dominant color for image resources, ignoring other resources.
+func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	if !e.Applies(r) {
+		return nil
+	}
+
+	// Only fetch the beginning of images for which we only read the headers.
+	size := e.decodeSize(r)
+	if size == 0 {
+		size = e.config.HeaderSize
+	}
+
+	return e.fetcher.Extract(ctx, r, m, e.config.RequestTimeout, int64(size), e.parse)
+}
+
+// New returns a new image extractor.
+func New(config *Config, client *http.Client, protocol protocol.Protocol, instr *instr.Instrumentation) extractor.Extractor {
+	return &Extractor{
+		config,
+		extractor.NewFetcher("images", client, protocol, instr),
+		protocol,
+		instr,
+	}
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ extractor.Extractor = &Extractor{}
+	_ extractor.Selective = &Extractor{}
+)
@@ -0,0 +1,201 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/extractor/extractortest"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type ImagesTestSuite struct {
+	extractortest.Suite
+
+	e   extractor.Extractor
+	cfg *Config
+}
+
+func (s *ImagesTestSuite) SetupTest() {
+	s.Suite.SetupTest()
+
+	s.cfg = DefaultConfig()
+
+	s.e = New(s.cfg, http.DefaultClient, s.Protocol, instr.New())
+}
+
+// testPNG returns a PNG image of 12x8 pixels, mostly red with a blue stripe.
+func (s *ImagesTestSuite) testPNG() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 12, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 12; x++ {
+			c := color.RGBA{0xff, 0, 0, 0xff}
+			if y == 0 {
+				c = color.RGBA{0, 0, 0xff, 0xff}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	s.Require().NoError(png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+func (s *ImagesTestSuite) TestExtract() {
+	body := s.testPNG()
+	r := s.Resource("photo.png", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertFetched()
+
+	s.Equal(12, f.ImageWidth)
+	s.Equal(8, f.ImageHeight)
+	s.Equal("#f80808", f.DominantColor)
+}
+
+func (s *ImagesTestSuite) TestExtractHeadersOnly() {
+	s.cfg.MaxDecodeSize = 10
+
+	body := s.testPNG()
+	r := s.Resource("photo.png", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+
+	s.Equal(12, f.ImageWidth)
+	s.Equal(8, f.ImageHeight)
+	s.Empty(f.DominantColor)
+}
+
+func (s *ImagesTestSuite) TestExtractTooManyPixels() {
+	s.cfg.MaxPixels = 12*8 - 1
+	s.cfg.Thumbnails = true
+
+	body := s.testPNG()
+	r := s.Resource("photo.png", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+
+	s.Equal(12, f.ImageWidth)
+	s.Equal(8, f.ImageHeight)
+	s.Empty(f.DominantColor)
+	s.Empty(f.ThumbnailCID)
+}
+
+func (s *ImagesTestSuite) TestExtractHeadersOnlyTruncated() {
+	s.cfg.MaxDecodeSize = 10
+	s.cfg.HeaderSize = 64
+
+	body := s.testPNG()
+	r := s.Resource("photo.png", "", len(body))
+
+	s.Protocol.
+		On("GatewayURL", r).
+		Return(s.MockGWServer.URL() + "/ipfs/" + extractortest.TestCID).
+		Once()
+
+	// Gateway ignores the Range header; only the first HeaderSize bytes are read.
+	s.MockGWHandler.
+		On("Handle", "GET", "/ipfs/"+extractortest.TestCID, mock.Anything).
+		Return(httpmock.Response{
+			Body: append(body[:64:64], make([]byte, 1024)...),
+		}).
+		Once()
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+
+	s.Equal(12, f.ImageWidth)
+	s.Equal(8, f.ImageHeight)
+}
+
+func (s *ImagesTestSuite) TestExtractThumbnailSmall() {
+	s.cfg.Thumbnails = true
+
+	body := s.testPNG()
+	r := s.Resource("photo.png", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+
+	// Small images are their own thumbnail.
+	s.Equal(extractortest.TestCID, f.ThumbnailCID)
+	s.Protocol.AssertNotCalled(s.T(), "Add", mock.Anything, mock.Anything)
+}
+
+func (s *ImagesTestSuite) TestExtractThumbnail() {
+	s.cfg.Thumbnails = true
+	s.cfg.ThumbnailSize = 6
+
+	body := s.testPNG()
+	r := s.Resource("photo.png", "", len(body))
+	s.ExpectGet(r, body)
+
+	s.Protocol.
+		On("Add", mock.Anything, mock.Anything).
+		Return("bafkthumbnail", nil).
+		Once()
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.Protocol.AssertExpectations(s.T())
+
+	s.Equal("bafkthumbnail", f.ThumbnailCID)
+}
+
+func (s *ImagesTestSuite) TestExtractNotImage() {
+	r := s.Resource("document.pdf", "", 100)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+
+	s.Zero(f.ImageWidth)
+}
+
+func (s *ImagesTestSuite) TestExtractCorrupt() {
+	r := s.Resource("photo.png", "", 100)
+	s.ExpectGet(r, []byte("not an image"))
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.Zero(f.ImageWidth)
+}
+
+func TestImagesTestSuite(t *testing.T) {
+	suite.Run(t, new(ImagesTestSuite))
+}
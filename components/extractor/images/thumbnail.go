@@ -0,0 +1,72 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+)
+
+// thumbnailQuality is the JPEG quality of thumbnails.
+const thumbnailQuality = 80
+
+// fits returns true when img fits within a square of size pixels.
+func fits(img image.Image, size int) bool {
+	bounds := img.Bounds()
+	return bounds.Dx() <= size && bounds.Dy() <= size
+}
+
+// resize scales img down to fit within a square of size pixels, preserving the aspect ratio, by averaging the
+// source pixels covered by every destination pixel. Images should not fit already; resize does not enlarge.
+func resize(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := size, size
+	if srcW > srcH {
+		dstH = max(1, srcH*size/srcW)
+	} else {
+		dstW = max(1, srcW*size/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		y0, y1 := bounds.Min.Y+y*srcH/dstH, bounds.Min.Y+(y+1)*srcH/dstH
+
+		for x := 0; x < dstW; x++ {
+			x0, x1 := bounds.Min.X+x*srcW/dstW, bounds.Min.X+(x+1)*srcW/dstW
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)})
+		}
+	}
+
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// thumbnail returns a JPEG thumbnail of img, fitting within a square of size pixels.
+func thumbnail(img image.Image, size int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := jpeg.Encode(&buf, resize(img, size), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
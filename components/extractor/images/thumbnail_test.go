@@ -0,0 +1,32 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 10))
+
+	assert.False(t, fits(img, 20))
+	assert.True(t, fits(img, 40))
+
+	thumb := resize(img, 20)
+	assert.Equal(t, image.Rect(0, 0, 20, 5), thumb.Bounds())
+}
+
+func TestThumbnail(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 40))
+
+	buf, err := thumbnail(img, 8)
+	assert.NoError(t, err)
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(buf))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cfg.Width)
+	assert.Equal(t, 8, cfg.Height)
+}
@@ -0,0 +1,21 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Merge merges the JSON encoding of v into m, which has been decoded from JSON. Returns ErrUnexpectedResponse when
+// m can't hold v, as m is provided by the caller of Extract.
+func Merge(v interface{}, m interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	if err := json.Unmarshal(buf, m); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+
+	return nil
+}
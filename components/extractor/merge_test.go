@@ -0,0 +1,29 @@
+package extractor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testProperties struct {
+	ExtractionMs int `json:"extraction_ms"`
+}
+
+func TestMerge(t *testing.T) {
+	m := map[string]interface{}{"content": "text"}
+
+	assert.NoError(t, Merge(testProperties{ExtractionMs: 42}, &m))
+	assert.Equal(t, "text", m["content"])
+	assert.Equal(t, 42.0, m["extraction_ms"])
+}
+
+func TestMergeUnexpectedType(t *testing.T) {
+	m := &struct {
+		ExtractionMs string `json:"extraction_ms"`
+	}{}
+
+	err := Merge(testProperties{ExtractionMs: 42}, m)
+	assert.True(t, errors.Is(err, ErrUnexpectedResponse))
+}
@@ -0,0 +1,39 @@
+package extractor
+
+import (
+	"mime"
+	"path"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// genericTypes are sniffed MIME types which are less specific than a type guessed from an extension, e.g. a CSV
+// file is sniffed as text/plain.
+var genericTypes = map[string]bool{
+	"application/octet-stream": true,
+	"text/plain":               true,
+}
+
+// extensionType returns the MIME type guessed from the extension of the name of r, or an empty string.
+func extensionType(r *t.AnnotatedResource) string {
+	mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(path.Ext(r.Reference.Name)))
+	if err != nil {
+		return ""
+	}
+
+	return mediaType
+}
+
+// MimeType returns the MIME type of r, or an empty string. The type sniffed from the content is preferred over the
+// type guessed from the extension of its name, unless the sniffed type is generic.
+func MimeType(r *t.AnnotatedResource) string {
+	if r.MimeType != "" && !genericTypes[r.MimeType] {
+		return r.MimeType
+	}
+
+	if mediaType := extensionType(r); mediaType != "" {
+		return mediaType
+	}
+
+	return r.MimeType
+}
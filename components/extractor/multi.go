@@ -0,0 +1,130 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/ipfs-search/ipfs-search/logging"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+var logger = logging.New("extractor")
+
+// Multi runs multiple extractors against the same resource, each adding to the metadata.
+// Selective extractors are skipped for resources they do not apply to; others are expected to ignore them.
+//
+// When a Sniffer is given, the MIME type of resources is sniffed before selecting extractors.
+//
+// In order (the default), extraction stops at the first error. In parallel, extractors run concurrently into
+// separate results which are merged in order, so that the metadata equals that of running them in order, and
+// errors of all extractors are returned.
+type Multi struct {
+	config     *Config
+	sniffer    *Sniffer
+	extractors []Extractor
+}
+
+// NewMulti returns a Multi extractor for the given extractors. A nil sniffer disables sniffing.
+func NewMulti(config *Config, sniffer *Sniffer, extractors ...Extractor) *Multi {
+	return &Multi{config, sniffer, extractors}
+}
+
+// applicable returns the extractors applying to r.
+func (m *Multi) applicable(r *t.AnnotatedResource) []Extractor {
+	extractors := make([]Extractor, 0, len(m.extractors))
+
+	for _, e := range m.extractors {
+		if s, ok := e.(Selective); ok && !s.Applies(r) {
+			continue
+		}
+
+		extractors = append(extractors, e)
+	}
+
+	return extractors
+}
+
+// Extract runs all applicable extractors within the configured timeout, in order or in parallel.
+func (m *Multi) Extract(ctx context.Context, r *t.AnnotatedResource, metadata interface{}) error {
+	if m.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.config.Timeout)
+		defer cancel()
+	}
+
+	if m.sniffer != nil && r.MimeType == "" {
+		if err := m.sniffer.Sniff(ctx, r); err != nil {
+			// Fall back to the extension, leaving errors fetching the content to the extractors.
+			logger.Warnf("Error sniffing MIME type of %v: %v", r, err)
+		}
+	}
+
+	extractors := m.applicable(r)
+
+	if m.config.Parallel && len(extractors) > 1 {
+		return extractParallel(ctx, extractors, r, metadata)
+	}
+
+	return extractInOrder(ctx, extractors, r, metadata)
+}
+
+// extractInOrder runs extractors in order, returning the first error.
+func extractInOrder(ctx context.Context, extractors []Extractor, r *t.AnnotatedResource, metadata interface{}) error {
+	for _, e := range extractors {
+		if err := e.Extract(ctx, r, metadata); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractParallel runs extractors concurrently and merges their results into metadata in order, returning the
+// errors of all failing extractors.
+func extractParallel(ctx context.Context, extractors []Extractor, r *t.AnnotatedResource, metadata interface{}) error {
+	var (
+		wg      sync.WaitGroup
+		results = make([]map[string]json.RawMessage, len(extractors))
+		errs    = make([]error, len(extractors))
+	)
+
+	for i, e := range extractors {
+		wg.Add(1)
+
+		go func(i int, e Extractor) {
+			defer wg.Done()
+
+			// Extractors decode JSON into metadata; keep raw values for merging.
+			errs[i] = e.Extract(ctx, r, &results[i])
+		}(i, e)
+	}
+
+	wg.Wait()
+
+	var failed Errors
+
+	for i, result := range results {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+
+		if len(result) == 0 {
+			continue
+		}
+
+		if err := Merge(result, metadata); err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return failed
+	}
+
+	return nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ Extractor = &Multi{}
@@ -0,0 +1,159 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// jsonExtractor merges fixed JSON into metadata, optionally only applying to resources with a given name or MIME type.
+type jsonExtractor struct {
+	json        string
+	applies     string
+	appliesType string
+	delay       time.Duration
+}
+
+func (e *jsonExtractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(e.delay):
+	}
+
+	return json.Unmarshal([]byte(e.json), m)
+}
+
+func (e *jsonExtractor) Applies(r *t.AnnotatedResource) bool {
+	if e.appliesType != "" {
+		return e.appliesType == MimeType(r)
+	}
+
+	return e.applies == "" || e.applies == r.Reference.Name
+}
+
+type testMetadata struct {
+	A string `json:"a"`
+	B string `json:"b"`
+	C string `json:"c"`
+}
+
+type MultiTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	cfg *Config
+	r   *t.AnnotatedResource
+}
+
+func (s *MultiTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.cfg = DefaultConfig()
+	s.r = &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmehHHRh1a7u66r7fugebp6f6wGNMGCa7eho9cgjwhAcm2",
+		},
+		Reference: t.Reference{
+			Name: "photo.png",
+		},
+	}
+}
+
+func (s *MultiTestSuite) TestInOrder() {
+	m := NewMulti(s.cfg, nil,
+		&jsonExtractor{json: `{"a":"1","b":"1"}`},
+		&jsonExtractor{json: `{"b":"2"}`},
+	)
+
+	var result testMetadata
+	s.NoError(m.Extract(s.ctx, s.r, &result))
+	s.Equal(testMetadata{A: "1", B: "2"}, result)
+}
+
+func (s *MultiTestSuite) TestInOrderStopsAtError() {
+	testErr := errors.New("extraction failed")
+
+	failing := &Mock{}
+	failing.On("Extract", mock.Anything, s.r, mock.Anything).Return(testErr).Once()
+
+	skipped := &Mock{}
+
+	m := NewMulti(s.cfg, nil, failing, skipped)
+
+	err := m.Extract(s.ctx, s.r, &testMetadata{})
+
+	s.True(errors.Is(err, testErr))
+	skipped.AssertNotCalled(s.T(), "Extract", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *MultiTestSuite) TestParallel() {
+	s.cfg.Parallel = true
+
+	// The slowest extractor comes first; results are merged in order nonetheless.
+	m := NewMulti(s.cfg, nil,
+		&jsonExtractor{json: `{"a":"1","b":"1"}`, delay: 20 * time.Millisecond},
+		&jsonExtractor{json: `{"b":"2"}`},
+		&jsonExtractor{json: `{"c":"3"}`, applies: "page.html"},
+	)
+
+	var result testMetadata
+	s.NoError(m.Extract(s.ctx, s.r, &result))
+	s.Equal(testMetadata{A: "1", B: "2"}, result)
+}
+
+func (s *MultiTestSuite) TestParallelErrors() {
+	s.cfg.Parallel = true
+
+	m := NewMulti(s.cfg, nil,
+		&Mock{},
+		&jsonExtractor{json: `{"a":"1"}`},
+	)
+
+	failing := m.extractors[0].(*Mock)
+	failing.On("Extract", mock.Anything, s.r, mock.Anything).Return(ErrFileTooLarge).Once()
+
+	var result testMetadata
+	err := m.Extract(s.ctx, s.r, &result)
+
+	s.True(errors.Is(err, ErrFileTooLarge))
+	s.Equal(testMetadata{A: "1"}, result)
+}
+
+func (s *MultiTestSuite) TestTimeout() {
+	s.cfg.Parallel = true
+	s.cfg.Timeout = 10 * time.Millisecond
+
+	m := NewMulti(s.cfg, nil,
+		&jsonExtractor{json: `{"a":"1"}`, delay: time.Second},
+		&jsonExtractor{json: `{"b":"2"}`, delay: time.Second},
+	)
+
+	err := m.Extract(s.ctx, s.r, &testMetadata{})
+
+	s.True(errors.Is(err, context.DeadlineExceeded))
+	s.Len(err.(Errors), 2)
+}
+
+func (s *MultiTestSuite) TestSelective() {
+	m := NewMulti(s.cfg, nil,
+		&jsonExtractor{json: `{"a":"1"}`, applies: "page.html"},
+	)
+
+	s.Empty(m.applicable(s.r))
+
+	var result testMetadata
+	s.NoError(m.Extract(s.ctx, s.r, &result))
+	s.Equal(testMetadata{}, result)
+}
+
+func TestMultiTestSuite(t *testing.T) {
+	suite.Run(t, new(MultiTestSuite))
+}
@@ -0,0 +1,47 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// limitedBody limits reading from a response body, while still closing it.
+type limitedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// GetRange requests the first size bytes of url with a Range header, or the full resource when size is 0.
+// Servers ignoring the Range header return 200 with the full body; in that case the body is limited to size bytes,
+// so that closing it aborts the transfer of the remainder. Any status other than 200 or 206 results in an error.
+func GetRange(ctx context.Context, client *http.Client, url string, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		// Errors here are programming errors.
+		panic(fmt.Sprintf("creating request: %s", err))
+	}
+
+	if size > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", size-1))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRequest, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if size > 0 {
+			resp.Body = limitedBody{io.LimitReader(resp.Body, size), resp.Body}
+		}
+	case http.StatusPartialContent:
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: unexpected status %s", ErrUnexpectedResponse, resp.Status)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,78 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const rangeTestBody = "0123456789"
+
+func TestGetRange(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(rangeTestBody))
+	}))
+	defer s.Close()
+
+	resp, err := GetRange(context.Background(), http.DefaultClient, s.URL, 4)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "0123", string(body))
+}
+
+func TestGetRangeIgnored(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rangeTestBody))
+	}))
+	defer s.Close()
+
+	resp, err := GetRange(context.Background(), http.DefaultClient, s.URL, 4)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	// Full body returned, limited by the client.
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0123", string(body))
+}
+
+func TestGetRangeFull(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Range"))
+		w.Write([]byte(rangeTestBody))
+	}))
+	defer s.Close()
+
+	resp, err := GetRange(context.Background(), http.DefaultClient, s.URL, 0)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, rangeTestBody, string(body))
+}
+
+func TestGetRangeStatus(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	_, err := GetRange(context.Background(), http.DefaultClient, s.URL, 4)
+	assert.True(t, errors.Is(err, ErrUnexpectedResponse))
+}
@@ -0,0 +1,74 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// sniffLen is the amount of bytes considered by http.DetectContentType.
+const sniffLen = 512
+
+// Sniffer determines the MIME type of resources from their first bytes, fetched from the gateway.
+type Sniffer struct {
+	client   *http.Client
+	protocol protocol.Protocol
+
+	*instr.Instrumentation
+}
+
+// NewSniffer returns a new Sniffer.
+func NewSniffer(client *http.Client, protocol protocol.Protocol, instr *instr.Instrumentation) *Sniffer {
+	return &Sniffer{
+		client,
+		protocol,
+		instr,
+	}
+}
+
+// sniff returns the MIME type for content, without parameters.
+func sniff(content []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil {
+		// DetectContentType only returns valid media types.
+		panic(err)
+	}
+
+	return mediaType
+}
+
+// Sniff sets the MIME type of r from its first bytes.
+func (s *Sniffer) Sniff(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := s.Tracer.Start(ctx, "extractor.Sniff")
+	defer span.End()
+
+	resp, err := GetRange(ctx, s.client, s.protocol.GatewayURL(r), sniffLen)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrRequest, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	r.MimeType = sniff(content)
+	span.SetAttributes(label.String("mimetype", r.MimeType))
+
+	return nil
+}
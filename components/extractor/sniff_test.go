@@ -0,0 +1,141 @@
+package extractor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+func TestSniffSignatures(t *testing.T) {
+	cases := map[string]string{
+		"\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR": "image/png",
+		"\xFF\xD8\xFF\xE0\x00\x10JFIF\x00":            "image/jpeg",
+		"GIF89a\x01\x00\x01\x00":                      "image/gif",
+		"RIFF\x24\x00\x00\x00WEBPVP8 ":                "image/webp",
+		"%PDF-1.4\n%\xE2\xE3\xCF\xD3":                 "application/pdf",
+		"PK\x03\x04\x14\x00\x00\x00":                  "application/zip",
+		"\x1F\x8B\x08\x00\x00\x00\x00\x00":            "application/x-gzip",
+		"ID3\x03\x00\x00\x00\x00\x00\x00":             "audio/mpeg",
+		"\x1A\x45\xDF\xA3\x01\x00\x00\x00":            "video/webm",
+		"  <!DOCTYPE html><html><head>":               "text/html",
+		"<?xml version=\"1.0\"?><feed>":               "text/xml",
+		"Just some plain text.":                       "text/plain",
+		"\x00\x01\x02\x03\x04\x05":                    "application/octet-stream",
+	}
+
+	for content, expected := range cases {
+		assert.Equal(t, expected, sniff([]byte(content)), "content %q", content)
+	}
+}
+
+type SnifferTestSuite struct {
+	suite.Suite
+
+	ctx      context.Context
+	protocol *protocol.Mock
+	server   *httptest.Server
+	sniffer  *Sniffer
+	r        *t.AnnotatedResource
+
+	rangeHeader string
+}
+
+func (s *SnifferTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.protocol = &protocol.Mock{}
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.rangeHeader = r.Header.Get("Range")
+		w.Write([]byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR"))
+	}))
+
+	s.sniffer = NewSniffer(http.DefaultClient, s.protocol, instr.New())
+
+	s.r = &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmehHHRh1a7u66r7fugebp6f6wGNMGCa7eho9cgjwhAcm2",
+		},
+		Reference: t.Reference{
+			Name: "image",
+		},
+	}
+
+	s.protocol.On("GatewayURL", s.r).Return(s.server.URL + "/ipfs/" + s.r.ID)
+}
+
+func (s *SnifferTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *SnifferTestSuite) TestSniff() {
+	s.NoError(s.sniffer.Sniff(s.ctx, s.r))
+
+	s.Equal("bytes=0-511", s.rangeHeader)
+	s.Equal("image/png", s.r.MimeType)
+}
+
+func (s *SnifferTestSuite) TestMultiSniff() {
+	images := &jsonExtractor{json: `{"a":"image"}`}
+	images.appliesType = "image/png"
+
+	m := NewMulti(DefaultConfig(), s.sniffer, images)
+
+	var result testMetadata
+	s.NoError(m.Extract(s.ctx, s.r, &result))
+
+	// The extractor is selected by the sniffed type, as the name has no extension.
+	s.Equal("image", result.A)
+}
+
+func (s *SnifferTestSuite) TestMultiSniffError() {
+	s.server.Close()
+
+	images := &jsonExtractor{json: `{"a":"image"}`}
+	images.appliesType = "image/png"
+
+	m := NewMulti(DefaultConfig(), s.sniffer, images)
+
+	var result testMetadata
+	s.NoError(m.Extract(s.ctx, s.r, &result))
+
+	// Without a MIME type, the extractor does not apply.
+	s.Equal("", result.A)
+}
+
+func (s *SnifferTestSuite) TestMimeType() {
+	r := &t.AnnotatedResource{
+		Resource: s.r.Resource,
+	}
+
+	// Neither sniffed nor named.
+	s.Equal("", MimeType(r))
+
+	// Misleading extension.
+	r.Reference.Name = "photo.txt"
+	r.MimeType = "image/png"
+	s.Equal("image/png", MimeType(r))
+
+	// Generic sniffed types fall back to the extension.
+	r.Reference.Name = "page.html"
+	r.MimeType = "text/plain"
+	s.Equal("text/html", MimeType(r))
+
+	// Generic sniffed types without extension.
+	r.Reference.Name = ""
+	r.MimeType = "application/octet-stream"
+	s.Equal("application/octet-stream", MimeType(r))
+}
+
+func TestSnifferTestSuite(t *testing.T) {
+	suite.Run(t, new(SnifferTestSuite))
+}
@@ -0,0 +1,27 @@
+package structureddata
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+)
+
+// Config specifies the configuration for the structured data extractor.
+type Config struct {
+	Enabled        bool              // Extract JSON-LD and microdata from HTML.
+	RequestTimeout time.Duration     // Timeout for requests to the gateway.
+	MaxFileSize    datasize.ByteSize // Skip larger HTML files.
+	MaxBlocks      int               // Maximum number of structured data blocks per file.
+	MaxBlockSize   datasize.ByteSize // Skip blocks larger than this (JSON encoded).
+}
+
+// DefaultConfig returns the default configuration for the structured data extractor.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:        false,
+		RequestTimeout: 60 * time.Second,
+		MaxFileSize:    1024 * 1024, // 1MB
+		MaxBlocks:      16,
+		MaxBlockSize:   64 * 1024, // 64KB
+	}
+}
@@ -0,0 +1,69 @@
+// Package structureddata extracts schema.org structured data (JSON-LD and microdata) from HTML.
+package structureddata
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// htmlTypes are the MIME types of HTML documents.
+var htmlTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+}
+
+// Extractor extracts structured data by fetching HTML documents from the gateway.
+type Extractor struct {
+	config  *Config
+	fetcher *extractor.Fetcher
+}
+
+// properties are merged into the extracted metadata.
+type properties struct {
+	StructuredData []Block `json:"structured_data,omitempty"`
+}
+
+// Applies returns true for HTML resources up to the maximum file size.
+func (e *Extractor) Applies(r *t.AnnotatedResource) bool {
+	return htmlTypes[extractor.MimeType(r)] && r.Size <= uint64(e.config.MaxFileSize)
+}
+
+// parse extracts structured data blocks from body, returning no properties for documents without them.
+func (e *Extractor) parse(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error) {
+	blocks, err := parse(body, e.config.MaxBlocks, int(e.config.MaxBlockSize))
+	if err != nil || len(blocks) == 0 {
+		return nil, err
+	}
+
+	return properties{blocks}, nil
+}
+
+// Extract structured data from HTML resources up to the maximum file size, ignoring other resources.
+func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	if !e.Applies(r) {
+		return nil
+	}
+
+	return e.fetcher.Extract(ctx, r, m, e.config.RequestTimeout, int64(e.config.MaxFileSize), e.parse)
+}
+
+// New returns a new structured data extractor.
+func New(config *Config, client *http.Client, protocol protocol.Protocol, instr *instr.Instrumentation) extractor.Extractor {
+	return &Extractor{
+		config,
+		extractor.NewFetcher("structureddata", client, protocol, instr),
+	}
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ extractor.Extractor = &Extractor{}
+	_ extractor.Selective = &Extractor{}
+)
@@ -0,0 +1,76 @@
+package structureddata
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/extractor/extractortest"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type StructuredDataTestSuite struct {
+	extractortest.Suite
+
+	e   extractor.Extractor
+	cfg *Config
+}
+
+func (s *StructuredDataTestSuite) SetupTest() {
+	s.Suite.SetupTest()
+
+	s.cfg = DefaultConfig()
+
+	s.e = New(s.cfg, http.DefaultClient, s.Protocol, instr.New())
+}
+
+func (s *StructuredDataTestSuite) TestExtract() {
+	body := []byte(`<html><head><script type="application/ld+json">{"@type": "Recipe", "name": "Pancakes"}</script></head></html>`)
+	r := s.Resource("index.html", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertFetched()
+
+	s.Equal([]map[string]interface{}{
+		{
+			"@type": "Recipe",
+			"name":  "Pancakes",
+		},
+	}, f.StructuredData)
+}
+
+func (s *StructuredDataTestSuite) TestExtractNotHTML() {
+	r := s.Resource("document.pdf", "", 100)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+
+	s.Empty(f.StructuredData)
+}
+
+func (s *StructuredDataTestSuite) TestExtractTooLarge() {
+	r := s.Resource("index.html", "", int(s.cfg.MaxFileSize)+1)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+
+	s.Empty(f.StructuredData)
+}
+
+func TestStructuredDataTestSuite(t *testing.T) {
+	suite.Run(t, new(StructuredDataTestSuite))
+}
@@ -0,0 +1,228 @@
+package structureddata
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Block is a single JSON-LD object or top-level microdata item.
+type Block map[string]interface{}
+
+// parser collects structured data blocks from an HTML document, up to the configured limits.
+type parser struct {
+	maxBlocks    int
+	maxBlockSize int
+
+	blocks []Block
+}
+
+// full returns true when no more blocks should be added.
+func (p *parser) full() bool {
+	return len(p.blocks) >= p.maxBlocks
+}
+
+// add appends a block, unless it exceeds the maximum block size.
+func (p *parser) add(b Block) {
+	if p.full() {
+		return
+	}
+
+	buf, err := json.Marshal(b)
+	if err != nil || len(buf) > p.maxBlockSize {
+		return
+	}
+
+	p.blocks = append(p.blocks, b)
+}
+
+// attr returns the value of the attribute with the given key, and whether it exists.
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+
+	return "", false
+}
+
+// textContent returns the concatenated text of n and its descendants.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return strings.TrimSpace(b.String())
+}
+
+// isJSONLD returns true for script elements containing JSON-LD.
+func isJSONLD(n *html.Node) bool {
+	if n.Data != "script" {
+		return false
+	}
+
+	t, _ := attr(n, "type")
+	mediaType, _, err := mime.ParseMediaType(t)
+
+	return err == nil && mediaType == "application/ld+json"
+}
+
+// addJSONLD adds the object(s) in a JSON-LD script, ignoring invalid JSON.
+func (p *parser) addJSONLD(n *html.Node) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(textContent(n)), &v); err != nil {
+		return
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		p.add(v)
+	case []interface{}:
+		for _, e := range v {
+			if o, ok := e.(map[string]interface{}); ok {
+				p.add(o)
+			}
+		}
+	}
+}
+
+// propertyValue returns the value of a microdata property element, according to the HTML microdata spec.
+func propertyValue(n *html.Node) string {
+	var key string
+
+	switch n.Data {
+	case "meta":
+		key = "content"
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		key = "src"
+	case "a", "area", "link":
+		key = "href"
+	case "object":
+		key = "data"
+	case "data", "meter":
+		key = "value"
+	case "time":
+		key = "datetime"
+	}
+
+	if key != "" {
+		if v, ok := attr(n, key); ok {
+			return v
+		}
+	}
+
+	return textContent(n)
+}
+
+// addProperty adds value to the property name of item, turning it into a list on repeated properties.
+func addProperty(item Block, name string, value interface{}) {
+	switch existing := item[name].(type) {
+	case nil:
+		item[name] = value
+	case []interface{}:
+		item[name] = append(existing, value)
+	default:
+		item[name] = []interface{}{existing, value}
+	}
+}
+
+// item returns the microdata item with root element n.
+func item(n *html.Node) Block {
+	b := Block{}
+
+	if t, ok := attr(n, "itemtype"); ok && t != "" {
+		b["@type"] = t
+	}
+
+	if id, ok := attr(n, "itemid"); ok && id != "" {
+		b["@id"] = id
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+
+			_, isScope := attr(c, "itemscope")
+
+			if props, ok := attr(c, "itemprop"); ok {
+				var value interface{}
+				if isScope {
+					value = item(c)
+				} else {
+					value = propertyValue(c)
+				}
+
+				for _, name := range strings.Fields(props) {
+					addProperty(b, name, value)
+				}
+			}
+
+			// Properties of nested items belong to those items.
+			if !isScope {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+
+	return b
+}
+
+// walk traverses the document, adding JSON-LD blocks and top-level microdata items.
+func (p *parser) walk(n *html.Node) {
+	if p.full() {
+		return
+	}
+
+	if n.Type == html.ElementNode {
+		if isJSONLD(n) {
+			p.addJSONLD(n)
+			return
+		}
+
+		_, isScope := attr(n, "itemscope")
+		_, isProp := attr(n, "itemprop")
+
+		if isScope && !isProp {
+			p.add(item(n))
+			return
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.walk(c)
+	}
+}
+
+// parse returns up to maxBlocks JSON-LD and microdata blocks from the HTML in r, skipping blocks larger than
+// maxBlockSize bytes.
+func parse(r io.Reader, maxBlocks int, maxBlockSize int) ([]Block, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		maxBlocks:    maxBlocks,
+		maxBlockSize: maxBlockSize,
+	}
+	p.walk(doc)
+
+	return p.blocks, nil
+}
@@ -0,0 +1,87 @@
+package structureddata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testHTML = `<!DOCTYPE html>
+<html>
+<head>
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@type": "Recipe",
+		"name": "Pancakes"
+	}
+	</script>
+	<script type="application/ld+json; charset=utf-8">[{"@type": "Person", "name": "Alice"}, "ignored"]</script>
+	<script type="application/ld+json">invalid</script>
+	<script type="text/javascript">{"@type": "NotStructuredData"}</script>
+</head>
+<body>
+	<div itemscope itemtype="https://schema.org/Product">
+		<span itemprop="name">Widget</span>
+		<img itemprop="image" src="widget.png">
+		<meta itemprop="sku" content="W-1">
+		<span itemprop="color">red</span>
+		<span itemprop="color">blue</span>
+		<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+			<span itemprop="price">9.99</span>
+		</div>
+	</div>
+</body>
+</html>`
+
+func TestParse(t *testing.T) {
+	blocks, err := parse(strings.NewReader(testHTML), 10, 1024)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Block{
+		{
+			"@context": "https://schema.org",
+			"@type":    "Recipe",
+			"name":     "Pancakes",
+		},
+		{
+			"@type": "Person",
+			"name":  "Alice",
+		},
+		{
+			"@type": "https://schema.org/Product",
+			"name":  "Widget",
+			"image": "widget.png",
+			"sku":   "W-1",
+			"color": []interface{}{"red", "blue"},
+			"offers": Block{
+				"@type": "https://schema.org/Offer",
+				"price": "9.99",
+			},
+		},
+	}, blocks)
+}
+
+func TestParseMaxBlocks(t *testing.T) {
+	blocks, err := parse(strings.NewReader(testHTML), 1, 1024)
+
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, "Recipe", blocks[0]["@type"])
+}
+
+func TestParseMaxBlockSize(t *testing.T) {
+	blocks, err := parse(strings.NewReader(testHTML), 10, 40)
+
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, "Person", blocks[0]["@type"])
+}
+
+func TestParseNoStructuredData(t *testing.T) {
+	blocks, err := parse(strings.NewReader("<html><body><p>Hello</p></body></html>"), 10, 1024)
+
+	assert.NoError(t, err)
+	assert.Empty(t, blocks)
+}
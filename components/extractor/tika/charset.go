@@ -0,0 +1,29 @@
+package tika
+
+import (
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// byteOrderMark is stripped from transcoded content.
+const byteOrderMark = "\uFEFF"
+
+// toUTF8 returns content transcoded to UTF-8, along with the (lowercase) name of its original encoding.
+//
+// The encoding is determined by the byte order mark, the charset in contentType, a <meta> tag for HTML or,
+// failing those, assumed to be UTF-8 when content is valid UTF-8 and windows-1252 (a superset of Latin-1)
+// otherwise. Content already in UTF-8 is not transcoded.
+func toUTF8(content []byte, contentType string) (string, string, error) {
+	e, name, _ := charset.DetermineEncoding(content, contentType)
+	name = strings.ToLower(name)
+
+	if name != "utf-8" {
+		var err error
+		if content, err = e.NewDecoder().Bytes(content); err != nil {
+			return "", name, err
+		}
+	}
+
+	return strings.TrimPrefix(string(content), byteOrderMark), name, nil
+}
@@ -0,0 +1,33 @@
+package tika
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToUTF8(t *testing.T) {
+	cases := []struct {
+		name        string
+		content     []byte
+		contentType string
+		expected    string
+		charset     string
+	}{
+		{"utf-8", []byte("café ☕"), "text/plain", "café ☕", "utf-8"},
+		{"utf-8 bom", []byte("\xef\xbb\xbfcafé"), "text/plain", "café", "utf-8"},
+		{"utf-16le bom", []byte("\xff\xfec\x00a\x00f\x00\xe9\x00"), "text/plain", "café", "utf-16le"},
+		{"latin-1 header", []byte("caf\xe9"), "text/plain; charset=ISO-8859-1", "café", "windows-1252"},
+		{"latin-1 undeclared", []byte("caf\xe9"), "text/plain", "café", "windows-1252"},
+		{"shift-jis header", []byte("\x93\xfa\x96\x7b"), "text/plain; charset=Shift_JIS", "日本", "shift_jis"},
+		{"shift-jis meta", []byte(`<meta charset="shift_jis">` + "\x93\xfa\x96\x7b"), "text/html", `<meta charset="shift_jis">日本`, "shift_jis"},
+	}
+
+	for _, c := range cases {
+		result, charset, err := toUTF8(c.content, c.contentType)
+
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.expected, result, c.name)
+		assert.Equal(t, c.charset, charset, c.name)
+	}
+}
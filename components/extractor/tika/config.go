@@ -1,15 +1,43 @@
 package tika
 
 import (
-	"github.com/c2h5oh/datasize"
+	"fmt"
+	"net/http"
+	"path"
 	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/utils"
 )
 
 // Config specifies the configuration for a Tika extractor.
 type Config struct {
-	TikaExtractorURL string            // TikaServer is the URL of the ipfs-tika server.
-	RequestTimeout   time.Duration     // Timeout for metadata requests for the server.
-	MaxFileSize      datasize.ByteSize // Don't attempt to get metadata for files over this size.
+	TikaExtractorURL string                   // TikaServer is the URL of the ipfs-tika server.
+	RequestTimeout   time.Duration            // Timeout for metadata requests for the server.
+	MimeTimeouts     map[string]time.Duration // Timeouts per MIME type or glob (e.g. `video/*`), overriding RequestTimeout.
+	MinFileSize      datasize.ByteSize        // Don't attempt to get metadata for files under this size.
+	MaxFileSize      datasize.ByteSize        // Don't attempt to get metadata for files over this size.
+	AcceptType       string                   // Requested representation; application/json or text/plain (content only).
+	MaxResponseSize  datasize.ByteSize        // Maximum size of responses from the server.
+	Headers          map[string]string        // Custom headers for requests to the server; values expand $VAR or ${VAR} from env.
+	HeaderFiles      map[string]string        // Custom headers read from files (e.g. secret mounts), by header name; re-read for every request.
+	MaxConcurrency   uint                     // Maximum concurrent requests to the server, across workers; unlimited when 0.
+	DeniedMetadata   []string                 // Metadata keys or globs (e.g. `X-TIKA:*`) removed before indexing.
+
+	FallbackGatewayURL string        // Public gateway to extract from when the local gateway times out; disabled when empty.
+	FallbackTimeout    time.Duration // Timeout for metadata requests through the fallback gateway.
+	FallbackRateLimit  float64       // Maximum fallback requests per second; unlimited when 0.
+	FallbackValidators bool          // Index the ETag and Last-Modified of content extracted through the fallback gateway.
+}
+
+// defaultDeniedMetadata are metadata keys describing the extraction environment rather than the content.
+var defaultDeniedMetadata = []string{
+	"X-Parsed-By",
+	"X-TIKA:Parsed-By*",
+	"X-TIKA:content_handler",
+	"X-TIKA:embedded_resource_path",
+	"X-TIKA:origResourceName",
 }
 
 // DefaultConfig returns the default configuration for a Sniffer.
@@ -17,6 +45,91 @@ func DefaultConfig() *Config {
 	return &Config{
 		TikaExtractorURL: "http://localhost:8081",
 		RequestTimeout:   300 * time.Duration(time.Second),
-		MaxFileSize:      4 * 1024 * 1024 * 1024, // 4GB
+		MimeTimeouts: map[string]time.Duration{
+			"application/pdf": 600 * time.Second,
+			"text/html":       60 * time.Second,
+		},
+		MinFileSize:        0,
+		MaxFileSize:        4 * 1024 * 1024 * 1024, // 4GB
+		AcceptType:         "application/json",
+		MaxResponseSize:    256 * 1024 * 1024, // 256MB
+		Headers:            map[string]string{},
+		HeaderFiles:        map[string]string{},
+		MaxConcurrency:     0,
+		DeniedMetadata:     defaultDeniedMetadata,
+		FallbackGatewayURL: "",
+		FallbackTimeout:    60 * time.Second,
+		FallbackRateLimit:  1,
+		FallbackValidators: false,
+	}
+}
+
+// reservedHeaders are set by the extractor itself and cannot be overridden by custom headers.
+var reservedHeaders = map[string]bool{
+	"Accept":     true,
+	"User-Agent": true,
+}
+
+// Validate returns an error when MimeTimeouts contains invalid patterns or non-positive timeouts, when Headers or
+// HeaderFiles would override headers set by the extractor, when HeaderFiles can't be read or when DeniedMetadata
+// contains invalid patterns.
+func (c *Config) Validate() error {
+	for name := range c.Headers {
+		if reservedHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("header '%s' is set by the extractor and cannot be overridden", name)
+		}
+	}
+
+	for name, file := range c.HeaderFiles {
+		if reservedHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("header '%s' is set by the extractor and cannot be overridden", name)
+		}
+
+		if _, err := utils.ReadSecretFile(file); err != nil {
+			return fmt.Errorf("reading file for header '%s': %w", name, err)
+		}
 	}
+
+	for _, pattern := range c.DeniedMetadata {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid denied metadata pattern '%s': %w", pattern, err)
+		}
+	}
+
+	for pattern, timeout := range c.MimeTimeouts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid MIME type pattern '%s': %w", pattern, err)
+		}
+
+		if timeout <= 0 {
+			return fmt.Errorf("timeout for MIME type '%s' should be positive, got %s", pattern, timeout)
+		}
+	}
+
+	return nil
+}
+
+// timeoutFor returns the request timeout for mimeType, preferring exact matches over the longest
+// matching glob and falling back to RequestTimeout.
+func (c *Config) timeoutFor(mimeType string) time.Duration {
+	if mimeType == "" {
+		return c.RequestTimeout
+	}
+
+	if timeout, ok := c.MimeTimeouts[mimeType]; ok {
+		return timeout
+	}
+
+	var (
+		timeout = c.RequestTimeout
+		longest string
+	)
+
+	for pattern, t := range c.MimeTimeouts {
+		if matched, _ := path.Match(pattern, mimeType); matched && len(pattern) > len(longest) {
+			timeout, longest = t, pattern
+		}
+	}
+
+	return timeout
 }
@@ -0,0 +1,82 @@
+package tika
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutFor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MimeTimeouts = map[string]time.Duration{
+		"application/pdf": 10 * time.Minute,
+		"video/*":         20 * time.Minute,
+		"video/mp4":       30 * time.Minute,
+		"*/*":             time.Minute,
+	}
+
+	assert.Equal(t, 10*time.Minute, cfg.timeoutFor("application/pdf"))
+	assert.Equal(t, 30*time.Minute, cfg.timeoutFor("video/mp4"))
+	assert.Equal(t, 20*time.Minute, cfg.timeoutFor("video/webm"))
+	assert.Equal(t, time.Minute, cfg.timeoutFor("text/html"))
+	assert.Equal(t, cfg.RequestTimeout, cfg.timeoutFor(""))
+}
+
+func TestValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.MimeTimeouts = map[string]time.Duration{"text/html": 0}
+	assert.Error(t, cfg.Validate())
+
+	cfg.MimeTimeouts = map[string]time.Duration{"text/[": time.Second}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidateDeniedMetadata(t *testing.T) {
+	cfg := DefaultConfig()
+
+	cfg.DeniedMetadata = []string{"X-TIKA:*"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.DeniedMetadata = []string{"X-TIKA:["}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidateHeaders(t *testing.T) {
+	cfg := DefaultConfig()
+
+	cfg.Headers = map[string]string{"X-Api-Key": "secret"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Headers = map[string]string{"accept": "text/plain"}
+	assert.Error(t, cfg.Validate())
+
+	cfg.Headers = map[string]string{"User-Agent": "custom"}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidateHeaderFiles(t *testing.T) {
+	f, err := ioutil.TempFile("", "tika-api-key")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString("secret")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	cfg := DefaultConfig()
+
+	cfg.HeaderFiles = map[string]string{"X-Api-Key": f.Name()}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.HeaderFiles = map[string]string{"X-Api-Key": f.Name() + ".missing"}
+	assert.Error(t, cfg.Validate())
+
+	cfg.HeaderFiles = map[string]string{"Accept": f.Name()}
+	assert.Error(t, cfg.Validate())
+}
@@ -0,0 +1,53 @@
+package tika
+
+import (
+	"encoding/json"
+	"path"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+)
+
+// isDenied returns true when key matches any of the patterns, which are metadata keys or globs (e.g. `X-TIKA:*`).
+func isDenied(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stripMetadata removes metadata keys matching patterns from m, which has been decoded from doc, the JSON document
+// returned by the server.
+func stripMetadata(doc json.RawMessage, patterns []string, m interface{}) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	var d struct {
+		Metadata map[string]json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(doc, &d); err != nil || d.Metadata == nil {
+		return nil
+	}
+
+	denied := false
+	for key := range d.Metadata {
+		if isDenied(key, patterns) {
+			delete(d.Metadata, key)
+			denied = true
+		}
+	}
+
+	if !denied {
+		return nil
+	}
+
+	// Decoding merges into existing maps; clear metadata before setting the remaining keys.
+	if err := extractor.Merge(json.RawMessage(`{"metadata":null}`), m); err != nil {
+		return err
+	}
+
+	return extractor.Merge(d, m)
+}
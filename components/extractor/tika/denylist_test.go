@@ -0,0 +1,51 @@
+package tika
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestIsDenied(t *testing.T) {
+	patterns := []string{"X-Parsed-By", "X-TIKA:Parsed-By*"}
+
+	assert.True(t, isDenied("X-Parsed-By", patterns))
+	assert.True(t, isDenied("X-TIKA:Parsed-By-Full-Set", patterns))
+	assert.False(t, isDenied("dc:title", patterns))
+	assert.False(t, isDenied("X-Parsed-By", nil))
+}
+
+func TestStripMetadata(t *testing.T) {
+	doc := json.RawMessage(`{
+		"metadata": {
+			"dc:title": ["Annual report"],
+			"X-Parsed-By": ["org.apache.tika.parser.DefaultParser"],
+			"X-TIKA:Parsed-By-Full-Set": ["org.apache.tika.parser.pdf.PDFParser"],
+			"X-TIKA:embedded_resource_path": ["/tmp/apache-tika-123.tmp"]
+		},
+		"content": "Annual report"
+	}`)
+
+	f := &indexTypes.File{}
+	require.NoError(t, json.Unmarshal(doc, f))
+
+	stripMetadata(doc, defaultDeniedMetadata, f)
+
+	assert.Equal(t, indexTypes.Metadata{"dc:title": []interface{}{"Annual report"}}, f.Metadata)
+	assert.Equal(t, "Annual report", f.Content)
+}
+
+func TestStripMetadataNoPatterns(t *testing.T) {
+	doc := json.RawMessage(`{"metadata": {"X-Parsed-By": ["org.apache.tika.parser.DefaultParser"]}}`)
+
+	f := &indexTypes.File{}
+	require.NoError(t, json.Unmarshal(doc, f))
+
+	stripMetadata(doc, nil, f)
+
+	assert.Contains(t, f.Metadata, "X-Parsed-By")
+}
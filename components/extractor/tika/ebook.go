@@ -0,0 +1,174 @@
+package tika
+
+import (
+	"encoding/json"
+	"math"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+)
+
+// Bound on the authors indexed per book.
+const maxAuthors = 32
+
+// ebookTypes are the MIME types detected by Tika for which OPF metadata is promoted to typed fields.
+var ebookTypes = map[string]bool{
+	"application/epub+zip":           true, // .epub
+	"application/x-mobipocket-ebook": true, // .mobi
+	"application/vnd.amazon.ebook":   true, // .azw
+}
+
+// Metadata keys for book properties, in order of preference. Series are Calibre's and EPUB 3's conventions.
+var (
+	bookTitleKeys       = []string{"dc:title", "title"}
+	bookAuthorKeys      = []string{"dc:creator", "meta:author", "Author"}
+	bookPublisherKeys   = []string{"dc:publisher", "publisher"}
+	bookLanguageKeys    = []string{"dc:language", "language"}
+	bookIdentifierKeys  = []string{"dc:identifier", "identifier"}
+	bookSeriesKeys      = []string{"calibre:series", "belongs-to-collection"}
+	bookSeriesIndexKeys = []string{"calibre:series_index", "group-position"}
+)
+
+// ebook metadata is merged into extracted documents.
+type ebook struct {
+	BookAuthor      []string `json:"book_author,omitempty"`
+	BookLanguage    string   `json:"book_language,omitempty"`
+	BookSeries      string   `json:"book_series,omitempty"`
+	BookSeriesIndex float64  `json:"book_series_index,omitempty"`
+	BookTitle       string   `json:"book_title,omitempty"`
+	ISBN            string   `json:"isbn,omitempty"`
+	Publisher       string   `json:"publisher,omitempty"`
+}
+
+// isEbook returns true when the Content-Type detected by Tika is an ebook type.
+func isEbook(metadata map[string]json.RawMessage) bool {
+	for _, value := range metadataValues(metadata["Content-Type"]) {
+		if mediaType, _, err := mime.ParseMediaType(value); err == nil && ebookTypes[mediaType] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isbnChecksum returns true when digits, an ISBN-10 or ISBN-13 without separators, has a valid check digit.
+func isbnChecksum(digits string) bool {
+	sum := 0
+
+	switch len(digits) {
+	case 10:
+		for i, d := range digits {
+			v := int(d - '0')
+			if d == 'X' && i == 9 {
+				v = 10
+			} else if d < '0' || d > '9' {
+				return false
+			}
+			sum += (10 - i) * v
+		}
+		return sum%11 == 0
+
+	case 13:
+		for i, d := range digits {
+			if d < '0' || d > '9' {
+				return false
+			}
+			v := int(d - '0')
+			if i%2 == 1 {
+				v *= 3
+			}
+			sum += v
+		}
+		return sum%10 == 0
+
+	default:
+		return false
+	}
+}
+
+// parseISBN returns the ISBN in identifier (e.g. `urn:isbn:978-0-306-40615-7`), without separators, or an empty
+// string when it isn't a valid ISBN.
+func parseISBN(identifier string) string {
+	id := strings.ToUpper(strings.TrimSpace(identifier))
+	for _, prefix := range []string{"URN:ISBN:", "ISBN:", "ISBN"} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+
+	id = strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(id))
+	if !isbnChecksum(id) {
+		return ""
+	}
+
+	return id
+}
+
+// bookAuthors returns the distinct authors for keys, from the first key with any.
+func bookAuthors(metadata map[string]json.RawMessage, keys []string) []string {
+	for _, key := range keys {
+		var authors []string
+		seen := make(map[string]struct{})
+
+		for _, value := range metadataValues(metadata[key]) {
+			value = strings.TrimSpace(value)
+			if _, ok := seen[value]; ok || value == "" || len(authors) >= maxAuthors {
+				continue
+			}
+
+			seen[value] = struct{}{}
+			authors = append(authors, value)
+		}
+
+		if len(authors) > 0 {
+			return authors
+		}
+	}
+
+	return nil
+}
+
+// getEbook returns book metadata from the JSON document returned by the server, or nil when it is not an ebook.
+func getEbook(doc json.RawMessage) *ebook {
+	var d struct {
+		Metadata map[string]json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(doc, &d); err != nil || !isEbook(d.Metadata) {
+		return nil
+	}
+
+	b := &ebook{
+		BookAuthor:   bookAuthors(d.Metadata, bookAuthorKeys),
+		BookLanguage: strings.ToLower(firstValue(d.Metadata, bookLanguageKeys)),
+		BookSeries:   firstValue(d.Metadata, bookSeriesKeys),
+		BookTitle:    firstValue(d.Metadata, bookTitleKeys),
+		Publisher:    firstValue(d.Metadata, bookPublisherKeys),
+	}
+
+	if b.BookSeries != "" {
+		index, err := strconv.ParseFloat(firstValue(d.Metadata, bookSeriesIndexKeys), 64)
+		if err == nil && index > 0 && !math.IsInf(index, 0) {
+			b.BookSeriesIndex = index
+		}
+	}
+
+	// Books have several identifiers (e.g. UUID, ASIN); take the first ISBN.
+	for _, key := range bookIdentifierKeys {
+		for _, value := range metadataValues(d.Metadata[key]) {
+			if b.ISBN == "" {
+				b.ISBN = parseISBN(value)
+			}
+		}
+	}
+
+	return b
+}
+
+// setEbook merges book metadata into m, which has been decoded from JSON.
+func setEbook(b *ebook, m interface{}) error {
+	if b == nil {
+		return nil
+	}
+
+	return extractor.Merge(b, m)
+}
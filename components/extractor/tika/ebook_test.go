@@ -0,0 +1,93 @@
+package tika
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testEbook is the output of ipfs-tika for a sample EPUB from Calibre, with two authors in a series.
+const testEbook = `{
+	"metadata": {
+		"Content-Type": ["application/epub+zip"],
+		"dc:title": ["The Fellowship of the Ring"],
+		"dc:creator": ["J. R. R. Tolkien", "Christopher Tolkien", "J. R. R. Tolkien"],
+		"dc:publisher": ["George Allen & Unwin"],
+		"dc:language": ["en-GB"],
+		"dc:identifier": ["urn:uuid:1b7ac2a4-7d0b-4f8e-9a6e-3c1f2f1d5a10", "urn:isbn:978-0-306-40615-7"],
+		"calibre:series": ["The Lord of the Rings"],
+		"calibre:series_index": ["1.0"]
+	},
+	"content": "Prologue\n\nConcerning Hobbits"
+}`
+
+func TestGetEbook(t *testing.T) {
+	assert.Equal(t, &ebook{
+		BookAuthor:      []string{"J. R. R. Tolkien", "Christopher Tolkien"},
+		BookLanguage:    "en-gb",
+		BookSeries:      "The Lord of the Rings",
+		BookSeriesIndex: 1,
+		BookTitle:       "The Fellowship of the Ring",
+		ISBN:            "9780306406157",
+		Publisher:       "George Allen & Unwin",
+	}, getEbook(json.RawMessage(testEbook)))
+}
+
+func TestGetEbookMobi(t *testing.T) {
+	doc := json.RawMessage(`{
+		"metadata": {
+			"Content-Type": "application/x-mobipocket-ebook",
+			"dc:title": "A Book",
+			"dc:creator": "An Author",
+			"dc:identifier": "ASIN B000FC1PJI",
+			"calibre:series_index": "3"
+		}
+	}`)
+
+	assert.Equal(t, &ebook{
+		BookAuthor: []string{"An Author"},
+		BookTitle:  "A Book",
+	}, getEbook(doc))
+}
+
+func TestGetEbookNotEbook(t *testing.T) {
+	doc := json.RawMessage(`{
+		"metadata": {
+			"Content-Type": ["application/pdf"],
+			"dc:title": ["A paper"],
+			"dc:identifier": ["urn:isbn:978-0-306-40615-7"]
+		}
+	}`)
+
+	assert.Nil(t, getEbook(doc))
+}
+
+func TestParseISBN(t *testing.T) {
+	cases := map[string]string{
+		"urn:isbn:978-0-306-40615-7": "9780306406157",
+		"ISBN 0-306-40615-2":         "0306406152",
+		"isbn:080442957X":            "080442957X",
+		"9780306406157":              "9780306406157",
+		"978-0-306-40615-8":          "", // Invalid check digit.
+		"urn:uuid:1b7ac2a4":          "",
+		"":                           "",
+	}
+
+	for identifier, expected := range cases {
+		assert.Equal(t, expected, parseISBN(identifier), identifier)
+	}
+}
+
+func TestSetEbook(t *testing.T) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(testEbook), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, setEbook(getEbook(json.RawMessage(testEbook)), &doc))
+
+	assert.Equal(t, "The Fellowship of the Ring", doc["book_title"])
+	assert.Equal(t, "9780306406157", doc["isbn"])
+	assert.Contains(t, doc, "metadata")
+}
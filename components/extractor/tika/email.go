@@ -0,0 +1,140 @@
+package tika
+
+import (
+	"encoding/json"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+)
+
+// Bound on the attachment names indexed per email.
+const maxAttachments = 64
+
+// emailTypes are the MIME types detected by Tika for which email headers are promoted to typed fields.
+var emailTypes = map[string]bool{
+	"message/rfc822":             true, // .eml
+	"application/vnd.ms-outlook": true, // .msg
+}
+
+// Metadata keys for email headers, in order of preference, covering both Tika's RFC822 and Outlook parsers.
+var (
+	emailFromKeys    = []string{"Message-From", "dc:creator", "Author"}
+	emailToKeys      = []string{"Message-To"}
+	emailSubjectKeys = []string{"dc:subject", "subject", "dc:title", "title"}
+	emailDateKeys    = []string{"dcterms:created", "Creation-Date", "meta:creation-date"}
+
+	// Paths of embedded documents, i.e. attachments, e.g. /report.pdf.
+	attachmentKey = "X-TIKA:embedded_resource_path"
+)
+
+// Layouts of dates in Tika metadata, which are ISO 8601 with or without time zone.
+var emailDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05"}
+
+// email headers are merged into extracted documents.
+type email struct {
+	EmailAttachments []string `json:"email_attachments,omitempty"`
+	EmailDate        string   `json:"email_date,omitempty"`
+	EmailFrom        string   `json:"email_from,omitempty"`
+	EmailSubject     string   `json:"email_subject,omitempty"`
+	EmailTo          []string `json:"email_to,omitempty"`
+}
+
+// firstValue returns the first non-empty value for keys, in order, or an empty string.
+func firstValue(metadata map[string]json.RawMessage, keys []string) string {
+	for _, key := range keys {
+		for _, value := range metadataValues(metadata[key]) {
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		}
+	}
+
+	return ""
+}
+
+// emailDate returns value as an RFC 3339 date in UTC, or an empty string when it can't be parsed.
+func emailDate(value string) string {
+	for _, layout := range emailDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return ""
+}
+
+// attachmentNames returns the distinct names of attachments from their embedded resource paths.
+func attachmentNames(paths []string) []string {
+	var names []string
+	seen := make(map[string]struct{})
+
+	for _, p := range paths {
+		if len(names) >= maxAttachments {
+			break
+		}
+
+		name := path.Base(strings.TrimSpace(p))
+		if name == "." || name == "/" {
+			continue
+		}
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// isEmail returns true when the Content-Type detected by Tika is an email type.
+func isEmail(metadata map[string]json.RawMessage) bool {
+	for _, value := range metadataValues(metadata["Content-Type"]) {
+		if mediaType, _, err := mime.ParseMediaType(value); err == nil && emailTypes[mediaType] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getEmail returns the email headers from the JSON document returned by the server, or nil when it is not an email.
+func getEmail(doc json.RawMessage) *email {
+	var d struct {
+		Metadata map[string]json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(doc, &d); err != nil || !isEmail(d.Metadata) {
+		return nil
+	}
+
+	e := &email{
+		EmailAttachments: attachmentNames(metadataValues(d.Metadata[attachmentKey])),
+		EmailDate:        emailDate(firstValue(d.Metadata, emailDateKeys)),
+		EmailFrom:        firstValue(d.Metadata, emailFromKeys),
+		EmailSubject:     firstValue(d.Metadata, emailSubjectKeys),
+	}
+
+	for _, key := range emailToKeys {
+		for _, value := range metadataValues(d.Metadata[key]) {
+			if value = strings.TrimSpace(value); value != "" {
+				e.EmailTo = append(e.EmailTo, value)
+			}
+		}
+	}
+
+	return e
+}
+
+// setEmail merges email headers into m, which has been decoded from JSON.
+func setEmail(e *email, m interface{}) error {
+	if e == nil {
+		return nil
+	}
+
+	return extractor.Merge(e, m)
+}
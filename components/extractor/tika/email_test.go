@@ -0,0 +1,83 @@
+package tika
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testEmail is the output of ipfs-tika for a sample .eml with two attachments.
+const testEmail = `{
+	"metadata": {
+		"Content-Type": ["message/rfc822"],
+		"Message-From": ["Alice Example <alice@example.com>"],
+		"Message-To": ["Bob Example <bob@example.com>", "carol@example.com"],
+		"Message:Raw-Header:Subject": ["Quarterly report"],
+		"dc:creator": ["Alice Example <alice@example.com>"],
+		"dc:subject": ["Quarterly report"],
+		"dc:title": ["Quarterly report"],
+		"dcterms:created": ["2021-03-04T09:15:00Z"],
+		"X-TIKA:embedded_resource_path": ["/report.pdf", "/figures.xlsx", "/report.pdf"]
+	},
+	"content": "Hi Bob,\n\nPlease find the quarterly report attached.\n\nAlice"
+}`
+
+func TestGetEmail(t *testing.T) {
+	assert.Equal(t, &email{
+		EmailAttachments: []string{"report.pdf", "figures.xlsx"},
+		EmailDate:        "2021-03-04T09:15:00Z",
+		EmailFrom:        "Alice Example <alice@example.com>",
+		EmailSubject:     "Quarterly report",
+		EmailTo:          []string{"Bob Example <bob@example.com>", "carol@example.com"},
+	}, getEmail(json.RawMessage(testEmail)))
+}
+
+func TestGetEmailOutlook(t *testing.T) {
+	doc := json.RawMessage(`{
+		"metadata": {
+			"Content-Type": "application/vnd.ms-outlook",
+			"Author": "Alice Example",
+			"Message-To": "bob@example.com",
+			"subject": "Lunch",
+			"Creation-Date": "2021-03-04T10:15:00"
+		}
+	}`)
+
+	assert.Equal(t, &email{
+		EmailDate:    "2021-03-04T10:15:00Z",
+		EmailFrom:    "Alice Example",
+		EmailSubject: "Lunch",
+		EmailTo:      []string{"bob@example.com"},
+	}, getEmail(doc))
+}
+
+func TestGetEmailNotEmail(t *testing.T) {
+	doc := json.RawMessage(`{
+		"metadata": {
+			"Content-Type": ["text/html; charset=UTF-8"],
+			"dc:title": ["Not an email"],
+			"Message-From": ["alice@example.com"]
+		}
+	}`)
+
+	assert.Nil(t, getEmail(doc))
+	assert.Nil(t, getEmail(json.RawMessage(`{"metadata": null}`)))
+}
+
+func TestEmailDate(t *testing.T) {
+	assert.Equal(t, "2021-03-04T08:15:00Z", emailDate("2021-03-04T09:15:00+01:00"))
+	assert.Equal(t, "", emailDate("Thu, 4 Mar 2021 09:15:00 +0100"))
+	assert.Equal(t, "", emailDate(""))
+}
+
+func TestAttachmentNamesBounds(t *testing.T) {
+	paths := make([]string, 0, maxAttachments+1)
+	for i := 0; i <= maxAttachments; i++ {
+		paths = append(paths, fmt.Sprintf("/attachment%d.txt", i))
+	}
+
+	assert.Len(t, attachmentNames(paths), maxAttachments)
+	assert.Empty(t, attachmentNames([]string{"", "/"}))
+}
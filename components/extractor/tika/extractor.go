@@ -3,54 +3,363 @@ package tika
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
+	"os"
+	"time"
 
+	"go.opentelemetry.io/otel/api/metric"
 	"go.opentelemetry.io/otel/api/trace"
 	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/sync/semaphore"
 
 	"github.com/ipfs-search/ipfs-search/components/extractor"
 	"github.com/ipfs-search/ipfs-search/components/protocol"
 
 	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
 	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils"
 )
 
+var logger = logging.New("extractor")
+
+// errTimeout signifies that a request to ipfs-tika timed out.
+var errTimeout = fmt.Errorf("%w: timeout", extractor.ErrRequest)
+
+// gatewaySource is merged into documents extracted through the fallback gateway.
+var gatewaySource = json.RawMessage(`{"extracted_via":"gateway"}`)
+
+// extractionStats are merged into extracted documents, for debugging extraction performance.
+type extractionStats struct {
+	ExtractionMs int64  `json:"extraction_ms"`
+	TikaVersion  string `json:"tika_version,omitempty"`
+}
+
+// tikaVersion returns the identity of the ipfs-tika server from the response headers, if any.
+func tikaVersion(resp *http.Response) string {
+	if v := resp.Header.Get("X-Tika-Version"); v != "" {
+		return v
+	}
+
+	return resp.Header.Get("Server")
+}
+
+// setStats merges extraction stats into m, which has been decoded from JSON.
+func setStats(resp *http.Response, duration time.Duration, m interface{}) error {
+	return extractor.Merge(extractionStats{
+		ExtractionMs: duration.Milliseconds(),
+		TikaVersion:  tikaVersion(resp),
+	}, m)
+}
+
 // Extractor extracts metadata using the ipfs-tika server.
 type Extractor struct {
 	config   *Config
 	client   *http.Client
 	protocol protocol.Protocol
 
+	fallbackLimiter *utils.RateLimiter
+	tooSmall        metric.Int64Counter
+
+	concurrency *semaphore.Weighted // Bounds concurrent requests to the server; nil when unlimited.
+	inflight    metric.Int64UpDownCounter
+	waiting     metric.Int64UpDownCounter
+
 	*instr.Instrumentation
 }
 
-func (e *Extractor) get(ctx context.Context, url string) (resp *http.Response, err error) {
+// newRequest returns a request for url with custom headers, expanding environment variables in their values and
+// reading header files.
+func (e *Extractor) newRequest(ctx context.Context, url string) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		// Errors here are programming errors.
 		panic(fmt.Sprintf("creating request: %s", err))
 	}
 
+	for name, value := range e.config.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+
+	// Read for every request, picking up rotated credentials.
+	for name, file := range e.config.HeaderFiles {
+		value, err := utils.ReadSecretFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set(name, value)
+	}
+
+	// Set after custom headers, which should not override it.
+	req.Header.Set("Accept", e.config.AcceptType)
+
+	return req, nil
+}
+
+func (e *Extractor) get(ctx context.Context, url string) (resp *http.Response, err error) {
+	req, err := e.newRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
 	return e.client.Do(req)
 }
 
-func (e *Extractor) getExtractURL(r *t.AnnotatedResource) string {
-	gwURL := e.protocol.GatewayURL(r)
+// decode decodes the response body into m, according to the requested representation, returning non-fatal
+// extraction warnings.
+func (e *Extractor) decode(resp *http.Response, m interface{}) ([]string, error) {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != e.config.AcceptType {
+		return nil, fmt.Errorf("%w: unexpected Content-Type '%s'", extractor.ErrUnexpectedResponse, resp.Header.Get("Content-Type"))
+	}
+
+	body := &limitedReader{resp.Body, int64(e.config.MaxResponseSize)}
+
+	if mediaType != "text/plain" {
+		// The decoder buffers the full document regardless, so keeping it for reading warnings is cheap.
+		var doc json.RawMessage
+		if err := json.NewDecoder(body).Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(doc, m); err != nil {
+			return nil, err
+		}
+
+		if err := stripMetadata(doc, e.config.DeniedMetadata, m); err != nil {
+			return nil, err
+		}
+
+		if err := setEmail(getEmail(doc), m); err != nil {
+			return nil, err
+		}
+
+		if err := setEbook(getEbook(doc), m); err != nil {
+			return nil, err
+		}
+
+		if err := setSpreadsheet(getSpreadsheet(doc), m); err != nil {
+			return nil, err
+		}
+
+		return getWarnings(resp.Header, doc), nil
+	}
+
+	// Plain text only contains the content; wrap it in JSON to decode into m.
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	text, charset, err := toUTF8(content, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", extractor.ErrUnexpectedResponse, err)
+	}
+
+	doc, err := json.Marshal(struct {
+		Content string `json:"content"`
+		Charset string `json:"charset"`
+	}{text, charset})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(doc, m); err != nil {
+		return nil, err
+	}
+
+	return getWarnings(resp.Header, nil), nil
+}
+
+func (e *Extractor) getExtractURL(gwURL string) string {
 	return fmt.Sprintf("%s/extract?url=%s", e.config.TikaExtractorURL, url.QueryEscape(gwURL))
 }
 
+// getFallbackURL returns gwURL with scheme and host replaced by those of the fallback gateway.
+func (e *Extractor) getFallbackURL(gwURL string) (string, error) {
+	fallback, err := url.Parse(e.config.FallbackGatewayURL)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(gwURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = fallback.Scheme
+	u.Host = fallback.Host
+	u.User = fallback.User
+
+	return u.String(), nil
+}
+
+// acquire waits for a concurrency slot for a request to the server, returning a function releasing it, or the
+// context's error when it is closed first.
+func (e *Extractor) acquire(ctx context.Context) (func(), error) {
+	if e.concurrency != nil {
+		e.waiting.Add(ctx, 1)
+		err := e.concurrency.Acquire(ctx, 1)
+		e.waiting.Add(ctx, -1)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.inflight.Add(ctx, 1)
+
+	return func() {
+		if e.concurrency != nil {
+			e.concurrency.Release(1)
+		}
+
+		e.inflight.Add(ctx, -1)
+	}, nil
+}
+
+// extract requests metadata for gwURL within timeout, decoding it into m.
+func (e *Extractor) extract(ctx context.Context, gwURL string, timeout time.Duration, m interface{}) error {
+	// Waiting for a slot doesn't count towards the timeout.
+	release, err := e.acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: waiting for concurrency slot: %v", extractor.ErrRequest, err)
+	}
+	defer release()
+
+	// Timeout if extraction hasn't fully completed within this time.
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	resp, err := e.get(ctx, e.getExtractURL(gwURL))
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %v", errTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", extractor.ErrRequest, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		// Tika failed to parse the content, which will fail again.
+		return fmt.Errorf("%w: status %s", extractor.ErrUnextractable, resp.Status)
+	default:
+		return fmt.Errorf("%w: unexpected status %s", extractor.ErrUnexpectedResponse, resp.Status)
+	}
+
+	warnings, err := e.decode(resp, m)
+	if err != nil {
+		if errors.Is(err, extractor.ErrUnexpectedResponse) {
+			return err
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %v", errTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", extractor.ErrUnexpectedResponse, err)
+	}
+
+	// Includes reading the response, as ipfs-tika streams it while extracting.
+	if err := setStats(resp, time.Since(start), m); err != nil {
+		return err
+	}
+
+	// Warnings don't fail extraction; whatever content was obtained is indexed.
+	if len(warnings) > 0 {
+		logger.Debugf("Extraction warnings for %s: %v", gwURL, warnings)
+		trace.SpanFromContext(ctx).AddEvent(ctx, "extraction-warnings")
+	}
+
+	return setWarnings(warnings, m)
+}
+
+// extractFallback attempts extraction through the fallback gateway, marking the result as gateway-sourced.
+// With FallbackValidators, extraction is skipped when m has been indexed with validators the gateway reports
+// unchanged; callers re-extracting an indexed document pass it as m.
+func (e *Extractor) extractFallback(ctx context.Context, gwURL string, m interface{}) error {
+	ctx, span := e.Tracer.Start(ctx, "extractor.tika.extractFallback")
+	defer span.End()
+
+	fallbackURL, err := e.getFallbackURL(gwURL)
+	if err != nil {
+		err := fmt.Errorf("%w: invalid fallback gateway: %v", extractor.ErrRequest, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	var v *validators
+	if e.config.FallbackValidators {
+		// Requested before extracting, so that changes during extraction are detected later.
+		var notModified bool
+		v, notModified, err = e.headValidators(ctx, fallbackURL, previousValidators(m))
+		if err != nil {
+			// Content is extracted regardless; missing validators only leave changes undetectable.
+			logger.Debugf("Error getting validators for %s: %v", fallbackURL, err)
+			span.RecordError(ctx, err)
+		}
+
+		if notModified {
+			logger.Debugf("Not re-extracting unmodified %s", fallbackURL)
+			span.AddEvent(ctx, "not-modified")
+			return nil
+		}
+	}
+
+	if err := e.fallbackLimiter.Wait(ctx); err != nil {
+		err := fmt.Errorf("%w: %v", extractor.ErrRequest, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	if err := e.extract(ctx, fallbackURL, e.config.FallbackTimeout, m); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	if err := extractor.Merge(gatewaySource, m); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	if err := setValidators(v, m); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return nil
+}
+
+// shouldFallback returns true when extraction through the local gateway timed out while the parent context is still
+// valid and a fallback gateway has been configured.
+func (e *Extractor) shouldFallback(ctx context.Context, err error) bool {
+	if e.config.FallbackGatewayURL == "" || ctx.Err() != nil {
+		return false
+	}
+
+	return errors.Is(err, errTimeout)
+}
+
 // Extract metadata from a (potentially) referenced resource, updating
 // Metadata or returning an error.
 func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
 	ctx, span := e.Tracer.Start(ctx, "extractor.tika.Extract")
 	defer span.End()
 
-	// Timeout if extraction hasn't fully completed within this time.
-	ctx, cancel := context.WithTimeout(ctx, e.config.RequestTimeout)
-	defer cancel()
+	if r.Size < uint64(e.config.MinFileSize) {
+		// Index tiny files without metadata rather than bothering the server.
+		e.tooSmall.Add(ctx, 1)
+		span.AddEvent(ctx, "file-too-small")
+		return nil
+	}
 
 	if r.Size > uint64(e.config.MaxFileSize) {
 		err := fmt.Errorf("%w: %d", extractor.ErrFileTooLarge, r.Size)
@@ -62,38 +371,51 @@ func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m inter
 		return err
 	}
 
-	resp, err := e.get(ctx, e.getExtractURL(r))
-	if err != nil {
-		err := fmt.Errorf("%w: %v", extractor.ErrRequest, err)
-		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return err
-	}
-	defer resp.Body.Close()
+	gwURL := e.protocol.GatewayURL(r)
 
-	if resp.StatusCode != 200 {
-		err := fmt.Errorf("%w: unexpected status %s", extractor.ErrUnexpectedResponse, resp.Status)
-		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return err
+	err := e.extract(ctx, gwURL, e.config.timeoutFor(extractor.MimeType(r)), m)
+	if err != nil && e.shouldFallback(ctx, err) {
+		logger.Infof("Extraction timed out for '%v', falling back to gateway", r)
+		err = e.extractFallback(ctx, gwURL, m)
 	}
 
-	// Parse resulting JSON
-	if err := json.NewDecoder(resp.Body).Decode(m); err != nil {
-		err := fmt.Errorf("%w: %v", extractor.ErrUnexpectedResponse, err)
+	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 		return err
 	}
 
-	log.Printf("Got metadata metadata for '%v'", r)
+	logger.Debugf("Got metadata metadata for '%v'", r)
 
 	return nil
 }
 
 // New returns a new Tika extractor.
 func New(config *Config, client *http.Client, protocol protocol.Protocol, instr *instr.Instrumentation) extractor.Extractor {
+	var concurrency *semaphore.Weighted
+	if config.MaxConcurrency > 0 {
+		concurrency = semaphore.NewWeighted(int64(config.MaxConcurrency))
+	}
+
+	meter := metric.Must(instr.Meter)
+
 	return &Extractor{
 		config,
 		client,
 		protocol,
+		utils.NewRateLimiter(config.FallbackRateLimit, 1),
+		meter.NewInt64Counter(
+			"ipfs_search.extractor.tika.skipped_too_small",
+			metric.WithDescription("Files skipped for being smaller than the minimum file size."),
+		),
+		concurrency,
+		meter.NewInt64UpDownCounter(
+			"ipfs_search.extractor.tika.inflight",
+			metric.WithDescription("Requests being processed by the server."),
+		),
+		meter.NewInt64UpDownCounter(
+			"ipfs_search.extractor.tika.waiting",
+			metric.WithDescription("Requests waiting for a concurrency slot."),
+		),
 		instr,
 	}
 }
@@ -2,10 +2,15 @@ package tika
 
 import (
     "context"
+    "errors"
     "fmt"
+    "io/ioutil"
     "net/http"
     "net/url"
+    "os"
+    "strings"
     "testing"
+    "time"
 
     "github.com/dankinder/httpmock"
     "github.com/stretchr/testify/mock"
@@ -102,7 +107,8 @@ func (s TikaTestSuite) TestExtract() {
     s.mockAPIHandler.
         On("Handle", "GET", extractorURL, mock.Anything).
         Return(httpmock.Response{
-            Body: testJSON,
+            Header: s.responseHeader,
+            Body:   testJSON,
         }).
         Once()
 
@@ -124,6 +130,209 @@ func (s TikaTestSuite) TestExtract() {
     s.Contains(f.URLs, "https://proto.school/#/tutorials?course=filecoin")
 }
 
+func (s TikaTestSuite) TestExtractWarnings() {
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := "/extract?url=http%3A%2F%2Flocalhost%3A8080%2Fipfs%2F" + testCID
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: http.Header{
+                "Content-Type":   []string{"application/json"},
+                "X-Tika-Warning": []string{"encrypted, partial text"},
+            },
+            Body: []byte(`{"content": "partial", "metadata": {"X-TIKA:EXCEPTION:write_limit_reached": ["true"]}}`),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    // Content is indexed nonetheless.
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("partial", f.Content)
+    s.Equal([]string{"encrypted, partial text", writeLimitWarning}, f.ExtractionWarnings)
+}
+
+func (s TikaTestSuite) TestExtractEmail() {
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+        Reference: t.Reference{
+            Name: "report.eml",
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := "/extract?url=http%3A%2F%2Flocalhost%3A8080%2Fipfs%2F" + testCID
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+            Body:   []byte(testEmail),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("Alice Example <alice@example.com>", f.EmailFrom)
+    s.Equal([]string{"Bob Example <bob@example.com>", "carol@example.com"}, f.EmailTo)
+    s.Equal("Quarterly report", f.EmailSubject)
+    s.Equal("2021-03-04T09:15:00Z", f.EmailDate)
+    s.Equal([]string{"report.pdf", "figures.xlsx"}, f.EmailAttachments)
+    s.Contains(f.Content, "Please find the quarterly report attached.")
+}
+
+func (s TikaTestSuite) TestExtractSpreadsheet() {
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+        Reference: t.Reference{
+            Name: "report.xlsx",
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := "/extract?url=http%3A%2F%2Flocalhost%3A8080%2Fipfs%2F" + testCID
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+            Body:   []byte(testSpreadsheet),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal([]string{"Revenue", "Costs per region"}, f.SheetNames)
+    s.Equal(uint64(11), f.CellCount)
+    s.Contains(f.Content, "Costs per region")
+}
+
+func (s TikaTestSuite) TestExtractEbook() {
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+        Reference: t.Reference{
+            Name: "fellowship.epub",
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := "/extract?url=http%3A%2F%2Flocalhost%3A8080%2Fipfs%2F" + testCID
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+            Body:   []byte(testEbook),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("The Fellowship of the Ring", f.BookTitle)
+    s.Equal([]string{"J. R. R. Tolkien", "Christopher Tolkien"}, f.BookAuthor)
+    s.Equal("9780306406157", f.ISBN)
+    s.Equal("George Allen & Unwin", f.Publisher)
+    s.Equal("en-gb", f.BookLanguage)
+    s.Equal("The Lord of the Rings", f.BookSeries)
+    s.Equal(1.0, f.BookSeriesIndex)
+}
+
+func (s TikaTestSuite) TestExtractStats() {
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := "/extract?url=http%3A%2F%2Flocalhost%3A8080%2Fipfs%2F" + testCID
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        After(20 * time.Millisecond).
+        Return(httpmock.Response{
+            Header: http.Header{
+                "Content-Type":   []string{"application/json"},
+                "X-Tika-Version": []string{"1.24"},
+            },
+            Body: []byte(`{"content": "content"}`),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("content", f.Content)
+    s.Equal("1.24", f.TikaVersion)
+    s.GreaterOrEqual(f.ExtractionMs, int64(20))
+}
+
 func (s TikaTestSuite) TestExtractMaxFileSize() {
     s.cfg.MaxFileSize = 100
     s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
@@ -145,6 +354,28 @@ func (s TikaTestSuite) TestExtractMaxFileSize() {
     s.mockAPIHandler.AssertExpectations(s.T())
 }
 
+func (s TikaTestSuite) TestExtractMinFileSize() {
+    s.cfg.MinFileSize = 100
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+        Stat: t.Stat{
+            Size: uint64(s.cfg.MinFileSize - 1),
+        },
+    }
+
+    f := &indexTypes.File{}
+    err := s.e.Extract(s.ctx, r, &f)
+
+    s.NoError(err)
+    s.protocol.AssertNotCalled(s.T(), "GatewayURL", mock.Anything)
+    s.mockAPIHandler.AssertNotCalled(s.T(), "Handle", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func (s TikaTestSuite) TestExtractUpstreamError() {
     r := &t.AnnotatedResource{
         Resource: &t.Resource{
@@ -201,7 +432,8 @@ func (s TikaTestSuite) TestURLEscape() {
     s.mockAPIHandler.
         On("Handle", "GET", extractorURL, mock.Anything).
         Return(httpmock.Response{
-            Body: []byte("{}"),
+            Header: s.responseHeader,
+            Body:   []byte("{}"),
         }).
         Once()
 
@@ -246,6 +478,40 @@ func (s TikaTestSuite) TestTika500() {
     s.mockAPIHandler.AssertExpectations(s.T())
 }
 
+func (s TikaTestSuite) TestTika422() {
+    // 422 means Tika could not parse the content, which is not worth retrying
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/%s" + testCID
+    extractorURL := fmt.Sprintf("/extract?url=%s", url.QueryEscape(gwURL))
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Status: 422,
+            Body:   []byte("{}"),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, &f)
+
+    s.True(errors.Is(err, extractor.ErrUnextractable))
+    s.True(errors.Is(err, extractor.ErrUnexpectedResponse))
+    s.mockAPIHandler.AssertExpectations(s.T())
+}
+
 func (s TikaTestSuite) TestExtractInvalidJSON() {
     testJSON := []byte(`invalid JSON`)
 
@@ -270,7 +536,8 @@ func (s TikaTestSuite) TestExtractInvalidJSON() {
     s.mockAPIHandler.
         On("Handle", "GET", extractorURL, mock.Anything).
         Return(httpmock.Response{
-            Body: testJSON,
+            Header: s.responseHeader,
+            Body:   testJSON,
         }).
         Once()
 
@@ -286,6 +553,406 @@ func (s TikaTestSuite) TestExtractInvalidJSON() {
     s.mockAPIHandler.AssertExpectations(s.T())
 }
 
+func (s TikaTestSuite) TestExtractFallback() {
+    s.cfg.RequestTimeout = 50 * time.Millisecond
+    s.cfg.FallbackGatewayURL = "https://ipfs.io"
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := "/extract?url=http%3A%2F%2Flocalhost%3A8080%2Fipfs%2F" + testCID
+    fallbackURL := "/extract?url=https%3A%2F%2Fipfs.io%2Fipfs%2F" + testCID
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        After(100 * time.Millisecond).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+        }).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", fallbackURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+            Body:   []byte(`{"content": "gateway content"}`),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("gateway content", f.Content)
+    s.Equal("gateway", f.ExtractedVia)
+}
+
+func (s TikaTestSuite) TestExtractFallbackValidators() {
+    s.cfg.RequestTimeout = 50 * time.Millisecond
+    s.cfg.FallbackGatewayURL = s.mockAPIServer.URL()
+    s.cfg.FallbackValidators = true
+    s.cfg.FallbackRateLimit = 0
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := "/extract?url=" + url.QueryEscape(gwURL)
+    fallbackURL := "/extract?url=" + url.QueryEscape(s.mockAPIServer.URL()+"/ipfs/"+testCID)
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        After(100 * time.Millisecond).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+        }).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", fallbackURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+            Body:   []byte(`{"content": "gateway content"}`),
+        }).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "HEAD", "/ipfs/"+testCID, mock.Anything).
+        Return(httpmock.Response{
+            Header: http.Header{
+                "Etag":          []string{`"` + testCID + `"`},
+                "Last-Modified": []string{"Wed, 21 Oct 2015 07:28:00 GMT"},
+            },
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("gateway", f.ExtractedVia)
+    s.Equal(`"`+testCID+`"`, f.GatewayETag)
+    s.Equal(time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC), *f.GatewayLastModified)
+}
+
+func (s TikaTestSuite) TestExtractFallbackNotModified() {
+    s.cfg.RequestTimeout = 50 * time.Millisecond
+    s.cfg.FallbackGatewayURL = s.mockAPIServer.URL()
+    s.cfg.FallbackValidators = true
+    s.cfg.FallbackRateLimit = 0
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := "/extract?url=" + url.QueryEscape(gwURL)
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        After(100 * time.Millisecond).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+        }).
+        Once()
+
+    // No extraction through the fallback gateway.
+    s.mockAPIHandler.
+        On("Handle", "HEAD", "/ipfs/"+testCID, mock.Anything).
+        Return(httpmock.Response{
+            Status: http.StatusNotModified,
+        }).
+        Once()
+
+    // Document indexed before, with validators.
+    f := &indexTypes.File{
+        Content:     "indexed content",
+        GatewayETag: `"` + testCID + `"`,
+    }
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("indexed content", f.Content)
+    s.Equal(`"`+testCID+`"`, f.GatewayETag)
+}
+
+func (s TikaTestSuite) TestExtractUnexpectedContentType() {
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := fmt.Sprintf("/extract?url=%s", url.QueryEscape(gwURL))
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: http.Header{
+                "Content-Type": []string{"text/html"},
+            },
+            Body: []byte("<html></html>"),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.True(errors.Is(err, extractor.ErrUnexpectedResponse))
+    s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s TikaTestSuite) TestExtractPlainText() {
+    s.cfg.AcceptType = "text/plain"
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := fmt.Sprintf("/extract?url=%s", url.QueryEscape(gwURL))
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: http.Header{
+                "Content-Type": []string{"text/plain; charset=UTF-8"},
+            },
+            Body: []byte("Just the content."),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("Just the content.", f.Content)
+    s.Equal("utf-8", f.Charset)
+}
+
+func (s TikaTestSuite) TestExtractPlainTextLatin1() {
+    s.cfg.AcceptType = "text/plain"
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := fmt.Sprintf("/extract?url=%s", url.QueryEscape(gwURL))
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: http.Header{
+                "Content-Type": []string{"text/plain; charset=ISO-8859-1"},
+            },
+            Body: []byte("Caf\xe9 cr\xe8me."),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.NoError(err)
+    s.mockAPIHandler.AssertExpectations(s.T())
+
+    s.Equal("Café crème.", f.Content)
+    s.Equal("windows-1252", f.Charset)
+}
+
+func (s TikaTestSuite) TestExtractResponseTooLarge() {
+    s.cfg.MaxResponseSize = 1024
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+    }
+
+    gwURL := "http://localhost:8080/ipfs/" + testCID
+    extractorURL := fmt.Sprintf("/extract?url=%s", url.QueryEscape(gwURL))
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return(gwURL).
+        Once()
+
+    s.mockAPIHandler.
+        On("Handle", "GET", extractorURL, mock.Anything).
+        Return(httpmock.Response{
+            Header: s.responseHeader,
+            Body:   []byte(`{"content": "` + strings.Repeat("a", 2048) + `"}`),
+        }).
+        Once()
+
+    f := &indexTypes.File{}
+
+    err := s.e.Extract(s.ctx, r, f)
+
+    s.True(errors.Is(err, extractor.ErrResponseTooLarge))
+    s.Empty(f.Content)
+    s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s TikaTestSuite) TestRequestHeaders() {
+    os.Setenv("TIKA_TEST_API_KEY", "secret")
+    defer os.Unsetenv("TIKA_TEST_API_KEY")
+
+    s.cfg.Headers = map[string]string{
+        "X-Api-Key":   "${TIKA_TEST_API_KEY}",
+        "X-Tenant-Id": "tenant",
+    }
+
+    req, err := s.e.(*Extractor).newRequest(s.ctx, "http://localhost/extract")
+    s.Require().NoError(err)
+
+    s.Equal("secret", req.Header.Get("X-Api-Key"))
+    s.Equal("tenant", req.Header.Get("X-Tenant-Id"))
+    s.Equal(s.cfg.AcceptType, req.Header.Get("Accept"))
+}
+
+func (s TikaTestSuite) TestRequestHeaderFiles() {
+    f, err := ioutil.TempFile("", "tika-api-key")
+    s.Require().NoError(err)
+    defer os.Remove(f.Name())
+
+    _, err = f.WriteString("secret\n")
+    s.Require().NoError(err)
+    s.Require().NoError(f.Close())
+
+    s.cfg.HeaderFiles = map[string]string{
+        "X-Api-Key": f.Name(),
+    }
+
+    req, err := s.e.(*Extractor).newRequest(s.ctx, "http://localhost/extract")
+    s.Require().NoError(err)
+    s.Equal("secret", req.Header.Get("X-Api-Key"))
+
+    // Rotated credentials are picked up.
+    s.Require().NoError(ioutil.WriteFile(f.Name(), []byte("rotated"), 0600))
+
+    req, err = s.e.(*Extractor).newRequest(s.ctx, "http://localhost/extract")
+    s.Require().NoError(err)
+    s.Equal("rotated", req.Header.Get("X-Api-Key"))
+
+    // Unreadable files fail the request.
+    s.cfg.HeaderFiles["X-Api-Key"] = f.Name() + ".missing"
+
+    _, err = s.e.(*Extractor).newRequest(s.ctx, "http://localhost/extract")
+    s.Error(err)
+}
+
+func (s TikaTestSuite) TestExtractMaxConcurrency() {
+    s.cfg.MaxConcurrency = 1
+    s.e = New(s.cfg, http.DefaultClient, s.protocol, instr.New())
+
+    r := &t.AnnotatedResource{
+        Resource: &t.Resource{
+            Protocol: t.IPFSProtocol,
+            ID:       testCID,
+        },
+        Stat: t.Stat{
+            Size: 400,
+        },
+    }
+
+    s.protocol.
+        On("GatewayURL", r).
+        Return("http://localhost:8080/ipfs/" + testCID)
+
+    // Occupy the only slot.
+    release, err := s.e.(*Extractor).acquire(s.ctx)
+    s.Require().NoError(err)
+
+    ctx, cancel := context.WithTimeout(s.ctx, 50*time.Millisecond)
+    defer cancel()
+
+    f := &indexTypes.File{}
+    err = s.e.Extract(ctx, r, f)
+
+    // Request waited for a slot until the context expired, without reaching the server.
+    s.True(errors.Is(err, extractor.ErrRequest))
+    s.mockAPIHandler.AssertNotCalled(s.T(), "Handle", mock.Anything, mock.Anything, mock.Anything)
+
+    // Slot becomes available after release.
+    release()
+
+    release, err = s.e.(*Extractor).acquire(s.ctx)
+    s.NoError(err)
+    release()
+}
+
 func TestTikaTestSuite(t *testing.T) {
     suite.Run(t, new(TikaTestSuite))
 }
@@ -0,0 +1,34 @@
+package tika
+
+import (
+	"io"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+)
+
+// limitedReader reads from r, returning extractor.ErrResponseTooLarge when more than n bytes are read.
+type limitedReader struct {
+	r io.Reader
+	n int64 // Remaining bytes
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, extractor.ErrResponseTooLarge
+	}
+
+	// Read at most a single byte over the limit, to detect it being exceeded.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	if l.n < 0 {
+		// Don't return the byte over the limit.
+		return n - 1, extractor.ErrResponseTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,169 @@
+package tika
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+)
+
+// Bound on the sheet names indexed per spreadsheet.
+const maxSheets = 256
+
+// spreadsheetTypes are the MIME types detected by Tika for which sheet names and cell counts are indexed; true for
+// formats with named sheets, false for single-sheet formats.
+var spreadsheetTypes = map[string]bool{
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": true, // .xlsx
+	"application/vnd.ms-excel.sheet.macroenabled.12":                    true, // .xlsm
+	"application/vnd.ms-excel":                                          true, // .xls
+	"application/vnd.oasis.opendocument.spreadsheet":                    true, // .ods
+
+	"text/csv":                  false, // .csv
+	"text/tab-separated-values": false, // .tsv
+}
+
+// csvDelimiters map the delimiters detected by Tika's CSV parser (`csv:delimiter`) to runes.
+var csvDelimiters = map[string]rune{
+	"comma":     ',',
+	"tab":       '\t',
+	"semicolon": ';',
+	"pipe":      '|',
+}
+
+// spreadsheet structure is merged into extracted documents.
+type spreadsheet struct {
+	SheetNames []string `json:"sheet_names,omitempty"`
+	CellCount  uint64   `json:"cell_count,omitempty"`
+}
+
+// spreadsheetType returns the spreadsheet MIME type detected by Tika and whether it was found.
+func spreadsheetType(metadata map[string]json.RawMessage) (string, bool) {
+	for _, value := range metadataValues(metadata["Content-Type"]) {
+		if mediaType, _, err := mime.ParseMediaType(value); err == nil {
+			if _, ok := spreadsheetTypes[mediaType]; ok {
+				return mediaType, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// countCells returns the number of non-empty cells in the delimited text of a CSV file.
+func countCells(content string, delimiter rune) uint64 {
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var cells uint64
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return cells
+		}
+		if err != nil {
+			// Approximate; count what could be parsed.
+			return cells
+		}
+
+		for _, field := range record {
+			if strings.TrimSpace(field) != "" {
+				cells++
+			}
+		}
+	}
+}
+
+// parseSheets returns the sheet names and the number of non-empty cells from the text Tika renders spreadsheets as:
+// every row is a line with each cell preceded by a tab, and with named sheets, each sheet starts with its name on a
+// line of its own, after a blank line. Returns false when the content has no rows.
+func parseSheets(content string, named bool) ([]string, uint64, bool) {
+	var (
+		names    []string
+		cells    uint64
+		rows     bool
+		boundary = true // At the start of the content or after a blank line.
+	)
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if strings.HasPrefix(line, "\t") {
+			rows = true
+			boundary = false
+
+			for _, cell := range strings.Split(line[1:], "\t") {
+				if strings.TrimSpace(cell) != "" {
+					cells++
+				}
+			}
+
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			boundary = true
+			continue
+		}
+
+		if named && boundary && len(names) < maxSheets {
+			names = append(names, line)
+		}
+
+		boundary = false
+	}
+
+	return names, cells, rows
+}
+
+// getSpreadsheet returns sheet names and cell count from the JSON document returned by the server, or nil when it is
+// not a spreadsheet. Counts are approximate, as content may have been truncated.
+func getSpreadsheet(doc json.RawMessage) *spreadsheet {
+	var d struct {
+		Metadata map[string]json.RawMessage `json:"metadata"`
+		Content  string                     `json:"content"`
+	}
+	if err := json.Unmarshal(doc, &d); err != nil {
+		return nil
+	}
+
+	mediaType, ok := spreadsheetType(d.Metadata)
+	if !ok {
+		return nil
+	}
+
+	named := spreadsheetTypes[mediaType]
+	names, cells, rows := parseSheets(d.Content, named)
+
+	if !rows && !named {
+		// CSV rendered as delimited text rather than as a table.
+		delimiter, ok := csvDelimiters[firstValue(d.Metadata, []string{"csv:delimiter"})]
+		if !ok {
+			delimiter = ','
+			if mediaType == "text/tab-separated-values" {
+				delimiter = '\t'
+			}
+		}
+
+		cells = countCells(d.Content, delimiter)
+	}
+
+	return &spreadsheet{
+		SheetNames: names,
+		CellCount:  cells,
+	}
+}
+
+// setSpreadsheet merges spreadsheet structure into m, which has been decoded from JSON.
+func setSpreadsheet(s *spreadsheet, m interface{}) error {
+	if s == nil {
+		return nil
+	}
+
+	return extractor.Merge(s, m)
+}
@@ -0,0 +1,78 @@
+package tika
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSpreadsheet is the output of ipfs-tika for a sample .xlsx with two sheets; Tika renders every sheet as its name
+// followed by its rows, with cells preceded by tabs.
+const testSpreadsheet = `{
+	"metadata": {
+		"Content-Type": ["application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"],
+		"dc:creator": ["Alice Example"]
+	},
+	"content": "Revenue\n\tQuarter\tAmount\n\tQ1\t1200\n\tQ2\t\n\nCosts per region\n\tRegion\tAmount\tNotes\n\tEU\t300\tEstimate\n\n"
+}`
+
+func TestGetSpreadsheet(t *testing.T) {
+	assert.Equal(t, &spreadsheet{
+		SheetNames: []string{"Revenue", "Costs per region"},
+		CellCount:  11,
+	}, getSpreadsheet(json.RawMessage(testSpreadsheet)))
+}
+
+func TestGetSpreadsheetCSVTable(t *testing.T) {
+	doc := json.RawMessage(`{
+		"metadata": {
+			"Content-Type": ["text/csv; charset=ISO-8859-1; delimiter=comma"],
+			"csv:delimiter": ["comma"]
+		},
+		"content": "\n\tname\tsize\n\treadme.md\t1024\n"
+	}`)
+
+	// A single, unnamed sheet.
+	assert.Equal(t, &spreadsheet{CellCount: 4}, getSpreadsheet(doc))
+}
+
+func TestGetSpreadsheetCSVText(t *testing.T) {
+	doc := json.RawMessage(`{
+		"metadata": {
+			"Content-Type": "text/csv; charset=UTF-8",
+			"csv:delimiter": "semicolon"
+		},
+		"content": "name;size\n\"read;me.md\";1024\nempty;\n"
+	}`)
+
+	assert.Equal(t, &spreadsheet{CellCount: 5}, getSpreadsheet(doc))
+}
+
+func TestGetSpreadsheetNotSpreadsheet(t *testing.T) {
+	doc := json.RawMessage(`{
+		"metadata": {
+			"Content-Type": ["text/plain; charset=UTF-8"]
+		},
+		"content": "Sheet\n\ta\tb\n"
+	}`)
+
+	assert.Nil(t, getSpreadsheet(doc))
+	assert.Nil(t, getSpreadsheet(json.RawMessage(`{"metadata": null}`)))
+}
+
+func TestParseSheetsBounds(t *testing.T) {
+	content := ""
+	for i := 0; i <= maxSheets; i++ {
+		content += "Sheet\n\tcell\n\n"
+	}
+
+	names, cells, rows := parseSheets(content, true)
+
+	assert.Len(t, names, maxSheets)
+	assert.Equal(t, uint64(maxSheets+1), cells)
+	assert.True(t, rows)
+
+	_, _, rows = parseSheets("no rows", true)
+	assert.False(t, rows)
+}
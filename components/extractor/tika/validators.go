@@ -0,0 +1,110 @@
+package tika
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+)
+
+// validators are the cache validators of content on the fallback gateway, merged into documents extracted through it.
+type validators struct {
+	GatewayETag         string     `json:"gateway_etag,omitempty"`
+	GatewayLastModified *time.Time `json:"gateway_last_modified,omitempty"`
+}
+
+// getValidators returns the validators from the headers of a response, or nil when the gateway sent none.
+// Unparseable Last-Modified headers are ignored.
+func getValidators(header http.Header) *validators {
+	v := &validators{
+		GatewayETag: header.Get("ETag"),
+	}
+
+	if t, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		t = t.UTC()
+		v.GatewayLastModified = &t
+	}
+
+	if v.GatewayETag == "" && v.GatewayLastModified == nil {
+		return nil
+	}
+
+	return v
+}
+
+// previousValidators returns the validators m has been indexed with, or nil when it has none.
+func previousValidators(m interface{}) *validators {
+	buf, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+
+	v := new(validators)
+	if err := json.Unmarshal(buf, v); err != nil {
+		return nil
+	}
+
+	if v.GatewayETag == "" && v.GatewayLastModified == nil {
+		return nil
+	}
+
+	return v
+}
+
+// headValidators requests the validators of fallbackURL from the fallback gateway, without its body, subject to the
+// fallback rate limit. With previous validators the request is conditional, returning notModified when the gateway
+// reports the content unchanged. Custom headers are meant for the server, so they're not sent.
+func (e *Extractor) headValidators(ctx context.Context, fallbackURL string, previous *validators) (v *validators, notModified bool, err error) {
+	if err := e.fallbackLimiter.Wait(ctx); err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.config.FallbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", fallbackURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if previous != nil {
+		if previous.GatewayETag != "" {
+			req.Header.Set("If-None-Match", previous.GatewayETag)
+		}
+
+		if previous.GatewayLastModified != nil {
+			req.Header.Set("If-Modified-Since", previous.GatewayLastModified.Format(http.TimeFormat))
+		}
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return getValidators(resp.Header), false, nil
+	case http.StatusNotModified:
+		if previous == nil {
+			return nil, false, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+
+		return previous, true, nil
+	default:
+		return nil, false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+// setValidators merges validators into m, which has been decoded from JSON.
+func setValidators(v *validators, m interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	return extractor.Merge(v, m)
+}
@@ -0,0 +1,69 @@
+package tika
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+func TestGetValidators(t *testing.T) {
+	header := http.Header{}
+	header.Set("ETag", `"bafybeigdyrzt"`)
+	header.Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+
+	v := getValidators(header)
+
+	assert.Equal(t, `"bafybeigdyrzt"`, v.GatewayETag)
+	assert.Equal(t, time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC), *v.GatewayLastModified)
+}
+
+func TestGetValidatorsETagOnly(t *testing.T) {
+	header := http.Header{}
+	header.Set("ETag", `W/"abc"`)
+	header.Set("Last-Modified", "yesterday")
+
+	v := getValidators(header)
+
+	assert.Equal(t, `W/"abc"`, v.GatewayETag)
+	assert.Nil(t, v.GatewayLastModified)
+}
+
+func TestGetValidatorsNone(t *testing.T) {
+	assert.Nil(t, getValidators(http.Header{}))
+}
+
+func TestPreviousValidators(t *testing.T) {
+	assert.Nil(t, previousValidators(&indexTypes.File{}))
+
+	v := previousValidators(&indexTypes.File{GatewayETag: `"abc"`})
+	assert.Equal(t, `"abc"`, v.GatewayETag)
+}
+
+func TestHeadValidatorsConditional(t *testing.T) {
+	lastModified := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "HEAD", r.Method)
+		assert.Equal(t, `"abc"`, r.Header.Get("If-None-Match"))
+		assert.Equal(t, "Wed, 21 Oct 2015 07:28:00 GMT", r.Header.Get("If-Modified-Since"))
+
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	e := New(DefaultConfig(), http.DefaultClient, nil, instr.New()).(*Extractor)
+	previous := &validators{GatewayETag: `"abc"`, GatewayLastModified: &lastModified}
+
+	v, notModified, err := e.headValidators(context.Background(), srv.URL, previous)
+
+	assert.NoError(t, err)
+	assert.True(t, notModified)
+	assert.Equal(t, previous, v)
+}
@@ -0,0 +1,132 @@
+package tika
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+)
+
+const (
+	// warningHeader may be set (repeatedly) by the server for non-fatal extraction problems.
+	warningHeader = "X-Tika-Warning"
+
+	// Bounds on the warnings indexed per document, as exceptions may include stack traces.
+	maxWarnings      = 16
+	maxWarningLength = 256
+)
+
+// warningKeys maps metadata keys for non-fatal Tika exceptions to the prefix of their warnings.
+var warningKeys = map[string]string{
+	"X-TIKA:EXCEPTION:warn":                "",
+	"X-TIKA:EXCEPTION:embedded_exception":  "embedded document: ",
+	"X-TIKA:EXCEPTION:embedded_resource":   "embedded resource: ",
+	"X-TIKA:EXCEPTION:write_limit_reached": "",
+}
+
+// writeLimitWarning replaces the (boolean) value of X-TIKA:EXCEPTION:write_limit_reached.
+const writeLimitWarning = "write limit reached, content truncated"
+
+// extractionWarnings are merged into extracted documents.
+type extractionWarnings struct {
+	ExtractionWarnings []string `json:"extraction_warnings,omitempty"`
+}
+
+// warningCollector deduplicates and bounds warnings.
+type warningCollector struct {
+	warnings []string
+	seen     map[string]struct{}
+}
+
+// add the first line of warning, truncated to maxWarningLength.
+func (c *warningCollector) add(warning string) {
+	if i := strings.IndexAny(warning, "\r\n"); i >= 0 {
+		warning = warning[:i]
+	}
+
+	warning = strings.TrimSpace(warning)
+	if len(warning) > maxWarningLength {
+		warning = strings.ToValidUTF8(warning[:maxWarningLength], "")
+	}
+
+	if warning == "" || len(c.warnings) >= maxWarnings {
+		return
+	}
+
+	if _, ok := c.seen[warning]; ok {
+		return
+	}
+
+	c.seen[warning] = struct{}{}
+	c.warnings = append(c.warnings, warning)
+}
+
+// metadataValues returns the values of a metadata field, which is either a string or a list of strings.
+func metadataValues(raw json.RawMessage) []string {
+	var values []string
+	if err := json.Unmarshal(raw, &values); err == nil {
+		return values
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return []string{value}
+	}
+
+	return nil
+}
+
+// getWarnings returns non-fatal extraction warnings from the response headers and, when not nil, the JSON document
+// returned by the server.
+func getWarnings(header http.Header, doc json.RawMessage) []string {
+	c := &warningCollector{seen: make(map[string]struct{})}
+
+	for _, warning := range header[http.CanonicalHeaderKey(warningHeader)] {
+		c.add(warning)
+	}
+
+	if doc == nil {
+		return c.warnings
+	}
+
+	var d struct {
+		Metadata map[string]json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(doc, &d); err != nil {
+		// The document has been decoded before; an unexpected metadata format carries no warnings.
+		return c.warnings
+	}
+
+	// Sort keys for stable output.
+	keys := make([]string, 0, len(warningKeys))
+	for key := range warningKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range metadataValues(d.Metadata[key]) {
+			if key == "X-TIKA:EXCEPTION:write_limit_reached" {
+				if value == "true" {
+					c.add(writeLimitWarning)
+				}
+				continue
+			}
+
+			c.add(warningKeys[key] + value)
+		}
+	}
+
+	return c.warnings
+}
+
+// setWarnings merges warnings into m, which has been decoded from JSON.
+func setWarnings(warnings []string, m interface{}) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	return extractor.Merge(extractionWarnings{warnings}, m)
+}
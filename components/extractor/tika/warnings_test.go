@@ -0,0 +1,59 @@
+package tika
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWarnings(t *testing.T) {
+	header := http.Header{
+		"X-Tika-Warning": []string{"encrypted, partial text", "encrypted, partial text"},
+	}
+
+	doc := json.RawMessage(`{
+		"metadata": {
+			"X-TIKA:EXCEPTION:warn": ["org.apache.tika.exception.TikaException: Unable to extract all PDF content\n\tat org.apache.tika..."],
+			"X-TIKA:EXCEPTION:embedded_exception": "java.io.IOException: corrupt attachment",
+			"X-TIKA:EXCEPTION:write_limit_reached": ["true"],
+			"title": ["Not a warning"]
+		},
+		"content": "partial"
+	}`)
+
+	assert.Equal(t, []string{
+		"encrypted, partial text",
+		"embedded document: java.io.IOException: corrupt attachment",
+		"org.apache.tika.exception.TikaException: Unable to extract all PDF content",
+		writeLimitWarning,
+	}, getWarnings(header, doc))
+}
+
+func TestGetWarningsNone(t *testing.T) {
+	assert.Empty(t, getWarnings(http.Header{}, json.RawMessage(`{"metadata": {"title": ["Title"]}}`)))
+	assert.Empty(t, getWarnings(http.Header{}, json.RawMessage(`{"metadata": null}`)))
+	assert.Empty(t, getWarnings(http.Header{}, nil))
+}
+
+func TestGetWarningsBounds(t *testing.T) {
+	header := http.Header{}
+	for i := 0; i < maxWarnings+1; i++ {
+		header.Add(warningHeader, strings.Repeat("w", maxWarningLength+i))
+	}
+
+	warnings := getWarnings(header, nil)
+
+	assert.Len(t, warnings, 1) // Truncated to identical warnings.
+
+	header = http.Header{}
+	for i := 0; i < maxWarnings+1; i++ {
+		header.Add(warningHeader, strings.Repeat("w", i+1))
+	}
+
+	warnings = getWarnings(header, nil)
+
+	assert.Len(t, warnings, maxWarnings)
+}
@@ -0,0 +1,25 @@
+package toc
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+)
+
+// Config specifies the configuration for the table of contents extractor.
+type Config struct {
+	Enabled        bool              // Extract the outline (bookmarks) of PDF and EPUB documents.
+	RequestTimeout time.Duration     // Timeout for requests to the gateway.
+	MaxFileSize    datasize.ByteSize // Skip larger documents; outlines are located by offset, requiring the whole file.
+	MaxEntries     int               // Maximum number of outline entries per document.
+}
+
+// DefaultConfig returns the default configuration for the table of contents extractor.
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:        false,
+		RequestTimeout: 60 * time.Second,
+		MaxFileSize:    32 * 1024 * 1024, // 32MB
+		MaxEntries:     256,
+	}
+}
@@ -0,0 +1,294 @@
+package toc
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// maxEPUBPartSize bounds the size of the container, package and navigation documents read from EPUBs.
+const maxEPUBPartSize = 4 * 1024 * 1024
+
+// container is META-INF/container.xml, pointing to the package document.
+type container struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// packageDocument is the (OPF) package document, listing the navigation document (EPUB 3) or NCX (EPUB 2).
+type packageDocument struct {
+	Items []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		TOC string `xml:"toc,attr"`
+	} `xml:"spine"`
+}
+
+// epub reads parts of an EPUB archive.
+type epub struct {
+	files map[string]*zip.File
+}
+
+func (e *epub) read(name string) ([]byte, error) {
+	f, ok := e.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	buf, err := ioutil.ReadAll(io.LimitReader(r, maxEPUBPartSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buf) > maxEPUBPartSize {
+		return nil, fmt.Errorf("%s too large", name)
+	}
+
+	return buf, nil
+}
+
+func (e *epub) decode(name string, v interface{}) error {
+	buf, err := e.read(name)
+	if err != nil {
+		return err
+	}
+
+	return newXMLDecoder(buf).Decode(v)
+}
+
+// newXMLDecoder returns a lenient decoder, as navigation documents are XHTML which is not always well-formed.
+func newXMLDecoder(buf []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(buf))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// Non-UTF-8 documents are rare; decode them as is rather than failing.
+		return input, nil
+	}
+
+	return d
+}
+
+// navigation returns the path of the navigation document and whether it's an NCX, relative to the archive.
+func (e *epub) navigation() (string, bool, error) {
+	var c container
+	if err := e.decode("META-INF/container.xml", &c); err != nil {
+		return "", false, err
+	}
+
+	if len(c.Rootfiles) == 0 {
+		return "", false, errors.New("no package document")
+	}
+
+	opfPath := c.Rootfiles[0].FullPath
+
+	var p packageDocument
+	if err := e.decode(opfPath, &p); err != nil {
+		return "", false, err
+	}
+
+	resolve := func(href string) string {
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		return path.Join(path.Dir(opfPath), href)
+	}
+
+	// Prefer the EPUB 3 navigation document.
+	for _, item := range p.Items {
+		for _, property := range strings.Fields(item.Properties) {
+			if property == "nav" {
+				return resolve(item.Href), false, nil
+			}
+		}
+	}
+
+	for _, item := range p.Items {
+		if item.ID == p.Spine.TOC || item.MediaType == "application/x-dtbncx+xml" {
+			return resolve(item.Href), true, nil
+		}
+	}
+
+	return "", false, errNoOutline
+}
+
+// textCollector collects the text of elements into entries, up to max.
+type textCollector struct {
+	max     int
+	entries []string
+	text    *strings.Builder
+}
+
+func (c *textCollector) start() {
+	c.text = new(strings.Builder)
+}
+
+func (c *textCollector) end() {
+	if c.text == nil {
+		return
+	}
+
+	if title := normalizeTitle(c.text.String()); title != "" && len(c.entries) < c.max {
+		c.entries = append(c.entries, title)
+	}
+
+	c.text = nil
+}
+
+func (c *textCollector) charData(data xml.CharData) {
+	if c.text != nil {
+		c.text.Write(data)
+	}
+}
+
+func (c *textCollector) full() bool {
+	return len(c.entries) >= c.max
+}
+
+// parseNav returns the titles of the links in the `toc` nav element of an EPUB 3 navigation document.
+func parseNav(d *xml.Decoder, max int) ([]string, error) {
+	var (
+		c        = &textCollector{max: max}
+		navDepth int // Nesting within the toc nav, 0 outside.
+	)
+
+	for !c.full() {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if navDepth > 0 {
+				navDepth++
+				if tok.Name.Local == "a" || tok.Name.Local == "span" {
+					c.start()
+				}
+				continue
+			}
+
+			if tok.Name.Local == "nav" {
+				for _, attr := range tok.Attr {
+					if attr.Name.Local == "type" && strings.Contains(attr.Value, "toc") {
+						navDepth = 1
+					}
+				}
+			}
+		case xml.EndElement:
+			if navDepth > 0 {
+				navDepth--
+				if tok.Name.Local == "a" || tok.Name.Local == "span" {
+					c.end()
+				}
+			}
+		case xml.CharData:
+			c.charData(tok)
+		}
+	}
+
+	return c.entries, nil
+}
+
+// parseNCX returns the labels of the navigation points of an EPUB 2 NCX document, in document order.
+func parseNCX(d *xml.Decoder, max int) ([]string, error) {
+	var (
+		c       = &textCollector{max: max}
+		inLabel bool
+	)
+
+	for !c.full() {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "navLabel":
+				inLabel = true
+			case "text":
+				if inLabel {
+					c.start()
+				}
+			}
+		case xml.EndElement:
+			switch tok.Name.Local {
+			case "navLabel":
+				inLabel = false
+			case "text":
+				c.end()
+			}
+		case xml.CharData:
+			c.charData(tok)
+		}
+	}
+
+	return c.entries, nil
+}
+
+// parseEPUB returns up to max titles of the table of contents of the EPUB archive in buf, or errNoOutline.
+func parseEPUB(buf []byte, max int) ([]string, error) {
+	z, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+
+	e := &epub{make(map[string]*zip.File, len(z.File))}
+	for _, f := range z.File {
+		e.files[f.Name] = f
+	}
+
+	navPath, isNCX, err := e.navigation()
+	if err != nil {
+		return nil, err
+	}
+
+	nav, err := e.read(navPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	if isNCX {
+		entries, err = parseNCX(newXMLDecoder(nav), max)
+	} else {
+		entries, err = parseNav(newXMLDecoder(nav), max)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, errNoOutline
+	}
+
+	return entries, nil
+}
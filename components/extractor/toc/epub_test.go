@@ -0,0 +1,122 @@
+package toc
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testContainer = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+	<rootfiles>
+		<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+	</rootfiles>
+</container>`
+
+// buildEPUB returns an EPUB archive with the given files, in addition to the mimetype and container.
+func buildEPUB(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	add := func(name, content string) {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+
+	add("mimetype", "application/epub+zip")
+	add("META-INF/container.xml", testContainer)
+	for name, content := range files {
+		add(name, content)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+var testEPUB3 = map[string]string{
+	"OEBPS/content.opf": `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+	<manifest>
+		<item id="nav" href="nav%20doc.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+	</manifest>
+</package>`,
+	"OEBPS/nav doc.xhtml": `<?xml version="1.0"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<body>
+	<nav epub:type="landmarks"><ol><li><a href="cover.xhtml">Cover</a></li></ol></nav>
+	<nav epub:type="toc">
+		<ol>
+			<li><a href="ch1.xhtml">Chapter
+				One</a>
+				<ol><li><a href="ch1.xhtml#s1">Section 1.1</a></li></ol>
+			</li>
+			<li><span>Part &amp; Two</span></li>
+		</ol>
+	</nav>
+</body>
+</html>`,
+}
+
+var testEPUB2 = map[string]string{
+	"OEBPS/content.opf": `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+	<manifest>
+		<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+	</manifest>
+	<spine toc="ncx"/>
+</package>`,
+	"OEBPS/toc.ncx": `<?xml version="1.0"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+	<docTitle><text>Not an entry</text></docTitle>
+	<navMap>
+		<navPoint id="p1"><navLabel><text>Prologue</text></navLabel>
+			<navPoint id="p2"><navLabel><text>Scene 1</text></navLabel></navPoint>
+		</navPoint>
+	</navMap>
+</ncx>`,
+}
+
+func TestParseEPUBNav(t *testing.T) {
+	entries, err := parseEPUB(buildEPUB(t, testEPUB3), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Chapter One", "Section 1.1", "Part & Two"}, entries)
+}
+
+func TestParseEPUBNCX(t *testing.T) {
+	entries, err := parseEPUB(buildEPUB(t, testEPUB2), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Prologue", "Scene 1"}, entries)
+}
+
+func TestParseEPUBMaxEntries(t *testing.T) {
+	entries, err := parseEPUB(buildEPUB(t, testEPUB3), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Chapter One"}, entries)
+}
+
+func TestParseEPUBNoOutline(t *testing.T) {
+	files := map[string]string{
+		"OEBPS/content.opf": `<package xmlns="http://www.idpf.org/2007/opf"><manifest/></package>`,
+	}
+
+	_, err := parseEPUB(buildEPUB(t, files), 10)
+
+	assert.Equal(t, errNoOutline, err)
+}
+
+func TestParseEPUBInvalid(t *testing.T) {
+	_, err := parseEPUB([]byte("not an EPUB"), 10)
+
+	assert.Error(t, err)
+}
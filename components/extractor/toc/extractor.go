@@ -0,0 +1,112 @@
+// Package toc extracts the outline (bookmarks) of PDF and EPUB documents as a table of contents.
+package toc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/trace"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	pdfType  = "application/pdf"
+	epubType = "application/epub+zip"
+)
+
+// Extractor extracts tables of contents by fetching documents from the gateway.
+type Extractor struct {
+	config  *Config
+	fetcher *extractor.Fetcher
+}
+
+// properties are merged into the extracted metadata.
+type properties struct {
+	TOC []string `json:"toc,omitempty"`
+}
+
+// normalizeTitle collapses whitespace in title.
+func normalizeTitle(title string) string {
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// documentType returns the type of document r is, or an empty string for unsupported documents.
+// EPUBs are recognized by their extension as well, as they're sniffed as ZIP archives.
+func documentType(r *t.AnnotatedResource) string {
+	if strings.EqualFold(path.Ext(r.Reference.Name), ".epub") {
+		return epubType
+	}
+
+	switch mimeType := extractor.MimeType(r); mimeType {
+	case pdfType, epubType:
+		return mimeType
+	default:
+		return ""
+	}
+}
+
+// Applies returns true for PDF and EPUB resources up to the maximum file size.
+func (e *Extractor) Applies(r *t.AnnotatedResource) bool {
+	return documentType(r) != "" && r.Size <= uint64(e.config.MaxFileSize)
+}
+
+// parse reads the outline of PDF and EPUB documents, both of which require random access, returning no properties
+// for documents without one.
+func (e *Extractor) parse(ctx context.Context, r *t.AnnotatedResource, body io.Reader) (interface{}, error) {
+	buf, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	if documentType(r) == epubType {
+		entries, err = parseEPUB(buf, e.config.MaxEntries)
+	} else {
+		entries, err = parsePDF(buf, e.config.MaxEntries)
+	}
+
+	if errors.Is(err, errNoOutline) {
+		trace.SpanFromContext(ctx).AddEvent(ctx, "no-outline")
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return properties{entries}, nil
+}
+
+// Extract the table of contents from PDF and EPUB resources up to the maximum file size, ignoring other resources
+// and documents without an outline.
+func (e *Extractor) Extract(ctx context.Context, r *t.AnnotatedResource, m interface{}) error {
+	if !e.Applies(r) {
+		return nil
+	}
+
+	return e.fetcher.Extract(ctx, r, m, e.config.RequestTimeout, int64(e.config.MaxFileSize), e.parse)
+}
+
+// New returns a new table of contents extractor.
+func New(config *Config, client *http.Client, protocol protocol.Protocol, instr *instr.Instrumentation) extractor.Extractor {
+	return &Extractor{
+		config,
+		extractor.NewFetcher("toc", client, protocol, instr),
+	}
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ extractor.Extractor = &Extractor{}
+	_ extractor.Selective = &Extractor{}
+)
@@ -0,0 +1,96 @@
+package toc
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/extractor/extractortest"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type TOCTestSuite struct {
+	extractortest.Suite
+
+	e   extractor.Extractor
+	cfg *Config
+}
+
+func (s *TOCTestSuite) SetupTest() {
+	s.Suite.SetupTest()
+
+	s.cfg = DefaultConfig()
+
+	s.e = New(s.cfg, http.DefaultClient, s.Protocol, instr.New())
+}
+
+func (s *TOCTestSuite) TestExtractPDF() {
+	r := s.Resource("manual.pdf", "", len(testPDF))
+	s.ExpectGet(r, []byte(testPDF))
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertFetched()
+
+	s.Equal([]string{"Introduction", "Getting started (quickly)", "Chapter 2"}, f.TOC)
+}
+
+func (s *TOCTestSuite) TestExtractEPUB() {
+	body := buildEPUB(s.T(), testEPUB2)
+	r := s.Resource("book.EPUB", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertFetched()
+
+	s.Equal([]string{"Prologue", "Scene 1"}, f.TOC)
+}
+
+func (s *TOCTestSuite) TestExtractUnparseable() {
+	body := []byte("%PDF-1.4 truncated")
+	r := s.Resource("broken.pdf", "", len(body))
+	s.ExpectGet(r, body)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.Empty(f.TOC)
+}
+
+func (s *TOCTestSuite) TestExtractUnsupported() {
+	r := s.Resource("index.html", "", 100)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+
+	s.Empty(f.TOC)
+}
+
+func (s *TOCTestSuite) TestExtractTooLarge() {
+	r := s.Resource("manual.pdf", "", int(s.cfg.MaxFileSize)+1)
+
+	f := new(indexTypes.File)
+	err := s.e.Extract(s.Ctx, r, f)
+
+	s.NoError(err)
+	s.AssertNotFetched()
+
+	s.Empty(f.TOC)
+}
+
+func TestTOCTestSuite(t *testing.T) {
+	suite.Run(t, new(TOCTestSuite))
+}
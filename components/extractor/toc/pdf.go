@@ -0,0 +1,620 @@
+package toc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"unicode/utf16"
+)
+
+const (
+	// maxOutlineDepth bounds the nesting of outline items traversed.
+	maxOutlineDepth = 16
+
+	// maxObjectStreamSize bounds the decompressed size of object streams.
+	maxObjectStreamSize = 16 * 1024 * 1024
+
+	// maxInflatedSize bounds the total decompressed size of object streams in a document.
+	maxInflatedSize = 64 * 1024 * 1024
+
+	// maxNesting bounds the nesting of arrays and dictionaries.
+	maxNesting = 64
+)
+
+var (
+	errNoOutline = errors.New("no outline")
+	errTooDeep   = errors.New("objects nested too deeply")
+	errTooLarge  = errors.New("object streams too large")
+
+	// objectPattern matches the start of indirect objects, `<num> <gen> obj`.
+	objectPattern = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+)
+
+// PDF objects are represented as nil, bool, float64, string, pdfName, pdfRef, []interface{} or pdfDict.
+type (
+	pdfName string
+	pdfRef  int // Object number; generations are ignored.
+	pdfDict map[pdfName]interface{}
+)
+
+// pdfLexer parses PDF objects from buf, starting at pos.
+type pdfLexer struct {
+	buf   []byte
+	pos   int
+	depth int // Nesting of arrays and dictionaries.
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return isPDFSpace(c) || bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips whitespace and comments.
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.buf) {
+		switch c := l.buf[l.pos]; {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.buf) && l.buf[l.pos] != '\r' && l.buf[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token returns the regular characters up to the next delimiter.
+func (l *pdfLexer) token() string {
+	start := l.pos
+	for l.pos < len(l.buf) && !isPDFDelimiter(l.buf[l.pos]) {
+		l.pos++
+	}
+
+	return string(l.buf[start:l.pos])
+}
+
+// peek returns true when the input at the current position starts with s.
+func (l *pdfLexer) peek(s string) bool {
+	return bytes.HasPrefix(l.buf[l.pos:], []byte(s))
+}
+
+// object parses the next object.
+func (l *pdfLexer) object() (interface{}, error) {
+	l.skipSpace()
+
+	if l.pos >= len(l.buf) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	switch c := l.buf[l.pos]; {
+	case c == '/':
+		l.pos++
+		return pdfName(decodeName(l.token())), nil
+	case c == '(':
+		l.pos++
+		return l.literalString()
+	case l.peek("<<"):
+		l.pos += 2
+		return l.nested(func() (interface{}, error) { return l.dict() })
+	case c == '<':
+		l.pos++
+		return l.hexString()
+	case c == '[':
+		l.pos++
+		return l.nested(func() (interface{}, error) { return l.array() })
+	case c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.':
+		return l.numberOrRef()
+	}
+
+	switch tok := l.token(); tok {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected token '%s' at %d", tok, l.pos)
+	}
+}
+
+// decodeName decodes #xx escapes in names.
+func decodeName(s string) string {
+	var b []byte
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && i+2 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b = append(b, byte(v))
+				i += 2
+				continue
+			}
+		}
+
+		b = append(b, s[i])
+	}
+
+	return string(b)
+}
+
+func (l *pdfLexer) literalString() (string, error) {
+	var (
+		b     []byte
+		depth = 1
+	)
+
+	for l.pos < len(l.buf) {
+		c := l.buf[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return string(b), nil
+			}
+		case '\\':
+			if l.pos >= len(l.buf) {
+				return "", io.ErrUnexpectedEOF
+			}
+
+			c = l.buf[l.pos]
+			l.pos++
+
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// Line continuation.
+				if c == '\r' && l.pos < len(l.buf) && l.buf[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					// Up to 3 octal digits.
+					v := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.buf) && l.buf[l.pos] >= '0' && l.buf[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.buf[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+
+		b = append(b, c)
+	}
+
+	return "", io.ErrUnexpectedEOF
+}
+
+func (l *pdfLexer) hexString() (string, error) {
+	var digits []byte
+
+	for l.pos < len(l.buf) {
+		c := l.buf[l.pos]
+		l.pos++
+
+		if c == '>' {
+			if len(digits)%2 == 1 {
+				digits = append(digits, '0')
+			}
+
+			b := make([]byte, len(digits)/2)
+			for i := range b {
+				v, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+				if err != nil {
+					return "", err
+				}
+				b[i] = byte(v)
+			}
+
+			return string(b), nil
+		}
+
+		if !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+
+	return "", io.ErrUnexpectedEOF
+}
+
+// nested parses a nested array or dictionary with parse, up to maxNesting levels deep.
+func (l *pdfLexer) nested(parse func() (interface{}, error)) (interface{}, error) {
+	if l.depth >= maxNesting {
+		return nil, fmt.Errorf("%w at %d", errTooDeep, l.pos)
+	}
+
+	l.depth++
+	defer func() { l.depth-- }()
+
+	return parse()
+}
+
+func (l *pdfLexer) dict() (pdfDict, error) {
+	d := make(pdfDict)
+
+	for {
+		l.skipSpace()
+
+		if l.peek(">>") {
+			l.pos += 2
+			return d, nil
+		}
+
+		key, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := key.(pdfName)
+		if !ok {
+			return nil, fmt.Errorf("unexpected dictionary key %v at %d", key, l.pos)
+		}
+
+		if d[name], err = l.object(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (l *pdfLexer) array() ([]interface{}, error) {
+	var a []interface{}
+
+	for {
+		l.skipSpace()
+
+		if l.peek("]") {
+			l.pos++
+			return a, nil
+		}
+
+		v, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+
+		a = append(a, v)
+	}
+}
+
+// numberOrRef parses a number, or a reference when it is followed by a generation and R.
+func (l *pdfLexer) numberOrRef() (interface{}, error) {
+	tok := l.token()
+
+	n, err := strconv.ParseFloat(tok, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number '%s' at %d", tok, l.pos)
+	}
+
+	if num, err := strconv.Atoi(tok); err == nil {
+		// Look ahead for `<gen> R`.
+		pos := l.pos
+
+		l.skipSpace()
+		if _, err := strconv.Atoi(l.token()); err == nil {
+			l.skipSpace()
+			if l.token() == "R" {
+				return pdfRef(num), nil
+			}
+		}
+
+		l.pos = pos
+	}
+
+	return n, nil
+}
+
+// objectLocation is the location of an indirect object in buf.
+type objectLocation struct {
+	buf []byte
+	pos int
+}
+
+// pdfFile resolves indirect objects of a PDF document, without relying on (possibly broken) cross-reference tables.
+type pdfFile struct {
+	buf      []byte
+	objects  map[int]objectLocation
+	inflated int // Total decompressed size of streams.
+}
+
+// newPDFFile indexes the objects of buf, including those in object streams.
+func newPDFFile(buf []byte) (*pdfFile, error) {
+	f := &pdfFile{
+		buf:     buf,
+		objects: make(map[int]objectLocation),
+	}
+
+	// Later definitions (incremental updates) override earlier ones.
+	for _, m := range objectPattern.FindAllSubmatchIndex(buf, -1) {
+		if m[0] > 0 && !isPDFDelimiter(buf[m[0]-1]) {
+			continue
+		}
+
+		num, err := strconv.Atoi(string(buf[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+
+		f.objects[num] = objectLocation{buf, m[1]}
+	}
+
+	direct := make([]int, 0, len(f.objects))
+	for num := range f.objects {
+		direct = append(direct, num)
+	}
+
+	// Objects in object streams only take effect when not defined directly.
+	for _, num := range direct {
+		if err := f.indexObjectStream(num); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// stream returns the dictionary and decoded data of the stream object num.
+func (f *pdfFile) stream(num int) (pdfDict, []byte, error) {
+	loc, ok := f.objects[num]
+	if !ok {
+		return nil, nil, fmt.Errorf("object %d not found", num)
+	}
+
+	l := &pdfLexer{buf: loc.buf, pos: loc.pos}
+
+	v, err := l.object()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d, ok := v.(pdfDict)
+	if !ok {
+		return nil, nil, fmt.Errorf("object %d is not a stream", num)
+	}
+
+	l.skipSpace()
+	if !l.peek("stream") {
+		return nil, nil, fmt.Errorf("object %d is not a stream", num)
+	}
+	l.pos += len("stream")
+
+	// The keyword is followed by CRLF or LF.
+	if l.peek("\r\n") {
+		l.pos += 2
+	} else if l.peek("\n") {
+		l.pos++
+	}
+
+	end := -1
+	if length, ok := f.resolve(d["Length"], 0).(float64); ok && l.pos+int(length) <= len(l.buf) {
+		end = l.pos + int(length)
+	} else if i := bytes.Index(l.buf[l.pos:], []byte("endstream")); i >= 0 {
+		end = l.pos + i
+	} else {
+		return nil, nil, fmt.Errorf("unterminated stream %d", num)
+	}
+
+	data := l.buf[l.pos:end]
+
+	switch filter := d["Filter"].(type) {
+	case nil:
+		return d, data, nil
+	case pdfName:
+		if filter == "FlateDecode" {
+			break
+		}
+		return nil, nil, fmt.Errorf("unsupported filter %s", filter)
+	case []interface{}:
+		if len(filter) == 1 && filter[0] == pdfName("FlateDecode") {
+			break
+		}
+		return nil, nil, fmt.Errorf("unsupported filters %v", filter)
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	limit := maxObjectStreamSize
+	if remaining := maxInflatedSize - f.inflated; remaining < limit {
+		limit = remaining
+	}
+
+	// Read one byte beyond the limit to detect exceeding it.
+	data, err = ioutil.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil, err
+	}
+
+	if len(data) > limit {
+		return nil, nil, fmt.Errorf("%w: stream %d exceeds %d bytes", errTooLarge, num, limit)
+	}
+
+	f.inflated += len(data)
+
+	return d, data, nil
+}
+
+// indexObjectStream adds the objects of object num to the index, when it is an object stream.
+// Malformed object streams are skipped; only exceeding the size or nesting limits returns an error.
+func (f *pdfFile) indexObjectStream(num int) error {
+	l := &pdfLexer{buf: f.objects[num].buf, pos: f.objects[num].pos}
+
+	v, err := l.object()
+	if errors.Is(err, errTooDeep) {
+		return err
+	}
+	if d, ok := v.(pdfDict); err != nil || !ok || d["Type"] != pdfName("ObjStm") {
+		return nil
+	}
+
+	d, data, err := f.stream(num)
+	if errors.Is(err, errTooLarge) {
+		return err
+	}
+	if err != nil {
+		return nil
+	}
+
+	n, _ := d["N"].(float64)
+	first, _ := d["First"].(float64)
+
+	// The header consists of pairs of object numbers and offsets, relative to First.
+	header := &pdfLexer{buf: data}
+	for i := 0; i < int(n); i++ {
+		objNum, err1 := header.object()
+		offset, err2 := header.object()
+		if err1 != nil || err2 != nil {
+			return nil
+		}
+
+		objNumF, ok1 := objNum.(float64)
+		offsetF, ok2 := offset.(float64)
+		if !ok1 || !ok2 || int(first+offsetF) >= len(data) {
+			return nil
+		}
+
+		if _, exists := f.objects[int(objNumF)]; !exists {
+			f.objects[int(objNumF)] = objectLocation{data, int(first + offsetF)}
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the object v refers to, or v itself when it is not a reference.
+func (f *pdfFile) resolve(v interface{}, depth int) interface{} {
+	ref, ok := v.(pdfRef)
+	if !ok {
+		return v
+	}
+
+	loc, ok := f.objects[int(ref)]
+	if !ok || depth > 8 {
+		return nil
+	}
+
+	obj, err := (&pdfLexer{buf: loc.buf, pos: loc.pos}).object()
+	if err != nil {
+		return nil
+	}
+
+	return f.resolve(obj, depth+1)
+}
+
+// catalog returns the document catalog, referred to from the last trailer or cross-reference stream.
+func (f *pdfFile) catalog() pdfDict {
+	i := bytes.LastIndex(f.buf, []byte("/Root"))
+	if i < 0 {
+		return nil
+	}
+
+	root, err := (&pdfLexer{buf: f.buf, pos: i + len("/Root")}).object()
+	if err != nil {
+		return nil
+	}
+
+	d, _ := f.resolve(root, 0).(pdfDict)
+	return d
+}
+
+// pdfText decodes a PDF text string, which is either UTF-16BE with a byte order mark, UTF-8 with a byte order mark
+// or (approximately) PDFDocEncoding.
+func pdfText(s string) string {
+	switch {
+	case len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff:
+		units := make([]uint16, 0, (len(s)-2)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	case len(s) >= 3 && s[:3] == "\xef\xbb\xbf":
+		return s[3:]
+	default:
+		// PDFDocEncoding equals Latin-1 for printable characters.
+		runes := make([]rune, len(s))
+		for i := 0; i < len(s); i++ {
+			runes[i] = rune(s[i])
+		}
+		return string(runes)
+	}
+}
+
+// outline appends the titles of the outline items starting at first, depth first, up to max entries.
+func (f *pdfFile) outline(first interface{}, depth int, max int, seen map[pdfRef]bool, entries []string) []string {
+	for item := first; len(entries) < max; {
+		ref, ok := item.(pdfRef)
+		if !ok || seen[ref] {
+			// End of list, or a cycle.
+			break
+		}
+		seen[ref] = true
+
+		d, ok := f.resolve(ref, 0).(pdfDict)
+		if !ok {
+			break
+		}
+
+		if title, ok := f.resolve(d["Title"], 0).(string); ok {
+			if title = normalizeTitle(pdfText(title)); title != "" {
+				entries = append(entries, title)
+			}
+		}
+
+		if depth < maxOutlineDepth {
+			entries = f.outline(d["First"], depth+1, max, seen, entries)
+		}
+
+		item = d["Next"]
+	}
+
+	return entries
+}
+
+// parsePDF returns up to max titles of the outline of the PDF document in buf, or errNoOutline.
+func parsePDF(buf []byte, max int) ([]string, error) {
+	f, err := newPDFFile(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := f.catalog()
+	if catalog == nil {
+		return nil, errors.New("document catalog not found")
+	}
+
+	outlines, ok := f.resolve(catalog["Outlines"], 0).(pdfDict)
+	if !ok {
+		return nil, errNoOutline
+	}
+
+	entries := f.outline(outlines["First"], 0, max, make(map[pdfRef]bool), nil)
+	if len(entries) == 0 {
+		return nil, errNoOutline
+	}
+
+	return entries, nil
+}
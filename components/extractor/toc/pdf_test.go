@@ -0,0 +1,116 @@
+package toc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPDF = `%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R /Outlines 3 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [] /Count 0 >>
+endobj
+3 0 obj
+<< /Type /Outlines /First 4 0 R /Last 6 0 R /Count 3 >>
+endobj
+4 0 obj
+<< /Title (Introduction) /Parent 3 0 R /Next 6 0 R /First 5 0 R /Last 5 0 R >>
+endobj
+5 0 obj
+<< /Title (Getting   started\n\(quickly\)) /Parent 4 0 R >>
+endobj
+6 0 obj
+<< /Title <FEFF004300680061007000740065007200200032> /Parent 3 0 R /Prev 4 0 R /Next 4 0 R >>
+endobj
+trailer
+<< /Size 7 /Root 1 0 R >>
+%%EOF
+`
+
+func TestParsePDF(t *testing.T) {
+	entries, err := parsePDF([]byte(testPDF), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Introduction", "Getting started (quickly)", "Chapter 2"}, entries)
+}
+
+func TestParsePDFMaxEntries(t *testing.T) {
+	entries, err := parsePDF([]byte(testPDF), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Introduction", "Getting started (quickly)"}, entries)
+}
+
+func TestParsePDFObjectStream(t *testing.T) {
+	objects := "3 0 4 39 " +
+		"<< /Type /Outlines /First 4 0 R >>\n    " +
+		"<< /Title (Compressed) /Parent 3 0 R >>"
+
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	w.Write([]byte(objects))
+	w.Close()
+
+	buf := fmt.Sprintf("%%PDF-1.5\n"+
+		"1 0 obj\n<< /Type /Catalog /Outlines 3 0 R >>\nendobj\n"+
+		"2 0 obj\n<< /Type /ObjStm /N 2 /First 9 /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream\nendobj\n"+
+		"trailer\n<< /Root 1 0 R >>\n%%%%EOF\n", compressed.Len(), compressed.String())
+
+	entries, err := parsePDF([]byte(buf), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Compressed"}, entries)
+}
+
+func TestParsePDFNoOutline(t *testing.T) {
+	buf := "%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+	_, err := parsePDF([]byte(buf), 10)
+
+	assert.Equal(t, errNoOutline, err)
+}
+
+func TestParsePDFInvalid(t *testing.T) {
+	_, err := parsePDF([]byte("not a PDF"), 10)
+
+	assert.Error(t, err)
+	assert.NotEqual(t, errNoOutline, err)
+}
+
+func TestParsePDFTooDeep(t *testing.T) {
+	buf := "%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Outlines " + strings.Repeat("[", 100000) + " >>\nendobj\n" +
+		"trailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+	_, err := (&pdfLexer{buf: []byte(buf), pos: strings.Index(buf, "<<")}).object()
+	assert.True(t, errors.Is(err, errTooDeep))
+
+	_, err = parsePDF([]byte(buf), 10)
+	assert.Error(t, err)
+}
+
+func TestParsePDFTooLarge(t *testing.T) {
+	// Compressed streams of 8MB each.
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	w.Write(bytes.Repeat([]byte(" "), 8*1024*1024))
+	w.Close()
+
+	buf := "%PDF-1.5\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+	for i := 0; i < maxInflatedSize/(8*1024*1024)+1; i++ {
+		buf += fmt.Sprintf("%d 0 obj\n<< /Type /ObjStm /N 0 /First 0 /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream\nendobj\n",
+			i+2, compressed.Len(), compressed.String())
+	}
+	buf += "trailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+	_, err := parsePDF([]byte(buf), 10)
+
+	assert.True(t, errors.Is(err, errTooLarge))
+}
@@ -0,0 +1,6 @@
+package extractor
+
+// Version of the extraction output, indexed with every extracted document.
+// Bump this whenever the output of extraction changes meaningfully, so that documents which predate the change can be
+// selected for re-crawling.
+const Version uint = 1
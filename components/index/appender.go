@@ -0,0 +1,14 @@
+package index
+
+import (
+	"context"
+)
+
+// Appender is implemented by indexes which can update documents in place, appending to array fields without
+// writing the arrays in full.
+type Appender interface {
+	// Append sets the fields in set and appends the values in add to the array fields of the document with id,
+	// skipping values already present. The reference count and popularity of the document are updated from the
+	// merged values.
+	Append(ctx context.Context, id string, set map[string]interface{}, add map[string][]interface{}) error
+}
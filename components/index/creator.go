@@ -0,0 +1,12 @@
+package index
+
+import (
+	"context"
+)
+
+// Creator is implemented by indexes which can create documents only when they don't exist yet, such that documents
+// indexed concurrently are not overwritten.
+type Creator interface {
+	// Create indexes the properties of a new document with id as Index, returning ErrConflict when it exists.
+	Create(ctx context.Context, id string, properties interface{}) error
+}
@@ -0,0 +1,110 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// BulkRetry configures retrying of bulk items which failed with a retryable status (e.g. 429 or 503).
+type BulkRetry struct {
+	MaxRetries uint          // Maximum number of retries per item; retryable failures are permanent when 0.
+	Backoff    time.Duration // Delay before the first retry, doubling on every subsequent retry.
+}
+
+// BulkCounts reports the outcome of bulk requests per item.
+type BulkCounts struct {
+	Succeeded int64 `json:"succeeded"` // Number of items written.
+	Retried   int64 `json:"retried"`   // Number of item retries.
+	Failed    int64 `json:"failed"`    // Number of items which failed permanently or after exhausting retries.
+}
+
+// BulkFailureHandler is called with the id of bulk items which failed permanently or kept failing after retries.
+// The error wraps the corresponding index error, e.g. `index.ErrMappingConflict` or `index.ErrIndexUnavailable`.
+type BulkFailureHandler func(id string, err error)
+
+// itemError returns the error for a failed bulk item, wrapped with the corresponding index error.
+func itemError(item *elastic.BulkResponseItem) error {
+	return wrapError(&elastic.Error{
+		Status:  item.Status,
+		Details: item.Error,
+	})
+}
+
+// bulkItemFailure is called with the position in the requests and the id of bulk items which failed permanently or
+// kept failing after retries.
+type bulkItemFailure func(pos int, id string, err error)
+
+// bulkDo writes reqs to indexName in a bulk request, parsing the result per item. Items which failed with a
+// retryable status are retried, without the successful items, with exponential backoff. Items which failed
+// permanently or exhausted their retries are passed to onFailure.
+// An error is only returned when a bulk request as a whole fails.
+func bulkDo(ctx context.Context, es *elastic.Client, indexName string, reqs []elastic.BulkableRequest,
+	retry BulkRetry, onFailure bulkItemFailure) (BulkCounts, error) {
+	var counts BulkCounts
+
+	backoff := retry.Backoff
+
+	// Positions of reqs in the original requests, as retried requests are a subset.
+	positions := make([]int, len(reqs))
+	for i := range positions {
+		positions[i] = i
+	}
+
+	for attempt := uint(0); len(reqs) > 0; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return counts, ctx.Err()
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+		}
+
+		res, err := es.Bulk().Index(indexName).Add(reqs...).Do(ctx)
+		if err != nil {
+			return counts, wrapError(err)
+		}
+
+		if len(res.Items) != len(reqs) {
+			// Items can't be matched to requests.
+			return counts, fmt.Errorf("unexpected bulk response: %d items for %d requests", len(res.Items), len(reqs))
+		}
+
+		var (
+			retryable          []elastic.BulkableRequest
+			retryablePositions []int
+		)
+
+		// Items in the response are in the order of the requests, with a single result per item.
+		for j, item := range res.Items {
+			for _, result := range item {
+				if result.Status >= 200 && result.Status < 300 {
+					counts.Succeeded++
+					continue
+				}
+
+				err := itemError(result)
+				if errors.Is(err, index.ErrIndexUnavailable) && attempt < retry.MaxRetries {
+					retryable = append(retryable, reqs[j])
+					retryablePositions = append(retryablePositions, positions[j])
+					continue
+				}
+
+				counts.Failed++
+				onFailure(positions[j], result.Id, err)
+			}
+		}
+
+		counts.Retried += int64(len(retryable))
+		reqs, positions = retryable, retryablePositions
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,180 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+type BulkTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+
+	// statuses returns the status for items by id, for subsequent attempts.
+	statuses map[string][]int
+	// requests records the ids in each bulk request.
+	requests   [][]string
+	requestsMu sync.Mutex
+
+	server *httptest.Server
+	es     *elastic.Client
+
+	failures  map[string]error
+	positions []int
+}
+
+func (s *BulkTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.statuses = make(map[string][]int)
+	s.requests = nil
+	s.failures = make(map[string]error)
+	s.positions = nil
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.handleBulk))
+
+	var err error
+	s.es, err = elastic.NewClient(
+		elastic.SetURL(s.server.URL),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheck(false),
+	)
+	s.Require().NoError(err)
+}
+
+func (s *BulkTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+// handleBulk mocks the Elasticsearch bulk API, responding with the next configured status for every item.
+func (s *BulkTestSuite) handleBulk(w http.ResponseWriter, r *http.Request) {
+	var (
+		ids   []string
+		items []map[string]interface{}
+	)
+
+	scanner := bufio.NewScanner(r.Body)
+	for action := true; scanner.Scan(); action = !action {
+		if !action {
+			// Document source
+			continue
+		}
+
+		var a map[string]struct {
+			ID string `json:"_id"`
+		}
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &a))
+
+		// Either an index or an update action.
+		var action, id string
+		for action = range a {
+			id = a[action].ID
+		}
+		ids = append(ids, id)
+
+		status := http.StatusCreated
+		if statuses := s.statuses[id]; len(statuses) > 0 {
+			status, s.statuses[id] = statuses[0], statuses[1:]
+		}
+
+		result := map[string]interface{}{
+			"_index": "test",
+			"_id":    id,
+			"status": status,
+		}
+
+		switch status {
+		case http.StatusBadRequest:
+			result["error"] = map[string]interface{}{"type": "mapper_parsing_exception", "reason": "failed to parse"}
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			result["error"] = map[string]interface{}{"type": "es_rejected_execution_exception", "reason": "rejected"}
+		}
+
+		items = append(items, map[string]interface{}{action: result})
+	}
+
+	s.requestsMu.Lock()
+	s.requests = append(s.requests, ids)
+	s.requestsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	s.NoError(json.NewEncoder(w).Encode(map[string]interface{}{
+		"took":   1,
+		"errors": true,
+		"items":  items,
+	}))
+}
+
+func (s *BulkTestSuite) onFailure(pos int, id string, err error) {
+	s.failures[id] = err
+	s.positions = append(s.positions, pos)
+}
+
+func (s *BulkTestSuite) bulkRequests(ids ...string) []elastic.BulkableRequest {
+	reqs := make([]elastic.BulkableRequest, len(ids))
+	for i, id := range ids {
+		reqs[i] = elastic.NewBulkIndexRequest().Id(id).Doc(map[string]string{"id": id})
+	}
+
+	return reqs
+}
+
+func (s *BulkTestSuite) TestPartialFailure() {
+	s.statuses["busy"] = []int{http.StatusTooManyRequests}
+	s.statuses["bad"] = []int{http.StatusBadRequest}
+
+	retry := BulkRetry{MaxRetries: 3, Backoff: time.Millisecond}
+
+	counts, err := bulkDo(s.ctx, s.es, "test", s.bulkRequests("ok", "busy", "bad"), retry, s.onFailure)
+
+	s.NoError(err)
+	s.Equal(BulkCounts{Succeeded: 2, Retried: 1, Failed: 1}, counts)
+
+	// Only the retryable item is retried.
+	s.Equal([][]string{{"ok", "busy", "bad"}, {"busy"}}, s.requests)
+
+	s.Len(s.failures, 1)
+	s.True(errors.Is(s.failures["bad"], index.ErrMappingConflict))
+	s.Equal([]int{2}, s.positions)
+}
+
+func (s *BulkTestSuite) TestRetriesExhausted() {
+	s.statuses["down"] = []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+
+	retry := BulkRetry{MaxRetries: 1, Backoff: time.Millisecond}
+
+	counts, err := bulkDo(s.ctx, s.es, "test", s.bulkRequests("ok", "down"), retry, s.onFailure)
+
+	s.NoError(err)
+	s.Equal(BulkCounts{Succeeded: 1, Retried: 1, Failed: 1}, counts)
+	s.Equal([][]string{{"ok", "down"}, {"down"}}, s.requests)
+
+	s.True(errors.Is(s.failures["down"], index.ErrIndexUnavailable))
+
+	// Retried items are reported at their original position.
+	s.Equal([]int{1}, s.positions)
+}
+
+func (s *BulkTestSuite) TestNoRequests() {
+	counts, err := bulkDo(s.ctx, s.es, "test", nil, BulkRetry{}, s.onFailure)
+
+	s.NoError(err)
+	s.Equal(BulkCounts{}, counts)
+	s.Empty(s.requests)
+}
+
+func TestBulkTestSuite(t *testing.T) {
+	suite.Run(t, new(BulkTestSuite))
+}
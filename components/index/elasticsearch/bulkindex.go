@@ -0,0 +1,283 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// defaultFlushTimeout bounds attempts of flushes of indexes without a request timeout.
+const defaultFlushTimeout = time.Minute
+
+// BulkConfig configures the flushing of pending writes of a BulkIndex, which happens as soon as any of the
+// thresholds is reached.
+type BulkConfig struct {
+	FlushBytes    int           // Size of the pending requests in bytes; disabled when 0.
+	FlushDocs     int           // Number of pending writes; disabled when 0.
+	FlushInterval time.Duration // Time since the first pending write, bounding the latency of writes.
+}
+
+// Validate the configuration, requiring a flush interval so that writes are never pending indefinitely.
+func (c *BulkConfig) Validate() error {
+	if c.FlushInterval <= 0 {
+		return errors.New("bulk flush interval should be positive")
+	}
+
+	if c.FlushBytes < 0 || c.FlushDocs < 0 {
+		return errors.New("bulk flush size and document count should not be negative")
+	}
+
+	return nil
+}
+
+// bulkWrite is a pending write, receiving its result on done.
+type bulkWrite struct {
+	req  elastic.BulkableRequest
+	done chan error
+}
+
+// BulkIndex wraps an Index, combining concurrent Index, Create and Update calls into bulk requests. Calls block until their
+// bulk request has been written, so that errors are returned as with Index and written documents can be retrieved
+// right away. Append, Get and versioned operations are not combined.
+type BulkIndex struct {
+	index   *Index
+	bulkCfg *BulkConfig
+
+	mu      sync.Mutex
+	pending []*bulkWrite
+	bytes   int
+	batch   uint64 // Incremented on every flush, so that timers only flush the batch they were started for.
+}
+
+// NewBulk returns a new index, writing in bulk.
+func NewBulk(es *elastic.Client, cfg *Config, bulkCfg *BulkConfig, i *instr.Instrumentation) index.Index {
+	return &BulkIndex{
+		index: &Index{
+			es:              es,
+			cfg:             cfg,
+			Instrumentation: i,
+		},
+		bulkCfg: bulkCfg,
+	}
+}
+
+// full returns whether the pending writes reached the document count or size threshold; b.mu must be held.
+func (b *BulkIndex) full() bool {
+	return (b.bulkCfg.FlushDocs > 0 && len(b.pending) >= b.bulkCfg.FlushDocs) ||
+		(b.bulkCfg.FlushBytes > 0 && b.bytes >= b.bulkCfg.FlushBytes)
+}
+
+// take returns and resets the pending writes, starting a new batch; b.mu must be held.
+func (b *BulkIndex) take() []*bulkWrite {
+	writes := b.pending
+
+	b.pending = nil
+	b.bytes = 0
+	b.batch++
+
+	return writes
+}
+
+// flushBatch flushes the pending writes when they are still of batch, after FlushInterval.
+func (b *BulkIndex) flushBatch(batch uint64) {
+	b.mu.Lock()
+	if b.batch != batch || len(b.pending) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	writes := b.take()
+	b.mu.Unlock()
+
+	b.flush(writes)
+}
+
+// flushTimeout returns the maximum duration of a flush: its attempts, each bounded by the request timeout, and the
+// backoff between them.
+func (b *BulkIndex) flushTimeout() time.Duration {
+	timeout := b.index.cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultFlushTimeout
+	}
+
+	total, backoff := timeout, b.index.cfg.RetryBackoff
+	for retry := uint(0); retry < b.index.cfg.MaxRetries; retry++ {
+		total += backoff + timeout
+		backoff *= 2
+	}
+
+	return total
+}
+
+// flush writes in a single bulk request, sending the result of every write to its caller.
+func (b *BulkIndex) flush(writes []*bulkWrite) {
+	// Writes of multiple callers are flushed together, hence none of their contexts apply; bound it instead, so that
+	// a hanging request doesn't block all of them indefinitely.
+	ctx, cancel := context.WithTimeout(context.Background(), b.flushTimeout())
+	defer cancel()
+
+	ctx, span := b.index.Tracer.Start(ctx, "index.elasticsearch.BulkIndex.flush",
+		trace.WithAttributes(label.Int64("writes", int64(len(writes)))),
+	)
+	defer span.End()
+
+	reqs := make([]elastic.BulkableRequest, len(writes))
+	for i, w := range writes {
+		reqs[i] = w.req
+	}
+
+	retry := BulkRetry{
+		MaxRetries: b.index.cfg.MaxRetries,
+		Backoff:    b.index.cfg.RetryBackoff,
+	}
+
+	// By position, as a batch might contain multiple writes of a document.
+	failures := make([]error, len(writes))
+	_, err := bulkDo(ctx, b.index.es, b.index.cfg.Name, reqs, retry, func(pos int, _ string, err error) {
+		failures[pos] = err
+	})
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	for i, w := range writes {
+		if err != nil {
+			w.done <- err
+			continue
+		}
+
+		w.done <- failures[i]
+	}
+}
+
+// write adds req to the pending writes, flushing them when full, and waits for its result.
+func (b *BulkIndex) write(ctx context.Context, req elastic.BulkableRequest) error {
+	lines, err := req.Source()
+	if err != nil {
+		return err
+	}
+
+	size := 0
+	for _, line := range lines {
+		size += len(line) + 1 // Newline delimited.
+	}
+
+	w := &bulkWrite{req, make(chan error, 1)}
+
+	b.mu.Lock()
+
+	b.pending = append(b.pending, w)
+	b.bytes += size
+
+	if len(b.pending) == 1 {
+		batch := b.batch
+		time.AfterFunc(b.bulkCfg.FlushInterval, func() { b.flushBatch(batch) })
+	}
+
+	var writes []*bulkWrite
+	if b.full() {
+		writes = b.take()
+	}
+
+	b.mu.Unlock()
+
+	if writes != nil {
+		b.flush(writes)
+	}
+
+	select {
+	case err := <-w.done:
+		return err
+	case <-ctx.Done():
+		// The write might still succeed.
+		return ctx.Err()
+	}
+}
+
+// Index a document's properties, identified by id, as part of a bulk request.
+func (b *BulkIndex) Index(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := b.index.Tracer.Start(ctx, "index.elasticsearch.BulkIndex.Index")
+	defer span.End()
+
+	err := b.write(ctx, elastic.NewBulkIndexRequest().Index(b.index.name(id)).Id(id).Doc(properties))
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// Create indexes a document's properties provided it doesn't exist yet, as part of a bulk request.
+func (b *BulkIndex) Create(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := b.index.Tracer.Start(ctx, "index.elasticsearch.BulkIndex.Create")
+	defer span.End()
+
+	err := b.write(ctx, elastic.NewBulkIndexRequest().OpType("create").Index(b.index.name(id)).Id(id).Doc(properties))
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// Update a document's properties, given id, as part of a bulk request.
+func (b *BulkIndex) Update(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := b.index.Tracer.Start(ctx, "index.elasticsearch.BulkIndex.Update")
+	defer span.End()
+
+	err := b.write(ctx, elastic.NewBulkUpdateRequest().Index(b.index.name(id)).Id(id).Doc(properties))
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// Append sets fields and appends values to array fields of a document in place, right away.
+func (b *BulkIndex) Append(ctx context.Context, id string, set map[string]interface{}, add map[string][]interface{}) error {
+	return b.index.Append(ctx, id, set, add)
+}
+
+// Get retreives `fields` from document with `id` from the index, as with Index.
+func (b *BulkIndex) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	return b.index.Get(ctx, id, dst, fields...)
+}
+
+// GetVersioned retrieves `fields` from document with `id` and its version, as with Index.
+func (b *BulkIndex) GetVersioned(ctx context.Context, id string, dst interface{}, fields ...string) (*index.Version, error) {
+	return b.index.GetVersioned(ctx, id, dst, fields...)
+}
+
+// UpdateVersioned updates a document's properties provided it is still at version v, right away.
+func (b *BulkIndex) UpdateVersioned(ctx context.Context, id string, properties interface{}, v *index.Version) error {
+	return b.index.UpdateVersioned(ctx, id, properties, v)
+}
+
+// Exists returns which of the documents with ids exist, as with Index. Pending writes are not taken into account.
+func (b *BulkIndex) Exists(ctx context.Context, ids []string) (map[string]bool, error) {
+	return b.index.Exists(ctx, ids)
+}
+
+// String returns the name of the index, for convenient logging.
+func (b *BulkIndex) String() string {
+	return b.index.String()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var (
+	_ index.Index     = &BulkIndex{}
+	_ index.Appender  = &BulkIndex{}
+	_ index.Versioned = &BulkIndex{}
+	_ index.Creator   = &BulkIndex{}
+	_ index.Exister   = &BulkIndex{}
+)
@@ -0,0 +1,180 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+func (s *BulkTestSuite) newBulkIndex(cfg *BulkConfig) *BulkIndex {
+	return NewBulk(s.es, &Config{Name: "test"}, cfg, instr.New()).(*BulkIndex)
+}
+
+// bulkRequestIDs returns the ids in each bulk request made.
+func (s *BulkTestSuite) bulkRequestIDs() [][]string {
+	s.requestsMu.Lock()
+	defer s.requestsMu.Unlock()
+
+	return s.requests
+}
+
+// waitPending waits until b has n pending writes.
+func (s *BulkTestSuite) waitPending(b *BulkIndex, n int) {
+	s.Eventually(func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		return len(b.pending) == n
+	}, time.Second, time.Millisecond)
+}
+
+// indexAsync indexes a document with id and properties in the background, returning a channel with the result.
+func (s *BulkTestSuite) indexAsync(b *BulkIndex, id string, properties interface{}) <-chan error {
+	result := make(chan error, 1)
+
+	go func() {
+		result <- b.Index(s.ctx, id, properties)
+	}()
+
+	return result
+}
+
+func (s *BulkTestSuite) TestBulkIndexFlushDocs() {
+	b := s.newBulkIndex(&BulkConfig{FlushDocs: 2, FlushInterval: time.Hour})
+
+	first := s.indexAsync(b, "first", map[string]string{"id": "first"})
+	s.waitPending(b, 1)
+
+	// The second write fills the batch, flushing both.
+	s.NoError(b.Update(s.ctx, "second", map[string]string{"id": "second"}))
+	s.NoError(<-first)
+
+	s.Equal([][]string{{"first", "second"}}, s.bulkRequestIDs())
+}
+
+func (s *BulkTestSuite) TestBulkIndexFlushBytes() {
+	b := s.newBulkIndex(&BulkConfig{FlushBytes: 1024, FlushInterval: time.Hour})
+
+	small := s.indexAsync(b, "small", map[string]string{"text": "small"})
+	s.waitPending(b, 1)
+
+	// The large document exceeds the size, flushing both.
+	s.NoError(b.Index(s.ctx, "large", map[string]string{"text": strings.Repeat("large", 1024)}))
+	s.NoError(<-small)
+
+	s.Equal([][]string{{"small", "large"}}, s.bulkRequestIDs())
+}
+
+func (s *BulkTestSuite) TestBulkIndexFlushInterval() {
+	b := s.newBulkIndex(&BulkConfig{FlushBytes: 1024 * 1024, FlushDocs: 100, FlushInterval: 10 * time.Millisecond})
+
+	start := time.Now()
+	s.NoError(b.Index(s.ctx, "only", map[string]string{"id": "only"}))
+
+	s.True(time.Since(start) >= 10*time.Millisecond)
+	s.Equal([][]string{{"only"}}, s.bulkRequestIDs())
+
+	// A new batch is flushed after its own interval.
+	s.NoError(b.Index(s.ctx, "next", map[string]string{"id": "next"}))
+	s.Equal([][]string{{"only"}, {"next"}}, s.bulkRequestIDs())
+}
+
+func (s *BulkTestSuite) TestBulkIndexItemFailure() {
+	s.statuses["bad"] = []int{http.StatusBadRequest}
+
+	b := s.newBulkIndex(&BulkConfig{FlushDocs: 2, FlushInterval: time.Hour})
+
+	var (
+		wg        sync.WaitGroup
+		okErr     error
+		failedErr error
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		okErr = b.Index(s.ctx, "ok", map[string]string{"id": "ok"})
+	}()
+	go func() {
+		defer wg.Done()
+		failedErr = b.Index(s.ctx, "bad", map[string]string{"id": "bad"})
+	}()
+	wg.Wait()
+
+	// Only the failed item returns an error.
+	s.NoError(okErr)
+	s.True(errors.Is(failedErr, index.ErrMappingConflict))
+	s.Len(s.bulkRequestIDs(), 1)
+}
+
+func (s *BulkTestSuite) TestBulkIndexCreateConflict() {
+	s.statuses["dup"] = []int{http.StatusConflict}
+
+	b := s.newBulkIndex(&BulkConfig{FlushDocs: 1, FlushInterval: time.Hour})
+
+	err := b.Create(s.ctx, "dup", map[string]string{"id": "dup"})
+
+	s.True(errors.Is(err, index.ErrConflict))
+	s.Equal([][]string{{"dup"}}, s.bulkRequestIDs())
+}
+
+func (s *BulkTestSuite) TestBulkIndexRetry() {
+	s.statuses["busy"] = []int{http.StatusTooManyRequests}
+
+	cfg := &Config{Name: "test", MaxRetries: 1, RetryBackoff: time.Millisecond}
+	b := NewBulk(s.es, cfg, &BulkConfig{FlushDocs: 1, FlushInterval: time.Hour}, instr.New())
+
+	// Items failing transiently are retried as configured for the index.
+	s.NoError(b.Index(s.ctx, "busy", map[string]string{"id": "busy"}))
+	s.Equal([][]string{{"busy"}, {"busy"}}, s.bulkRequestIDs())
+}
+
+func (s *BulkTestSuite) TestBulkIndexSameDocument() {
+	// Only the first write of the document fails.
+	s.statuses["same"] = []int{http.StatusBadRequest}
+
+	b := s.newBulkIndex(&BulkConfig{FlushDocs: 2, FlushInterval: time.Hour})
+
+	first := s.indexAsync(b, "same", map[string]string{"id": "first"})
+	s.waitPending(b, 1)
+	second := s.indexAsync(b, "same", map[string]string{"id": "second"})
+
+	// Results are matched to writes by position rather than by id.
+	s.True(errors.Is(<-first, index.ErrMappingConflict))
+	s.NoError(<-second)
+	s.Equal([][]string{{"same", "same"}}, s.bulkRequestIDs())
+}
+
+func (s *BulkTestSuite) TestBulkIndexFlushTimeout() {
+	cfg := &Config{Name: "test", Timeout: time.Second, MaxRetries: 2, RetryBackoff: 100 * time.Millisecond}
+	b := NewBulk(s.es, cfg, &BulkConfig{FlushInterval: time.Second}, instr.New()).(*BulkIndex)
+
+	// Three attempts and two backoffs.
+	s.Equal(3300*time.Millisecond, b.flushTimeout())
+
+	// Bounded without a request timeout as well.
+	cfg.Timeout = 0
+	s.Equal(3*defaultFlushTimeout+300*time.Millisecond, b.flushTimeout())
+}
+
+func (s *BulkTestSuite) TestBulkIndexContextDone() {
+	b := s.newBulkIndex(&BulkConfig{FlushInterval: time.Hour})
+
+	ctx, cancel := context.WithTimeout(s.ctx, time.Millisecond)
+	defer cancel()
+
+	s.Equal(context.DeadlineExceeded, b.Index(ctx, "pending", map[string]string{"id": "pending"}))
+	s.Empty(s.bulkRequestIDs())
+}
+
+func (s *BulkTestSuite) TestBulkConfigValidate() {
+	s.NoError((&BulkConfig{FlushInterval: time.Second}).Validate())
+	s.Error((&BulkConfig{FlushDocs: 100}).Validate())
+	s.Error((&BulkConfig{FlushDocs: -1, FlushInterval: time.Second}).Validate())
+}
@@ -1,6 +1,13 @@
 package elasticsearch
 
+import "time"
+
 // Config represents the configuration for an Elasticsearch index.
 type Config struct {
-	Name string
+	Name     string
+	Sharding ShardConfig // Route documents to shards of the index, by ID; a single index by default.
+
+	Timeout      time.Duration // Timeout per request attempt; disabled when 0.
+	MaxRetries   uint          // Maximum number of retries of requests failing transiently; no retries when 0.
+	RetryBackoff time.Duration // Delay before the first retry, doubling on every subsequent retry.
 }
@@ -0,0 +1,77 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// mappingErrorTypes are Elasticsearch error types signifying documents not matching the mapping.
+var mappingErrorTypes = map[string]bool{
+	"mapper_parsing_exception":         true,
+	"strict_dynamic_mapping_exception": true,
+	"illegal_argument_exception":       true,
+	"document_parsing_exception":       true,
+}
+
+// connErrors are errors of connections to Elasticsearch which are transient, e.g. when a node restarts.
+var connErrors = []error{
+	elastic.ErrNoClient,
+	syscall.ECONNRESET,
+	syscall.ECONNREFUSED,
+	syscall.EPIPE,
+	io.ErrUnexpectedEOF,
+}
+
+// isConnError returns true when err is a (transient) connection error.
+func isConnError(err error) bool {
+	if elastic.IsConnErr(err) {
+		return true
+	}
+
+	for _, connErr := range connErrors {
+		if errors.Is(err, connErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wrapError wraps errors from Elasticsearch with the corresponding index error, based on the response status.
+// Errors which cannot be classified are returned as-is.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if isConnError(err) {
+		return fmt.Errorf("%w: %v", index.ErrIndexUnavailable, err)
+	}
+
+	e, ok := err.(*elastic.Error)
+	if !ok {
+		return err
+	}
+
+	switch e.Status {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %v", index.ErrNotFound, err)
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %v", index.ErrConflict, err)
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: %v", index.ErrIndexUnavailable, err)
+	case http.StatusBadRequest:
+		if e.Details != nil && mappingErrorTypes[e.Details.Type] {
+			return fmt.Errorf("%w: %v", index.ErrMappingConflict, err)
+		}
+	}
+
+	return err
+}
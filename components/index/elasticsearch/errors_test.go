@@ -0,0 +1,93 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"not found", &elastic.Error{Status: 404}, index.ErrNotFound},
+		{"conflict", &elastic.Error{Status: 409}, index.ErrConflict},
+		{"too many requests", &elastic.Error{Status: 429}, index.ErrIndexUnavailable},
+		{"bad gateway", &elastic.Error{Status: 502}, index.ErrIndexUnavailable},
+		{"service unavailable", &elastic.Error{Status: 503}, index.ErrIndexUnavailable},
+		{"gateway timeout", &elastic.Error{Status: 504}, index.ErrIndexUnavailable},
+		{"mapper parsing", &elastic.Error{
+			Status:  400,
+			Details: &elastic.ErrorDetails{Type: "mapper_parsing_exception"},
+		}, index.ErrMappingConflict},
+		{"strict dynamic mapping", &elastic.Error{
+			Status:  400,
+			Details: &elastic.ErrorDetails{Type: "strict_dynamic_mapping_exception"},
+		}, index.ErrMappingConflict},
+		{"connection", elastic.ErrNoClient, index.ErrIndexUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.Is(wrapError(tt.err), tt.want))
+		})
+	}
+}
+
+func TestWrapErrorUnclassified(t *testing.T) {
+	unknown := &elastic.Error{
+		Status:  400,
+		Details: &elastic.ErrorDetails{Type: "parsing_exception"},
+	}
+	assert.Equal(t, unknown, wrapError(unknown))
+
+	other := errors.New("other")
+	assert.Equal(t, other, wrapError(other))
+
+	assert.NoError(t, wrapError(nil))
+}
+
+func TestIsConnError(t *testing.T) {
+	reset := &url.Error{
+		Op:  "Post",
+		URL: "http://localhost:9200/_bulk",
+		Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"no client", elastic.ErrNoClient, true},
+		{"wrapped no client", fmt.Errorf("search: %w", elastic.ErrNoClient), true},
+		{"connection reset", reset, true},
+		{"wrapped connection reset", fmt.Errorf("bulk: %w", reset), true},
+		{"connection refused", os.NewSyscallError("connect", syscall.ECONNREFUSED), true},
+		{"unexpected EOF", fmt.Errorf("decoding: %w", io.ErrUnexpectedEOF), true},
+		{"status", &elastic.Error{Status: 503}, false},
+		{"other", errors.New("other"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isConnError(tt.err))
+
+			if tt.want {
+				assert.True(t, errors.Is(wrapError(tt.err), index.ErrIndexUnavailable))
+			}
+		})
+	}
+}
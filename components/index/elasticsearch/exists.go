@@ -0,0 +1,54 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/olivere/elastic/v7"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// Exists returns which of the documents with ids exist, with a single multi get request without their source.
+// Shards which have not been created yet hold no documents.
+func (i *Index) Exists(ctx context.Context, ids []string) (map[string]bool, error) {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.Exists")
+	defer span.End()
+
+	items := make([]*elastic.MultiGetItem, len(ids))
+	for n, id := range ids {
+		items[n] = elastic.NewMultiGetItem().
+			Index(i.name(id)).
+			Id(id).
+			FetchSource(elastic.NewFetchSourceContext(false))
+	}
+
+	var result *elastic.MgetResponse
+
+	err := i.do(ctx, func(ctx context.Context) (err error) {
+		result, err = i.es.Mget().Add(items...).Do(ctx)
+		return
+	})
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	exists := make(map[string]bool, len(ids))
+
+	for _, doc := range result.Docs {
+		if doc.Error != nil && doc.Error.Type != "index_not_found_exception" {
+			err := fmt.Errorf("%w: looking up '%s': %s", index.ErrIndexUnavailable, doc.Id, doc.Error.Reason)
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return nil, err
+		}
+
+		exists[doc.Id] = doc.Found
+	}
+
+	return exists, nil
+}
@@ -3,6 +3,8 @@ package elasticsearch
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+
 	"github.com/olivere/elastic/v7"
 
 	"go.opentelemetry.io/otel/api/trace"
@@ -34,24 +36,54 @@ func (i *Index) String() string {
 	return i.cfg.Name
 }
 
-// Index a document's properties, identified by id
+// name returns the name of the index (shard) holding the document with id.
+func (i *Index) name(id string) string {
+	return i.cfg.Sharding.Shard(i.cfg.Name, id)
+}
+
+// Index a document's properties, identified by id.
+// Errors are wrapped with the corresponding index error (e.g. `index.ErrIndexUnavailable`) where possible.
 func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
 	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.Index")
 	defer span.End()
 
-	_, err := i.es.Index().
-		Index(i.cfg.Name).
-		Id(id).
-		BodyJson(properties).
-		Do(ctx)
+	err := i.do(ctx, func(ctx context.Context) error {
+		_, err := i.es.Index().
+			Index(i.name(id)).
+			Id(id).
+			BodyJson(properties).
+			Do(ctx)
+		return err
+	})
 
 	if err != nil {
-		// Handle error
-		return err
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
 	}
 
-	return nil
+	return err
+}
+
+// Create indexes a document's properties as Index, provided no document with id exists yet.
+// Returns `index.ErrConflict` when it does.
+func (i *Index) Create(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.Create")
+	defer span.End()
+
+	err := i.do(ctx, func(ctx context.Context) error {
+		_, err := i.es.Index().
+			Index(i.name(id)).
+			Id(id).
+			OpType("create").
+			BodyJson(properties).
+			Do(ctx)
+		return err
+	})
 
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
 }
 
 // Update a document's properties, given id
@@ -59,11 +91,71 @@ func (i *Index) Update(ctx context.Context, id string, properties interface{}) e
 	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.Update")
 	defer span.End()
 
-	_, err := i.es.Update().
-		Index(i.cfg.Name).
-		Id(id).
-		Doc(properties).
-		Do(ctx)
+	err := i.do(ctx, func(ctx context.Context) error {
+		_, err := i.es.Update().
+			Index(i.name(id)).
+			Id(id).
+			Doc(properties).
+			Do(ctx)
+		return err
+	})
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// appendScript sets fields and appends values to array fields, skipping values already present. The reference count and
+// popularity are derived from the merged fields, as in the crawler, so that concurrent appends are all counted.
+const appendScript = `
+for (def field : params.set.entrySet()) {
+	ctx._source[field.getKey()] = field.getValue();
+}
+for (def field : params.add.entrySet()) {
+	def values = ctx._source[field.getKey()];
+	if (values == null) {
+		values = new ArrayList();
+		ctx._source[field.getKey()] = values;
+	}
+	for (def value : field.getValue()) {
+		if (!values.contains(value)) {
+			values.add(value);
+		}
+	}
+}
+def parents = new HashSet();
+if (ctx._source.references != null) {
+	for (def ref : ctx._source.references) {
+		parents.add(ref.parent_hash);
+	}
+}
+def names = ctx._source.ipns_names == null ? 0 : ctx._source.ipns_names.size();
+ctx._source.reference_count = parents.size();
+ctx._source.popularity = Math.log1p(parents.size() + names);`
+
+// Append sets fields and appends values to array fields of a document in place, using a script, so that arrays
+// are neither sent in full nor lost in concurrent updates. The reference count and popularity are updated as well.
+func (i *Index) Append(ctx context.Context, id string, set map[string]interface{}, add map[string][]interface{}) error {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.Append")
+	defer span.End()
+
+	script := elastic.NewScript(appendScript).
+		Lang("painless").
+		Params(map[string]interface{}{
+			"set": set,
+			"add": add,
+		})
+
+	err := i.do(ctx, func(ctx context.Context) error {
+		_, err := i.es.Update().
+			Index(i.name(id)).
+			Id(id).
+			Script(script).
+			Do(ctx)
+		return err
+	})
 
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
@@ -72,6 +164,38 @@ func (i *Index) Update(ctx context.Context, id string, properties interface{}) e
 	return err
 }
 
+// get retrieves `fields` from document with `id` into dst, returning a nil result when not found.
+func (i *Index) get(ctx context.Context, id string, dst interface{}, fields []string) (*elastic.GetResult, error) {
+	fsc := elastic.NewFetchSourceContext(true)
+	fsc.Include(fields...)
+
+	var result *elastic.GetResult
+
+	err := i.do(ctx, func(ctx context.Context) (err error) {
+		result, err = i.es.
+			Get().
+			Index(i.name(id)).
+			FetchSourceContext(fsc).
+			Id(id).
+			Do(ctx)
+
+		if elastic.IsNotFound(err) {
+			// 404
+			result, err = nil, nil
+		}
+
+		return
+	})
+
+	if err != nil || result == nil {
+		// Not found or other error, propagate
+		return nil, err
+	}
+
+	// Found; decode resulting field json into `dst`
+	return result, json.Unmarshal(result.Source, dst)
+}
+
 // Get retreives `fields` from document with `id` from the index, returning:
 // - (true, decoding_error) if found (decoding error set when errors in json)
 // - (false, nil) when not found
@@ -80,40 +204,69 @@ func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...s
 	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.Get")
 	defer span.End()
 
-	fsc := elastic.NewFetchSourceContext(true)
-	fsc.Include(fields...)
+	result, err := i.get(ctx, id, dst, fields)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
 
-	result, err := i.es.
-		Get().
-		Index(i.cfg.Name).
-		FetchSourceContext(fsc).
-		Id(id).
-		Do(ctx)
+	return result != nil, err
+}
 
-	switch {
-	case err == nil:
-		// Found
+// GetVersioned retreives `fields` from document with `id` as Get, returning its sequence number and primary term
+// or nil when not found.
+func (i *Index) GetVersioned(ctx context.Context, id string, dst interface{}, fields ...string) (*index.Version, error) {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.GetVersioned")
+	defer span.End()
 
-		// Decode resulting field json into `dst`
-		err = json.Unmarshal(result.Source, dst)
+	result, err := i.get(ctx, id, dst, fields)
+	if err == nil && result != nil && (result.SeqNo == nil || result.PrimaryTerm == nil) {
+		err = fmt.Errorf("no sequence number or primary term for '%s'", id)
+	}
 
-		if err != nil {
-			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		}
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, nil
+	}
 
-		return true, err
-	case elastic.IsNotFound(err):
-		// 404
-		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Ok))
+	return &index.Version{
+		SeqNo:       *result.SeqNo,
+		PrimaryTerm: *result.PrimaryTerm,
+	}, nil
+}
 
-		return false, nil
+// UpdateVersioned updates a document's properties, given id, provided its sequence number and primary term still
+// match v. Otherwise Elasticsearch rejects the update and index.ErrConflict is returned.
+func (i *Index) UpdateVersioned(ctx context.Context, id string, properties interface{}, v *index.Version) error {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.UpdateVersioned")
+	defer span.End()
+
+	err := i.do(ctx, func(ctx context.Context) error {
+		_, err := i.es.Update().
+			Index(i.name(id)).
+			Id(id).
+			Doc(properties).
+			IfSeqNo(v.SeqNo).
+			IfPrimaryTerm(v.PrimaryTerm).
+			Do(ctx)
+		return err
+	})
 
-	default:
-		// Unknown error, propagate
+	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-		return false, err
 	}
+
+	return err
 }
 
 // Compile-time assurance that implementation satisfies interface.
-var _ index.Index = &Index{}
+var (
+	_ index.Index     = &Index{}
+	_ index.Appender  = &Index{}
+	_ index.Versioned = &Index{}
+	_ index.Creator   = &Index{}
+	_ index.Exister   = &Index{}
+)
@@ -1,4 +1,211 @@
 package elasticsearch
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
 // TODO: Test whether indexed items with omitempty are actually left out - otherwise
 // non-updating references will overwrite the existing!
+
+type IndexTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+
+	// request records the path, query and body of the last request.
+	path  string
+	query url.Values
+	body  map[string]interface{}
+
+	// status and response are returned for requests.
+	status   int
+	response string
+
+	server *httptest.Server
+	i      *Index
+}
+
+func (s *IndexTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.body = nil
+	s.status = http.StatusOK
+	s.response = `{"_index": "test", "_id": "id", "result": "updated"}`
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.path = r.URL.Path
+		s.query = r.URL.Query()
+		if r.Method != http.MethodGet {
+			s.Require().NoError(json.NewDecoder(r.Body).Decode(&s.body))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.status)
+		w.Write([]byte(s.response))
+	}))
+
+	es, err := elastic.NewClient(
+		elastic.SetURL(s.server.URL),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheck(false),
+	)
+	s.Require().NoError(err)
+
+	s.i = New(es, &Config{Name: "test"}, instr.New()).(*Index)
+}
+
+func (s *IndexTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *IndexTestSuite) TestAppend() {
+	err := s.i.Append(s.ctx, "id",
+		map[string]interface{}{"last-seen": "2020-01-01T00:00:00Z"},
+		map[string][]interface{}{"source": {"test"}},
+	)
+
+	s.NoError(err)
+	s.Equal("/test/_update/id", s.path)
+
+	// Only the new values are sent, to the script.
+	script := s.body["script"].(map[string]interface{})
+	// The client trims the script.
+	s.Equal(strings.TrimSpace(appendScript), script["source"])
+	s.Equal(map[string]interface{}{
+		"set": map[string]interface{}{"last-seen": "2020-01-01T00:00:00Z"},
+		"add": map[string]interface{}{"source": []interface{}{"test"}},
+	}, script["params"])
+	s.NotContains(s.body, "doc")
+}
+
+func (s *IndexTestSuite) TestGetVersioned() {
+	s.response = `{"_index": "test", "_id": "id", "_seq_no": 7, "_primary_term": 2, "found": true,
+		"_source": {"references": [{"parent_hash": "parent", "name": "name"}]}}`
+
+	dst := new(struct {
+		References []map[string]string `json:"references"`
+	})
+
+	v, err := s.i.GetVersioned(s.ctx, "id", dst, "references")
+
+	s.NoError(err)
+	s.Equal("/test/_doc/id", s.path)
+	s.Equal(&index.Version{SeqNo: 7, PrimaryTerm: 2}, v)
+	s.Equal([]map[string]string{{"parent_hash": "parent", "name": "name"}}, dst.References)
+}
+
+func (s *IndexTestSuite) TestGetVersionedNotFound() {
+	s.status = http.StatusNotFound
+	s.response = `{"_index": "test", "_id": "id", "found": false}`
+
+	v, err := s.i.GetVersioned(s.ctx, "id", new(struct{}), "references")
+
+	s.NoError(err)
+	s.Nil(v)
+}
+
+func (s *IndexTestSuite) TestUpdateVersioned() {
+	err := s.i.UpdateVersioned(s.ctx, "id", map[string]interface{}{"last-seen": "2020-01-01T00:00:00Z"},
+		&index.Version{SeqNo: 7, PrimaryTerm: 2})
+
+	s.NoError(err)
+	s.Equal("/test/_update/id", s.path)
+	s.Equal("7", s.query.Get("if_seq_no"))
+	s.Equal("2", s.query.Get("if_primary_term"))
+	s.Equal(map[string]interface{}{"last-seen": "2020-01-01T00:00:00Z"}, s.body["doc"])
+}
+
+func (s *IndexTestSuite) TestUpdateVersionedConflict() {
+	s.status = http.StatusConflict
+	s.response = `{"error": {"type": "version_conflict_engine_exception", "reason": "version conflict"}, "status": 409}`
+
+	err := s.i.UpdateVersioned(s.ctx, "id", map[string]interface{}{}, &index.Version{SeqNo: 7, PrimaryTerm: 2})
+
+	s.True(errors.Is(err, index.ErrConflict))
+}
+
+func (s *IndexTestSuite) TestCreate() {
+	s.response = `{"_index": "test", "_id": "id", "result": "created"}`
+
+	err := s.i.Create(s.ctx, "id", map[string]interface{}{"cid": "id"})
+
+	s.NoError(err)
+	s.Equal("/test/_doc/id", s.path)
+	s.Equal("create", s.query.Get("op_type"))
+	s.Equal(map[string]interface{}{"cid": "id"}, s.body)
+}
+
+func (s *IndexTestSuite) TestCreateConflict() {
+	s.status = http.StatusConflict
+	s.response = `{"error": {"type": "version_conflict_engine_exception", "reason": "document already exists"}, "status": 409}`
+
+	err := s.i.Create(s.ctx, "id", map[string]interface{}{"cid": "id"})
+
+	s.True(errors.Is(err, index.ErrConflict))
+}
+
+func (s *IndexTestSuite) TestSharded() {
+	s.i.cfg = &Config{Name: "test", Sharding: ShardConfig{Strategy: ShardPrefix, PrefixLength: 2}}
+
+	s.NoError(s.i.Index(s.ctx, shardCIDv0, map[string]interface{}{"size": 1}))
+	s.Equal("/test-bf/_doc/"+shardCIDv0, s.path)
+
+	// Documents are read from the same shard.
+	s.response = `{"_index": "test-bf", "_id": "id", "found": true, "_source": {}}`
+
+	found, err := s.i.Get(s.ctx, shardCIDv1, new(struct{}), "references")
+	s.NoError(err)
+	s.True(found)
+	s.Equal("/test-bf/_doc/"+shardCIDv1, s.path)
+}
+
+func (s *IndexTestSuite) TestExists() {
+	s.response = `{"docs": [
+		{"_index": "test", "_id": "a", "found": true},
+		{"_index": "test", "_id": "b", "found": false}
+	]}`
+
+	exists, err := s.i.Exists(s.ctx, []string{"a", "b"})
+
+	s.NoError(err)
+	s.Equal(map[string]bool{"a": true, "b": false}, exists)
+	s.Equal("/_mget", s.path)
+}
+
+func (s *IndexTestSuite) TestExistsShardNotFound() {
+	s.response = `{"docs": [
+		{"_index": "test-bf", "_id": "a", "error": {"type": "index_not_found_exception", "reason": "no such index"}}
+	]}`
+
+	exists, err := s.i.Exists(s.ctx, []string{"a"})
+
+	s.NoError(err)
+	s.False(exists["a"])
+}
+
+func (s *IndexTestSuite) TestExistsError() {
+	s.response = `{"docs": [
+		{"_index": "test", "_id": "a", "error": {"type": "no_shard_available_action_exception", "reason": "no shard"}}
+	]}`
+
+	_, err := s.i.Exists(s.ctx, []string{"a"})
+
+	s.True(errors.Is(err, index.ErrIndexUnavailable))
+}
+
+func TestIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexTestSuite))
+}
@@ -0,0 +1,404 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+var logger = logging.New("indexer")
+
+// Transform transforms the source of a document with the given id for the destination index.
+// Returning a nil source skips the document.
+type Transform func(id string, source json.RawMessage) (json.RawMessage, error)
+
+// ReindexOptions configures Reindex.
+type ReindexOptions struct {
+	Source      string        // Index to read documents from.
+	Destination string        // Index to write documents to.
+	BatchSize   int           // Number of documents per scroll page and bulk request.
+	KeepAlive   time.Duration // Time to keep the scroll context alive between pages.
+	StateFile   string        // File persisting the scroll, allowing to resume; not resumable when empty.
+	Transform   Transform     // Transform applied to documents; copied as-is when nil.
+	Workers     int           // Number of pages written concurrently; 1 when 0.
+	RateLimit   float64       // Maximum bulk requests per second, across workers; unlimited when 0.
+
+	Retry     BulkRetry          // Retrying of documents which failed to be written with a retryable status.
+	OnFailure BulkFailureHandler // Called for documents which could not be written; logged when nil.
+}
+
+// ReindexProgress reports the progress of Reindex.
+type ReindexProgress struct {
+	Total     int64 `json:"total"`     // Total number of documents in the source index.
+	Processed int64 `json:"processed"` // Number of documents read from the source index.
+	Skipped   int64 `json:"skipped"`   // Number of documents skipped by the transform.
+	Retried   int64 `json:"retried"`   // Number of retries of documents which failed to be written.
+	Failed    int64 `json:"failed"`    // Number of documents which could not be written.
+}
+
+// String returns a human-readable representation of progress, for logging.
+func (p ReindexProgress) String() string {
+	return fmt.Sprintf("%d/%d documents processed, %d skipped, %d retried, %d failed", p.Processed, p.Total, p.Skipped, p.Retried, p.Failed)
+}
+
+// logFailure is the default BulkFailureHandler for Reindex.
+func logFailure(id string, err error) {
+	logger.Warnf("Error reindexing %s: %v", id, err)
+}
+
+// reindexDocument is a document read from the source index.
+type reindexDocument struct {
+	ID     string          `json:"id"`
+	Source json.RawMessage `json:"source"`
+}
+
+// reindexState is persisted to the state file after each step, allowing Reindex to resume.
+type reindexState struct {
+	ScrollID string            `json:"scroll_id"`
+	Pending  []reindexDocument `json:"pending,omitempty"` // Pages read from the scroll but not yet written.
+	ReindexProgress
+}
+
+// loadReindexState reads state from file, returning an empty state if it does not exist.
+func loadReindexState(file string) (*reindexState, error) {
+	s := new(reindexState)
+
+	if file == "" {
+		return s, nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid reindex state in %s: %w", file, err)
+	}
+
+	return s, nil
+}
+
+// save atomically writes state to file; a noop when file is empty.
+func (s *reindexState) save(file string) error {
+	if file == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, file)
+}
+
+// Reindex copies documents from the source to the destination index, through scrolling and bulk requests,
+// applying the transform. Pages are read from a single scroll and written by Workers concurrent workers, sharing the
+// rate limit. Progress is aggregated across workers, logged after every page and returned.
+//
+// When a state file is specified, the scroll and progress are persisted after every step so that an interrupted
+// reindex resumes where it left off, as long as the scroll context has not expired (see KeepAlive). The state file
+// is removed on completion.
+func Reindex(ctx context.Context, es *elastic.Client, opts *ReindexOptions, i *instr.Instrumentation) (ReindexProgress, error) {
+	ctx, span := i.Tracer.Start(ctx, "index.elasticsearch.Reindex",
+		trace.WithAttributes(label.String("source", opts.Source)),
+		trace.WithAttributes(label.String("destination", opts.Destination)),
+	)
+	defer span.End()
+
+	progress, err := reindex(ctx, es, opts)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return progress, err
+}
+
+// reindexPage is a page of documents read from the source index, numbered in the order it was read.
+type reindexPage struct {
+	seq  int
+	docs []reindexDocument
+}
+
+// reindexRun tracks pages being written by concurrent workers, aggregating progress and persisting the state with
+// all pages read but not yet written as pending.
+type reindexRun struct {
+	file string
+
+	mu      sync.Mutex
+	state   *reindexState
+	pending map[int][]reindexDocument
+	next    int
+}
+
+func newReindexRun(state *reindexState, file string) *reindexRun {
+	return &reindexRun{
+		file:    file,
+		state:   state,
+		pending: make(map[int][]reindexDocument),
+	}
+}
+
+// save persists the state with all pending pages, in the order they were read; r.mu must be held.
+func (r *reindexRun) save() error {
+	seqs := make([]int, 0, len(r.pending))
+	for seq := range r.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+
+	r.state.Pending = nil
+	for _, seq := range seqs {
+		r.state.Pending = append(r.state.Pending, r.pending[seq]...)
+	}
+
+	return r.state.save(r.file)
+}
+
+// add records a page read from the scroll with scrollID and total, returning it numbered.
+func (r *reindexRun) add(docs []reindexDocument, scrollID string, total int64) (reindexPage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	page := reindexPage{r.next, docs}
+	r.next++
+
+	r.pending[page.seq] = docs
+	if scrollID != "" {
+		r.state.ScrollID = scrollID
+	}
+	if total > 0 {
+		r.state.Total = total
+	}
+
+	return page, r.save()
+}
+
+// done records the outcome of writing page, returning the aggregate progress. Failed writes leave the page pending,
+// to be written again on resume.
+func (r *reindexRun) done(page reindexPage, skipped int64, counts BulkCounts, err error) (ReindexProgress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.state.Retried += counts.Retried
+	if err != nil {
+		return r.state.ReindexProgress, err
+	}
+
+	r.state.Skipped += skipped
+	r.state.Failed += counts.Failed
+	r.state.Processed += int64(len(page.docs))
+	delete(r.pending, page.seq)
+
+	return r.state.ReindexProgress, r.save()
+}
+
+// progress returns the aggregate progress.
+func (r *reindexRun) progress() ReindexProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.state.ReindexProgress
+}
+
+func reindex(ctx context.Context, es *elastic.Client, opts *ReindexOptions) (ReindexProgress, error) {
+	state, err := loadReindexState(opts.StateFile)
+	if err != nil {
+		return ReindexProgress{}, err
+	}
+
+	if state.ScrollID != "" {
+		logger.Infof("Resuming reindex from %s: %s", opts.StateFile, state.ReindexProgress)
+	}
+
+	scroll := es.Scroll(opts.Source).
+		Size(opts.BatchSize).
+		KeepAlive(fmt.Sprintf("%ds", int(opts.KeepAlive.Seconds())))
+
+	if state.ScrollID != "" {
+		scroll = scroll.ScrollId(state.ScrollID)
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		run     = newReindexRun(state, opts.StateFile)
+		limiter = utils.NewRateLimiter(opts.RateLimit, workers)
+		pages   = make(chan reindexPage, workers)
+	)
+
+	wg, wgCtx := errgroup.WithContext(ctx)
+
+	// Only this goroutine uses the scroll, as scroll requests must be sequential.
+	wg.Go(func() error {
+		defer close(pages)
+
+		// Write pending documents first; these might remain from an interrupted run.
+		if len(state.Pending) > 0 {
+			page, err := run.add(state.Pending, "", 0)
+			if err != nil {
+				return err
+			}
+
+			select {
+			case pages <- page:
+			case <-wgCtx.Done():
+				return wgCtx.Err()
+			}
+		}
+
+		for {
+			result, err := scroll.Do(wgCtx)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return wrapError(err)
+			}
+
+			docs := make([]reindexDocument, len(result.Hits.Hits))
+			for j, hit := range result.Hits.Hits {
+				docs[j] = reindexDocument{hit.Id, hit.Source}
+			}
+
+			var total int64
+			if result.Hits.TotalHits != nil {
+				total = result.Hits.TotalHits.Value
+			}
+
+			page, err := run.add(docs, result.ScrollId, total)
+			if err != nil {
+				return err
+			}
+
+			select {
+			case pages <- page:
+			case <-wgCtx.Done():
+				return wgCtx.Err()
+			}
+		}
+	})
+
+	for w := 0; w < workers; w++ {
+		wg.Go(func() error {
+			for page := range pages {
+				if err := limiter.Wait(wgCtx); err != nil {
+					return err
+				}
+
+				skipped, counts, err := writeBatch(wgCtx, es, opts, page.docs)
+
+				progress, err := run.done(page, skipped, counts, err)
+				if err != nil {
+					return err
+				}
+
+				logger.Infof("Reindexing %s to %s: %s", opts.Source, opts.Destination, progress)
+			}
+
+			return nil
+		})
+	}
+
+	if err := wg.Wait(); err != nil {
+		return run.progress(), err
+	}
+
+	if err := scroll.Clear(ctx); err != nil {
+		logger.Warnf("Error clearing scroll: %v", err)
+	}
+
+	if opts.StateFile != "" {
+		if err := os.Remove(opts.StateFile); err != nil && !os.IsNotExist(err) {
+			return run.progress(), err
+		}
+	}
+
+	return run.progress(), nil
+}
+
+// writeBatch transforms and bulk-writes docs, retrying failed documents, returning the number of documents skipped
+// by the transform and the outcome of writing the others.
+func writeBatch(ctx context.Context, es *elastic.Client, opts *ReindexOptions, docs []reindexDocument) (int64, BulkCounts, error) {
+	var (
+		reqs    []elastic.BulkableRequest
+		skipped int64
+	)
+
+	for _, doc := range docs {
+		source := doc.Source
+
+		if opts.Transform != nil {
+			var err error
+			if source, err = opts.Transform(doc.ID, source); err != nil {
+				return 0, BulkCounts{}, fmt.Errorf("transforming %s: %w", doc.ID, err)
+			}
+		}
+
+		if source == nil {
+			skipped++
+			continue
+		}
+
+		reqs = append(reqs, elastic.NewBulkIndexRequest().Id(doc.ID).Doc(source))
+	}
+
+	onFailure := opts.OnFailure
+	if onFailure == nil {
+		onFailure = logFailure
+	}
+
+	counts, err := bulkDo(ctx, es, opts.Destination, reqs, opts.Retry, func(_ int, id string, err error) {
+		onFailure(id, err)
+	})
+
+	return skipped, counts, err
+}
+
+// RenameFields returns a Transform renaming top-level fields of documents, mapping old to new names.
+func RenameFields(names map[string]string) Transform {
+	return func(id string, source json.RawMessage) (json.RawMessage, error) {
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(source, &doc); err != nil {
+			return nil, err
+		}
+
+		for from, to := range names {
+			if v, ok := doc[from]; ok {
+				delete(doc, from)
+				doc[to] = v
+			}
+		}
+
+		return json.Marshal(doc)
+	}
+}
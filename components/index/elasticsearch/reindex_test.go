@@ -0,0 +1,109 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameFields(t *testing.T) {
+	transform := RenameFields(map[string]string{
+		"old":     "new",
+		"missing": "other",
+	})
+
+	result, err := transform("id", json.RawMessage(`{"old":1,"kept":"x"}`))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"new":1,"kept":"x"}`, string(result))
+}
+
+func TestRenameFieldsInvalid(t *testing.T) {
+	transform := RenameFields(map[string]string{"old": "new"})
+
+	_, err := transform("id", json.RawMessage(`[]`))
+
+	assert.Error(t, err)
+}
+
+func TestReindexState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reindex")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "state.json")
+
+	// Missing state file yields empty state.
+	state, err := loadReindexState(file)
+	assert.NoError(t, err)
+	assert.Equal(t, &reindexState{}, state)
+
+	state.ScrollID = "scroll"
+	state.Pending = []reindexDocument{{"id", json.RawMessage(`{"a":1}`)}}
+	state.Total = 10
+	state.Processed = 5
+
+	assert.NoError(t, state.save(file))
+
+	loaded, err := loadReindexState(file)
+	assert.NoError(t, err)
+	assert.Equal(t, state, loaded)
+}
+
+func TestReindexStateInvalid(t *testing.T) {
+	f, err := ioutil.TempFile("", "reindex")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString("invalid")
+	assert.NoError(t, err)
+	f.Close()
+
+	_, err = loadReindexState(f.Name())
+	assert.Error(t, err)
+}
+
+func TestReindexRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reindex")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "state.json")
+
+	run := newReindexRun(&reindexState{}, file)
+
+	first, err := run.add([]reindexDocument{{"a", json.RawMessage(`{}`)}}, "scroll1", 3)
+	assert.NoError(t, err)
+
+	second, err := run.add([]reindexDocument{{"b", json.RawMessage(`{}`)}, {"c", json.RawMessage(`{}`)}}, "scroll2", 3)
+	assert.NoError(t, err)
+
+	// Pages written out of order.
+	progress, err := run.done(second, 1, BulkCounts{Succeeded: 1, Retried: 2}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ReindexProgress{Total: 3, Processed: 2, Skipped: 1, Retried: 2}, progress)
+
+	// Only the first page remains pending, with the latest scroll.
+	state, err := loadReindexState(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "scroll2", state.ScrollID)
+	assert.Equal(t, []reindexDocument{{"a", json.RawMessage(`{}`)}}, state.Pending)
+
+	// Failed pages remain pending.
+	_, err = run.done(first, 0, BulkCounts{}, errors.New("unavailable"))
+	assert.Error(t, err)
+	assert.Equal(t, int64(2), run.progress().Processed)
+
+	progress, err = run.done(first, 0, BulkCounts{Succeeded: 1}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), progress.Processed)
+
+	state, err = loadReindexState(file)
+	assert.NoError(t, err)
+	assert.Empty(t, state.Pending)
+}
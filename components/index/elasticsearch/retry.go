@@ -0,0 +1,59 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// isRetryable returns true when err, as returned by attempt, is transient: Elasticsearch being unreachable,
+// overloaded or timing out. Other errors, e.g. mapping conflicts or other 4xx responses, are permanent.
+func isRetryable(err error) bool {
+	return errors.Is(err, index.ErrIndexUnavailable)
+}
+
+// attempt performs request f once, bounded by the configured timeout, returning wrapped errors.
+func (i *Index) attempt(ctx context.Context, f func(context.Context) error) error {
+	reqCtx := ctx
+	if i.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, i.cfg.Timeout)
+		defer cancel()
+	}
+
+	err := f(reqCtx)
+	if err != nil && ctx.Err() == nil && reqCtx.Err() == context.DeadlineExceeded {
+		// The request timed out rather than the caller's context.
+		return fmt.Errorf("%w: request timed out after %s: %v", index.ErrIndexUnavailable, i.cfg.Timeout, err)
+	}
+
+	return wrapError(err)
+}
+
+// do performs request f, retrying transient failures with exponential backoff, up to the configured maximum.
+// This is distinct from retrying crawls; it prevents brief unavailability of Elasticsearch from failing them.
+func (i *Index) do(ctx context.Context, f func(context.Context) error) error {
+	backoff := i.cfg.RetryBackoff
+
+	for retry := uint(0); ; retry++ {
+		err := i.attempt(ctx, f)
+		if err == nil || !isRetryable(err) || retry >= i.cfg.MaxRetries {
+			return err
+		}
+
+		trace.SpanFromContext(ctx).AddEvent(ctx, "retry")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
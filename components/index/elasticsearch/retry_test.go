@@ -0,0 +1,164 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+func TestIsRetryable(t *testing.T) {
+	connReset := &url.Error{
+		Op:  "Post",
+		URL: "http://localhost:9200/test/_doc/id",
+		Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+	}
+
+	tests := []struct {
+		name  string
+		err   error
+		retry bool
+	}{
+		{"connection reset", connReset, true},
+		{"no client", elastic.ErrNoClient, true},
+		{"too many requests", &elastic.Error{Status: 429}, true},
+		{"service unavailable", &elastic.Error{Status: 503}, true},
+		{"gateway timeout", &elastic.Error{Status: 504}, true},
+		{"internal server error", &elastic.Error{Status: 500}, false},
+		{"not found", &elastic.Error{Status: 404}, false},
+		{"conflict", &elastic.Error{Status: 409}, false},
+		{"mapping conflict", &elastic.Error{
+			Status:  400,
+			Details: &elastic.ErrorDetails{Type: "mapper_parsing_exception"},
+		}, false},
+		{"bad request", &elastic.Error{Status: 400}, false},
+		{"other", errors.New("other"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retry, isRetryable(wrapError(tt.err)))
+		})
+	}
+}
+
+// newRetryIndex returns an Index for cfg, served by handler until closed, and the number of requests made to it.
+func newRetryIndex(t *testing.T, cfg *Config, handler func(w http.ResponseWriter, r *http.Request, n int32)) (*Index, *int32, func()) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r, atomic.AddInt32(&requests, 1))
+	}))
+
+	es, err := elastic.NewClient(
+		elastic.SetURL(server.URL),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheck(false),
+	)
+	require.NoError(t, err)
+
+	return New(es, cfg, instr.New()).(*Index), &requests, server.Close
+}
+
+const unavailableResponse = `{"error": {"type": "unavailable_shards_exception"}, "status": 503}`
+
+func TestRetryTransient(t *testing.T) {
+	cfg := &Config{Name: "test", MaxRetries: 3, RetryBackoff: time.Millisecond}
+
+	i, requests, stop := newRetryIndex(t, cfg, func(w http.ResponseWriter, r *http.Request, n int32) {
+		if n <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(unavailableResponse))
+			return
+		}
+
+		w.Write([]byte(`{"_index": "test", "_id": "id", "result": "created"}`))
+	})
+	defer stop()
+
+	err := i.Index(context.Background(), "id", map[string]interface{}{"size": 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestRetryExhausted(t *testing.T) {
+	cfg := &Config{Name: "test", MaxRetries: 2, RetryBackoff: time.Millisecond}
+
+	i, requests, stop := newRetryIndex(t, cfg, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(unavailableResponse))
+	})
+	defer stop()
+
+	err := i.Update(context.Background(), "id", map[string]interface{}{"size": 1})
+
+	assert.True(t, errors.Is(err, index.ErrIndexUnavailable))
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestRetryPermanent(t *testing.T) {
+	cfg := &Config{Name: "test", MaxRetries: 3, RetryBackoff: time.Millisecond}
+
+	i, requests, stop := newRetryIndex(t, cfg, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"type": "mapper_parsing_exception", "reason": "failed to parse"}, "status": 400}`))
+	})
+	defer stop()
+
+	err := i.Index(context.Background(), "id", map[string]interface{}{"size": "large"})
+
+	assert.True(t, errors.Is(err, index.ErrMappingConflict))
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func TestRetryTimeout(t *testing.T) {
+	cfg := &Config{Name: "test", Timeout: 10 * time.Millisecond, MaxRetries: 1, RetryBackoff: time.Millisecond}
+
+	i, requests, stop := newRetryIndex(t, cfg, func(w http.ResponseWriter, r *http.Request, n int32) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	defer stop()
+
+	var dst map[string]interface{}
+	_, err := i.Get(context.Background(), "id", &dst)
+
+	assert.True(t, errors.Is(err, index.ErrIndexUnavailable))
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestRetryCanceled(t *testing.T) {
+	cfg := &Config{Name: "test", MaxRetries: 3, RetryBackoff: time.Hour}
+
+	i, requests, stop := newRetryIndex(t, cfg, func(w http.ResponseWriter, r *http.Request, n int32) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(unavailableResponse))
+	})
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := i.Index(ctx, "id", map[string]interface{}{"size": 1})
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
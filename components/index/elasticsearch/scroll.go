@@ -0,0 +1,40 @@
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+// ScrollIDs sends the id of every document in the index called name to ids, scrolling batchSize documents at a
+// time. It does not close ids.
+func ScrollIDs(ctx context.Context, es *elastic.Client, name string, batchSize int, keepAlive time.Duration, ids chan<- string) error {
+	scroll := es.Scroll(name).
+		Size(batchSize).
+		KeepAlive(fmt.Sprintf("%ds", int(keepAlive.Seconds()))).
+		FetchSource(false)
+
+	defer scroll.Clear(context.Background())
+
+	for {
+		result, err := scroll.Do(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return wrapError(err)
+		}
+
+		for _, hit := range result.Hits.Hits {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ids <- hit.Id:
+			}
+		}
+	}
+}
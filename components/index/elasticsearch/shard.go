@@ -0,0 +1,96 @@
+package elasticsearch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+// Strategies for routing documents to shard indexes.
+const (
+	ShardNone   = "none"   // A single index.
+	ShardPrefix = "prefix" // `<name>-<prefix>`, by the first hex characters of the digest of the CID's multihash.
+	ShardHash   = "hash"   // `<name>-<n>`, by the hash of the document ID modulo the shard count.
+)
+
+// maxShardPrefixLength bounds the shards of the prefix strategy to 16^3.
+const maxShardPrefixLength = 3
+
+// ShardConfig configures routing documents to one of several indexes, e.g. for very large indexes.
+type ShardConfig struct {
+	Strategy     string // ShardNone, ShardPrefix or ShardHash; a single index when empty.
+	PrefixLength int    // Digest characters in shard names, for ShardPrefix.
+	Count        int    // Number of shards, for ShardHash.
+}
+
+// Validate returns an error when the strategy is unknown or its parameters out of range.
+func (c *ShardConfig) Validate() error {
+	switch c.Strategy {
+	case "", ShardNone:
+	case ShardPrefix:
+		if c.PrefixLength < 1 || c.PrefixLength > maxShardPrefixLength {
+			return fmt.Errorf("shard prefix length should be between 1 and %d", maxShardPrefixLength)
+		}
+	case ShardHash:
+		if c.Count < 1 {
+			return fmt.Errorf("shard count should be positive")
+		}
+	default:
+		return fmt.Errorf("unknown sharding strategy '%s'", c.Strategy)
+	}
+
+	return nil
+}
+
+// sharded returns true when documents are spread across several indexes.
+func (c *ShardConfig) sharded() bool {
+	return c.Strategy == ShardPrefix || c.Strategy == ShardHash
+}
+
+// Pattern returns the index pattern matching all shards of the index called name, e.g. for searching.
+func (c *ShardConfig) Pattern(name string) string {
+	if !c.sharded() {
+		return name
+	}
+
+	return name + "-*"
+}
+
+// digest returns the digest of the multihash of id when it is a CID, or of its SHA-256 hash otherwise (e.g. for
+// hashed document IDs), so that prefixes are evenly distributed.
+func digest(id string) []byte {
+	if c, err := cid.Decode(id); err == nil {
+		if mh, err := multihash.Decode(c.Hash()); err == nil && len(mh.Digest) > 0 {
+			return mh.Digest
+		}
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	return sum[:]
+}
+
+// Shard returns the name of the shard of the index called name holding the document with id.
+func (c *ShardConfig) Shard(name, id string) string {
+	switch c.Strategy {
+	case ShardPrefix:
+		prefix := hex.EncodeToString(digest(id))
+		if len(prefix) > c.PrefixLength {
+			prefix = prefix[:c.PrefixLength]
+		}
+
+		return name + "-" + prefix
+
+	case ShardHash:
+		h := fnv.New32a()
+		h.Write([]byte(id))
+
+		return fmt.Sprintf("%s-%d", name, h.Sum32()%uint32(c.Count))
+
+	default:
+		return name
+	}
+}
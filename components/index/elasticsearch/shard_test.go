@@ -0,0 +1,50 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	shardCIDv0 = "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH"
+	shardCIDv1 = "bafybeif7ztnhq65lumvvtr4ekcwd2ifwgm3awq4zfr3srh462rwyinlb4y" // Same multihash as shardCIDv0.
+)
+
+func TestShardNone(t *testing.T) {
+	for _, c := range []ShardConfig{{}, {Strategy: ShardNone}} {
+		assert.Equal(t, "ipfs_files", c.Shard("ipfs_files", shardCIDv0))
+		assert.Equal(t, "ipfs_files", c.Pattern("ipfs_files"))
+	}
+}
+
+func TestShardPrefix(t *testing.T) {
+	c := ShardConfig{Strategy: ShardPrefix, PrefixLength: 2}
+
+	assert.Equal(t, "ipfs_files-bf", c.Shard("ipfs_files", shardCIDv0))
+	// Routed by multihash, regardless of CID version.
+	assert.Equal(t, "ipfs_files-bf", c.Shard("ipfs_files", shardCIDv1))
+	// Other IDs by their SHA-256 hash.
+	assert.Equal(t, "ipfs_files-36", c.Shard("ipfs_files", "not-a-cid"))
+
+	assert.Equal(t, "ipfs_files-*", c.Pattern("ipfs_files"))
+}
+
+func TestShardHash(t *testing.T) {
+	c := ShardConfig{Strategy: ShardHash, Count: 16}
+
+	assert.Equal(t, "ipfs_files-1", c.Shard("ipfs_files", shardCIDv0))
+	// Stable across calls.
+	assert.Equal(t, c.Shard("ipfs_files", shardCIDv1), c.Shard("ipfs_files", shardCIDv1))
+}
+
+func TestShardValidate(t *testing.T) {
+	assert.NoError(t, (&ShardConfig{}).Validate())
+	assert.NoError(t, (&ShardConfig{Strategy: ShardPrefix, PrefixLength: 1}).Validate())
+	assert.NoError(t, (&ShardConfig{Strategy: ShardHash, Count: 8}).Validate())
+
+	assert.Error(t, (&ShardConfig{Strategy: ShardPrefix}).Validate())
+	assert.Error(t, (&ShardConfig{Strategy: ShardPrefix, PrefixLength: 4}).Validate())
+	assert.Error(t, (&ShardConfig{Strategy: ShardHash}).Validate())
+	assert.Error(t, (&ShardConfig{Strategy: "range"}).Validate())
+}
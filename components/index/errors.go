@@ -0,0 +1,19 @@
+package index
+
+import (
+	"errors"
+)
+
+var (
+	// ErrIndexUnavailable is returned when the index backend is (temporarily) unavailable; operations may be retried.
+	ErrIndexUnavailable = errors.New("index unavailable")
+
+	// ErrMappingConflict is returned when a document does not match the index mapping; retrying will not help.
+	ErrMappingConflict = errors.New("mapping conflict")
+
+	// ErrNotFound is returned when a document to operate on does not exist.
+	ErrNotFound = errors.New("document not found")
+
+	// ErrConflict is returned on version conflicts, e.g. concurrent updates of the same document.
+	ErrConflict = errors.New("document version conflict")
+)
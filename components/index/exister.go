@@ -0,0 +1,53 @@
+package index
+
+import (
+	"context"
+)
+
+// Exister is implemented by indexes which can look up many documents at once.
+type Exister interface {
+	// Exists returns which of the documents with ids exist, in a single request.
+	Exists(ctx context.Context, ids []string) (map[string]bool, error)
+}
+
+// MultiExists returns the first of indexes holding a document with each of ids, omitting ids of documents in none of
+// them, with a single request per index. It returns false when any of the indexes does not implement Exister.
+func MultiExists(ctx context.Context, indexes []Index, ids []string) (map[string]Index, bool, error) {
+	existers := make([]Exister, len(indexes))
+	for n, i := range indexes {
+		e, ok := i.(Exister)
+		if !ok {
+			return nil, false, nil
+		}
+		existers[n] = e
+	}
+
+	found := make(map[string]Index, len(ids))
+
+	for n, e := range existers {
+		// Only look up documents not found in earlier indexes.
+		remaining := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if _, ok := found[id]; !ok {
+				remaining = append(remaining, id)
+			}
+		}
+
+		if len(remaining) == 0 {
+			break
+		}
+
+		exists, err := e.Exists(ctx, remaining)
+		if err != nil {
+			return nil, true, err
+		}
+
+		for _, id := range remaining {
+			if exists[id] {
+				found[id] = indexes[n]
+			}
+		}
+	}
+
+	return found, true, nil
+}
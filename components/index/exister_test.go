@@ -0,0 +1,54 @@
+package index
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiExists(t *testing.T) {
+	ctx := context.Background()
+
+	files := &ExisterMock{}
+	files.Test(t)
+	dirs := &ExisterMock{}
+	dirs.Test(t)
+
+	files.On("Exists", ctx, []string{"a", "b", "c"}).Return(map[string]bool{"a": true}, nil)
+	dirs.On("Exists", ctx, []string{"b", "c"}).Return(map[string]bool{"b": true}, nil)
+
+	found, ok, err := MultiExists(ctx, []Index{files, dirs}, []string{"a", "b", "c"})
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]Index{"a": files, "b": dirs}, found)
+	files.AssertExpectations(t)
+	dirs.AssertExpectations(t)
+}
+
+func TestMultiExistsAllFound(t *testing.T) {
+	ctx := context.Background()
+
+	files := &ExisterMock{}
+	files.Test(t)
+	dirs := &ExisterMock{}
+	dirs.Test(t)
+
+	files.On("Exists", ctx, []string{"a"}).Return(map[string]bool{"a": true}, nil)
+
+	found, ok, err := MultiExists(ctx, []Index{files, dirs}, []string{"a"})
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]Index{"a": files}, found)
+	dirs.AssertNotCalled(t, "Exists", ctx, []string{})
+}
+
+func TestMultiExistsUnsupported(t *testing.T) {
+	found, ok, err := MultiExists(context.Background(), []Index{&ExisterMock{}, &Mock{}}, []string{"a"})
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, found)
+}
@@ -0,0 +1,113 @@
+// Package mirror implements an index writing to a primary index, while mirroring writes to a secondary index.
+package mirror
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
+)
+
+var logger = logging.New("indexer")
+
+// Index writes to a primary index, mirroring writes to a secondary index, e.g. while migrating clusters.
+// Writes to the secondary are best-effort: they happen in the background and failures are logged and counted,
+// never affecting the primary. Reads are served from the primary only.
+type Index struct {
+	primary   index.Index
+	secondary index.Index
+
+	// sem bounds the amount of concurrent secondary writes; writes are dropped when it's full.
+	sem      chan struct{}
+	failures metric.Int64Counter
+
+	*instr.Instrumentation
+}
+
+// New returns a new mirroring index, performing at most maxPending concurrent secondary writes.
+func New(primary index.Index, secondary index.Index, maxPending int, i *instr.Instrumentation) index.Index {
+	return &Index{
+		primary:   primary,
+		secondary: secondary,
+		sem:       make(chan struct{}, maxPending),
+		failures: metric.Must(i.Meter).NewInt64Counter(
+			"ipfs_search.index.mirror.failures",
+			metric.WithDescription("Failed or dropped writes to the secondary index."),
+		),
+		Instrumentation: i,
+	}
+}
+
+// String returns the name of the primary index, for convenient logging.
+func (i *Index) String() string {
+	if s, ok := i.primary.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return "mirror"
+}
+
+// mirror performs write on the secondary index in the background.
+func (i *Index) mirror(ctx context.Context, op string, id string, write func(context.Context) error) {
+	opLabel := label.String("op", op)
+
+	select {
+	case i.sem <- struct{}{}:
+	default:
+		logger.Warnf("Dropping secondary %s of %s: too many pending writes", op, id)
+		i.failures.Add(ctx, 1, opLabel, label.Bool("dropped", true))
+		return
+	}
+
+	// Detach from the caller's cancellation, but keep tracing.
+	ctx = trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+
+	go func() {
+		defer func() { <-i.sem }()
+
+		if err := write(ctx); err != nil {
+			logger.Warnf("Error in secondary %s of %s: %v", op, id, err)
+			i.failures.Add(ctx, 1, opLabel, label.Bool("dropped", false))
+		}
+	}()
+}
+
+// Index a document's properties in the primary index, mirroring it to the secondary index.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	if err := i.primary.Index(ctx, id, properties); err != nil {
+		return err
+	}
+
+	i.mirror(ctx, "index", id, func(ctx context.Context) error {
+		return i.secondary.Index(ctx, id, properties)
+	})
+
+	return nil
+}
+
+// Update a document's properties in the primary index, mirroring it to the secondary index.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	if err := i.primary.Update(ctx, id, properties); err != nil {
+		return err
+	}
+
+	i.mirror(ctx, "update", id, func(ctx context.Context) error {
+		return i.secondary.Update(ctx, id, properties)
+	})
+
+	return nil
+}
+
+// Get retrieves a document from the primary index.
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	return i.primary.Get(ctx, id, dst, fields...)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Index = &Index{}
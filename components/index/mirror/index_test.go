@@ -0,0 +1,99 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type MirrorTestSuite struct {
+	suite.Suite
+
+	ctx       context.Context
+	primary   *index.Mock
+	secondary *index.Mock
+	i         index.Index
+}
+
+func (s *MirrorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.primary = &index.Mock{}
+	s.secondary = &index.Mock{}
+	s.i = New(s.primary, s.secondary, 1, instr.New())
+}
+
+// waitFor returns a channel which is closed when the mock call is made.
+func waitFor(call *mock.Call) <-chan struct{} {
+	done := make(chan struct{})
+	call.Run(func(mock.Arguments) { close(done) })
+	return done
+}
+
+func (s *MirrorTestSuite) wait(done <-chan struct{}) {
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.Fail("timeout waiting for secondary write")
+	}
+}
+
+func (s *MirrorTestSuite) TestIndex() {
+	props := map[string]interface{}{"size": 1}
+
+	s.primary.On("Index", mock.Anything, "QmTest", props).Return(nil).Once()
+	done := waitFor(s.secondary.On("Index", mock.Anything, "QmTest", props).Return(nil).Once())
+
+	s.NoError(s.i.Index(s.ctx, "QmTest", props))
+
+	s.wait(done)
+	s.primary.AssertExpectations(s.T())
+	s.secondary.AssertExpectations(s.T())
+}
+
+func (s *MirrorTestSuite) TestUpdateSecondaryError() {
+	props := map[string]interface{}{"size": 1}
+
+	s.primary.On("Update", mock.Anything, "QmTest", props).Return(nil).Once()
+	done := waitFor(s.secondary.On("Update", mock.Anything, "QmTest", props).Return(errors.New("unavailable")).Once())
+
+	// Secondary errors are not returned.
+	s.NoError(s.i.Update(s.ctx, "QmTest", props))
+
+	s.wait(done)
+	s.secondary.AssertExpectations(s.T())
+}
+
+func (s *MirrorTestSuite) TestIndexPrimaryError() {
+	props := map[string]interface{}{"size": 1}
+	primaryErr := errors.New("primary error")
+
+	s.primary.On("Index", mock.Anything, "QmTest", props).Return(primaryErr).Once()
+
+	// Primary errors are returned, without writing to the secondary.
+	s.Equal(primaryErr, s.i.Index(s.ctx, "QmTest", props))
+
+	s.secondary.AssertNotCalled(s.T(), "Index", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *MirrorTestSuite) TestGet() {
+	dst := new(struct{})
+
+	s.primary.On("Get", mock.Anything, "QmTest", dst, []string{"size"}).Return(true, nil).Once()
+
+	found, err := s.i.Get(s.ctx, "QmTest", dst, "size")
+
+	s.True(found)
+	s.NoError(err)
+	s.secondary.AssertNotCalled(s.T(), "Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMirrorTestSuite(t *testing.T) {
+	suite.Run(t, new(MirrorTestSuite))
+}
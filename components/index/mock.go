@@ -28,5 +28,69 @@ func (m *Mock) Get(ctx context.Context, id string, dst interface{}, fields ...st
 	return args.Bool(0), args.Error(1)
 }
 
+// AppenderMock mocks an Index which also implements Appender.
+type AppenderMock struct {
+	Mock
+}
+
+// Append mocks the Append method on the Appender interface.
+func (m *AppenderMock) Append(ctx context.Context, id string, set map[string]interface{}, add map[string][]interface{}) error {
+	args := m.Called(ctx, id, set, add)
+	return args.Error(0)
+}
+
+// VersionedMock mocks an Index which also implements Versioned.
+type VersionedMock struct {
+	Mock
+}
+
+// GetVersioned mocks the GetVersioned method on the Versioned interface.
+func (m *VersionedMock) GetVersioned(ctx context.Context, id string, dst interface{}, fields ...string) (*Version, error) {
+	args := m.Called(ctx, id, dst, fields)
+
+	v, _ := args.Get(0).(*Version)
+	return v, args.Error(1)
+}
+
+// UpdateVersioned mocks the UpdateVersioned method on the Versioned interface.
+func (m *VersionedMock) UpdateVersioned(ctx context.Context, id string, properties interface{}, v *Version) error {
+	args := m.Called(ctx, id, properties, v)
+	return args.Error(0)
+}
+
+// CreatorMock mocks an Index which also implements Creator.
+type CreatorMock struct {
+	Mock
+}
+
+// Create mocks the Create method on the Creator interface.
+func (m *CreatorMock) Create(ctx context.Context, id string, properties interface{}) error {
+	args := m.Called(ctx, id, properties)
+	return args.Error(0)
+}
+
+// ExisterMock mocks an Index which also implements Exister.
+type ExisterMock struct {
+	Mock
+}
+
+// Exists mocks the Exists method on the Exister interface.
+func (m *ExisterMock) Exists(ctx context.Context, ids []string) (map[string]bool, error) {
+	args := m.Called(ctx, ids)
+
+	exists, _ := args.Get(0).(map[string]bool)
+	return exists, args.Error(1)
+}
+
 // Compile-time assurance that implementation satisfies interface.
-var _ Index = &Mock{}
+var (
+	_ Index     = &Mock{}
+	_ Index     = &AppenderMock{}
+	_ Appender  = &AppenderMock{}
+	_ Index     = &VersionedMock{}
+	_ Versioned = &VersionedMock{}
+	_ Index     = &CreatorMock{}
+	_ Creator   = &CreatorMock{}
+	_ Index     = &ExisterMock{}
+	_ Exister   = &ExisterMock{}
+)
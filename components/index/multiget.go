@@ -21,3 +21,35 @@ func MultiGet(ctx context.Context, indexes []Index, id string, dst interface{},
 
 	return nil, nil
 }
+
+// MultiGetVersioned is MultiGet, also returning the version of the document when its index implements Versioned.
+func MultiGetVersioned(ctx context.Context, indexes []Index, id string, dst interface{}, fields ...string) (Index, *Version, error) {
+	for _, i := range indexes {
+		versioned, ok := i.(Versioned)
+		if !ok {
+			found, err := i.Get(ctx, id, dst, fields...)
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if found {
+				return i, nil, nil
+			}
+
+			continue
+		}
+
+		v, err := versioned.GetVersioned(ctx, id, dst, fields...)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if v != nil {
+			return i, v, nil
+		}
+	}
+
+	return nil, nil, nil
+}
@@ -44,6 +44,25 @@ func (s *MultiGetTestSuite) TestMultiGetFound() {
 	s.mock.AssertExpectations(s.T())
 }
 
+// TestMultiGetVersioned tests "Document is found in a versioned index, after an unversioned one"
+func (s *MultiGetTestSuite) TestMultiGetVersioned() {
+	dst := new(struct{})
+	versioned := &VersionedMock{}
+	versioned.Test(s.T())
+	v := &Version{SeqNo: 3, PrimaryTerm: 1}
+
+	s.mock.On("Get", s.ctx, "objId", dst, []string{"testField"}).Return(false, nil)
+	versioned.On("GetVersioned", s.ctx, "objId", dst, []string{"testField"}).Return(v, nil)
+
+	index, version, err := MultiGetVersioned(s.ctx, []Index{s.mock, versioned}, "objId", dst, "testField")
+
+	s.NoError(err)
+	s.Equal(versioned, index)
+	s.Equal(v, version)
+	s.mock.AssertExpectations(s.T())
+	versioned.AssertExpectations(s.T())
+}
+
 func TestMultiGetTestSuite(t *testing.T) {
 	suite.Run(t, new(MultiGetTestSuite))
 }
@@ -0,0 +1,6 @@
+package ndjson
+
+// Config represents the configuration for a newline-delimited JSON index.
+type Config struct {
+	Name string
+}
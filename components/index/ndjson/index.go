@@ -0,0 +1,85 @@
+// Package ndjson implements an index writing documents as newline-delimited JSON, e.g. to a file or stdout.
+package ndjson
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// Operations, as written to the output.
+const (
+	opIndex  = "index"
+	opUpdate = "update"
+)
+
+// line represents a single written operation.
+type line struct {
+	Index      string      `json:"index"`
+	Op         string      `json:"op"`
+	ID         string      `json:"id"`
+	Properties interface{} `json:"properties"`
+}
+
+// Index writes operations on documents as lines of JSON. As documents can't be read back, Get never finds
+// documents, hence every encountered resource is written.
+type Index struct {
+	w   *Writer
+	cfg *Config
+
+	*instr.Instrumentation
+}
+
+// New returns a new index, writing to w. Multiple indexes may share a Writer.
+func New(w *Writer, cfg *Config, i *instr.Instrumentation) index.Index {
+	return &Index{
+		w:               w,
+		cfg:             cfg,
+		Instrumentation: i,
+	}
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *Index) String() string {
+	return i.cfg.Name
+}
+
+func (i *Index) write(ctx context.Context, op string, id string, properties interface{}) error {
+	ctx, span := i.Tracer.Start(ctx, "index.ndjson."+op)
+	defer span.End()
+
+	err := i.w.Write(&line{
+		Index:      i.cfg.Name,
+		Op:         op,
+		ID:         id,
+		Properties: properties,
+	})
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// Index writes a document's properties, identified by id.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	return i.write(ctx, opIndex, id, properties)
+}
+
+// Update writes updated properties of a document, identified by id.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	return i.write(ctx, opUpdate, id, properties)
+}
+
+// Get always returns false, as written documents can't be retrieved.
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	return false, nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Index = &Index{}
@@ -0,0 +1,35 @@
+package ndjson
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+func TestIndex(t *testing.T) {
+	var (
+		ctx = context.Background()
+		buf bytes.Buffer
+		w   = NewWriter(&buf)
+	)
+
+	files := New(w, &Config{Name: "files"}, instr.New())
+	dirs := New(w, &Config{Name: "directories"}, instr.New())
+
+	assert.NoError(t, files.Index(ctx, "QmFile", map[string]interface{}{"content": "hello"}))
+	assert.NoError(t, dirs.Update(ctx, "QmDir", map[string]interface{}{"size": 4}))
+
+	assert.Equal(t,
+		`{"index":"files","op":"index","id":"QmFile","properties":{"content":"hello"}}`+"\n"+
+			`{"index":"directories","op":"update","id":"QmDir","properties":{"size":4}}`+"\n",
+		buf.String(),
+	)
+
+	found, err := files.Get(ctx, "QmFile", &struct{}{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
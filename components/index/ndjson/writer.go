@@ -0,0 +1,28 @@
+package ndjson
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Writer writes JSON values as lines to an underlying io.Writer, safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriter returns a new Writer, writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		enc: json.NewEncoder(w),
+	}
+}
+
+// Write writes v as a single line.
+func (w *Writer) Write(v interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.enc.Encode(v)
+}
@@ -26,5 +26,10 @@ type Links []Link
 type Directory struct {
 	Document
 
-	Links Links `json:"links"`
+	Links              Links  `json:"links"`
+	LinkCount          uint64 `json:"link_count"`                     // Total number of links, including those not stored in Links.
+	DuplicateLinkCount uint64 `json:"duplicate_link_count,omitempty"` // Number of links left out of Links and LinkCount as duplicates.
+	Title              string `json:"title,omitempty"`                // Title of the index page, for website directories.
+	IsWebsite          bool   `json:"is_website"`                     // Whether the directory contains an index page.
+	LargeDirPolicy     string `json:"large_dir_policy,omitempty"`     // Policy applied to entries beyond the large directory threshold.
 }
@@ -8,6 +8,8 @@ import (
 type Reference struct {
 	ParentHash string `json:"parent_hash"`
 	Name       string `json:"name"`
+	Path       string `json:"path,omitempty"` // Full path the Document was discovered through.
+	Root       string `json:"root,omitempty"` // Root CID of Path.
 }
 
 // References is a collection of references to a Document.
@@ -18,5 +20,24 @@ type Document struct {
 	FirstSeen  time.Time  `json:"first-seen"`
 	LastSeen   time.Time  `json:"last-seen"`
 	References References `json:"references"`
+	Paths      []string   `json:"paths,omitempty"`
+	IPNSNames  []string   `json:"ipns_names,omitempty"`
+	DNSLinks   []string   `json:"dnslink,omitempty"` // Domains of DNSLink names the Document was resolved from.
+	Sources    []string   `json:"source,omitempty"`  // Crawl sources (origins) the Document was indexed by.
+	RefCount   int        `json:"reference_count"`   // Distinct directories referencing the Document.
+	Popularity float64    `json:"popularity"`        // Grows with references and IPNS names, for boosting search results.
 	Size       uint64     `json:"size"`
+	SizeBucket string     `json:"size_bucket,omitempty"` // tiny, small, medium, large or huge
+	URL        string     `json:"url,omitempty"`         // On the public gateway, when configured.
+	SortKey    string     `json:"sort_key,omitempty"`    // Unique, stable tiebreaker for paginating with search_after.
+
+	CID          string `json:"cid,omitempty"`           // Only when documents are not keyed by CID.
+	CIDCodec     string `json:"cid_codec,omitempty"`     // e.g. dag-pb or raw
+	CIDMultihash string `json:"cid_multihash,omitempty"` // e.g. sha2-256
+
+	// DAG statistics, for sampled documents.
+	BlockCount   uint64 `json:"block_count,omitempty"`
+	DagSize      uint64 `json:"dag_size,omitempty"`
+	DagDepth     uint   `json:"dag_depth,omitempty"`
+	DagTruncated bool   `json:"dag_truncated,omitempty"` // Statistics are lower bounds when true.
 }
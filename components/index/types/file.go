@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // Language represents the language of a File.
 type Language struct {
 	Confidence string  `json:"confidence"`
@@ -14,9 +16,51 @@ type Metadata map[string]interface{}
 type File struct {
 	Document
 
-	Content         string   `json:"content"`
-	IpfsTikaVersion string   `json:"ipfs_tika_version"`
-	Language        Language `json:"language"`
-	Metadata        Metadata `json:"metadata"`
-	URLs            []string `json:"urls"`
+	BookAuthor          []string                 `json:"book_author,omitempty"` // Authors, for ebooks.
+	BookLanguage        string                   `json:"book_language,omitempty"`
+	BookSeries          string                   `json:"book_series,omitempty"`
+	BookSeriesIndex     float64                  `json:"book_series_index,omitempty"`
+	BookTitle           string                   `json:"book_title,omitempty"`
+	Category            string                   `json:"category,omitempty"`   // Coarse category (e.g. document, image) derived from the MIME type.
+	CellCount           uint64                   `json:"cell_count,omitempty"` // Approximate number of non-empty cells, for spreadsheets.
+	Charset             string                   `json:"charset,omitempty"`    // Original (lowercase) encoding of the content.
+	Content             string                   `json:"content"`
+	CodeLanguage        string                   `json:"code_language,omitempty"`      // Programming language, for source code.
+	ContentCompressed   []byte                   `json:"content_compressed,omitempty"` // Gzipped full content, when content is an excerpt.
+	ContentLang         map[string]string        `json:"content_lang,omitempty"`       // Content keyed by detected language, for language-specific analyzers.
+	DominantColor       string                   `json:"dominant_color,omitempty"`     // #rrggbb, for images.
+	EmailAttachments    []string                 `json:"email_attachments,omitempty"`  // Names of attachments, for emails.
+	EmailDate           string                   `json:"email_date,omitempty"`
+	EmailFrom           string                   `json:"email_from,omitempty"`
+	EmailSubject        string                   `json:"email_subject,omitempty"`
+	EmailTo             []string                 `json:"email_to,omitempty"`
+	Empty               bool                     `json:"empty,omitempty"`               // Zero-byte file, indexed without extraction.
+	ExtractedVia        string                   `json:"extracted_via,omitempty"`       // "gateway" when extracted through the fallback gateway.
+	ExtractionMs        int64                    `json:"extraction_ms,omitempty"`       // Time taken by ipfs-tika, in milliseconds.
+	ExtractionWarnings  []string                 `json:"extraction_warnings,omitempty"` // Non-fatal problems reported by Tika, e.g. partially extracted content.
+	ExtractorVersion    uint                     `json:"extractor_version"`
+	FontFamily          string                   `json:"font_family,omitempty"` // Typographic family, for fonts.
+	FontGlyphCount      int                      `json:"font_glyph_count,omitempty"`
+	FontStyle           string                   `json:"font_style,omitempty"` // e.g. Bold Italic
+	ImageHeight         int                      `json:"image_height,omitempty"`
+	ImageWidth          int                      `json:"image_width,omitempty"`
+	ISBN                string                   `json:"isbn,omitempty"`         // ISBN-10 or ISBN-13 without separators, for ebooks.
+	GatewayETag         string                   `json:"gateway_etag,omitempty"` // Of content extracted through the fallback gateway.
+	GatewayLastModified *time.Time               `json:"gateway_last_modified,omitempty"`
+	IpfsTikaVersion     string                   `json:"ipfs_tika_version"`
+	Language            Language                 `json:"language"`
+	Metadata            Metadata                 `json:"metadata"`
+	MimeType            string                   `json:"mimetype,omitempty"` // Sniffed from the content, or guessed from the extension.
+	Publisher           string                   `json:"publisher,omitempty"`
+	SheetNames          []string                 `json:"sheet_names,omitempty"`     // Names of sheets, for spreadsheets.
+	Simhash             string                   `json:"simhash,omitempty"`         // 64-bit simhash of content, hex encoded.
+	SimhashBands        []string                 `json:"simhash_bands,omitempty"`   // Bands of Simhash, for finding near-duplicates.
+	StructuredData      []map[string]interface{} `json:"structured_data,omitempty"` // JSON-LD objects and microdata items, for HTML.
+	Subtitles           string                   `json:"subtitles,omitempty"`
+	Symbols             []string                 `json:"symbols,omitempty"`       // Top-level function, class and type names, for source code.
+	ThumbnailCID        string                   `json:"thumbnail_cid,omitempty"` // CID of a JPEG thumbnail, for images.
+	TikaVersion         string                   `json:"tika_version,omitempty"`  // Server or version header of the ipfs-tika response.
+	TOC                 []string                 `json:"toc,omitempty"`           // Outline of PDF and EPUB documents.
+	URLs                []string                 `json:"urls"`
+	WebsiteRoot         string                   `json:"website_root,omitempty"` // Nearest ancestor directory with an index page.
 }
@@ -2,5 +2,6 @@ package types
 
 // Invalid represents invalid (unindexable) resources in an Index.
 type Invalid struct {
+	CID   string `json:"cid,omitempty"` // Only when documents are not keyed by CID.
 	Error string `json:"error"`
 }
@@ -8,4 +8,10 @@ import (
 type Update struct {
 	LastSeen   time.Time  `json:"last-seen"`
 	References References `json:"references,omitempty"`
+	Paths      []string   `json:"paths,omitempty"`
+	IPNSNames  []string   `json:"ipns_names,omitempty"`
+	DNSLinks   []string   `json:"dnslink,omitempty"`
+	Sources    []string   `json:"source,omitempty"`
+	RefCount   int        `json:"reference_count"`
+	Popularity float64    `json:"popularity"`
 }
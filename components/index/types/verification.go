@@ -0,0 +1,11 @@
+package types
+
+import (
+	"time"
+)
+
+// Verification represents the result of checking whether the content of a Document is still retrievable.
+type Verification struct {
+	Reachable   bool      `json:"reachable"`
+	LastChecked time.Time `json:"last_checked"`
+}
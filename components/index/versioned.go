@@ -0,0 +1,22 @@
+package index
+
+import (
+	"context"
+)
+
+// Version identifies the revision of a document, for optimistic concurrency control.
+type Version struct {
+	SeqNo       int64
+	PrimaryTerm int64
+}
+
+// Versioned is implemented by indexes supporting optimistic concurrency control, such that read-modify-write updates
+// of documents are not lost to concurrent modifications.
+type Versioned interface {
+	// GetVersioned retrieves fields of the document with id as Get, returning its version or nil when not found.
+	GetVersioned(ctx context.Context, id string, dst interface{}, fields ...string) (*Version, error)
+
+	// UpdateVersioned updates the properties of the document with id as Update, provided it is still at version v.
+	// Returns ErrConflict when the document has been modified since.
+	UpdateVersioned(ctx context.Context, id string, properties interface{}, v *Version) error
+}
@@ -0,0 +1,39 @@
+package ipfs
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type addResult struct {
+	Hash string
+}
+
+// Add adds (and pins) the data in r to the IPFS node as a CIDv1, returning its CID.
+// Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-add
+func (i *IPFS) Add(ctx context.Context, r io.Reader) (string, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Add")
+	defer span.End()
+
+	const cmd = "add"
+
+	body, contentType := multipartFile(r)
+
+	req := i.shell.Request(cmd).
+		Option("pin", true).
+		Option("cid-version", 1).
+		Header("Content-Type", contentType).
+		Body(body)
+
+	result := new(addResult)
+
+	if err := req.Exec(ctx, result); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return "", err
+	}
+
+	return result.Hash, nil
+}
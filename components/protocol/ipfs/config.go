@@ -1,14 +1,25 @@
 package ipfs
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
 	"github.com/c2h5oh/datasize"
 )
 
 // Config specifies the configuration for the IPFS protocol.
 type Config struct {
-	APIURL      string            // URL of an IPFS API endpoint (for Ls and Stat calls).
+	APIURL      string            // URL or multiaddr of an IPFS API endpoint (for Ls and Stat calls).
 	GatewayURL  string            // URL of an IPFS Gateway (to request content).
 	PartialSize datasize.ByteSize // Filesize of items which are being considered partials (chunks).
+
+	APITimeout time.Duration // Timeout for API requests; none when 0.
+	APIPrefix  string        // Path the API is served under, e.g. behind a reverse proxy; /api/v0 is appended to it.
+
+	PathPrefixes map[string]string // Namespace per resource type name (e.g. unsupported: /ipld); /ipfs for types not listed.
 }
 
 // DefaultConfig returns the default configuration for a Sniffer.
@@ -19,5 +30,47 @@ func DefaultConfig() *Config {
 		PartialSize: 262144,
 		// 256KB is the default chunker block size. Therefore, unreferenced files with exactly
 		// this size are very likely to be chunks of files (partials) rather than full files.
+
+		APITimeout: 0,
+		APIPrefix:  "",
+
+		PathPrefixes: map[string]string{},
+	}
+}
+
+// isMultiaddr returns true when APIURL is a multiaddr, e.g. /ip4/127.0.0.1/tcp/5001.
+func (c *Config) isMultiaddr() bool {
+	return strings.HasPrefix(c.APIURL, "/")
+}
+
+// Validate returns an error when APIURL is not a valid URL nor a multiaddr, when APIPrefix is used with a
+// multiaddr or when PathPrefixes is invalid.
+func (c *Config) Validate() error {
+	if err := c.validatePathPrefixes(); err != nil {
+		return err
 	}
+
+	if c.isMultiaddr() {
+		if c.APIPrefix != "" {
+			return errors.New("API prefix requires an API URL rather than a multiaddr")
+		}
+
+		return nil
+	}
+
+	if _, err := url.Parse(c.APIURL); err != nil {
+		return fmt.Errorf("invalid API URL: %w", err)
+	}
+
+	return nil
+}
+
+// apiURL returns the base URL (or multiaddr) of the API, including APIPrefix.
+func (c *Config) apiURL() string {
+	prefix := strings.Trim(c.APIPrefix, "/")
+	if prefix == "" {
+		return c.APIURL
+	}
+
+	return strings.TrimSuffix(c.APIURL, "/") + "/" + prefix
 }
@@ -0,0 +1,76 @@
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type dagImportResult struct {
+	Root struct {
+		Cid struct {
+			Value string `json:"/"`
+		}
+		PinErrorMsg string
+	}
+}
+
+// DagImport imports the CAR (Content Addressable aRchive) in r into the IPFS node, pinning its roots, and
+// returns the root CIDs.
+// Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-dag-import
+func (i *IPFS) DagImport(ctx context.Context, r io.Reader) ([]string, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.DagImport")
+	defer span.End()
+
+	const cmd = "dag/import"
+
+	body, contentType := multipartFile(r)
+
+	resp, err := i.shell.Request(cmd).
+		Option("pin-roots", true).
+		Header("Content-Type", contentType).
+		Body(body).
+		Send(ctx)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+	defer resp.Close()
+
+	if resp.Error != nil {
+		span.RecordError(ctx, resp.Error, trace.WithErrorStatus(codes.Error))
+		return nil, resp.Error
+	}
+
+	var roots []string
+
+	// The result is streamed as a JSON object per root.
+	dec := json.NewDecoder(resp.Output)
+	for {
+		var result dagImportResult
+
+		err := dec.Decode(&result)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return roots, err
+		}
+
+		if result.Root.PinErrorMsg != "" {
+			err := fmt.Errorf("pinning root %s: %s", result.Root.Cid.Value, result.Root.PinErrorMsg)
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return roots, err
+		}
+
+		roots = append(roots, result.Root.Cid.Value)
+	}
+
+	return roots, nil
+}
@@ -0,0 +1,93 @@
+package ipfs
+
+import (
+	"context"
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type DagImportTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	ipfs *IPFS
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+}
+
+func (s *DagImportTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.mockAPIServer.URL()
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+}
+
+func (s *DagImportTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+func (s *DagImportTestSuite) TestDagImportMultipleRoots() {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/dag/import?pin-roots=true", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`
+				{"Root":{"Cid":{"/":"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"},"PinErrorMsg":""}}
+				{"Root":{"Cid":{"/":"QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp"},"PinErrorMsg":""}}
+			`),
+		}).
+		Once()
+
+	roots, err := s.ipfs.DagImport(s.ctx, strings.NewReader("car"))
+
+	s.NoError(err)
+	s.Equal([]string{
+		"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+		"QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp",
+	}, roots)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *DagImportTestSuite) TestDagImportPinError() {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/dag/import?pin-roots=true", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"Root":{"Cid":{"/":"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"},"PinErrorMsg":"block not found"}}`),
+		}).
+		Once()
+
+	_, err := s.ipfs.DagImport(s.ctx, strings.NewReader("car"))
+
+	s.Error(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *DagImportTestSuite) TestDagImportError() {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/dag/import?pin-roots=true", mock.Anything).
+		Return(httpmock.Response{
+			Status: 500,
+			Body:   []byte(`{"Message":"invalid car header","Code":0,"Type":"error"}`),
+		}).
+		Once()
+
+	_, err := s.ipfs.DagImport(s.ctx, strings.NewReader("invalid"))
+
+	s.Error(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func TestDagImportTestSuite(t *testing.T) {
+	suite.Run(t, new(DagImportTestSuite))
+}
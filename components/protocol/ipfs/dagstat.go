@@ -0,0 +1,161 @@
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// refsResult is a single streamed result of refs.
+type refsResult struct {
+	Ref string
+	Err string
+}
+
+// dagStatWalker tracks the depth of blocks from (src, dst) edges returned by refs.
+type dagStatWalker struct {
+	maxDepth  uint
+	maxBlocks uint
+	depths    map[string]uint
+	stat      t.DagStat
+}
+
+// add records an edge, returning false when no further edges should be read.
+// Sources are always visited before their links, so an unknown source is the root (at depth 0).
+func (w *dagStatWalker) add(src, dst string) bool {
+	depth := w.depths[src] + 1
+
+	if w.maxDepth > 0 && depth > w.maxDepth {
+		// Refs are requested one level beyond the maximum, to detect deeper DAGs.
+		w.stat.Truncated = true
+		return true
+	}
+
+	if known, ok := w.depths[dst]; ok {
+		// Blocks can be linked to multiple times; only count them once, at the smallest depth.
+		if depth < known {
+			w.depths[dst] = depth
+		}
+		return true
+	}
+
+	if w.maxBlocks > 0 && w.stat.Blocks >= uint64(w.maxBlocks) {
+		w.stat.Truncated = true
+		return false
+	}
+
+	w.depths[dst] = depth
+	w.stat.Blocks++
+
+	if depth > w.stat.Depth {
+		w.stat.Depth = depth
+	}
+
+	return true
+}
+
+// refs walks the refs of path, with up to maxDepth levels and up to maxBlocks blocks.
+// Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-refs
+func (i *IPFS) refs(ctx context.Context, path string, maxDepth uint, maxBlocks uint) (*dagStatWalker, error) {
+	const cmd = "refs"
+
+	// Stop reading when maxBlocks has been reached.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req := i.shell.Request(cmd, path).
+		Option("recursive", true).
+		Option("format", "<src> <dst>")
+
+	if maxDepth > 0 {
+		req.Option("max-depth", maxDepth+1)
+	}
+
+	resp, err := req.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	w := &dagStatWalker{
+		maxDepth:  maxDepth,
+		maxBlocks: maxBlocks,
+		depths:    make(map[string]uint),
+		stat:      t.DagStat{Blocks: 1},
+	}
+
+	// The result is streamed as a JSON object per edge.
+	dec := json.NewDecoder(resp.Output)
+	for {
+		var result refsResult
+
+		err := dec.Decode(&result)
+		if errors.Is(err, io.EOF) {
+			return w, nil
+		}
+		if err != nil {
+			return w, err
+		}
+
+		if result.Err != "" {
+			return w, errors.New(result.Err)
+		}
+
+		edge := strings.Fields(result.Ref)
+		if len(edge) != 2 {
+			return w, fmt.Errorf("unexpected ref: %s", result.Ref)
+		}
+
+		if !w.add(edge[0], edge[1]) {
+			return w, nil
+		}
+	}
+}
+
+// DagStat returns structural statistics of the DAG of r, traversing it up to maxDepth levels and counting up to
+// maxBlocks blocks; when either is 0, it is unbounded.
+func (i *IPFS) DagStat(ctx context.Context, r *t.AnnotatedResource, maxDepth uint, maxBlocks uint) (*t.DagStat, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.DagStat")
+	defer span.End()
+
+	path := i.absolutePath(r)
+
+	result := new(objectStatResult)
+	if err := i.shell.Request("object/stat", path).Exec(ctx, result); err != nil {
+		if isInvalidResourceErr(err) {
+			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		}
+
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	w, err := i.refs(ctx, path, maxDepth, maxBlocks)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	stat := w.stat
+	stat.Size = result.CumulativeSize
+
+	span.SetAttributes(
+		label.Int64("blocks", int64(stat.Blocks)),
+		label.Bool("truncated", stat.Truncated),
+	)
+
+	return &stat, nil
+}
@@ -0,0 +1,137 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type DagStatTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	ipfs *IPFS
+	r    *t.AnnotatedResource
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+}
+
+func (s *DagStatTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.mockAPIServer.URL()
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+
+	s.r = &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmRoot",
+		},
+	}
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/object/stat?arg=%2Fipfs%2FQmRoot", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"Hash":"QmRoot","NumLinks":2,"BlockSize":100,"LinksSize":90,"DataSize":10,"CumulativeSize":6544}`),
+		}).
+		Once()
+}
+
+func (s *DagStatTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+func (s *DagStatTestSuite) mockRefs(maxDepth string, body string) {
+	url := "/api/v0/refs?arg=%2Fipfs%2FQmRoot&format=%3Csrc%3E+%3Cdst%3E"
+	if maxDepth != "" {
+		url += fmt.Sprintf("&max-depth=%s", maxDepth)
+	}
+	url += "&recursive=true"
+
+	s.mockAPIHandler.
+		On("Handle", "POST", url, mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(body),
+		}).
+		Once()
+}
+
+// refs is a DAG with a block linked to twice, at different depths:
+// QmRoot -> QmA -> QmC, QmRoot -> QmB -> QmA
+const refs = `
+	{"Ref":"QmRoot QmA","Err":""}
+	{"Ref":"QmA QmC","Err":""}
+	{"Ref":"QmRoot QmB","Err":""}
+	{"Ref":"QmB QmA","Err":""}
+`
+
+func (s *DagStatTestSuite) TestDagStat() {
+	s.mockRefs("", refs)
+
+	stat, err := s.ipfs.DagStat(s.ctx, s.r, 0, 0)
+
+	s.NoError(err)
+	s.Equal(&t.DagStat{
+		Blocks: 4,
+		Size:   6544,
+		Depth:  2,
+	}, stat)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *DagStatTestSuite) TestDagStatMaxDepth() {
+	// One level beyond the maximum depth is requested.
+	s.mockRefs("2", refs)
+
+	stat, err := s.ipfs.DagStat(s.ctx, s.r, 1, 0)
+
+	s.NoError(err)
+	s.Equal(&t.DagStat{
+		Blocks:    3,
+		Size:      6544,
+		Depth:     1,
+		Truncated: true,
+	}, stat)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *DagStatTestSuite) TestDagStatMaxBlocks() {
+	s.mockRefs("", refs)
+
+	stat, err := s.ipfs.DagStat(s.ctx, s.r, 0, 2)
+
+	s.NoError(err)
+	s.Equal(&t.DagStat{
+		Blocks:    2,
+		Size:      6544,
+		Depth:     1,
+		Truncated: true,
+	}, stat)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *DagStatTestSuite) TestDagStatRefError() {
+	s.mockRefs("", `{"Ref":"","Err":"merkledag: not found"}`)
+
+	_, err := s.ipfs.DagStat(s.ctx, s.r, 0, 0)
+
+	s.Error(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func TestDagStatTestSuite(t *testing.T) {
+	suite.Run(t, new(DagStatTestSuite))
+}
@@ -10,12 +10,13 @@ import (
 // namedPath returns the (escaped/raw) path for a resource.
 // If a reference is available, it is used to generate the filename to facilitate content
 // type detection (e.g. /ipfs/<parent_hash>/my_file.jpg instead of /ipfs/<file_hash>/).
-func namedPath(r *t.AnnotatedResource) string {
-	if ref := r.Reference; ref.Name != "" {
-		return fmt.Sprintf("/ipfs/%s/%s", ref.Parent.ID, url.PathEscape(ref.Name))
+// Named paths traverse UnixFS directories, so resources in other namespaces are addressed by CID.
+func (i *IPFS) namedPath(r *t.AnnotatedResource) string {
+	if ref := r.Reference; ref.Name != "" && i.config.pathPrefix(r.Type) == defaultPathPrefix {
+		return fmt.Sprintf("%s/%s/%s", defaultPathPrefix, ref.Parent.ID, url.PathEscape(ref.Name))
 	}
 
-	return absolutePath(r)
+	return i.absolutePath(r)
 }
 
 // GatewayURL returns the URL to request a resource from the gateway.
@@ -23,7 +24,7 @@ func namedPath(r *t.AnnotatedResource) string {
 // type detection (e.g. /ipfs/<parent_hash>/my_file.jpg instead of /ipfs/<file_hash>/).
 // Ref: http://docs.ipfs.io.ipns.localhost:8080/concepts/ipfs-gateway/#gateway-types
 func (i *IPFS) GatewayURL(r *t.AnnotatedResource) string {
-	url, err := i.gatewayURL.Parse(namedPath(r))
+	url, err := i.gatewayURL.Parse(i.namedPath(r))
 
 	if err != nil {
 		panic(fmt.Sprintf("error generating GatewayURL: %v", err))
@@ -102,6 +102,63 @@ func (s *GatewayURLTestSuite) TestEscapeURL() {
 	s.Equal(url, gatewayURL+"/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp/Killing_Yourself_to_Live:_85%25_of_a_True_Story.html")
 }
 
+func (s *GatewayURLTestSuite) TestGatewayURLPathPrefix() {
+	cfg := DefaultConfig()
+	cfg.GatewayURL = gatewayURL
+	cfg.PathPrefixes = map[string]string{
+		"unsupported": "/ipld/",
+	}
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+
+	ref := t.Reference{
+		Parent: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Name: "node",
+	}
+
+	ipld := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua",
+		},
+		Reference: ref,
+		Stat: t.Stat{
+			Type: t.UnsupportedType,
+		},
+	}
+
+	// IPLD nodes are addressed by CID in their namespace.
+	s.Equal(gatewayURL+"/ipld/bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua", s.ipfs.GatewayURL(ipld))
+
+	unixfs := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p",
+		},
+		Reference: ref,
+		Stat: t.Stat{
+			Type: t.FileType,
+		},
+	}
+
+	s.Equal(gatewayURL+"/ipfs/QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp/node", s.ipfs.GatewayURL(unixfs))
+}
+
+func (s *GatewayURLTestSuite) TestValidatePathPrefixes() {
+	cfg := DefaultConfig()
+
+	cfg.PathPrefixes = map[string]string{"unsupported": "/ipld"}
+	s.NoError(cfg.Validate())
+
+	cfg.PathPrefixes = map[string]string{"dag": "/ipld"}
+	s.Error(cfg.Validate())
+
+	cfg.PathPrefixes = map[string]string{"unsupported": "ipld"}
+	s.Error(cfg.Validate())
+}
+
 func TestGatewayURLTestSuite(t *testing.T) {
 	suite.Run(t, new(GatewayURLTestSuite))
 }
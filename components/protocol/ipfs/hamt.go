@@ -0,0 +1,123 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	unixfs "github.com/ipfs/go-unixfs"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	// maxNodeDataSize bounds the data read to detect HAMT shards; larger nodes are file chunks rather than shards.
+	maxNodeDataSize = 64 * 1024
+
+	// maxShardDepth bounds the nesting of shards; 64-bit hashes are exhausted after 8 levels at the default fanout.
+	maxShardDepth = 64
+)
+
+type objectLinksResult struct {
+	Links []struct {
+		Name, Hash string
+	}
+}
+
+// hamtFanout returns the fanout of the directory at path when it is a HAMT-sharded directory, or 0 otherwise.
+// Failing detection is not an error; listing reports errors for the directory.
+func (i *IPFS) hamtFanout(ctx context.Context, path string) uint64 {
+	resp, err := i.shell.Request("object/data", path).Send(ctx)
+	if err != nil {
+		return 0
+	}
+	defer resp.Close()
+
+	if resp.Error != nil {
+		return 0
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Output, maxNodeDataSize+1))
+	if err != nil || len(data) > maxNodeDataSize {
+		return 0
+	}
+
+	n, err := unixfs.FSNodeFromBytes(data)
+	if err != nil || n.Type() != unixfs.THAMTShard {
+		return 0
+	}
+
+	return n.Fanout()
+}
+
+// lsHAMT lists the entries of the HAMT-sharded directory r by traversing its shards, rather than relying on ls
+// doing so. Links of shards are prefixed with their (hex) bucket; links consisting only of a prefix refer to
+// child shards.
+func (i *IPFS) lsHAMT(ctx context.Context, r *t.AnnotatedResource, fanout uint64, out chan<- *t.AnnotatedResource) error {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.lsHAMT")
+	defer span.End()
+
+	prefixLen := len(fmt.Sprintf("%X", fanout-1))
+	namespace := i.config.pathPrefix(r.Type)
+
+	var walk func(hash string, depth int) error
+	walk = func(hash string, depth int) error {
+		if depth > maxShardDepth {
+			return fmt.Errorf("%w: HAMT shards nested over %d levels", t.ErrInvalidResource, maxShardDepth)
+		}
+
+		result := new(objectLinksResult)
+		if err := i.shell.Request("object/links", namespace+"/"+hash).Exec(ctx, result); err != nil {
+			if isInvalidResourceErr(err) {
+				err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+			}
+
+			return err
+		}
+
+		for _, link := range result.Links {
+			if len(link.Name) < prefixLen {
+				return fmt.Errorf("%w: invalid HAMT link name '%s'", t.ErrInvalidResource, link.Name)
+			}
+
+			if len(link.Name) == prefixLen {
+				if err := walk(link.Hash, depth+1); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			// Like ls without resolving, entries have an undefined type and size.
+			refR := t.AnnotatedResource{
+				Resource: &t.Resource{
+					Protocol: t.IPFSProtocol,
+					ID:       link.Hash,
+				},
+				Reference: t.Reference{
+					Parent: r.Resource,
+					Name:   link.Name[prefixLen:],
+				},
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- &refR:
+			}
+		}
+
+		return nil
+	}
+
+	if err := walk(r.ID, 0); err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return nil
+}
@@ -10,9 +10,12 @@ import (
 	"github.com/ipfs-search/ipfs-search/components/protocol"
 
 	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
+var logger = logging.New("ipfs")
+
 // IPFS implements the Protocol interface for the Interplanery Filesystem. It is concurrency-safe.
 type IPFS struct {
 	config *Config
@@ -23,9 +26,9 @@ type IPFS struct {
 	*instr.Instrumentation
 }
 
-// absolutePath returns the absolute (CID-only) path for a resource.
-func absolutePath(r *t.AnnotatedResource) string {
-	return fmt.Sprintf("/ipfs/%s", r.ID)
+// absolutePath returns the absolute (CID-only) path for a resource, in the namespace for its type.
+func (i *IPFS) absolutePath(r *t.AnnotatedResource) string {
+	return fmt.Sprintf("%s/%s", i.config.pathPrefix(r.Type), r.ID)
 }
 
 // New returns a new IPFS protocol.
@@ -40,8 +43,11 @@ func New(config *Config, client *http.Client, instr *instr.Instrumentation) *IPF
 		panic(fmt.Sprintf("gateway URL is not absolute: %s", gatewayURL))
 	}
 
-	// Create IPFS shell
-	shell := ipfs.NewShellWithClient(config.APIURL, client)
+	// Create IPFS shell; it copies client, so the timeout only applies to the shell.
+	shell := ipfs.NewShellWithClient(config.apiURL(), client)
+	if config.APITimeout > 0 {
+		shell.SetTimeout(config.APITimeout)
+	}
 
 	return &IPFS{
 		config,
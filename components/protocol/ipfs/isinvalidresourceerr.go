@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	ipfs "github.com/ipfs/go-ipfs-api"
-	"log"
 )
 
 // isInvalidResourceErr determines whether an error returned by protocol methods represents invalid content.
@@ -17,11 +16,11 @@ func isInvalidResourceErr(err error) bool {
 	ipfsErr, ok := err.(*ipfs.Error)
 
 	if !ok {
-		log.Printf("Unexpected protocol error: %T:%v", err, err)
+		logger.Warnf("Unexpected protocol error: %T:%v", err, err)
 		return false
 	}
 
-	log.Printf("*ipfs.Error: %v", ipfsErr.Message)
+	logger.Debugf("*ipfs.Error: %v", ipfsErr.Message)
 
 	switch ipfsErr.Message {
 	case "proto: required field \"Type\" not set", // Example: QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8
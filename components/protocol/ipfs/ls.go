@@ -124,11 +124,17 @@ func decodeLink(dec *json.Decoder) (*lsLink, error) {
 }
 
 // Ls returns a channel with AnnotatedResource's with Type and Size populated.
+// HAMT-sharded directories are listed by explicitly traversing their shards.
 func (i *IPFS) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
 	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Ls")
 	defer span.End()
 
-	path := absolutePath(r)
+	path := i.absolutePath(r)
+
+	if fanout := i.hamtFanout(ctx, path); fanout > 0 {
+		span.AddEvent(ctx, "hamt-sharded")
+		return i.lsHAMT(ctx, r, fanout, out)
+	}
 
 	resp, err := i.shell.Request("ls", path).
 		Option("resolve-type", false).
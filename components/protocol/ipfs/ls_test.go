@@ -14,6 +14,12 @@ import (
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
+var (
+	// UnixFS data of a plain directory, and of a HAMT shard with a 256 fanout.
+	directoryData = []byte{0x08, 0x01}
+	hamtShardData = []byte{0x08, 0x05, 0x12, 0x01, 0xff, 0x28, 0x22, 0x30, 0x80, 0x02}
+)
+
 type LsTestSuite struct {
 	suite.Suite
 
@@ -44,6 +50,15 @@ func (s *LsTestSuite) TearDownTest() {
 	s.mockAPIServer.Close()
 }
 
+// expectNodeData sets up the response to detecting whether r is a HAMT-sharded directory.
+func (s *LsTestSuite) expectNodeData(r *t.AnnotatedResource, data []byte) {
+	s.mockAPIHandler.
+		On("Handle", "POST", fmt.Sprintf("/api/v0/object/data?arg=%%2Fipfs%%2F%s", r.ID), mock.Anything).
+		Return(httpmock.Response{
+			Body: data,
+		})
+}
+
 func (s *LsTestSuite) TestLsEmpty() {
 	r := &t.AnnotatedResource{
 		Resource: &t.Resource{
@@ -53,6 +68,7 @@ func (s *LsTestSuite) TestLsEmpty() {
 	}
 
 	rURL := fmt.Sprintf("/api/v0/ls?arg=%%2Fipfs%%2F%s&resolve-type=false&size=false&stream=true", r.ID)
+	s.expectNodeData(r, nil)
 
 	// Setup mock handler
 	s.mockAPIHandler.
@@ -78,6 +94,8 @@ func (s *LsTestSuite) TestLsHAMTDirectory() {
 	}
 
 	rURL := fmt.Sprintf("/api/v0/ls?arg=%%2Fipfs%%2F%s&resolve-type=false&size=false&stream=true", r.ID)
+	// Without detecting the shards (e.g. object/data being unavailable), ls lists sharded directories.
+	s.expectNodeData(r, nil)
 
 	// Setup mock handler
 	s.mockAPIHandler.
@@ -174,6 +192,7 @@ func (s *LsTestSuite) TestNormalDirectory() {
 	}
 
 	rURL := fmt.Sprintf("/api/v0/ls?arg=%%2Fipfs%%2F%s&resolve-type=false&size=false&stream=true", r.ID)
+	s.expectNodeData(r, directoryData)
 
 	// Setup mock handler
 	s.mockAPIHandler.
@@ -244,6 +263,7 @@ func (s *LsTestSuite) TestLsInvalid() {
 	}
 
 	rURL := fmt.Sprintf("/api/v0/ls?arg=%%2Fipfs%%2F%s&resolve-type=false&size=false&stream=true", r.ID)
+	s.expectNodeData(r, directoryData)
 
 	for _, errStr := range errStrs {
 		msgStruct := &struct {
@@ -282,6 +302,7 @@ func (s *LsTestSuite) TestLsNonInvalid500() {
 	}
 
 	rURL := fmt.Sprintf("/api/v0/ls?arg=%%2Fipfs%%2F%s&resolve-type=false&size=false&stream=true", r.ID)
+	s.expectNodeData(r, directoryData)
 
 	msgStruct := &struct {
 		Message string
@@ -309,6 +330,103 @@ func (s *LsTestSuite) TestLsNonInvalid500() {
 	s.False(errors.Is(t.ErrInvalidResource, err))
 }
 
+func (s *LsTestSuite) TestLsShardedDirectory() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp", // Wikipedia
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	s.expectNodeData(r, hamtShardData)
+
+	// Root shard with an entry and a child shard.
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/object/links?arg=%2Fipfs%2FQmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"Hash":"QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp","Links":[
+				{"Name":"1EBack_of_the_moon.html","Hash":"bafkreidnsi74hf7n2dtidxnqjdyr6lxidnsikdgwxktd7m3duwkuwl2u5u","Size":5169},
+				{"Name":"4B","Hash":"QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv","Size":10000}
+			]}`),
+		}).
+		Once()
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/object/links?arg=%2Fipfs%2FQmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"Hash":"QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv","Links":[
+				{"Name":"07wiki","Hash":"QmZTR5bcpQD7cFgTorqxZDYaew1Wqgfbd2ud9QqGPAkK2V","Size":4986}
+			]}`),
+		}).
+		Once()
+
+	resultChan := make(chan *t.AnnotatedResource, 2)
+	err := s.ipfs.Ls(s.ctx, r, resultChan)
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	// Entries of all shards are listed, without bucket prefixes.
+	s.Equal(&t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "bafkreidnsi74hf7n2dtidxnqjdyr6lxidnsikdgwxktd7m3duwkuwl2u5u",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "Back_of_the_moon.html",
+		},
+	}, <-resultChan)
+
+	s.Equal(&t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmZTR5bcpQD7cFgTorqxZDYaew1Wqgfbd2ud9QqGPAkK2V",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "wiki",
+		},
+	}, <-resultChan)
+}
+
+func (s *LsTestSuite) TestLsShardedDirectoryShardError() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp",
+		},
+	}
+
+	s.expectNodeData(r, hamtShardData)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/object/links?arg=%2Fipfs%2FQmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"Links":[{"Name":"4B","Hash":"QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}]}`),
+		}).
+		Once()
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/object/links?arg=%2Fipfs%2FQmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv", mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Status: 500,
+			Body:   httpmock.ToJSON(map[string]interface{}{"Message": "context deadline exceeded", "Code": 0, "Type": "error"}),
+		}).
+		Once()
+
+	resultChan := make(chan *t.AnnotatedResource, 1)
+	err := s.ipfs.Ls(s.ctx, r, resultChan)
+
+	// Failing shards fail the listing, rather than silently leaving out their entries.
+	s.Error(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
 func TestLsTestSuite(t *testing.T) {
 	suite.Run(t, new(LsTestSuite))
 }
@@ -0,0 +1,28 @@
+package ipfs
+
+import (
+	"io"
+	"mime/multipart"
+)
+
+// multipartFile streams r as a single file in a multipart form, as expected by API commands taking a file argument.
+// It returns the form body and its content type.
+func multipartFile(r io.Reader) (io.Reader, string) {
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+
+	go func() {
+		part, err := w.CreateFormFile("file", "")
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+
+		// Propagates errors to the reader; closes normally when err is nil.
+		pw.CloseWithError(err)
+	}()
+
+	return pr, w.FormDataContentType()
+}
@@ -0,0 +1,51 @@
+package ipfs
+
+import (
+	"fmt"
+	"strings"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// defaultPathPrefix is the namespace of UnixFS content, used for resource types without a configured prefix.
+const defaultPathPrefix = "/ipfs"
+
+// resourceTypes are the resource types which path prefixes can be configured for.
+var resourceTypes = []t.ResourceType{
+	t.UndefinedType,
+	t.UnsupportedType,
+	t.FileType,
+	t.DirectoryType,
+	t.PartialType,
+}
+
+// validatePathPrefixes returns an error when PathPrefixes has unknown resource types or relative prefixes.
+func (c *Config) validatePathPrefixes() error {
+	for name, prefix := range c.PathPrefixes {
+		known := false
+		for _, rType := range resourceTypes {
+			if rType.String() == name {
+				known = true
+			}
+		}
+
+		if !known {
+			return fmt.Errorf("unknown resource type '%s' in path prefixes", name)
+		}
+
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("path prefix '%s' for %s is not absolute", prefix, name)
+		}
+	}
+
+	return nil
+}
+
+// pathPrefix returns the namespace to address resources of rType in, e.g. /ipfs or /ipld.
+func (c *Config) pathPrefix(rType t.ResourceType) string {
+	if prefix, ok := c.PathPrefixes[rType.String()]; ok {
+		return strings.TrimSuffix(prefix, "/")
+	}
+
+	return defaultPathPrefix
+}
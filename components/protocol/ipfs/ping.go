@@ -0,0 +1,32 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type versionResult struct {
+	Version string
+}
+
+// Ping returns an error when the IPFS API is unreachable, for checking the configuration at startup.
+// Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-version
+func (i *IPFS) Ping(ctx context.Context) error {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Ping")
+	defer span.End()
+
+	const cmd = "version"
+
+	result := new(versionResult)
+
+	if err := i.shell.Request(cmd).Exec(ctx, result); err != nil {
+		err = fmt.Errorf("IPFS API at %s unreachable: %w", i.config.apiURL(), err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package ipfs
+
+import (
+	"context"
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type PingTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	cfg *Config
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+}
+
+func (s *PingTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+
+	s.cfg = DefaultConfig()
+	s.cfg.APIURL = s.mockAPIServer.URL()
+}
+
+func (s *PingTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+func (s *PingTestSuite) TestPing() {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/version", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"Version":"0.8.0","Commit":"","Repo":"11","System":"amd64/linux","Golang":"go1.15.8"}`),
+		}).
+		Once()
+
+	i := New(s.cfg, http.DefaultClient, instr.New())
+
+	s.NoError(i.Ping(s.ctx))
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *PingTestSuite) TestPingPrefix() {
+	s.cfg.APIPrefix = "/proxy/ipfs/"
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/proxy/ipfs/api/v0/version", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"Version":"0.8.0"}`),
+		}).
+		Once()
+
+	i := New(s.cfg, http.DefaultClient, instr.New())
+
+	s.NoError(i.Ping(s.ctx))
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *PingTestSuite) TestPingUnreachable() {
+	s.mockAPIServer.Close()
+
+	i := New(s.cfg, http.DefaultClient, instr.New())
+
+	s.Error(i.Ping(s.ctx))
+}
+
+func (s *PingTestSuite) TestValidate() {
+	s.NoError(DefaultConfig().Validate())
+
+	s.cfg.APIURL = "/ip4/127.0.0.1/tcp/5001"
+	s.NoError(s.cfg.Validate())
+
+	s.cfg.APIPrefix = "/proxy"
+	s.Error(s.cfg.Validate())
+
+	s.cfg.APIURL = "http://[::1"
+	s.cfg.APIPrefix = ""
+	s.Error(s.cfg.Validate())
+}
+
+func TestPingTestSuite(t *testing.T) {
+	suite.Run(t, new(PingTestSuite))
+}
@@ -0,0 +1,93 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type resolveResult struct {
+	Path string
+}
+
+// validateName returns an error when name is not an IPNS name of a key (a CID or peer ID) or a (DNSLink) domain,
+// optionally followed by a path.
+func validateName(name string) error {
+	key := strings.TrimPrefix(name, "/ipns/")
+	if key == name {
+		return fmt.Errorf("name '%s' lacks /ipns/ prefix", name)
+	}
+
+	key = strings.SplitN(key, "/", 2)[0]
+
+	switch {
+	case key == "":
+		return fmt.Errorf("empty name '%s'", name)
+	case strings.Contains(key, "."):
+		// Domains are only known to be invalid after resolving them.
+		return nil
+	}
+
+	if _, err := cid.Decode(key); err == nil {
+		return nil
+	}
+
+	// Peer IDs of (inlined) ed25519 keys are plain base58 multihashes.
+	if _, err := multihash.FromB58String(key); err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("invalid key in name '%s'", name)
+}
+
+// Resolve resolves the IPNS name of a resource, setting the ID to the CID it currently points to.
+// Returns ErrInvalidResource for malformed names and ErrUnresolvable, which may be retried (up to the configured
+// number of attempts), when resolution fails.
+// Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-name-resolve
+func (i *IPFS) Resolve(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Resolve",
+		trace.WithAttributes(label.String("ipns_name", r.IPNSName)),
+	)
+	defer span.End()
+
+	if err := validateName(r.IPNSName); err != nil {
+		err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	const cmd = "name/resolve"
+
+	req := i.shell.Request(cmd, r.IPNSName).
+		Option("recursive", true)
+
+	result := new(resolveResult)
+
+	if err := req.Exec(ctx, result); err != nil {
+		// Names without a record may well be published later; whether they can be resolved is up to the attempts.
+		err = fmt.Errorf("%w: %v", t.ErrUnresolvable, err)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	// Expect a path of the form /ipfs/<cid>
+	cid := strings.TrimPrefix(result.Path, "/ipfs/")
+	if cid == result.Path || cid == "" || strings.Contains(cid, "/") {
+		err := fmt.Errorf("%w: unexpected path '%s' for %s", t.ErrInvalidResource, result.Path, r.IPNSName)
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return err
+	}
+
+	r.ID = cid
+
+	return nil
+}
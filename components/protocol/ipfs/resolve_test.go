@@ -0,0 +1,165 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type ResolveTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	ipfs *IPFS
+	r    *t.AnnotatedResource
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+	responseHeader http.Header
+}
+
+func (s *ResolveTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+	s.responseHeader = http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.mockAPIServer.URL()
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+
+	s.r = &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "/ipns/ipfs.io",
+		},
+		IPNSName: "/ipns/ipfs.io",
+	}
+}
+
+func (s *ResolveTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+// expectResolve sets up a response to resolving the name of s.r.
+func (s *ResolveTestSuite) expectResolve(response httpmock.Response) {
+	isResolve := mock.MatchedBy(func(url string) bool {
+		return strings.HasPrefix(url, "/api/v0/name/resolve?arg=%2Fipns%2Fipfs.io")
+	})
+
+	response.Header = s.responseHeader
+
+	s.mockAPIHandler.
+		On("Handle", "POST", isResolve, mock.Anything).
+		Return(response).
+		Once()
+}
+
+func (s *ResolveTestSuite) TestResolve() {
+	s.expectResolve(httpmock.Response{
+		Body: []byte(`{"Path":"/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}`),
+	})
+
+	s.NoError(s.ipfs.Resolve(s.ctx, s.r))
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal("QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv", s.r.ID)
+}
+
+func (s *ResolveTestSuite) TestUnexpectedPath() {
+	s.expectResolve(httpmock.Response{
+		Body: []byte(`{"Path":"/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv/docs"}`),
+	})
+
+	err := s.ipfs.Resolve(s.ctx, s.r)
+
+	s.True(errors.Is(err, t.ErrInvalidResource))
+	s.Equal("/ipns/ipfs.io", s.r.ID)
+}
+
+func (s *ResolveTestSuite) TestAPIError() {
+	msgStruct := &struct {
+		Message string
+		Code    int
+		Type    string
+	}{
+		"could not resolve name", 0, "error",
+	}
+
+	s.expectResolve(httpmock.Response{
+		Status: 500,
+		Body:   httpmock.ToJSON(msgStruct),
+	})
+
+	err := s.ipfs.Resolve(s.ctx, s.r)
+
+	// Retried, as records may be published later.
+	s.True(errors.Is(err, t.ErrUnresolvable))
+	s.False(errors.Is(err, t.ErrInvalidResource))
+}
+
+func (s *ResolveTestSuite) TestMalformedName() {
+	for _, name := range []string{"/ipns/", "/ipfs/ipfs.io", "/ipns/notakey", "/ipns//docs"} {
+		s.r.IPNSName = name
+
+		err := s.ipfs.Resolve(s.ctx, s.r)
+
+		// Final rather than retried.
+		s.True(errors.Is(err, t.ErrInvalidResource), name)
+		s.False(errors.Is(err, t.ErrUnresolvable), name)
+	}
+
+	s.mockAPIHandler.AssertNotCalled(s.T(), "Handle", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ResolveTestSuite) TestValidateName() {
+	s.NoError(validateName("/ipns/ipfs.io"))
+	s.NoError(validateName("/ipns/docs.ipfs.io/concepts"))
+	s.NoError(validateName("/ipns/QmNnooDu7bfjPFoTZYxMNLWUQJyrVwtbZg5gBMjTezGAJN"))
+	s.NoError(validateName("/ipns/k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8"))
+	s.NoError(validateName("/ipns/12D3KooWLQzUv2FHWGVPXTXSZpdHs7oHbXub2G5WC8Tx4NQhyd2d"))
+}
+
+func (s *ResolveTestSuite) TestAPITimeout() {
+	msgStruct := &struct {
+		Message string
+		Code    int
+		Type    string
+	}{
+		"context deadline exceeded", 0, "error",
+	}
+
+	s.expectResolve(httpmock.Response{
+		Status: 500,
+		Body:   httpmock.ToJSON(msgStruct),
+	})
+
+	err := s.ipfs.Resolve(s.ctx, s.r)
+
+	s.True(errors.Is(err, t.ErrUnresolvable))
+}
+
+func (s *ResolveTestSuite) TestUnreachable() {
+	s.mockAPIServer.Close()
+
+	err := s.ipfs.Resolve(s.ctx, s.r)
+
+	s.True(errors.Is(err, t.ErrUnresolvable))
+	s.False(errors.Is(err, t.ErrInvalidResource))
+}
+
+func TestResolveTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolveTestSuite))
+}
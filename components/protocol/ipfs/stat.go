@@ -16,6 +16,12 @@ type statResult struct {
 	CumulativeSize uint64
 }
 
+// objectStatResult is the result of object/stat.
+type objectStatResult struct {
+	NumLinks       int
+	CumulativeSize uint64
+}
+
 func typeFromString(strType string) t.ResourceType {
 	switch strType {
 	case "file":
@@ -35,6 +41,33 @@ func getSize(rType t.ResourceType, result *statResult) uint64 {
 	return result.CumulativeSize
 }
 
+// objectStat determines the type of path from whether it has links (directory) or not (file), for use when
+// files/stat does not return a type. Note that this is a heuristic; chunked files have links as well.
+// Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-object-stat
+func (i *IPFS) objectStat(ctx context.Context, path string) (t.ResourceType, uint64, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.objectStat")
+	defer span.End()
+
+	const cmd = "object/stat"
+
+	result := new(objectStatResult)
+
+	if err := i.shell.Request(cmd, path).Exec(ctx, result); err != nil {
+		if isInvalidResourceErr(err) {
+			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		}
+
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return t.UndefinedType, 0, err
+	}
+
+	if result.NumLinks > 0 {
+		return t.DirectoryType, result.CumulativeSize, nil
+	}
+
+	return t.FileType, result.CumulativeSize, nil
+}
+
 // Stat returns a AnnotatedResource with Type and Size populated.
 // Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-files-stat
 func (i *IPFS) Stat(ctx context.Context, r *t.AnnotatedResource) error {
@@ -43,7 +76,7 @@ func (i *IPFS) Stat(ctx context.Context, r *t.AnnotatedResource) error {
 
 	const cmd = "files/stat"
 
-	path := absolutePath(r)
+	path := i.absolutePath(r)
 	req := i.shell.Request(cmd, path)
 
 	result := new(statResult)
@@ -60,6 +93,17 @@ func (i *IPFS) Stat(ctx context.Context, r *t.AnnotatedResource) error {
 	rType := typeFromString(result.Type)
 	size := getSize(rType, result)
 
+	if result.Type == "" {
+		// Rather than skipping resources without type as unsupported, determine it from the node's links.
+		logger.Debugf("No type returned for %v, falling back to object stat", r)
+		span.AddEvent(ctx, "type-fallback")
+
+		var err error
+		if rType, size, err = i.objectStat(ctx, path); err != nil {
+			return err
+		}
+	}
+
 	r.Stat = t.Stat{
 		Type: rType,
 		Size: size,
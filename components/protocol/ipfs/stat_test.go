@@ -231,6 +231,45 @@ func (s *StatTestSuite) TestUnsupported() {
 	})
 }
 
+func (s *StatTestSuite) TestEmptyTypeFallback() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		},
+	}
+
+	statURL := fmt.Sprintf("/api/v0/files/stat?arg=%%2Fipfs%%2F%s", r.ID)
+	objectStatURL := fmt.Sprintf("/api/v0/object/stat?arg=%%2Fipfs%%2F%s", r.ID)
+
+	// Setup mock handler
+	s.mockAPIHandler.
+		On("Handle", "POST", statURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Hash":"QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv","Size":0,"CumulativeSize":6544,"Blocks":7,"Type":""}`),
+		}).
+		Once()
+
+	s.mockAPIHandler.
+		On("Handle", "POST", objectStatURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Hash":"QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv","NumLinks":7,"BlockSize":355,"LinksSize":353,"DataSize":2,"CumulativeSize":6544}`),
+		}).
+		Once()
+
+	err := s.ipfs.Stat(s.ctx, r)
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal(r.Stat, t.Stat{
+		Type: t.DirectoryType,
+		Size: 6544,
+	})
+}
+
 func (s *StatTestSuite) TestInvalid() {
 	errStrs := []string{
 		"proto: required field \"Type\" not set",             // Example: QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8
@@ -3,6 +3,7 @@ package protocol
 import (
 	"context"
 	"github.com/stretchr/testify/mock"
+	"io"
 
 	t "github.com/ipfs-search/ipfs-search/types"
 )
@@ -30,6 +31,24 @@ func (m *Mock) Ls(ctx context.Context, r *t.AnnotatedResource, c chan<- *t.Annot
 	return args.Error(0)
 }
 
+// Resolve mocks the corresponding method on the Protocol interface.
+func (m *Mock) Resolve(ctx context.Context, r *t.AnnotatedResource) error {
+	args := m.Called(ctx, r)
+	return args.Error(0)
+}
+
+// Add mocks the corresponding method on the Protocol interface.
+func (m *Mock) Add(ctx context.Context, r io.Reader) (string, error) {
+	args := m.Called(ctx, r)
+	return args.String(0), args.Error(1)
+}
+
+// DagStat mocks the corresponding method on the Protocol interface.
+func (m *Mock) DagStat(ctx context.Context, r *t.AnnotatedResource, maxDepth uint, maxBlocks uint) (*t.DagStat, error) {
+	args := m.Called(ctx, r, maxDepth, maxBlocks)
+	return args.Get(0).(*t.DagStat), args.Error(1)
+}
+
 // IsInvalidResourceErr mocks the corresponding method on the Protocol interface.
 func (m *Mock) IsInvalidResourceErr(err error) bool {
 	args := m.Called(err)
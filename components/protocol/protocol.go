@@ -3,6 +3,7 @@ package protocol
 
 import (
 	"context"
+	"io"
 
 	t "github.com/ipfs-search/ipfs-search/types"
 )
@@ -12,4 +13,7 @@ type Protocol interface {
 	GatewayURL(*t.AnnotatedResource) string
 	Stat(context.Context, *t.AnnotatedResource) error
 	Ls(context.Context, *t.AnnotatedResource, chan<- *t.AnnotatedResource) error
+	Resolve(context.Context, *t.AnnotatedResource) error
+	Add(context.Context, io.Reader) (string, error)
+	DagStat(ctx context.Context, r *t.AnnotatedResource, maxDepth uint, maxBlocks uint) (*t.DagStat, error)
 }
@@ -16,7 +16,39 @@ import (
 type Channel struct {
 	ch *amqp.Channel
 	*instr.Instrumentation
-	MessageTTL time.Duration
+	MessageTTL  time.Duration
+	ConsumerTag string
+	Exclusive   bool
+
+	DeadLetterSuffix string
+	Durable          bool
+	AutoDelete       bool
+	QueueMode        string
+
+	DelayedExchange string // Declared delayed-message exchange; empty when disabled.
+
+	confirm *confirmPublisher // Publishes retried messages with confirmation, on a channel of its own.
+}
+
+// deadLetterName returns the name of the dead-letter queue for the queue with the given name.
+func (c *Channel) deadLetterName(name string) string {
+	return name + c.DeadLetterSuffix
+}
+
+// declareDeadLetter declares the dead-letter queue for the queue with the given name.
+func (c *Channel) declareDeadLetter(name string) error {
+	_, err := c.ch.QueueDeclare(
+		c.deadLetterName(name), // name
+		true,                   // durable
+		false,                  // delete when unused
+		false,                  // exclusive
+		false,                  // no-wait
+		amqp.Table{
+			"x-queue-mode": "lazy",
+		},
+	)
+
+	return err
 }
 
 // Queue creates a named queue on a given chennel
@@ -24,17 +56,30 @@ func (c *Channel) Queue(ctx context.Context, name string) (*Queue, error) {
 	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel.Queue", trace.WithAttributes(label.String("queue", name)))
 	defer span.End()
 
+	args := amqp.Table{
+		"x-max-priority": 9, // Enable all 9 priorities
+		"x-message-ttl":  c.MessageTTL.Milliseconds(),
+		"x-queue-mode":   c.QueueMode, // "lazy" allows RabbitMQ to write queue to disk as fast as possible
+	}
+
+	if c.DeadLetterSuffix != "" {
+		// Note: enabling dead-lettering on existing queues requires deleting and re-creating them.
+		if err := c.declareDeadLetter(name); err != nil {
+			span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+			return nil, err
+		}
+
+		args["x-dead-letter-exchange"] = ""
+		args["x-dead-letter-routing-key"] = c.deadLetterName(name)
+	}
+
 	_, err := c.ch.QueueDeclare(
-		name,  // name
-		true,  // durable
-		false, // delete when unused
-		false, // exclusive
-		false, // no-wait
-		amqp.Table{
-			"x-max-priority": 9, // Enable all 9 priorities
-			"x-message-ttl":  c.MessageTTL.Milliseconds(),
-			"x-queue-mode":   "lazy", // Allow RabbitMQ to write queue to disk as fast as possible
-		},
+		name,         // name
+		c.Durable,    // durable
+		c.AutoDelete, // delete when unused
+		false,        // exclusive
+		false,        // no-wait
+		args,
 	)
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
@@ -50,5 +95,7 @@ func (c *Channel) Queue(ctx context.Context, name string) (*Queue, error) {
 
 // Close closes a Channel
 func (c *Channel) Close() error {
+	c.confirm.close()
+
 	return c.ch.Close()
 }
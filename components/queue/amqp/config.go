@@ -1,15 +1,28 @@
 package amqp
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"time"
+
+	"github.com/ipfs-search/ipfs-search/utils"
 )
 
 // Config specifies the configuration for an AMQP queue.
 type Config struct {
-	URL           string
-	MaxReconnect  int
-	ReconnectTime time.Duration
-	MessageTTL    time.Duration
+	URL              string
+	URLFile          string // Read the URL from this file instead, re-reading it on reconnect; disabled when empty.
+	MaxReconnect     int
+	ReconnectTime    time.Duration
+	MessageTTL       time.Duration
+	ConsumerTag      string // Defaults to <hostname>-<pid> when empty.
+	Exclusive        bool
+	DeadLetterSuffix string // Rejected messages are routed to <queue><suffix>; disabled when empty.
+	Durable          bool   // Declare queues as durable, surviving broker restarts.
+	AutoDelete       bool   // Delete queues when their last consumer unsubscribes.
+	QueueMode        string // Value of x-queue-mode: "lazy" moves messages to disk as early as possible, or "default".
+	DelayedExchange  string // Delayed-message exchange for delayed publishing, requiring the plugin; disabled when empty.
 }
 
 // DefaultConfig generates a default configuration for an AMQP queue.
@@ -19,5 +32,54 @@ func DefaultConfig() *Config {
 		MaxReconnect:  100,
 		ReconnectTime: 2 * time.Second,
 		MessageTTL:    4 * time.Hour,
+		Durable:       true,
+		AutoDelete:    false,
+		QueueMode:     "lazy",
+	}
+}
+
+// dialURL returns the URL of the AMQP server, read from URLFile when set.
+func (c *Config) dialURL() (string, error) {
+	if c.URLFile == "" {
+		return c.URL, nil
 	}
+
+	return utils.ReadSecretFile(c.URLFile)
+}
+
+// redactURL returns u without its password, for logging and tracing.
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "<invalid URL>"
+	}
+
+	if parsed.User != nil {
+		parsed.User = url.User(parsed.User.Username())
+	}
+
+	return parsed.String()
+}
+
+// Validate returns an error for invalid or incompatible queue declaration options, or when URLFile can't be read.
+func (c *Config) Validate() error {
+	if _, err := c.dialURL(); err != nil {
+		return fmt.Errorf("reading URL file: %w", err)
+	}
+
+	if c.QueueMode != "lazy" && c.QueueMode != "default" {
+		return fmt.Errorf("invalid queue mode '%s', expected 'lazy' or 'default'", c.QueueMode)
+	}
+
+	if c.AutoDelete && c.DeadLetterSuffix != "" {
+		// Dead-lettered messages are replayed to the queue they came from, which might not exist anymore.
+		return errors.New("auto-deleted queues cannot be combined with dead-lettering")
+	}
+
+	if c.AutoDelete && c.Exclusive {
+		// Queues would be deleted whenever the single exclusive consumer goes away, losing all messages.
+		return errors.New("auto-deleted queues cannot be combined with exclusive consumers")
+	}
+
+	return nil
 }
@@ -0,0 +1,137 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	// errReturned is returned when a published message could not be routed to its queue.
+	errReturned = errors.New("message returned as unroutable")
+
+	// errNotConfirmed is returned when the broker failed to take responsibility for a published message.
+	errNotConfirmed = errors.New("message not confirmed by broker")
+)
+
+// waitConfirm waits for the broker to confirm a published message, returning errReturned when it was returned as
+// unroutable and errNotConfirmed when the broker failed to take responsibility for it.
+func waitConfirm(ctx context.Context, confirms <-chan amqp.Confirmation, returns <-chan amqp.Return) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c, ok := <-confirms:
+		if !ok {
+			return amqp.ErrClosed
+		}
+
+		if !c.Ack {
+			return errNotConfirmed
+		}
+	}
+
+	// Unroutable messages are returned before they are confirmed.
+	select {
+	case r := <-returns:
+		return fmt.Errorf("%w: %s", errReturned, r.ReplyText)
+	default:
+		return nil
+	}
+}
+
+// confirmChannel is the part of an AMQP channel used for confirmed publishing.
+type confirmChannel interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	NotifyReturn(c chan amqp.Return) chan amqp.Return
+	Close() error
+}
+
+// confirmPublisher publishes messages one at a time on a channel of its own in confirm mode, waiting for the broker
+// to confirm each, such that the delivery a message replaces is only acknowledged once the broker took
+// responsibility for it.
+//
+// Publishing is serialized, so that confirmations and returns unambiguously belong to the message published last.
+// The channel is (re)opened on demand, and closed whenever its confirmation could not be awaited, as it may still
+// arrive.
+type confirmPublisher struct {
+	open func() (confirmChannel, error)
+
+	mu       sync.Mutex
+	ch       confirmChannel
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+}
+
+func newConfirmPublisher(open func() (confirmChannel, error)) *confirmPublisher {
+	return &confirmPublisher{
+		open: open,
+	}
+}
+
+// channel returns the channel in confirm mode, opening it when necessary; p.mu must be held.
+func (p *confirmPublisher) channel() (confirmChannel, error) {
+	if p.ch != nil {
+		return p.ch, nil
+	}
+
+	ch, err := p.open()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	p.ch = ch
+	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	p.returns = ch.NotifyReturn(make(chan amqp.Return, 1))
+
+	return ch, nil
+}
+
+// reset closes the channel, to be reopened by the next publication; p.mu must be held.
+func (p *confirmPublisher) reset() {
+	if p.ch != nil {
+		p.ch.Close()
+		p.ch = nil
+	}
+}
+
+// publish publishes msg and waits for the broker to confirm it, returning errReturned or errNotConfirmed when it
+// was returned or not confirmed.
+func (p *confirmPublisher) publish(ctx context.Context, exchange, key string, mandatory bool, msg amqp.Publishing) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.channel()
+	if err != nil {
+		return err
+	}
+
+	if err := ch.Publish(exchange, key, mandatory, false, msg); err != nil {
+		p.reset()
+		return err
+	}
+
+	err = waitConfirm(ctx, p.confirms, p.returns)
+	if err != nil && !errors.Is(err, errReturned) && !errors.Is(err, errNotConfirmed) {
+		p.reset()
+	}
+
+	return err
+}
+
+// close closes the channel, if open.
+func (p *confirmPublisher) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reset()
+}
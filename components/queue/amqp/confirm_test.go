@@ -0,0 +1,83 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitConfirmCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := waitConfirm(ctx, make(chan amqp.Confirmation), make(chan amqp.Return))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestWaitConfirmClosed(t *testing.T) {
+	confirms := make(chan amqp.Confirmation)
+	close(confirms)
+
+	err := waitConfirm(context.Background(), confirms, make(chan amqp.Return))
+	assert.Equal(t, amqp.ErrClosed, err)
+}
+
+// publisher returns a confirmPublisher opening the given channels in order.
+func publisher(channels ...*replayChannelFake) *confirmPublisher {
+	return newConfirmPublisher(func() (confirmChannel, error) {
+		ch := channels[0]
+		channels = channels[1:]
+		return ch, nil
+	})
+}
+
+func TestConfirmPublisherConfirmed(t *testing.T) {
+	ch := &replayChannelFake{}
+	p := publisher(ch)
+
+	assert.NoError(t, p.publish(context.Background(), "", "files", true, amqp.Publishing{}))
+	assert.NoError(t, p.publish(context.Background(), "", "files", true, amqp.Publishing{}))
+
+	// Opened once, in confirm mode.
+	assert.True(t, ch.confirmMode)
+	assert.Len(t, ch.published, 2)
+}
+
+func TestConfirmPublisherReturned(t *testing.T) {
+	ch := &replayChannelFake{unroutable: true}
+	p := publisher(ch)
+
+	err := p.publish(context.Background(), "", "files", true, amqp.Publishing{})
+
+	assert.True(t, errors.Is(err, errReturned))
+	assert.False(t, ch.closed)
+}
+
+func TestConfirmPublisherNotConfirmed(t *testing.T) {
+	ch := &replayChannelFake{nack: true}
+	p := publisher(ch)
+
+	err := p.publish(context.Background(), "", "files", true, amqp.Publishing{})
+
+	assert.True(t, errors.Is(err, errNotConfirmed))
+}
+
+func TestConfirmPublisherCancelled(t *testing.T) {
+	first := &replayChannelFake{lost: true}
+	second := &replayChannelFake{}
+	p := publisher(first, second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := p.publish(ctx, "", "files", true, amqp.Publishing{})
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, first.closed)
+
+	// A late confirmation can't be mistaken for that of the next message, published on a new channel.
+	assert.NoError(t, p.publish(context.Background(), "", "files", true, amqp.Publishing{}))
+	assert.Len(t, second.published, 1)
+}
@@ -2,7 +2,8 @@ package amqp
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/streadway/amqp"
@@ -11,8 +12,11 @@ import (
 	"go.opentelemetry.io/otel/label"
 
 	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
 )
 
+var logger = logging.New("amqp")
+
 // Connection wraps an AMQP connection
 type Connection struct {
 	config *Config
@@ -22,10 +26,18 @@ type Connection struct {
 
 // NewConnection returns new AMQP connection
 func NewConnection(ctx context.Context, cfg *Config, amqpConfig *amqp.Config, i *instr.Instrumentation) (*Connection, error) {
-	ctx, span := i.Tracer.Start(ctx, "queue.amqp.NewConnection", trace.WithAttributes(label.String("amqp_url", cfg.URL)))
+	ctx, span := i.Tracer.Start(ctx, "queue.amqp.NewConnection")
 	defer span.End()
 
-	amqpConn, err := amqp.DialConfig(cfg.URL, *amqpConfig)
+	dialURL, err := cfg.dialURL()
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	span.SetAttributes(label.String("amqp_url", redactURL(dialURL)))
+
+	amqpConn, err := amqp.DialConfig(dialURL, *amqpConfig)
 
 	if err != nil {
 		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
@@ -57,17 +69,23 @@ func NewConnection(ctx context.Context, cfg *Config, amqpConfig *amqp.Config, i
 					span.AddEvent(ctx, "amqp-connection-blocked",
 						label.String("reason", b.Reason),
 					)
-					log.Println("AMQP connection blocked")
+					logger.Warnf("AMQP connection blocked")
 				} else {
 					span.AddEvent(ctx, "amqp-connection-unblocked")
-					log.Println("AMQP connection unblocked")
+					logger.Infof("AMQP connection unblocked")
 				}
 			case err := <-closeChan:
 				span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
-				log.Printf("AMQP connection lost, attempting reconnect in %s", cfg.ReconnectTime)
+				logger.Warnf("AMQP connection lost, attempting reconnect in %s", cfg.ReconnectTime)
 				time.Sleep(cfg.ReconnectTime)
 
-				amqpConn, amqpErr := amqp.Dial(cfg.URL)
+				// Re-read the URL, picking up rotated credentials.
+				var amqpConn *amqp.Connection
+				dialURL, amqpErr := cfg.dialURL()
+				if amqpErr == nil {
+					amqpConn, amqpErr = amqp.Dial(dialURL)
+				}
+
 				if amqpErr != nil {
 					if errCnt > cfg.MaxReconnect {
 						// TODO: Proper error propagation/recovery
@@ -75,7 +93,7 @@ func NewConnection(ctx context.Context, cfg *Config, amqpConfig *amqp.Config, i
 						panic("Repeated AMQP reconnect errors")
 					} else {
 						errCnt++
-						log.Printf("Error connecting to AMQP: %v", amqpErr)
+						logger.Errorf("Error connecting to AMQP: %v", amqpErr)
 						span.RecordError(ctx, amqpErr)
 					}
 
@@ -115,9 +133,23 @@ func (c *Connection) channel(ctx context.Context, prefetchCount int) (*Channel,
 	}
 
 	return &Channel{
-		ch:              ch,
-		Instrumentation: c.Instrumentation,
-		MessageTTL:      c.config.MessageTTL,
+		ch:               ch,
+		Instrumentation:  c.Instrumentation,
+		MessageTTL:       c.config.MessageTTL,
+		ConsumerTag:      c.config.ConsumerTag,
+		Exclusive:        c.config.Exclusive,
+		DeadLetterSuffix: c.config.DeadLetterSuffix,
+		Durable:          c.config.Durable,
+		AutoDelete:       c.config.AutoDelete,
+		QueueMode:        c.config.QueueMode,
+		confirm: newConfirmPublisher(func() (confirmChannel, error) {
+			ch, err := c.conn.Channel()
+			if err != nil {
+				return nil, err
+			}
+
+			return ch, nil
+		}),
 	}, nil
 }
 
@@ -134,6 +166,104 @@ func (c *Connection) NewChannelQueue(ctx context.Context, name string, prefetchC
 	return ch.Queue(ctx, name)
 }
 
+// exchangeChannel is the part of a channel used for declaring exchanges.
+type exchangeChannel interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	ExchangeDeclarePassive(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	Close() error
+}
+
+// isNotFound returns true for errors signaling that an entity, e.g. an exchange, does not exist.
+func isNotFound(err error) bool {
+	var amqpErr *amqp.Error
+	return errors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound
+}
+
+// declareExchange declares the delayed-message exchange name, unless it exists already. Each declaration uses a
+// channel of its own from open, as failing declarations close their channel.
+//
+// Exchanges are checked with a passive declaration first: a missing exchange is a channel error. Declaring an
+// exchange of an unknown type, i.e. without the rabbitmq_delayed_message_exchange plugin, is a connection error
+// instead, which closes the connection; hence the delayed exchange is opt-in.
+func declareExchange(name string, open func() (exchangeChannel, error)) error {
+	ch, err := open()
+	if err != nil {
+		return err
+	}
+
+	err = ch.ExchangeDeclarePassive(name, delayedExchangeType, true, false, false, false, nil)
+	ch.Close()
+
+	if !isNotFound(err) {
+		return err
+	}
+
+	if ch, err = open(); err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	err = ch.ExchangeDeclare(
+		name,                // name
+		delayedExchangeType, // type
+		true,                // durable
+		false,               // auto-deleted
+		false,               // internal
+		false,               // no-wait
+		amqp.Table{
+			"x-delayed-type": "direct",
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("declaring delayed-message exchange (is the rabbitmq_delayed_message_exchange plugin enabled?): %w", err)
+	}
+
+	return nil
+}
+
+// declareDelayedExchange declares the configured delayed-message exchange, returning its name, or an empty name when
+// disabled.
+func (c *Connection) declareDelayedExchange(ctx context.Context) (string, error) {
+	name := c.config.DelayedExchange
+	if name == "" {
+		return "", nil
+	}
+
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.declareDelayedExchange", trace.WithAttributes(label.String("exchange", name)))
+	defer span.End()
+
+	err := declareExchange(name, func() (exchangeChannel, error) {
+		return c.conn.Channel()
+	})
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return "", err
+	}
+
+	return name, nil
+}
+
+// NewDelayedChannelQueue returns a new delayed queue on a new channel, using the delayed-message exchange when
+// configured or a delay queue otherwise.
+func (c *Connection) NewDelayedChannelQueue(ctx context.Context, name string, delay time.Duration, jitter time.Duration) (*DelayedQueue, error) {
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.NewDelayedChannelQueue", trace.WithAttributes(label.String("queue", name)))
+	defer span.End()
+
+	exchange, err := c.declareDelayedExchange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := c.channel(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	ch.DelayedExchange = exchange
+
+	return ch.DelayedQueue(ctx, name, delay, jitter)
+}
+
 func (c *Connection) String() string {
 	return c.conn.LocalAddr().String()
 }
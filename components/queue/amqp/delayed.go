@@ -0,0 +1,196 @@
+package amqp
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// delayedSuffix is appended to the name of a queue for its delay queue.
+const delayedSuffix = ".delayed"
+
+// delayedExchangeType is the exchange type provided by the rabbitmq_delayed_message_exchange plugin.
+const delayedExchangeType = "x-delayed-message"
+
+// delayHeader is the header specifying the delay of messages, in milliseconds, to the delayed-message exchange.
+const delayHeader = "x-delay"
+
+// DelayedQueue publishes to a queue after a delay with random jitter.
+//
+// When the channel has a delayed-message exchange, messages are published to it and held by the broker until
+// their delay passes. Otherwise, messages are held in a delay queue without consumers, from which they are
+// dead-lettered into the target queue as they expire. Note that RabbitMQ only expires messages at the head of the
+// queue, hence messages may then be delayed by up to the longest delay of messages before them. Retries, of which
+// the delay grows with every attempt, are therefore held in a delay queue per delay instead.
+type DelayedQueue struct {
+	*Queue // Target queue with a delayed exchange, delay queue otherwise.
+
+	Delay  time.Duration
+	Jitter time.Duration
+
+	exchange string // Delayed-message exchange; empty for the delay queue.
+	target   string // Name of the target queue.
+
+	mu     sync.Mutex
+	levels map[time.Duration]*Queue // Delay queues with a fixed delay, by delay.
+}
+
+// delayQueueName returns the name of the delay queue for the queue with the given name, holding messages for ttl
+// or, when 0, until their expiration.
+func delayQueueName(name string, ttl time.Duration) string {
+	if ttl == 0 {
+		return name + delayedSuffix
+	}
+
+	return name + delayedSuffix + "." + strconv.FormatInt(ttl.Milliseconds(), 10)
+}
+
+// delayQueue declares the delay queue for the queue with the given name, holding messages for ttl or, when 0, until
+// their expiration.
+func (c *Channel) delayQueue(ctx context.Context, name string, ttl time.Duration) (*Queue, error) {
+	delayedName := delayQueueName(name, ttl)
+
+	args := amqp.Table{
+		"x-max-priority":            9,
+		"x-queue-mode":              c.QueueMode,
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": name,
+	}
+
+	if ttl > 0 {
+		args["x-message-ttl"] = ttl.Milliseconds()
+	}
+
+	_, err := c.ch.QueueDeclare(
+		delayedName, // name
+		c.Durable,   // durable
+		false,       // delete when unused; there are no consumers
+		false,       // exclusive
+		false,       // no-wait
+		args,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Queue{
+		channel:         c,
+		name:            delayedName,
+		Instrumentation: c.Instrumentation,
+	}, nil
+}
+
+// DelayedQueue declares a queue with the given name, as well as a delay queue publishing into it or, with a
+// delayed-message exchange, a binding to that exchange.
+func (c *Channel) DelayedQueue(ctx context.Context, name string, delay time.Duration, jitter time.Duration) (*DelayedQueue, error) {
+	ctx, span := c.Tracer.Start(ctx, "queue.amqp.Channel.DelayedQueue", trace.WithAttributes(label.String("queue", name)))
+	defer span.End()
+
+	// Make sure the target queue exists.
+	q, err := c.Queue(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.DelayedExchange != "" {
+		err = c.ch.QueueBind(
+			name,              // queue
+			name,              // routing key
+			c.DelayedExchange, // exchange
+			false,             // no-wait
+			nil,               // args
+		)
+	} else {
+		q, err = c.delayQueue(ctx, name, 0)
+	}
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return nil, err
+	}
+
+	return &DelayedQueue{
+		Queue:    q,
+		Delay:    delay,
+		Jitter:   jitter,
+		exchange: c.DelayedExchange,
+		target:   name,
+		levels:   make(map[time.Duration]*Queue),
+	}, nil
+}
+
+// delay returns the delay for a message, with random jitter.
+func (q *DelayedQueue) delay() time.Duration {
+	delay := q.Delay
+	if q.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(q.Jitter)))
+	}
+
+	return delay
+}
+
+// Publish adds a task to be moved to the target queue after the configured delay.
+func (q *DelayedQueue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	return q.PublishDelayed(ctx, params, priority, q.delay())
+}
+
+// PublishDelayed adds a task to be moved to the target queue after delay, e.g. for scheduling recrawls.
+func (q *DelayedQueue) PublishDelayed(ctx context.Context, params interface{}, priority uint8, delay time.Duration) error {
+	if q.exchange != "" {
+		return q.publish(ctx, q.exchange, params, priority, "", amqp.Table{
+			delayHeader: delay.Milliseconds(),
+		})
+	}
+
+	return q.publish(ctx, "", params, priority, strconv.FormatInt(delay.Milliseconds(), 10), nil)
+}
+
+// levelQueue returns the delay queue holding messages for delay, declaring it when first used.
+func (q *DelayedQueue) levelQueue(ctx context.Context, delay time.Duration) (*Queue, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if lq, ok := q.levels[delay]; ok {
+		return lq, nil
+	}
+
+	lq, err := q.channel.delayQueue(ctx, q.target, delay)
+	if err != nil {
+		return nil, err
+	}
+
+	q.levels[delay] = lq
+
+	return lq, nil
+}
+
+// RetryDelayed re-publishes a delivery like Queue.Retry, moving it into the target queue after delay.
+// Without a delayed-message exchange, it is held in the delay queue for delay, as delays vary by attempt.
+func (q *DelayedQueue) RetryDelayed(ctx context.Context, d *amqp.Delivery, body []byte, cause error, delay time.Duration) error {
+	p := retryPublishing(d, body, cause)
+
+	if q.exchange != "" {
+		p.Headers[delayHeader] = delay.Milliseconds()
+
+		return q.republish(ctx, q.exchange, q.target, p)
+	}
+
+	lq, err := q.levelQueue(ctx, delay)
+	if err != nil {
+		return err
+	}
+
+	return lq.republish(ctx, "", lq.name, p)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ queue.DelayedPublisher = &DelayedQueue{}
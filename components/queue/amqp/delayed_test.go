@@ -0,0 +1,135 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+func TestDelay(t *testing.T) {
+	q := &DelayedQueue{Delay: time.Minute}
+	assert.Equal(t, time.Minute, q.delay())
+
+	q.Jitter = time.Second
+	for i := 0; i < 100; i++ {
+		delay := q.delay()
+		assert.True(t, delay >= time.Minute && delay < time.Minute+time.Second, "delay %s", delay)
+	}
+}
+
+// exchangeChannelMock mocks an AMQP channel declaring exchanges.
+type exchangeChannelMock struct {
+	mock.Mock
+}
+
+func (m *exchangeChannelMock) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	return m.Called(name, kind).Error(0)
+}
+
+func (m *exchangeChannelMock) ExchangeDeclarePassive(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	return m.Called(name, kind).Error(0)
+}
+
+func (m *exchangeChannelMock) Close() error {
+	return m.Called().Error(0)
+}
+
+// opener returns a function opening the given channels in order.
+func opener(channels ...*exchangeChannelMock) func() (exchangeChannel, error) {
+	return func() (exchangeChannel, error) {
+		ch := channels[0]
+		channels = channels[1:]
+		return ch, nil
+	}
+}
+
+func TestDeclareExchangeExisting(t *testing.T) {
+	ch := &exchangeChannelMock{}
+	ch.On("ExchangeDeclarePassive", "delayed", delayedExchangeType).Return(nil).Once()
+	ch.On("Close").Return(nil).Once()
+
+	assert.NoError(t, declareExchange("delayed", opener(ch)))
+	ch.AssertExpectations(t)
+	ch.AssertNotCalled(t, "ExchangeDeclare", mock.Anything, mock.Anything)
+}
+
+func TestDeclareExchangeMissing(t *testing.T) {
+	notFound := &amqp.Error{Code: amqp.NotFound, Reason: "NOT_FOUND - no exchange 'delayed' in vhost '/'"}
+
+	// The failed passive declaration closes the first channel; the exchange is declared on a new one.
+	passive := &exchangeChannelMock{}
+	passive.On("ExchangeDeclarePassive", "delayed", delayedExchangeType).Return(notFound).Once()
+	passive.On("Close").Return(nil).Once()
+
+	declare := &exchangeChannelMock{}
+	declare.On("ExchangeDeclare", "delayed", delayedExchangeType).Return(nil).Once()
+	declare.On("Close").Return(nil).Once()
+
+	assert.NoError(t, declareExchange("delayed", opener(passive, declare)))
+	passive.AssertExpectations(t)
+	declare.AssertExpectations(t)
+}
+
+func TestDeclareExchangeWithoutPlugin(t *testing.T) {
+	notFound := &amqp.Error{Code: amqp.NotFound}
+	invalid := &amqp.Error{Code: amqp.CommandInvalid, Reason: "COMMAND_INVALID - unknown exchange type 'x-delayed-message'"}
+
+	passive := &exchangeChannelMock{}
+	passive.On("ExchangeDeclarePassive", "delayed", delayedExchangeType).Return(notFound).Once()
+	passive.On("Close").Return(nil).Once()
+
+	declare := &exchangeChannelMock{}
+	declare.On("ExchangeDeclare", "delayed", delayedExchangeType).Return(invalid).Once()
+	declare.On("Close").Return(nil).Once()
+
+	// Configuring an exchange without the plugin is an error, rather than silently falling back.
+	err := declareExchange("delayed", opener(passive, declare))
+	assert.True(t, errors.Is(err, invalid))
+}
+
+func TestDeclareExchangePassiveError(t *testing.T) {
+	ch := &exchangeChannelMock{}
+	ch.On("ExchangeDeclarePassive", "delayed", delayedExchangeType).Return(amqp.ErrClosed).Once()
+	ch.On("Close").Return(nil).Once()
+
+	assert.Equal(t, amqp.ErrClosed, declareExchange("delayed", opener(ch)))
+	ch.AssertNotCalled(t, "ExchangeDeclare", mock.Anything, mock.Anything)
+}
+
+func TestDeclareDelayedExchangeDisabled(t *testing.T) {
+	// Without a configured exchange, messages are delayed through a delay queue; the broker is not asked about the
+	// exchange at all (there's no connection here).
+	c := &Connection{
+		config:          DefaultConfig(),
+		Instrumentation: instr.New(),
+	}
+
+	name, err := c.declareDelayedExchange(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, name)
+}
+
+func TestDelayQueueName(t *testing.T) {
+	assert.Equal(t, "files.delayed", delayQueueName("files", 0))
+	assert.Equal(t, "files.delayed.60000", delayQueueName("files", time.Minute))
+}
+
+func TestLevelQueueDeclaredOnce(t *testing.T) {
+	lq := &Queue{name: "files.delayed.60000"}
+	q := &DelayedQueue{
+		target: "files",
+		levels: map[time.Duration]*Queue{time.Minute: lq},
+	}
+
+	// Declared once per delay.
+	got, err := q.levelQueue(context.Background(), time.Minute)
+	assert.NoError(t, err)
+	assert.Same(t, lq, got)
+}
@@ -2,7 +2,7 @@ package amqp
 
 import (
 	"context"
-	"log"
+	"time"
 
 	"github.com/streadway/amqp"
 	"go.opentelemetry.io/otel/api/trace"
@@ -18,6 +18,8 @@ type PublisherFactory struct {
 	*Config
 	AMQPConfig *amqp.Config
 	Queue      string
+	Delay      time.Duration // Delay publishing into Queue by this much (plus Jitter); disabled when 0.
+	Jitter     time.Duration // Maximum random additional delay.
 	*instr.Instrumentation
 }
 
@@ -40,9 +42,13 @@ func (f PublisherFactory) NewPublisher(ctx context.Context) (queue.Publisher, er
 	go func() {
 		<-ctx.Done()
 		span.AddEvent(ctx, "closing-amqp-context-closed")
-		log.Printf("Closing AMQP connection; context closed")
+		logger.Infof("Closing AMQP connection; context closed")
 		conn.Close()
 	}()
 
+	if f.Delay > 0 {
+		return conn.NewDelayedChannelQueue(ctx, f.Queue, f.Delay, f.Jitter)
+	}
+
 	return conn.NewChannelQueue(ctx, f.Queue, 1)
 }
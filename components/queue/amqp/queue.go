@@ -3,6 +3,9 @@ package amqp
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"os"
+	"time"
 
 	"github.com/streadway/amqp"
 	"go.opentelemetry.io/otel/api/trace"
@@ -29,6 +32,11 @@ func (q *Queue) String() string {
 // priority: higher number, higher priority
 // TODO: Add context parameter, allow for timeouts etc
 func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8) error {
+	return q.publish(ctx, "", params, priority, "", nil)
+}
+
+// publish adds a task to the Queue through exchange, expiring after expiration milliseconds unless empty.
+func (q *Queue) publish(ctx context.Context, exchange string, params interface{}, priority uint8, expiration string, headers amqp.Table) error {
 	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Publish",
 		trace.WithAttributes(label.String("queue", q.name)),
 		trace.WithAttributes(label.Any("params", params)),
@@ -42,16 +50,25 @@ func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8)
 		return err
 	}
 
+	// Propagate the trace context to consumers.
+	if headers == nil {
+		headers = amqp.Table{}
+	}
+	queue.InjectTraceContext(ctx, headers)
+
 	err = q.channel.ch.Publish(
-		"",     // exchange
-		q.name, // routing key
-		true,   // mandatory
-		false,  // immediate
+		exchange,       // exchange
+		q.name,         // routing key
+		exchange == "", // mandatory; delayed messages are only routed after their delay
+		false,          // immediate
 		amqp.Publishing{
+			Headers:      headers,
 			DeliveryMode: amqp.Transient,
 			ContentType:  "application/json",
 			Body:         body,
 			Priority:     priority,
+			Expiration:   expiration,
+			Timestamp:    time.Now(), // Allows for measuring the age of messages when consumed.
 		})
 
 	if err != nil {
@@ -61,19 +78,58 @@ func (q *Queue) Publish(ctx context.Context, params interface{}, priority uint8)
 	return err
 }
 
+// Depth returns the number of messages ready for delivery in the queue, through a passive declare.
+func (q *Queue) Depth(ctx context.Context) (int, error) {
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Depth",
+		trace.WithAttributes(label.String("queue", q.name)),
+	)
+	defer span.End()
+
+	state, err := q.channel.ch.QueueInspect(q.name)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return 0, err
+	}
+
+	return state.Messages, nil
+}
+
+// defaultConsumerTag returns <hostname>-<pid>, allowing operators to identify which worker holds which consumer.
+func defaultConsumerTag() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+func (q *Queue) consumerTag() string {
+	if q.channel.ConsumerTag != "" {
+		return q.channel.ConsumerTag
+	}
+
+	return defaultConsumerTag()
+}
+
 // Consume consumes messages from a queue
 func (q *Queue) Consume(ctx context.Context) (<-chan amqp.Delivery, error) {
-	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Consume")
+	consumerTag := q.consumerTag()
+
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Consume",
+		trace.WithAttributes(label.String("queue", q.name)),
+		trace.WithAttributes(label.String("consumer_tag", consumerTag)),
+	)
 	defer span.End()
 
 	c, err := q.channel.ch.Consume(
-		q.name, // queue
-		"",     // consumer
-		false,  // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
+		q.name,              // queue
+		consumerTag,         // consumer
+		false,               // auto-ack
+		q.channel.Exclusive, // exclusive
+		false,               // no-local
+		false,               // no-wait
+		nil,                 // args
 	)
 
 	if err != nil {
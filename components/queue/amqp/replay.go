@@ -0,0 +1,194 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+)
+
+// ErrNoDeadLetter is returned when replaying without dead-lettering configured.
+var ErrNoDeadLetter = errors.New("dead-lettering not configured")
+
+// ReplayOptions specifies which dead-lettered messages to replay.
+type ReplayOptions struct {
+	Reason   string // Only replay messages dead-lettered with this reason (e.g. "rejected", "expired"); all when empty.
+	MaxCount int    // Maximum number of messages to replay; all when 0.
+	DryRun   bool   // List matching messages without replaying them.
+}
+
+// lastDeath returns the most recent x-death entry of a dead-lettered delivery, or nil.
+func lastDeath(d *amqp.Delivery) amqp.Table {
+	deaths, ok := d.Headers["x-death"].([]interface{})
+	if !ok || len(deaths) == 0 {
+		return nil
+	}
+
+	// RabbitMQ keeps the most recent death first.
+	death, _ := deaths[0].(amqp.Table)
+	return death
+}
+
+// deathField returns a string field from an x-death entry.
+func deathField(death amqp.Table, field string) string {
+	v, _ := death[field].(string)
+	return v
+}
+
+// replayPublishing returns a Publishing for a dead-lettered delivery, with the x-death history cleared.
+func replayPublishing(d *amqp.Delivery) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		if k != "x-death" && !strings.HasPrefix(k, "x-first-death-") {
+			headers[k] = v
+		}
+	}
+
+	return amqp.Publishing{
+		Headers:      headers,
+		DeliveryMode: d.DeliveryMode,
+		ContentType:  d.ContentType,
+		Priority:     d.Priority,
+		Body:         d.Body,
+	}
+}
+
+// replayChannel is the part of an AMQP channel used for replaying.
+type replayChannel interface {
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Nack(tag uint64, multiple bool, requeue bool) error
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	NotifyReturn(c chan amqp.Return) chan amqp.Return
+}
+
+// Replay re-publishes messages from the dead-letter queue of q to the queue they were dead-lettered from,
+// returning the number of (matching) messages replayed.
+//
+// The channel is put in confirm mode, so that messages are only removed from the dead-letter queue once the broker
+// confirmed their replay. Messages which are skipped or could not be routed, or all messages in dry-run mode, are
+// returned to the dead-letter queue.
+func (q *Queue) Replay(ctx context.Context, opts ReplayOptions) (int, error) {
+	if q.channel.DeadLetterSuffix == "" {
+		return 0, ErrNoDeadLetter
+	}
+
+	dlName := q.channel.deadLetterName(q.name)
+
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.Replay",
+		trace.WithAttributes(label.String("queue", dlName)),
+		trace.WithAttributes(label.Bool("dry_run", opts.DryRun)),
+	)
+	defer span.End()
+
+	cnt, err := replay(ctx, q.channel.ch, dlName, q.name, opts)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return cnt, err
+}
+
+// replay re-publishes messages from the dead-letter queue dlName on ch, to the queue they were dead-lettered from or
+// name otherwise.
+func replay(ctx context.Context, ch replayChannel, dlName string, name string, opts ReplayOptions) (int, error) {
+	var (
+		cnt       int
+		unacked   uint64 // Highest tag of the deliveries which have not been acknowledged.
+		resultErr error
+
+		confirms chan amqp.Confirmation
+		returns  chan amqp.Return
+	)
+
+	if !opts.DryRun {
+		if err := ch.Confirm(false); err != nil {
+			return 0, err
+		}
+
+		confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+		returns = ch.NotifyReturn(make(chan amqp.Return, 1))
+	}
+
+	// Unacknowledged messages are not redelivered on this channel, hence getting until the queue is empty
+	// visits every message exactly once.
+	for opts.MaxCount == 0 || cnt < opts.MaxCount {
+		if err := ctx.Err(); err != nil {
+			resultErr = err
+			break
+		}
+
+		d, ok, err := ch.Get(dlName, false)
+		if err != nil {
+			resultErr = err
+			break
+		}
+
+		if !ok {
+			// Queue empty
+			break
+		}
+
+		// Acknowledging d restores the highest unacknowledged tag from before it.
+		prevUnacked := unacked
+		unacked = d.DeliveryTag
+
+		death := lastDeath(&d)
+		reason := deathField(death, "reason")
+		origin := deathField(death, "queue")
+		if origin == "" {
+			origin = name
+		}
+
+		if opts.Reason != "" && reason != opts.Reason {
+			continue
+		}
+
+		if opts.DryRun {
+			logger.Infof("Would replay message to '%s' (reason: %s): %s", origin, reason, d.Body)
+			cnt++
+			continue
+		}
+
+		if err := ch.Publish("", origin, true, false, replayPublishing(&d)); err != nil {
+			resultErr = err
+			break
+		}
+
+		if err := waitConfirm(ctx, confirms, returns); err != nil {
+			if errors.Is(err, errReturned) {
+				// E.g. the queue no longer exists; keep the message.
+				logger.Warnf("Could not replay message to '%s' (%v): %s", origin, err, d.Body)
+				continue
+			}
+
+			resultErr = err
+			break
+		}
+
+		logger.Infof("Replayed message to '%s' (reason: %s): %s", origin, reason, d.Body)
+		cnt++
+
+		if err := d.Ack(false); err != nil {
+			resultErr = err
+			break
+		}
+
+		unacked = prevUnacked
+	}
+
+	if unacked != 0 {
+		// Return unacknowledged messages to the dead-letter queue; nacking the tag of an acknowledged message
+		// would close the channel.
+		if err := ch.Nack(unacked, true, true); err != nil && resultErr == nil {
+			resultErr = err
+		}
+	}
+
+	return cnt, resultErr
+}
@@ -0,0 +1,194 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+// acknowledgerFake records acknowledgements of deliveries.
+type acknowledgerFake struct {
+	acked []uint64
+}
+
+func (a *acknowledgerFake) Ack(tag uint64, multiple bool) error {
+	a.acked = append(a.acked, tag)
+	return nil
+}
+
+func (a *acknowledgerFake) Nack(tag uint64, multiple bool, requeue bool) error {
+	return errors.New("unexpected nack")
+}
+
+func (a *acknowledgerFake) Reject(tag uint64, requeue bool) error {
+	return errors.New("unexpected reject")
+}
+
+// replayChannelFake serves dead-lettered deliveries, confirming or returning published messages.
+type replayChannelFake struct {
+	deliveries []amqp.Delivery
+	nack       bool // Nack published messages.
+	unroutable bool // Return published messages.
+	lost       bool // Never confirm published messages.
+
+	confirmMode bool
+	confirms    chan amqp.Confirmation
+	returns     chan amqp.Return
+	published   []amqp.Publishing
+	nacked      []uint64
+	closed      bool
+}
+
+func (c *replayChannelFake) Get(queue string, autoAck bool) (amqp.Delivery, bool, error) {
+	if len(c.deliveries) == 0 {
+		return amqp.Delivery{}, false, nil
+	}
+
+	d := c.deliveries[0]
+	c.deliveries = c.deliveries[1:]
+
+	return d, true, nil
+}
+
+func (c *replayChannelFake) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	c.published = append(c.published, msg)
+
+	if !c.confirmMode || c.lost {
+		return nil
+	}
+
+	if c.unroutable && mandatory {
+		c.returns <- amqp.Return{ReplyText: "NO_ROUTE", RoutingKey: key}
+	}
+
+	c.confirms <- amqp.Confirmation{DeliveryTag: uint64(len(c.published)), Ack: !c.nack}
+
+	return nil
+}
+
+func (c *replayChannelFake) Nack(tag uint64, multiple bool, requeue bool) error {
+	c.nacked = append(c.nacked, tag)
+	return nil
+}
+
+func (c *replayChannelFake) Confirm(noWait bool) error {
+	c.confirmMode = true
+	return nil
+}
+
+func (c *replayChannelFake) NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation {
+	c.confirms = confirm
+	return confirm
+}
+
+func (c *replayChannelFake) NotifyReturn(r chan amqp.Return) chan amqp.Return {
+	c.returns = r
+	return r
+}
+
+func (c *replayChannelFake) Close() error {
+	c.closed = true
+	return nil
+}
+
+func deadLettered(a *acknowledgerFake, tags ...uint64) []amqp.Delivery {
+	deliveries := make([]amqp.Delivery, len(tags))
+	for i, tag := range tags {
+		deliveries[i] = amqp.Delivery{
+			Acknowledger: a,
+			DeliveryTag:  tag,
+			Headers: amqp.Table{
+				"x-death": []interface{}{
+					amqp.Table{"reason": "rejected", "queue": "files"},
+				},
+			},
+			Body: []byte("{}"),
+		}
+	}
+
+	return deliveries
+}
+
+func TestReplayConfirmed(t *testing.T) {
+	a := &acknowledgerFake{}
+	ch := &replayChannelFake{deliveries: deadLettered(a, 1, 2)}
+
+	cnt, err := replay(context.Background(), ch, "files.dead", "files", ReplayOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cnt)
+	assert.True(t, ch.confirmMode)
+	assert.Len(t, ch.published, 2)
+	assert.NotContains(t, ch.published[0].Headers, "x-death")
+	assert.Equal(t, []uint64{1, 2}, a.acked)
+	assert.Empty(t, ch.nacked)
+}
+
+func TestReplayReturned(t *testing.T) {
+	a := &acknowledgerFake{}
+	ch := &replayChannelFake{deliveries: deadLettered(a, 1, 2), unroutable: true}
+
+	cnt, err := replay(context.Background(), ch, "files.dead", "files", ReplayOptions{})
+
+	// Kept in the dead-letter queue.
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cnt)
+	assert.Empty(t, a.acked)
+	assert.Equal(t, []uint64{2}, ch.nacked)
+}
+
+func TestReplayNotConfirmed(t *testing.T) {
+	a := &acknowledgerFake{}
+	ch := &replayChannelFake{deliveries: deadLettered(a, 1, 2), nack: true}
+
+	cnt, err := replay(context.Background(), ch, "files.dead", "files", ReplayOptions{})
+
+	assert.True(t, errors.Is(err, errNotConfirmed))
+	assert.Equal(t, 0, cnt)
+	assert.Empty(t, a.acked)
+	assert.Equal(t, []uint64{1}, ch.nacked)
+}
+
+func TestReplayDryRun(t *testing.T) {
+	a := &acknowledgerFake{}
+	ch := &replayChannelFake{deliveries: deadLettered(a, 1, 2)}
+
+	cnt, err := replay(context.Background(), ch, "files.dead", "files", ReplayOptions{DryRun: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cnt)
+	assert.False(t, ch.confirmMode)
+	assert.Empty(t, ch.published)
+	assert.Empty(t, a.acked)
+	assert.Equal(t, []uint64{2}, ch.nacked)
+}
+
+func TestReplayReason(t *testing.T) {
+	a := &acknowledgerFake{}
+	ch := &replayChannelFake{deliveries: deadLettered(a, 1)}
+
+	cnt, err := replay(context.Background(), ch, "files.dead", "files", ReplayOptions{Reason: "expired"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cnt)
+	assert.Empty(t, ch.published)
+	assert.Equal(t, []uint64{1}, ch.nacked)
+}
+
+func TestReplayReasonSkippedBeforeReplayed(t *testing.T) {
+	a := &acknowledgerFake{}
+	deliveries := deadLettered(a, 1, 2, 3)
+	deliveries[0].Headers["x-death"] = []interface{}{amqp.Table{"reason": "expired", "queue": "files"}}
+	ch := &replayChannelFake{deliveries: deliveries}
+
+	cnt, err := replay(context.Background(), ch, "files.dead", "files", ReplayOptions{Reason: "rejected"})
+
+	// Only the skipped message is returned, not the last (acknowledged) one.
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cnt)
+	assert.Equal(t, []uint64{2, 3}, a.acked)
+	assert.Equal(t, []uint64{1}, ch.nacked)
+}
@@ -0,0 +1,70 @@
+package amqp
+
+import (
+	"context"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// ErrorHistoryHeader is the header containing the errors of previous attempts of a retried message.
+const ErrorHistoryHeader = "x-error-history"
+
+// retryPublishing returns a Publishing for a delivery with the given body, adding cause to its error history.
+func retryPublishing(d *amqp.Delivery, body []byte, cause error) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+
+	history, _ := d.Headers[ErrorHistoryHeader].([]interface{})
+	headers[ErrorHistoryHeader] = append(append([]interface{}{}, history...), cause.Error())
+
+	return amqp.Publishing{
+		Headers:      headers,
+		DeliveryMode: d.DeliveryMode,
+		ContentType:  d.ContentType,
+		Priority:     d.Priority,
+		Body:         body,
+	}
+}
+
+// republish publishes a retried delivery through exchange with the given routing key, returning once the broker
+// confirmed it; the delivery may be acknowledged afterwards.
+func (q *Queue) republish(ctx context.Context, exchange string, key string, p amqp.Publishing) error {
+	ctx, span := q.Tracer.Start(ctx, "queue.amqp.republish",
+		trace.WithAttributes(label.String("queue", key)),
+	)
+	defer span.End()
+
+	// Continue the trace of the failed attempt, rather than that of the original publisher.
+	queue.InjectTraceContext(ctx, p.Headers)
+
+	// Mandatory unless delayed; delayed messages are only routed after their delay.
+	err := q.channel.confirm.publish(ctx, exchange, key, exchange == "", p)
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// Retry re-publishes a delivery to the queue with the given (updated) body, adding cause to the error history.
+// The delivery itself should be acknowledged afterwards, once Retry returned without error.
+func (q *Queue) Retry(ctx context.Context, d *amqp.Delivery, body []byte, cause error) error {
+	return q.republish(ctx, "", q.name, retryPublishing(d, body, cause))
+}
+
+// DeadLetter publishes a delivery to the dead-letter queue with the given body, adding cause to the error history.
+// The delivery itself should be acknowledged afterwards. Returns ErrNoDeadLetter without dead-lettering configured.
+func (q *Queue) DeadLetter(ctx context.Context, d *amqp.Delivery, body []byte, cause error) error {
+	if q.channel.DeadLetterSuffix == "" {
+		return ErrNoDeadLetter
+	}
+
+	return q.republish(ctx, "", q.channel.deadLetterName(q.name), retryPublishing(d, body, cause))
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"time"
+)
+
+// message is a published message, waiting for delivery.
+type message struct {
+	body        []byte
+	priority    uint8
+	seq         uint64 // Publication order, for FIFO delivery within a priority.
+	timestamp   time.Time
+	redelivered bool
+}
+
+// messages is a container/heap of messages, highest priority first and FIFO within a priority.
+type messages []*message
+
+func (m messages) Len() int { return len(m) }
+
+func (m messages) Less(i, j int) bool {
+	if m[i].priority != m[j].priority {
+		return m[i].priority > m[j].priority
+	}
+
+	return m[i].seq < m[j].seq
+}
+
+func (m messages) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+
+func (m *messages) Push(x interface{}) {
+	*m = append(*m, x.(*message))
+}
+
+func (m *messages) Pop() interface{} {
+	old := *m
+	n := len(old)
+	msg := old[n-1]
+	old[n-1] = nil
+	*m = old[:n-1]
+
+	return msg
+}
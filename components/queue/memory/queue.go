@@ -0,0 +1,204 @@
+// Package memory provides an in-memory Queue, allowing to crawl without a message broker in tests and
+// single-process deployments.
+package memory
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+)
+
+// Queue is an in-memory, channel-backed Queue, delivering messages highest priority first and, within a
+// priority, in order of publication. Deliveries are acknowledged as with AMQP; rejected or nacked deliveries
+// which are requeued are delivered again, marked as redelivered.
+type Queue struct {
+	capacity int
+
+	mu      sync.Mutex
+	ready   messages
+	unacked map[uint64]*message
+	seq     uint64
+	tag     uint64
+	changed chan struct{} // Closed and replaced whenever messages are added or removed.
+}
+
+// New returns a Queue holding up to capacity messages ready for delivery, or an unbounded Queue when capacity is 0.
+func New(capacity int) *Queue {
+	return &Queue{
+		capacity: capacity,
+		unacked:  make(map[uint64]*message),
+		changed:  make(chan struct{}),
+	}
+}
+
+// notify wakes up publishers and consumers waiting for a change; q.mu must be held.
+func (q *Queue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// wait blocks until cond holds or ctx is done, returning with q.mu held when cond holds.
+func (q *Queue) wait(ctx context.Context, cond func() bool) error {
+	q.mu.Lock()
+
+	for !cond() {
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+
+		q.mu.Lock()
+	}
+
+	return nil
+}
+
+// Publish adds a message with JSON-encoded pub, blocking while the Queue is full until ctx is done.
+// priority: higher number, higher priority
+func (q *Queue) Publish(ctx context.Context, pub interface{}, priority uint8) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(pub)
+	if err != nil {
+		return err
+	}
+
+	if err := q.wait(ctx, func() bool { return q.capacity == 0 || len(q.ready) < q.capacity }); err != nil {
+		return err
+	}
+	defer q.mu.Unlock()
+
+	q.seq++
+	heap.Push(&q.ready, &message{
+		body:      body,
+		priority:  priority,
+		seq:       q.seq,
+		timestamp: time.Now(),
+	})
+	q.notify()
+
+	return nil
+}
+
+// get takes the next message from the Queue as an unacknowledged delivery, blocking until one is ready or ctx
+// is done.
+func (q *Queue) get(ctx context.Context) (amqp.Delivery, error) {
+	if err := q.wait(ctx, func() bool { return len(q.ready) > 0 }); err != nil {
+		return amqp.Delivery{}, err
+	}
+	defer q.mu.Unlock()
+
+	m := heap.Pop(&q.ready).(*message)
+
+	q.tag++
+	q.unacked[q.tag] = m
+	q.notify()
+
+	return amqp.Delivery{
+		Acknowledger: q,
+		DeliveryTag:  q.tag,
+		ContentType:  "application/json",
+		Priority:     m.priority,
+		Timestamp:    m.timestamp,
+		Redelivered:  m.redelivered,
+		Body:         m.body,
+	}, nil
+}
+
+// Consume returns a channel of deliveries, which is closed when ctx is done. A delivery which could not be
+// handed to the consumer before ctx is done is requeued.
+func (q *Queue) Consume(ctx context.Context) (<-chan amqp.Delivery, error) {
+	deliveries := make(chan amqp.Delivery)
+
+	go func() {
+		defer close(deliveries)
+
+		for {
+			d, err := q.get(ctx)
+			if err != nil {
+				return
+			}
+
+			select {
+			case deliveries <- d:
+			case <-ctx.Done():
+				_ = q.Reject(d.DeliveryTag, true)
+				return
+			}
+		}
+	}()
+
+	return deliveries, nil
+}
+
+// Depth returns the number of messages ready for delivery.
+func (q *Queue) Depth(ctx context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.ready), nil
+}
+
+// settle removes the unacknowledged delivery with tag or, when multiple, all unacknowledged deliveries up to and
+// including tag (all of them for tag 0), requeueing them when requeue is set.
+func (q *Queue) settle(tag uint64, multiple bool, requeue bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.unacked[tag]; !ok && !(multiple && tag == 0) {
+		return fmt.Errorf("unknown delivery tag %d", tag)
+	}
+
+	for t, m := range q.unacked {
+		if t != tag && !(multiple && (tag == 0 || t < tag)) {
+			continue
+		}
+
+		delete(q.unacked, t)
+
+		if requeue {
+			m.redelivered = true
+			heap.Push(&q.ready, m)
+		}
+	}
+
+	if requeue {
+		q.notify()
+	}
+
+	return nil
+}
+
+// Ack implements amqp.Acknowledger, acknowledging deliveries.
+func (q *Queue) Ack(tag uint64, multiple bool) error {
+	return q.settle(tag, multiple, false)
+}
+
+// Nack implements amqp.Acknowledger, negatively acknowledging deliveries.
+func (q *Queue) Nack(tag uint64, multiple bool, requeue bool) error {
+	return q.settle(tag, multiple, requeue)
+}
+
+// Reject implements amqp.Acknowledger, rejecting a delivery.
+func (q *Queue) Reject(tag uint64, requeue bool) error {
+	return q.settle(tag, false, requeue)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var (
+	_ queue.Queue       = &Queue{}
+	_ amqp.Acknowledger = &Queue{}
+)
@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, deliveries <-chan amqp.Delivery) amqp.Delivery {
+	select {
+	case d, ok := <-deliveries:
+		assert.True(t, ok, "deliveries closed")
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for delivery")
+		return amqp.Delivery{}
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(0)
+
+	assert.NoError(t, q.Publish(ctx, "low", 1))
+	assert.NoError(t, q.Publish(ctx, "high first", 9))
+	assert.NoError(t, q.Publish(ctx, "medium", 5))
+	assert.NoError(t, q.Publish(ctx, "high second", 9))
+
+	depth, err := q.Depth(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, depth)
+
+	deliveries, err := q.Consume(ctx)
+	assert.NoError(t, err)
+
+	for _, expected := range []string{`"high first"`, `"high second"`, `"medium"`, `"low"`} {
+		d := receive(t, deliveries)
+		assert.Equal(t, expected, string(d.Body))
+		assert.Equal(t, "application/json", d.ContentType)
+		assert.NoError(t, d.Ack(false))
+	}
+}
+
+func TestPublishFull(t *testing.T) {
+	q := New(1)
+
+	assert.NoError(t, q.Publish(context.Background(), "first", 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, q.Publish(ctx, "second", 1))
+
+	depth, _ := q.Depth(ctx)
+	assert.Equal(t, 1, depth)
+}
+
+func TestPublishUnblocksOnConsume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(1)
+
+	assert.NoError(t, q.Publish(ctx, "first", 1))
+
+	published := make(chan error)
+	go func() {
+		published <- q.Publish(ctx, "second", 1)
+	}()
+
+	deliveries, err := q.Consume(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `"first"`, string(receive(t, deliveries).Body))
+	assert.NoError(t, <-published)
+	assert.Equal(t, `"second"`, string(receive(t, deliveries).Body))
+}
+
+func TestPublishCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	q := New(0)
+
+	assert.Equal(t, context.Canceled, q.Publish(ctx, "item", 1))
+
+	depth, _ := q.Depth(context.Background())
+	assert.Equal(t, 0, depth)
+}
+
+func TestConsumeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := New(0)
+
+	deliveries, err := q.Consume(ctx)
+	assert.NoError(t, err)
+
+	cancel()
+
+	select {
+	case _, ok := <-deliveries:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("deliveries not closed after cancellation")
+	}
+}
+
+func TestConsumeCancelledRequeues(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := New(0)
+	assert.NoError(t, q.Publish(ctx, "item", 1))
+
+	// The message is taken, but never received.
+	deliveries, err := q.Consume(ctx)
+	assert.NoError(t, err)
+
+	cancel()
+
+	for range deliveries {
+	}
+
+	deliveries, err = q.Consume(context.Background())
+	assert.NoError(t, err)
+
+	d := receive(t, deliveries)
+	assert.Equal(t, `"item"`, string(d.Body))
+}
+
+func TestRejectRequeue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(0)
+	assert.NoError(t, q.Publish(ctx, "item", 1))
+
+	deliveries, err := q.Consume(ctx)
+	assert.NoError(t, err)
+
+	d := receive(t, deliveries)
+	assert.False(t, d.Redelivered)
+	assert.NoError(t, d.Reject(true))
+
+	d = receive(t, deliveries)
+	assert.True(t, d.Redelivered)
+	assert.Equal(t, `"item"`, string(d.Body))
+	assert.NoError(t, d.Reject(false))
+
+	assert.Error(t, d.Ack(false), "settling twice")
+}
+
+func TestAckMultiple(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(0)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, q.Publish(ctx, i, 1))
+	}
+
+	deliveries, err := q.Consume(ctx)
+	assert.NoError(t, err)
+
+	first := receive(t, deliveries)
+	second := receive(t, deliveries)
+	third := receive(t, deliveries)
+
+	assert.NoError(t, second.Ack(true))
+	assert.Error(t, first.Ack(false), "acknowledged through second")
+	assert.NoError(t, third.Nack(false, true))
+
+	d := receive(t, deliveries)
+	assert.Equal(t, third.Body, d.Body)
+	assert.True(t, d.Redelivered)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 // Mock mocks the Queue interface.
@@ -17,6 +18,12 @@ func (m *Mock) Publish(ctx context.Context, pub interface{}, priority uint8) err
 	return args.Error(0)
 }
 
+// PublishDelayed mocks the corresponding method on the DelayedPublisher interface.
+func (m *Mock) PublishDelayed(ctx context.Context, pub interface{}, priority uint8, delay time.Duration) error {
+	args := m.Called(ctx, pub, priority, delay)
+	return args.Error(0)
+}
+
 // Consume mocks the corresponding method on the Queue interface.
 func (m *Mock) Consume(ctx context.Context) (<-chan amqp.Delivery, error) {
 	args := m.Called(ctx)
@@ -36,4 +43,5 @@ func (f *MockFactory) NewPublisher(ctx context.Context) (Publisher, error) {
 
 // Compile-time assurance that implementation satisfies interface.
 var _ Queue = &Mock{}
+var _ DelayedPublisher = &Mock{}
 var _ PublisherFactory = &MockFactory{}
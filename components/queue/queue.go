@@ -4,6 +4,7 @@ package queue
 import (
 	"context"
 	"github.com/streadway/amqp"
+	"time"
 )
 
 // Publisher allows publishing of sniffed items.
@@ -11,6 +12,12 @@ type Publisher interface {
 	Publish(context.Context, interface{}, uint8) error
 }
 
+// DelayedPublisher allows publishing of items for processing after a delay.
+type DelayedPublisher interface {
+	Publisher
+	PublishDelayed(context.Context, interface{}, uint8, time.Duration) error
+}
+
 // Consumer allows consuming of published items.
 type Consumer interface {
 	Consume(context.Context) (<-chan amqp.Delivery, error)
@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// headerCarrier allows propagating trace context through AMQP message headers.
+type headerCarrier amqp.Table
+
+// Get returns the string value of header key, or an empty string.
+func (c headerCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+// Set header key to value.
+func (c headerCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+// InjectTraceContext adds the trace context of ctx to headers (e.g. W3C `traceparent`), using the global propagator.
+func InjectTraceContext(ctx context.Context, headers amqp.Table) {
+	global.TextMapPropagator().Inject(ctx, headerCarrier(headers))
+}
+
+// ExtractTraceContext returns a context with the trace context propagated in the headers of d, making the span of
+// the publisher the parent of spans started with it, and whether d carries a trace context. The current span of ctx,
+// e.g. of a long-running consumer, is discarded as it would take precedence. Without trace context, ctx is returned.
+func ExtractTraceContext(ctx context.Context, d *amqp.Delivery) (context.Context, bool) {
+	if len(d.Headers) == 0 {
+		return ctx, false
+	}
+
+	remoteCtx := global.TextMapPropagator().Extract(ctx, headerCarrier(d.Headers))
+	if !trace.RemoteSpanContextFromContext(remoteCtx).IsValid() {
+		return ctx, false
+	}
+
+	// Replace the current span by a no-op span, which has no span context.
+	remoteCtx = trace.ContextWithSpan(remoteCtx, trace.SpanFromContext(context.Background()))
+
+	return remoteCtx, true
+}
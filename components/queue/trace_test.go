@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/propagators"
+)
+
+func TestTraceContext(t *testing.T) {
+	defer global.SetTextMapPropagator(global.TextMapPropagator())
+	global.SetTextMapPropagator(propagators.TraceContext{})
+
+	traceID, err := trace.IDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	assert.NoError(t, err)
+
+	sc := trace.SpanContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}
+
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), sc)
+
+	headers := amqp.Table{"x-delay": int64(1000)}
+	InjectTraceContext(ctx, headers)
+
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", headers["traceparent"])
+	assert.Equal(t, int64(1000), headers["x-delay"])
+
+	ctx, ok := ExtractTraceContext(context.Background(), &amqp.Delivery{Headers: headers})
+	assert.True(t, ok)
+	assert.Equal(t, sc, trace.RemoteSpanContextFromContext(ctx))
+	assert.False(t, trace.SpanFromContext(ctx).SpanContext().IsValid())
+}
+
+func TestExtractTraceContextNone(t *testing.T) {
+	defer global.SetTextMapPropagator(global.TextMapPropagator())
+	global.SetTextMapPropagator(propagators.TraceContext{})
+
+	ctx := context.Background()
+
+	extracted, ok := ExtractTraceContext(ctx, &amqp.Delivery{})
+	assert.False(t, ok)
+	assert.Equal(t, ctx, extracted)
+
+	_, ok = ExtractTraceContext(ctx, &amqp.Delivery{Headers: amqp.Table{"traceparent": "invalid"}})
+	assert.False(t, ok)
+}
@@ -0,0 +1,164 @@
+// Package seed bulk-queues resources for crawling from a list of CIDs.
+package seed
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/logging"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+var logger = logging.New("seed")
+
+// ErrInvalidLine is returned for lines which could not be parsed into a resource.
+var ErrInvalidLine = errors.New("invalid line")
+
+// Options configures Seed.
+type Options struct {
+	Rate             float64       // Maximum number of resources queued per second; unlimited when 0.
+	ProgressInterval time.Duration // Interval at which progress is logged; never when 0.
+	Priority         uint8         // Priority to queue resources with.
+}
+
+// Progress reports the progress of Seed.
+type Progress struct {
+	Lines   uint // Number of (non-empty, non-comment) lines read.
+	Queued  uint // Number of resources queued.
+	Invalid uint // Number of lines skipped as they were invalid.
+}
+
+// String returns a human-readable representation of progress, for logging.
+func (p Progress) String() string {
+	return fmt.Sprintf("%d lines read, %d queued, %d invalid", p.Lines, p.Queued, p.Invalid)
+}
+
+// line is a JSON line, allowing for the name and parent of a CID to be specified.
+type line struct {
+	CID    string `json:"cid"`
+	Name   string `json:"name"`
+	Parent string `json:"parent"`
+}
+
+// parseCID returns the CID in s, optionally prefixed with /ipfs/.
+func parseCID(s string) (string, error) {
+	s = strings.TrimPrefix(s, "/ipfs/")
+
+	if _, err := cid.Decode(s); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrInvalidLine, s, err)
+	}
+
+	return s, nil
+}
+
+// parseLine parses either a bare CID or a JSON object with a cid and, optionally, a name and parent.
+func parseLine(s string) (*t.AnnotatedResource, error) {
+	l := line{CID: s}
+
+	if strings.HasPrefix(s, "{") {
+		l = line{}
+		if err := json.Unmarshal([]byte(s), &l); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLine, err)
+		}
+	}
+
+	id, err := parseCID(l.CID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       id,
+		},
+	}
+
+	if l.Parent != "" {
+		parent, err := parseCID(l.Parent)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Reference = t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       parent,
+			},
+			Name: l.Name,
+		}
+	} else if l.Name != "" {
+		return nil, fmt.Errorf("%w: name %s without parent", ErrInvalidLine, l.Name)
+	}
+
+	return r, nil
+}
+
+// Seed reads newline-delimited CIDs or JSON objects from r and publishes them to q, returning the progress.
+// Empty lines and lines starting with # are ignored. Invalid lines are logged and skipped; errors reading r or
+// publishing to q abort.
+func Seed(ctx context.Context, r io.Reader, q queue.Publisher, opts Options) (Progress, error) {
+	var (
+		progress   Progress
+		throttle   <-chan time.Time
+		lastReport = time.Now()
+		lineNo     uint
+	)
+
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+
+		throttle = ticker.C
+	}
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		lineNo++
+
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+
+		progress.Lines++
+
+		resource, err := parseLine(s)
+		if err != nil {
+			logger.Warnf("Skipping line %d: %v", lineNo, err)
+			progress.Invalid++
+			continue
+		}
+
+		if throttle != nil {
+			select {
+			case <-ctx.Done():
+				return progress, ctx.Err()
+			case <-throttle:
+			}
+		}
+
+		if err := q.Publish(ctx, resource, opts.Priority); err != nil {
+			return progress, fmt.Errorf("error queueing %s on line %d: %w", resource, lineNo, err)
+		}
+
+		progress.Queued++
+
+		if opts.ProgressInterval > 0 && time.Since(lastReport) >= opts.ProgressInterval {
+			logger.Infof("Seeding: %s", progress)
+			lastReport = time.Now()
+		}
+	}
+
+	return progress, scanner.Err()
+}
@@ -0,0 +1,148 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	testCID    = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+	testParent = "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
+)
+
+type SeedTestSuite struct {
+	suite.Suite
+	ctx context.Context
+	q   *queue.Mock
+}
+
+func (s *SeedTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.q = &queue.Mock{}
+	s.q.Test(s.T())
+}
+
+func (s *SeedTestSuite) TestParseLineCID() {
+	r, err := parseLine("/ipfs/" + testCID)
+
+	s.NoError(err)
+	s.Equal(&t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       testCID,
+		},
+	}, r)
+}
+
+func (s *SeedTestSuite) TestParseLineJSON() {
+	r, err := parseLine(`{"cid": "` + testCID + `", "name": "readme.md", "parent": "` + testParent + `"}`)
+
+	s.NoError(err)
+	s.Equal(&t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       testCID,
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       testParent,
+			},
+			Name: "readme.md",
+		},
+	}, r)
+}
+
+func (s *SeedTestSuite) TestParseLineInvalid() {
+	for _, l := range []string{
+		"invalid",
+		`{"cid": "invalid"}`,
+		`{"cid": "` + testCID + `", "parent": "invalid"}`,
+		`{"cid": "` + testCID + `", "name": "readme.md"}`,
+		`{"cid": `,
+	} {
+		_, err := parseLine(l)
+		s.True(errors.Is(err, ErrInvalidLine), l)
+	}
+}
+
+func (s *SeedTestSuite) TestSeed() {
+	input := strings.Join([]string{
+		"# Comment",
+		testCID,
+		"",
+		"invalid",
+		`{"cid": "` + testParent + `"}`,
+	}, "\n")
+
+	for _, id := range []string{testCID, testParent} {
+		id := id
+		s.q.
+			On("Publish", mock.Anything, mock.MatchedBy(func(r *t.AnnotatedResource) bool {
+				return r.ID == id
+			}), uint8(9)).
+			Return(nil).
+			Once()
+	}
+
+	progress, err := Seed(s.ctx, strings.NewReader(input), s.q, Options{Priority: 9})
+
+	s.NoError(err)
+	s.Equal(Progress{Lines: 3, Queued: 2, Invalid: 1}, progress)
+	s.q.AssertExpectations(s.T())
+}
+
+func (s *SeedTestSuite) TestSeedRate() {
+	input := strings.Repeat(testCID+"\n", 3)
+
+	s.q.
+		On("Publish", mock.Anything, mock.Anything, uint8(0)).
+		Return(nil).
+		Times(3)
+
+	start := time.Now()
+	progress, err := Seed(s.ctx, strings.NewReader(input), s.q, Options{Rate: 50})
+
+	s.NoError(err)
+	s.Equal(uint(3), progress.Queued)
+	s.GreaterOrEqual(int64(time.Since(start)), int64(60*time.Millisecond))
+}
+
+func (s *SeedTestSuite) TestSeedPublishError() {
+	input := strings.Repeat(testCID+"\n", 2)
+	mockErr := errors.New("mock")
+
+	s.q.
+		On("Publish", mock.Anything, mock.Anything, uint8(0)).
+		Return(mockErr).
+		Once()
+
+	progress, err := Seed(s.ctx, strings.NewReader(input), s.q, Options{})
+
+	s.True(errors.Is(err, mockErr))
+	s.Equal(Progress{Lines: 1}, progress)
+	s.q.AssertExpectations(s.T())
+}
+
+func (s *SeedTestSuite) TestSeedContextCancel() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	cancel()
+
+	progress, err := Seed(ctx, strings.NewReader(testCID), s.q, Options{Rate: 1})
+
+	s.True(errors.Is(err, context.Canceled))
+	s.Equal(Progress{Lines: 1}, progress)
+}
+
+func TestSeedTestSuite(t *testing.T) {
+	suite.Run(t, new(SeedTestSuite))
+}
@@ -8,6 +8,8 @@ type Config struct {
 	LastSeenPruneLen   int           // Cleanup expired resources from the last-seen
 	LoggerTimeout      time.Duration // Throw timeout error when no log messages arrive
 	BufferSize         uint          // Size of the channels buffering between yielder, filter and adder
+	FirstCrawlDelay    time.Duration // Delay before the first crawl of sniffed resources; disabled when 0
+	FirstCrawlJitter   time.Duration // Maximum random additional delay before the first crawl
 }
 
 // DefaultConfig returns the default configuration for a Sniffer.
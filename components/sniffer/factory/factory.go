@@ -36,7 +36,7 @@ func getInstr(cfg *instr.Config) (*instr.Instrumentation, func(), error) {
 	return instr.New(), instFlusher, nil
 }
 
-func getQueue(ctx context.Context, cfg *amqp.Config, i *instr.Instrumentation) amqp.PublisherFactory {
+func getQueue(ctx context.Context, cfg *amqp.Config, snifferCfg *sniffer.Config, i *instr.Instrumentation) amqp.PublisherFactory {
 	// Retrying dialer for connecting
 	dialer := &utils.RetryingDialer{
 		Dialer: net.Dialer{
@@ -54,6 +54,8 @@ func getQueue(ctx context.Context, cfg *amqp.Config, i *instr.Instrumentation) a
 		Config:          cfg,
 		AMQPConfig:      samqpConfig,
 		Queue:           "hashes",
+		Delay:           snifferCfg.FirstCrawlDelay,
+		Jitter:          snifferCfg.FirstCrawlJitter,
 		Instrumentation: i,
 	}
 }
@@ -78,7 +80,7 @@ func Start(ctx context.Context, ds datastore.Batching) (context.Context, datasto
 	// Create context which can be canceled by sniffer so as to propagate failure from sniffer goroutine.
 	ctx, cancel := context.WithCancel(ctx)
 
-	q := getQueue(ctx, cfg.AMQPConfig(), i)
+	q := getQueue(ctx, cfg.AMQPConfig(), cfg.SnifferConfig(), i)
 
 	s, err := getSniffer(cfg.SnifferConfig(), ds, q, i)
 	if err != nil {
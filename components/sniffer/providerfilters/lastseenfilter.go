@@ -1,12 +1,14 @@
 package providerfilters
 
 import (
-	"log"
 	"time"
 
+	"github.com/ipfs-search/ipfs-search/logging"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
+var logger = logging.New("sniffer")
+
 // LastSeenFilter filters out recently seen Providers.
 type LastSeenFilter struct {
 	resources  map[t.Resource]time.Time
@@ -39,7 +41,7 @@ func (f *LastSeenFilter) prune() {
 			}
 		}
 
-		log.Printf("Pruned %d resources, len: %d, pruneLen: %d", cnt, len(f.resources), f.PruneLen)
+		logger.Debugf("Pruned %d resources, len: %d, pruneLen: %d", cnt, len(f.resources), f.PruneLen)
 	}
 }
 
@@ -52,7 +54,7 @@ func (f *LastSeenFilter) Filter(p t.Provider) (bool, error) {
 
 	if !present {
 		// Not present, add it!
-		log.Printf("Adding LastSeen: %v, len: %d", p, len(f.resources))
+		logger.Debugf("Adding LastSeen: %v, len: %d", p, len(f.resources))
 		f.resources[*(p.Resource)] = p.Date
 
 		// Index it!
@@ -61,7 +63,7 @@ func (f *LastSeenFilter) Filter(p t.Provider) (bool, error) {
 
 	if p.Date.Sub(lastSeen) > f.Expiration {
 		// Last seen longer than expiration ago, update last seen.
-		log.Printf("Updating LastSeen: %v, len: %d", p, len(f.resources))
+		logger.Debugf("Updating LastSeen: %v, len: %d", p, len(f.resources))
 		f.resources[*(p.Resource)] = p.Date
 
 		// Index it!
@@ -69,6 +71,6 @@ func (f *LastSeenFilter) Filter(p t.Provider) (bool, error) {
 	}
 
 	// Too recent, don't index
-	log.Printf("Filtering recent %v, LastSeen %s", p, lastSeen)
+	logger.Debugf("Filtering recent %v, LastSeen %s", p, lastSeen)
 	return false, nil
 }
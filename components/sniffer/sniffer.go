@@ -11,7 +11,6 @@ import (
 	"context"
 	"fmt"
 	"golang.org/x/sync/errgroup"
-	"log"
 	"time"
 
 	// "go.opentelemetry.io/otel/codes"
@@ -26,9 +25,12 @@ import (
 	filter "github.com/ipfs-search/ipfs-search/components/sniffer/streamfilter"
 
 	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
+var logger = logging.New("sniffer")
+
 // Sniffer allows sniffing Batching datastore's events, effectively allowing sniffing of the IPFS DHT.
 // To effectively use the Sniffer, the proxied datastore needs to be acquired by calling `Batching()` on the Sniffer.
 type Sniffer struct {
@@ -139,16 +141,16 @@ func (s *Sniffer) Sniff(ctx context.Context) error {
 
 		// Closing the parent context should cause a return, other errors cause a restart
 		if err := ctx.Err(); err != nil {
-			log.Printf("Parent context closed with error '%s', returning error", err)
+			logger.Infof("Parent context closed with error '%s', returning error", err)
 			// span.RecordError(ctx, err)
 			// span.SetStatus(codes.Internal, err.Error())
 			return err
 		}
 
-		log.Printf("Wait group exited with error '%s', restarting", err)
+		logger.Errorf("Wait group exited with error '%s', restarting", err)
 
 		// TODO: Add circuit breaker here
-		log.Printf("Stubbornly restarting in 1s")
+		logger.Infof("Stubbornly restarting in 1s")
 		time.Sleep(time.Second)
 	}
 }
@@ -0,0 +1,56 @@
+package transform
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+// dropKeys removes the comma-separated metadata `keys`.
+func dropKeys(options map[string]string) (Transform, error) {
+	var keys []string
+	for _, k := range strings.Split(options["keys"], ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("option 'keys' is required")
+	}
+
+	return func(m indexTypes.Metadata) {
+		for _, k := range keys {
+			delete(m, k)
+		}
+	}, nil
+}
+
+// truncateField truncates the string values of metadata `field` to `length` bytes.
+func truncateField(options map[string]string) (Transform, error) {
+	field := options["field"]
+	if field == "" {
+		return nil, errors.New("option 'field' is required")
+	}
+
+	length, err := strconv.Atoi(options["length"])
+	if err != nil || length < 0 {
+		return nil, errors.New("option 'length' should be a non-negative integer")
+	}
+
+	return func(m indexTypes.Metadata) {
+		switch v := m[field].(type) {
+		case string:
+			m[field] = utils.Truncate(v, length)
+		case []interface{}:
+			for i, e := range v {
+				if s, ok := e.(string); ok {
+					v[i] = utils.Truncate(s, length)
+				}
+			}
+		}
+	}, nil
+}
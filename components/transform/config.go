@@ -0,0 +1,25 @@
+package transform
+
+// Spec selects a named transform with its options, e.g. `drop-keys` with option `keys`.
+type Spec struct {
+	Name    string
+	Options map[string]string
+}
+
+// Config specifies the transforms applied to extracted metadata.
+type Config struct {
+	Steps []Spec // Transforms to apply, in order.
+}
+
+// DefaultConfig returns the default configuration, without transforms.
+func DefaultConfig() *Config {
+	return &Config{
+		Steps: []Spec{},
+	}
+}
+
+// Validate returns an error when Steps contains unknown transforms or invalid options.
+func (c *Config) Validate() error {
+	_, err := New(c)
+	return err
+}
@@ -0,0 +1,75 @@
+// Package transform provides named transforms of extracted metadata, applied before indexing; e.g. to redact
+// personal information.
+package transform
+
+import (
+	"fmt"
+	"sync"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// Transform modifies extracted metadata in place.
+type Transform func(indexTypes.Metadata)
+
+// Factory returns a Transform configured by options, or an error when options are invalid.
+type Factory func(options map[string]string) (Transform, error)
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[string]Factory{
+		"drop-keys":      dropKeys,
+		"truncate-field": truncateField,
+	}
+)
+
+// Register makes a transform available by name, replacing any existing transform with that name.
+func Register(name string, f Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	registry[name] = f
+}
+
+func getFactory(name string) (Factory, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	f, ok := registry[name]
+	return f, ok
+}
+
+// Chain applies transforms in order. The nil Chain applies nothing.
+type Chain []Transform
+
+// Apply applies the transforms in c to m.
+func (c Chain) Apply(m indexTypes.Metadata) {
+	if m == nil {
+		return
+	}
+
+	for _, t := range c {
+		t(m)
+	}
+}
+
+// New returns a Chain of the transforms configured in config.
+func New(config *Config) (Chain, error) {
+	var c Chain
+
+	for _, spec := range config.Steps {
+		f, ok := getFactory(spec.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown transform '%s'", spec.Name)
+		}
+
+		t, err := f(spec.Options)
+		if err != nil {
+			return nil, fmt.Errorf("transform '%s': %w", spec.Name, err)
+		}
+
+		c = append(c, t)
+	}
+
+	return c, nil
+}
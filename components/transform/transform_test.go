@@ -0,0 +1,81 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestChain(t *testing.T) {
+	c, err := New(&Config{
+		Steps: []Spec{
+			{Name: "drop-keys", Options: map[string]string{"keys": "author, email"}},
+			{Name: "truncate-field", Options: map[string]string{"field": "title", "length": "5"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	m := indexTypes.Metadata{
+		"author":       "Jane Doe",
+		"email":        []interface{}{"jane@example.com"},
+		"title":        []interface{}{"Lorem ipsum", "Dolor"},
+		"Content-Type": "text/plain",
+	}
+
+	c.Apply(m)
+
+	assert.Equal(t, indexTypes.Metadata{
+		"title":        []interface{}{"Lorem", "Dolor"},
+		"Content-Type": "text/plain",
+	}, m)
+}
+
+func TestChainNil(t *testing.T) {
+	var c Chain
+
+	m := indexTypes.Metadata{"author": "Jane Doe"}
+	c.Apply(m)
+
+	assert.Equal(t, indexTypes.Metadata{"author": "Jane Doe"}, m)
+
+	// Nil metadata is left alone.
+	c = Chain{func(m indexTypes.Metadata) { m["x"] = "y" }}
+	c.Apply(nil)
+}
+
+func TestRegister(t *testing.T) {
+	Register("lowercase-author", func(options map[string]string) (Transform, error) {
+		return func(m indexTypes.Metadata) {
+			if s, ok := m["author"].(string); ok {
+				m["author"] = strings.ToLower(s)
+			}
+		}, nil
+	})
+
+	c, err := New(&Config{Steps: []Spec{{Name: "lowercase-author"}}})
+	assert.NoError(t, err)
+
+	m := indexTypes.Metadata{"author": "Jane Doe"}
+	c.Apply(m)
+
+	assert.Equal(t, "jane doe", m["author"])
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+
+	invalid := []Spec{
+		{Name: "unknown"},
+		{Name: "drop-keys"},
+		{Name: "truncate-field", Options: map[string]string{"length": "10"}},
+		{Name: "truncate-field", Options: map[string]string{"field": "title", "length": "-1"}},
+	}
+
+	for _, spec := range invalid {
+		cfg := &Config{Steps: []Spec{spec}}
+		assert.Error(t, cfg.Validate(), "%v", spec)
+	}
+}
@@ -0,0 +1,31 @@
+package verifier
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config specifies the configuration for the Verifier.
+type Config struct {
+	SampleRate float64       // Fraction of indexed documents to check, between 0 and 1.
+	RateLimit  float64       // Maximum checks per second; unlimited when 0.
+	Timeout    time.Duration // Content not found within this time is considered unreachable.
+}
+
+// DefaultConfig returns the default configuration for the Verifier.
+func DefaultConfig() *Config {
+	return &Config{
+		SampleRate: 0.01,
+		RateLimit:  10,
+		Timeout:    30 * time.Second,
+	}
+}
+
+// Validate returns an error when SampleRate is not within (0, 1].
+func (c *Config) Validate() error {
+	if c.SampleRate <= 0 || c.SampleRate > 1 {
+		return fmt.Errorf("sample rate should be between 0 and 1, got %v", c.SampleRate)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package verifier checks whether indexed content is still retrievable, marking unreachable documents rather than
+// deleting them so that they may be filtered from search results.
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
+	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+var logger = logging.New("verifier")
+
+// Counts reports the results of Verify.
+type Counts struct {
+	Sampled     uint64
+	Reachable   uint64
+	Unreachable uint64
+}
+
+// String returns a human-readable representation of counts, for logging.
+func (c Counts) String() string {
+	return fmt.Sprintf("%d sampled, %d reachable, %d unreachable", c.Sampled, c.Reachable, c.Unreachable)
+}
+
+// Verifier samples indexed documents, checking whether their content can still be retrieved.
+type Verifier struct {
+	config   *Config
+	protocol protocol.Protocol
+	limiter  *utils.RateLimiter
+
+	*instr.Instrumentation
+}
+
+// New returns a new Verifier.
+func New(config *Config, protocol protocol.Protocol, i *instr.Instrumentation) *Verifier {
+	return &Verifier{
+		config:          config,
+		protocol:        protocol,
+		limiter:         utils.NewRateLimiter(config.RateLimit, 1),
+		Instrumentation: i,
+	}
+}
+
+// check returns whether the content with id can be retrieved within the configured timeout.
+// Errors other than timeouts, e.g. an unavailable IPFS node, are returned rather than marking content unreachable.
+func (v *Verifier) check(ctx context.Context, id string) (bool, error) {
+	ctx, span := v.Tracer.Start(ctx, "verifier.check")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, v.config.Timeout)
+	defer cancel()
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       id,
+		},
+	}
+
+	err := v.protocol.Stat(ctx, r)
+
+	switch {
+	case err == nil, errors.Is(err, t.ErrInvalidResource):
+		// Invalid resources have been retrieved, they're merely not supported.
+		return true, nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		span.AddEvent(ctx, "unreachable")
+		return false, nil
+	default:
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+		return false, err
+	}
+}
+
+// Verify samples documents from ids, checking whether their content is reachable and updating `reachable` and
+// `last_checked` in idx accordingly. It returns when ids is closed, on the first error, or when ctx is cancelled.
+func (v *Verifier) Verify(ctx context.Context, idx index.Index, ids <-chan string) (Counts, error) {
+	var counts Counts
+
+	for {
+		var (
+			id string
+			ok bool
+		)
+
+		select {
+		case <-ctx.Done():
+			return counts, ctx.Err()
+		case id, ok = <-ids:
+			if !ok {
+				return counts, nil
+			}
+		}
+
+		if rand.Float64() >= v.config.SampleRate {
+			continue
+		}
+
+		if err := v.limiter.Wait(ctx); err != nil {
+			return counts, err
+		}
+
+		reachable, err := v.check(ctx, id)
+		if err != nil {
+			return counts, err
+		}
+
+		counts.Sampled++
+		if reachable {
+			counts.Reachable++
+		} else {
+			logger.Debugf("Content of %s in %v is unreachable", id, idx)
+			counts.Unreachable++
+		}
+
+		// Strip milliseconds to cater to legacy ES index format.
+		now := time.Now().UTC().Truncate(time.Second)
+
+		if err := idx.Update(ctx, id, &indexTypes.Verification{
+			Reachable:   reachable,
+			LastChecked: now,
+		}); err != nil {
+			return counts, err
+		}
+	}
+}
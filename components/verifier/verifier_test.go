@@ -0,0 +1,137 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type VerifierTestSuite struct {
+	suite.Suite
+
+	ctx      context.Context
+	cfg      *Config
+	index    *index.Mock
+	protocol *protocol.Mock
+}
+
+func (s *VerifierTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.cfg = DefaultConfig()
+	s.cfg.SampleRate = 1
+	s.cfg.RateLimit = 0
+	s.cfg.Timeout = 10 * time.Millisecond
+
+	s.index = &index.Mock{}
+	s.index.Test(s.T())
+
+	s.protocol = &protocol.Mock{}
+	s.protocol.Test(s.T())
+}
+
+// ids returns a closed channel with ids.
+func (s *VerifierTestSuite) ids(ids ...string) <-chan string {
+	c := make(chan string, len(ids))
+	for _, id := range ids {
+		c <- id
+	}
+	close(c)
+
+	return c
+}
+
+// stat expects a Stat call for id, returning err after delay.
+func (s *VerifierTestSuite) stat(id string, delay time.Duration, err error) {
+	s.protocol.
+		On("Stat", mock.Anything, mock.MatchedBy(func(r *t.AnnotatedResource) bool {
+			return r.ID == id
+		})).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+			}
+		}).
+		Return(err).
+		Once()
+}
+
+// update expects the verification result for id to be updated.
+func (s *VerifierTestSuite) update(id string, reachable bool) {
+	s.index.
+		On("Update", mock.Anything, id, mock.MatchedBy(func(v *indexTypes.Verification) bool {
+			return v.Reachable == reachable && !v.LastChecked.IsZero()
+		})).
+		Return(nil).
+		Once()
+}
+
+func (s *VerifierTestSuite) TestVerify() {
+	s.stat("QmReachable", 0, nil)
+	s.update("QmReachable", true)
+
+	s.stat("QmInvalid", 0, t.ErrInvalidResource)
+	s.update("QmInvalid", true)
+
+	s.stat("QmUnreachable", time.Second, context.DeadlineExceeded)
+	s.update("QmUnreachable", false)
+
+	v := New(s.cfg, s.protocol, instr.New())
+	counts, err := v.Verify(s.ctx, s.index, s.ids("QmReachable", "QmInvalid", "QmUnreachable"))
+
+	s.NoError(err)
+	s.Equal(Counts{Sampled: 3, Reachable: 2, Unreachable: 1}, counts)
+	s.protocol.AssertExpectations(s.T())
+	s.index.AssertExpectations(s.T())
+}
+
+func (s *VerifierTestSuite) TestVerifyProtocolError() {
+	testErr := errors.New("connection refused")
+
+	s.stat("QmA", 0, testErr)
+
+	v := New(s.cfg, s.protocol, instr.New())
+	_, err := v.Verify(s.ctx, s.index, s.ids("QmA", "QmB"))
+
+	// Node errors do not mark content unreachable.
+	s.True(errors.Is(err, testErr))
+	s.index.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *VerifierTestSuite) TestVerifySampling() {
+	s.cfg.SampleRate = 1e-12
+
+	v := New(s.cfg, s.protocol, instr.New())
+	counts, err := v.Verify(s.ctx, s.index, s.ids("QmA", "QmB", "QmC"))
+
+	s.NoError(err)
+	s.Equal(Counts{}, counts)
+	s.protocol.AssertNotCalled(s.T(), "Stat", mock.Anything, mock.Anything)
+}
+
+func (s *VerifierTestSuite) TestValidate() {
+	s.NoError(s.cfg.Validate())
+
+	s.cfg.SampleRate = 0
+	s.Error(s.cfg.Validate())
+
+	s.cfg.SampleRate = 1.5
+	s.Error(s.cfg.Validate())
+}
+
+func TestVerifierTestSuite(t *testing.T) {
+	suite.Run(t, new(VerifierTestSuite))
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config specifies the configuration for the webhook notifier.
+type Config struct {
+	URL          string        // URL to POST events to; disabled when empty.
+	Fields       []string      // Fields to include in the payload, see payloadFields.
+	Timeout      time.Duration // Timeout for requests to the webhook.
+	MaxRetries   uint          // Retry failed requests this many times before dropping the event.
+	RetryBackoff time.Duration // Initial time to wait before retrying, doubled on every retry.
+	QueueSize    uint          // Drop events when this many are waiting to be sent.
+	Workers      uint          // Number of concurrent requests to the webhook.
+}
+
+// DefaultConfig returns the default configuration for the webhook notifier.
+func DefaultConfig() *Config {
+	return &Config{
+		URL:          "",
+		Fields:       []string{"hash", "type", "name"},
+		Timeout:      10 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: time.Second,
+		QueueSize:    1024,
+		Workers:      4,
+	}
+}
+
+// Validate returns an error when Fields contains unknown fields.
+func (c *Config) Validate() error {
+	for _, f := range c.Fields {
+		if _, ok := payloadFields[f]; !ok {
+			return fmt.Errorf("unknown payload field '%s'", f)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package webhook notifies an external webhook of indexed resources.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	"github.com/ipfs-search/ipfs-search/logging"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+var logger = logging.New("webhook")
+
+// errPermanent wraps errors for which retrying is pointless.
+var errPermanent = errors.New("permanent failure")
+
+// Notifier POSTs JSON payloads for indexed resources to a webhook, from a bounded queue.
+// A nil Notifier does nothing.
+type Notifier struct {
+	config *Config
+	client *http.Client
+	queue  chan map[string]interface{}
+
+	dropped metric.Int64Counter
+
+	*instr.Instrumentation
+}
+
+// New returns a new Notifier; call Start to send events.
+func New(config *Config, client *http.Client, i *instr.Instrumentation) *Notifier {
+	return &Notifier{
+		config: config,
+		client: client,
+		queue:  make(chan map[string]interface{}, config.QueueSize),
+		dropped: metric.Must(i.Meter).NewInt64Counter(
+			"ipfs_search.webhook.dropped",
+			metric.WithDescription("Webhook events dropped because the queue was full or requests failed."),
+		),
+		Instrumentation: i,
+	}
+}
+
+// Notify queues an event for r without blocking, dropping it when the queue is full.
+func (n *Notifier) Notify(ctx context.Context, r *t.AnnotatedResource) {
+	if n == nil {
+		return
+	}
+
+	select {
+	case n.queue <- payload(r, n.config.Fields):
+	default:
+		logger.Warnf("Webhook queue full, dropping event for %v", r)
+		n.dropped.Add(ctx, 1, label.String("reason", "queue_full"))
+	}
+}
+
+// post sends a single payload to the webhook.
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		// Invalid URL; retrying won't help.
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	default:
+		return fmt.Errorf("%w: unexpected status %s", errPermanent, resp.Status)
+	}
+}
+
+// send posts a payload, retrying with exponential backoff.
+func (n *Notifier) send(ctx context.Context, p map[string]interface{}) error {
+	ctx, span := n.Tracer.Start(ctx, "webhook.send")
+	defer span.End()
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		panic(fmt.Sprintf("encoding webhook payload: %s", err))
+	}
+
+	backoff := n.config.RetryBackoff
+
+	for attempt := uint(0); ; attempt++ {
+		err = n.post(ctx, body)
+		if err == nil || errors.Is(err, errPermanent) || attempt >= n.config.MaxRetries {
+			break
+		}
+
+		span.AddEvent(ctx, "retry")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+
+	if err != nil {
+		span.RecordError(ctx, err, trace.WithErrorStatus(codes.Error))
+	}
+
+	return err
+}
+
+// worker sends queued events until the context is closed.
+func (n *Notifier) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-n.queue:
+			if err := n.send(ctx, p); err != nil && ctx.Err() == nil {
+				logger.Warnf("Error notifying webhook, dropping event %v: %v", p, err)
+				n.dropped.Add(ctx, 1, label.String("reason", "failed"))
+			}
+		}
+	}
+}
+
+// Start sends queued events in the background until the context is closed.
+func (n *Notifier) Start(ctx context.Context) {
+	if n == nil {
+		return
+	}
+
+	for i := uint(0); i < n.config.Workers; i++ {
+		go n.worker(ctx)
+	}
+}
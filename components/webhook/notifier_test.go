@@ -0,0 +1,197 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type NotifierTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	cancel func()
+
+	cfg      *Config
+	statuses chan int
+	requests chan map[string]interface{}
+	server   *httptest.Server
+}
+
+func (s *NotifierTestSuite) SetupTest() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	s.statuses = make(chan int, 10)
+	s.requests = make(chan map[string]interface{}, 10)
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		var p map[string]interface{}
+		s.NoError(json.Unmarshal(body, &p))
+		s.Equal("application/json", r.Header.Get("Content-Type"))
+
+		status := http.StatusOK
+		select {
+		case status = <-s.statuses:
+		default:
+		}
+
+		w.WriteHeader(status)
+		s.requests <- p
+	}))
+
+	s.cfg = DefaultConfig()
+	s.cfg.URL = s.server.URL
+	s.cfg.RetryBackoff = time.Millisecond
+}
+
+func (s *NotifierTestSuite) TearDownTest() {
+	s.cancel()
+	s.server.Close()
+}
+
+func (s *NotifierTestSuite) resource() *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmehHHRh1a7u66r7fugebp6f6wGNMGCa7eho9cgjwhAcm2",
+		},
+		Reference: t.Reference{
+			Name: "readme.md",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 42,
+		},
+	}
+}
+
+// next returns the next request received by the webhook.
+func (s *NotifierTestSuite) next() map[string]interface{} {
+	select {
+	case p := <-s.requests:
+		return p
+	case <-time.After(time.Second):
+		s.FailNow("timeout waiting for webhook request")
+		return nil
+	}
+}
+
+// assertNoRequest asserts that no further request is received by the webhook.
+func (s *NotifierTestSuite) assertNoRequest() {
+	select {
+	case p := <-s.requests:
+		s.Fail("unexpected webhook request", "%v", p)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (s *NotifierTestSuite) TestNotify() {
+	n := New(s.cfg, http.DefaultClient, instr.New())
+	n.Start(s.ctx)
+
+	n.Notify(s.ctx, s.resource())
+
+	s.Equal(map[string]interface{}{
+		"hash": "QmehHHRh1a7u66r7fugebp6f6wGNMGCa7eho9cgjwhAcm2",
+		"type": "file",
+		"name": "readme.md",
+	}, s.next())
+}
+
+func (s *NotifierTestSuite) TestNotifyFields() {
+	s.cfg.Fields = []string{"hash", "size"}
+
+	n := New(s.cfg, http.DefaultClient, instr.New())
+	n.Start(s.ctx)
+
+	n.Notify(s.ctx, s.resource())
+
+	s.Equal(map[string]interface{}{
+		"hash": "QmehHHRh1a7u66r7fugebp6f6wGNMGCa7eho9cgjwhAcm2",
+		"size": float64(42),
+	}, s.next())
+}
+
+func (s *NotifierTestSuite) TestRetry() {
+	s.statuses <- http.StatusServiceUnavailable
+	s.statuses <- http.StatusTooManyRequests
+
+	n := New(s.cfg, http.DefaultClient, instr.New())
+	n.Start(s.ctx)
+
+	n.Notify(s.ctx, s.resource())
+
+	// Two failures, then success.
+	s.next()
+	s.next()
+	s.next()
+	s.assertNoRequest()
+}
+
+func (s *NotifierTestSuite) TestRetryExhausted() {
+	s.cfg.MaxRetries = 1
+	s.statuses <- http.StatusInternalServerError
+	s.statuses <- http.StatusInternalServerError
+
+	n := New(s.cfg, http.DefaultClient, instr.New())
+	n.Start(s.ctx)
+
+	n.Notify(s.ctx, s.resource())
+
+	s.next()
+	s.next()
+	s.assertNoRequest()
+}
+
+func (s *NotifierTestSuite) TestNoRetryOnClientError() {
+	s.statuses <- http.StatusBadRequest
+
+	n := New(s.cfg, http.DefaultClient, instr.New())
+	n.Start(s.ctx)
+
+	n.Notify(s.ctx, s.resource())
+
+	s.next()
+	s.assertNoRequest()
+}
+
+func (s *NotifierTestSuite) TestQueueFull() {
+	s.cfg.QueueSize = 0
+
+	// Not started; nothing consumes the queue.
+	n := New(s.cfg, http.DefaultClient, instr.New())
+
+	// Should not block.
+	n.Notify(s.ctx, s.resource())
+
+	s.assertNoRequest()
+}
+
+func (s *NotifierTestSuite) TestNil() {
+	var n *Notifier
+
+	n.Start(s.ctx)
+	n.Notify(s.ctx, s.resource())
+}
+
+func (s *NotifierTestSuite) TestValidate() {
+	s.NoError(s.cfg.Validate())
+
+	s.cfg.Fields = []string{"hash", "unknown"}
+	s.Error(s.cfg.Validate())
+}
+
+func TestNotifierTestSuite(t *testing.T) {
+	suite.Run(t, new(NotifierTestSuite))
+}
@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// payloadFields are the available payload fields, with functions returning their value for a resource.
+var payloadFields = map[string]func(r *t.AnnotatedResource) interface{}{
+	"hash":     func(r *t.AnnotatedResource) interface{} { return r.ID },
+	"protocol": func(r *t.AnnotatedResource) interface{} { return r.Protocol.String() },
+	"type":     func(r *t.AnnotatedResource) interface{} { return r.Type.String() },
+	"size":     func(r *t.AnnotatedResource) interface{} { return r.Size },
+	"name":     func(r *t.AnnotatedResource) interface{} { return r.Reference.Name },
+	"path":     func(r *t.AnnotatedResource) interface{} { return r.Reference.Path },
+	"parent": func(r *t.AnnotatedResource) interface{} {
+		if r.Reference.Parent == nil {
+			return ""
+		}
+		return r.Reference.Parent.ID
+	},
+	"ipns_name": func(r *t.AnnotatedResource) interface{} { return r.IPNSName },
+}
+
+// payload returns the configured fields for r.
+func payload(r *t.AnnotatedResource, fields []string) map[string]interface{} {
+	p := make(map[string]interface{}, len(fields))
+
+	for _, f := range fields {
+		if value, ok := payloadFields[f]; ok {
+			p[f] = value(r)
+		}
+	}
+
+	return p
+}
@@ -7,10 +7,18 @@ import (
 
 // AMQP contains configuration pertaining to AMQP.
 type AMQP struct {
-	URL           string        `yaml:"url" env:"AMQP_URL"`                 // URL of AMQP server.
-	MaxReconnect  int           `yaml:"max_reconnect"`                      // The maximum number of reconnection attempts after the server connection is lost.
-	ReconnectTime time.Duration `yaml:"reconnect_time"`                     // The time to wait in between reconnect attempts.
-	MessageTTL    time.Duration `yaml:"message_ttl" env:"AMQP_MESSAGE_TTL"` // The expiration time for messages in the queue.
+	URL              string        `yaml:"url" env:"AMQP_URL"`                                   // URL of AMQP server.
+	URLFile          string        `yaml:"url_file" env:"AMQP_URL_FILE" optional:"true"`         // Read the URL from this file (e.g. a secret mount) instead, re-read on reconnect.
+	MaxReconnect     int           `yaml:"max_reconnect"`                                        // The maximum number of reconnection attempts after the server connection is lost.
+	ReconnectTime    time.Duration `yaml:"reconnect_time"`                                       // The time to wait in between reconnect attempts.
+	MessageTTL       time.Duration `yaml:"message_ttl" env:"AMQP_MESSAGE_TTL"`                   // The expiration time for messages in the queue.
+	ConsumerTag      string        `yaml:"consumer_tag" env:"AMQP_CONSUMER_TAG" optional:"true"` // Consumer tag, identifying consumers in the management UI. Defaults to <hostname>-<pid>.
+	Exclusive        bool          `yaml:"exclusive"`                                            // Request exclusive access to consumed queues.
+	DeadLetterSuffix string        `yaml:"dead_letter_suffix" optional:"true"`                   // Route rejected messages to <queue><suffix>, disabled when empty.
+	Durable          bool          `yaml:"durable"`                                              // Declare queues as durable, surviving broker restarts.
+	AutoDelete       bool          `yaml:"auto_delete"`                                          // Delete queues when their last consumer unsubscribes.
+	QueueMode        string        `yaml:"queue_mode"`                                           // Value of x-queue-mode: "lazy" or "default".
+	DelayedExchange  string        `yaml:"delayed_exchange" optional:"true"`                     // Delayed-message exchange for delayed publishing; disabled when empty.
 }
 
 // AMQPConfig returns component-specific configuration from the canonical configuration.
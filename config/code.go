@@ -0,0 +1,41 @@
+package config
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor/code"
+)
+
+// Code is configuration pertaining to the code extractor
+type Code struct {
+	Enabled        bool              `yaml:"enabled" env:"CODE_ENABLED"`
+	RequestTimeout time.Duration     `yaml:"timeout"`
+	MaxFileSize    datasize.ByteSize `yaml:"max_file_size"`
+	MaxSymbols     int               `yaml:"max_symbols"`
+}
+
+// CodeConfig returns component-specific configuration from the canonical central configuration. Source code is
+// recognized by the crawler's MIME categories.
+func (c *Config) CodeConfig() *code.Config {
+	return &code.Config{
+		Enabled:        c.Code.Enabled,
+		RequestTimeout: c.Code.RequestTimeout,
+		MaxFileSize:    c.Code.MaxFileSize,
+		MaxSymbols:     c.Code.MaxSymbols,
+		Categories:     c.Crawler.MimeCategories,
+	}
+}
+
+// CodeDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func CodeDefaults() Code {
+	cfg := code.DefaultConfig()
+
+	return Code{
+		Enabled:        cfg.Enabled,
+		RequestTimeout: cfg.RequestTimeout,
+		MaxFileSize:    cfg.MaxFileSize,
+		MaxSymbols:     cfg.MaxSymbols,
+	}
+}
@@ -37,17 +37,27 @@ import (
 
 // Config contains the configuration for all components.
 type Config struct {
-	IPFS          `yaml:"ipfs"`
-	ElasticSearch `yaml:"elasticsearch"`
-	AMQP          `yaml:"amqp"`
-	Tika          `yaml:"tika"`
-
-	Instr   `yaml:"instrumentation"`
-	Crawler `yaml:"crawler"`
-	Sniffer `yaml:"sniffer"`
-	Indexes `yaml:"indexes"`
-	Queues  `yaml:"queues"`
-	Workers `yaml:"workers"`
+	IPFS           `yaml:"ipfs"`
+	ElasticSearch  `yaml:"elasticsearch"`
+	AMQP           `yaml:"amqp"`
+	Tika           `yaml:"tika"`
+	Images         `yaml:"images"`
+	StructuredData `yaml:"structured_data"`
+	TOC            `yaml:"toc"`
+	Fonts          `yaml:"fonts"`
+	Code           `yaml:"code"`
+	Extractor      `yaml:"extractor"`
+	Transform      `yaml:"transform"`
+
+	Instr    `yaml:"instrumentation"`
+	Logging  `yaml:"logging"`
+	Crawler  `yaml:"crawler"`
+	Sniffer  `yaml:"sniffer"`
+	Indexes  `yaml:"indexes"`
+	Queues   `yaml:"queues"`
+	Workers  `yaml:"workers"`
+	Webhook  `yaml:"webhook"`
+	Verifier `yaml:"verifier"`
 }
 
 // String renders config as YAML
@@ -92,6 +102,68 @@ func (c *Config) Check() error {
 
 	}
 
+	if err := c.LoggingConfig().Validate(); err != nil {
+		return fmt.Errorf("Invalid logging configuration: %w", err)
+	}
+
+	if err := c.IPFSConfig().Validate(); err != nil {
+		return fmt.Errorf("Invalid IPFS configuration: %w", err)
+	}
+
+	if _, err := c.ElasticSearchURL(); err != nil {
+		return fmt.Errorf("Invalid Elasticsearch configuration: %w", err)
+	}
+
+	if err := c.ElasticSearchIndexConfig("").Sharding.Validate(); err != nil {
+		return fmt.Errorf("Invalid Elasticsearch configuration: %w", err)
+	}
+
+	if bulk := c.ElasticSearchBulkConfig(); bulk != nil {
+		if err := bulk.Validate(); err != nil {
+			return fmt.Errorf("Invalid Elasticsearch configuration: %w", err)
+		}
+	}
+
+	if err := c.AMQPConfig().Validate(); err != nil {
+		return fmt.Errorf("Invalid AMQP configuration: %w", err)
+	}
+
+	if err := c.TikaConfig().Validate(); err != nil {
+		return fmt.Errorf("Invalid tika configuration: %w", err)
+	}
+
+	if err := c.CrawlerConfig().Validate(); err != nil {
+		return fmt.Errorf("Invalid crawler configuration: %w", err)
+	}
+
+	if c.Crawler.MaxExtractionAttempts > c.Workers.MaxAttempts {
+		// Attempts are counted by re-publishing messages, which dead-letters them after max_attempts.
+		return fmt.Errorf("Invalid crawler configuration: max_extraction_attempts %d exceeds workers max_attempts %d",
+			c.Crawler.MaxExtractionAttempts, c.Workers.MaxAttempts)
+	}
+
+	switch c.Workers.Duplicates {
+	case DuplicatesProcess, DuplicatesDrop, DuplicatesDropRedeliveries:
+	default:
+		return fmt.Errorf("Invalid workers configuration: unknown duplicates behaviour '%s'", c.Workers.Duplicates)
+	}
+
+	if c.Workers.MaxCrawlRate < 0 {
+		return fmt.Errorf("Invalid workers configuration: negative max_crawl_rate %g", c.Workers.MaxCrawlRate)
+	}
+
+	if err := c.TransformConfig().Validate(); err != nil {
+		return fmt.Errorf("Invalid transform configuration: %w", err)
+	}
+
+	if err := c.WebhookConfig().Validate(); err != nil {
+		return fmt.Errorf("Invalid webhook configuration: %w", err)
+	}
+
+	if err := c.VerifierConfig().Validate(); err != nil {
+		return fmt.Errorf("Invalid verifier configuration: %w", err)
+	}
+
 	return nil
 }
 
@@ -1,17 +1,74 @@
 package config
 
 import (
+	"github.com/c2h5oh/datasize"
 	"github.com/ipfs-search/ipfs-search/components/crawler"
 	"time"
 )
 
 // Crawler contains configuration for a Crawler.
 type Crawler struct {
-	DirEntryBufferSize uint          `yaml:"direntry_buffer_size"` // Size of buffer for processing directory entry channels.
-	MinUpdateAge       time.Duration `yaml:"min_update_age"`       // The minimum age for items to be updated.
-	StatTimeout        time.Duration `yaml:"stat_timeout"`         // Timeout for Stat() calls.
-	DirEntryTimeout    time.Duration `yaml:"direntry_timeout"`     // Timeout *between* directory entries.
-	MaxDirSize         uint          `yaml:"max_dirsize"`          // Maximum number of directory entries
+	DirEntryBufferSize uint          `yaml:"direntry_buffer_size"`           // Size of buffer for processing directory entry channels.
+	MinUpdateAge       time.Duration `yaml:"min_update_age"`                 // The minimum age for items to be updated.
+	StatTimeout        time.Duration `yaml:"stat_timeout"`                   // Timeout for Stat() calls.
+	DirEntryTimeout    time.Duration `yaml:"direntry_timeout"`               // Timeout *between* directory entries.
+	MaxDirSize         uint          `yaml:"max_dirsize"`                    // Maximum number of directory entries
+	MaxLinks           uint          `yaml:"max_links" optional:"true"`      // Store at most this many links in directory documents; unlimited when 0.
+	LinkDedup          string        `yaml:"link_dedup"`                     // Deduplicate links of directories by hash, name or not (none).
+	IndexPaths         bool          `yaml:"index_paths"`                    // Index full paths from known roots.
+	MaxReferences      uint          `yaml:"max_references" optional:"true"` // Stop adding references to documents with this many references; unlimited when 0.
+	MaxPaths           uint          `yaml:"max_paths" optional:"true"`      // Stop adding paths to documents with this many paths; unlimited when 0.
+
+	DenylistFile           string        `yaml:"denylist_file" env:"DENYLIST_FILE" optional:"true"` // File with denied CIDs (badbits format); disabled when empty.
+	DenylistReloadInterval time.Duration `yaml:"denylist_reload_interval"`                          // Interval for checking the denylist file for modifications.
+
+	ExtractSubtitles bool              `yaml:"extract_subtitles"` // Index sidecar subtitles (.srt/.vtt) with video files.
+	MaxSubtitleSize  datasize.ByteSize `yaml:"max_subtitle_size"` // Maximum size of indexed subtitle text.
+
+	Simhash        bool              `yaml:"simhash"`          // Index a simhash fingerprint of extracted text, for finding near-duplicates.
+	MaxSimhashSize datasize.ByteSize `yaml:"max_simhash_size"` // Maximum amount of text to compute the simhash over.
+
+	SizeBuckets []datasize.ByteSize `yaml:"size_buckets" optional:"true"` // Upper bounds of the tiny, small, medium and large size buckets; disabled when empty.
+
+	Source string `yaml:"source" env:"CRAWLER_SOURCE" optional:"true"` // Add this crawl source (origin) to the `source` of documents; disabled when empty.
+
+	DagStats           bool    `yaml:"dag_stats" env:"CRAWLER_DAG_STATS"`    // Index the block count, size and depth of the DAG of documents.
+	DagStatsSampleRate float64 `yaml:"dag_stats_sample_rate"`                // Fraction of documents to index DAG statistics for, between 0 and 1.
+	DagStatsMaxDepth   uint    `yaml:"dag_stats_max_depth" optional:"true"`  // Maximum depth to traverse the DAG to; unlimited when 0.
+	DagStatsMaxBlocks  uint    `yaml:"dag_stats_max_blocks" optional:"true"` // Maximum number of blocks to count; unlimited when 0.
+
+	DirectoryTitles  bool              `yaml:"directory_titles"`                 // Index the title of the index.html of directories as their title.
+	MaxIndexPageSize datasize.ByteSize `yaml:"max_index_page_size"`              // Skip index pages larger than this.
+	IndexPageNames   []string          `yaml:"index_page_names" optional:"true"` // Names of index pages (case-insensitive) marking directories as websites, in order of preference.
+	WebsiteRoots     bool              `yaml:"website_roots" optional:"true"`    // Index the nearest ancestor website directory of files as their website root.
+
+	LargeDirThreshold  uint    `yaml:"large_dir_threshold" optional:"true"` // Apply LargeDirPolicy to entries of directories beyond this many; disabled when 0.
+	LargeDirPolicy     string  `yaml:"large_dir_policy"`                    // Queue entries of large directories fully (full), not (skip) or sampled (sample).
+	LargeDirSampleRate float64 `yaml:"large_dir_sample_rate"`               // Fraction of entries beyond LargeDirThreshold to queue with sample, between 0 and 1.
+
+	PrefetchBatchSize uint          `yaml:"prefetch_batch_size" optional:"true"` // Look up which entries of directories are indexed in batches of this many, with a request per index; disabled when 0.
+	PrefetchTTL       time.Duration `yaml:"prefetch_ttl" optional:"true"`        // Trust looked up entries to be indexed or not for this long.
+	PrefetchCacheSize int           `yaml:"prefetch_cache_size" optional:"true"` // Maximum number of looked up entries remembered.
+
+	DocumentIDs       string `yaml:"document_ids"`                        // Key documents by cid, cid_version or cid_name.
+	DocumentIDVersion string `yaml:"document_id_version" optional:"true"` // Version appended to CIDs with cid_version.
+
+	CompressContent    bool              `yaml:"compress_content" env:"CRAWLER_COMPRESS_CONTENT"` // Store content over ContentExcerptSize gzipped, indexing an excerpt of it for search.
+	ContentExcerptSize datasize.ByteSize `yaml:"content_excerpt_size"`                            // Size of the content excerpt indexed when compressing content.
+	ContentLanguages   []string          `yaml:"content_languages" optional:"true"`               // Languages (e.g. `en`) of which content is also indexed with a language-specific analyzer; disabled when empty.
+
+	MaxExtractionAttempts uint `yaml:"max_extraction_attempts" optional:"true"` // Index files as unsupported after this many failed extraction attempts; disabled when 0.
+
+	GatewayURL string `yaml:"gateway_url" env:"CRAWLER_GATEWAY_URL" optional:"true"` // Base URL of a public gateway, indexing the `url` of documents on it; disabled when empty.
+
+	OptimisticUpdates  bool `yaml:"optimistic_updates" optional:"true"`   // Merge updates of existing documents in the crawler, guarded by their version, rather than by script.
+	MaxUpdateConflicts uint `yaml:"max_update_conflicts" optional:"true"` // Re-read and retry optimistic updates up to this many times on concurrent modification.
+
+	DNSLinkTTL time.Duration `yaml:"dnslink_ttl" env:"CRAWLER_DNSLINK_TTL" optional:"true"` // Re-resolve and recrawl DNSLink names this long after resolving them; disabled when 0.
+
+	EmptyFiles string `yaml:"empty_files" optional:"true"` // Index zero-byte files as empty without extraction (index) or skip them (skip).
+
+	MimeCategories map[string]string `yaml:"mime_categories" optional:"true"` // Categories of files by MIME type or glob (e.g. image/*); other when not matched.
 }
 
 // CrawlerConfig returns component-specific configuration from the canonical central configuration.
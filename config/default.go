@@ -7,11 +7,21 @@ func Default() *Config {
         ElasticSearchDefaults(),
         AMQPDefaults(),
         TikaDefaults(),
+        ImagesDefaults(),
+        StructuredDataDefaults(),
+        TOCDefaults(),
+        FontsDefaults(),
+        CodeDefaults(),
+        ExtractorDefaults(),
+        TransformDefaults(),
         InstrDefaults(),
+        LoggingDefaults(),
         CrawlerDefaults(),
         SnifferDefaults(),
         IndexesDefaults(),
         QueuesDefaults(),
         WorkersDefaults(),
+        WebhookDefaults(),
+        VerifierDefaults(),
     }
 }
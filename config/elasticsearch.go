@@ -1,13 +1,92 @@
 package config
 
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
 // ElasticSearch holds configuration for ElasticSearch.
 type ElasticSearch struct {
-	URL string `yaml:"url" env:"ELASTICSEARCH_URL"`
+	URL     string `yaml:"url" env:"ELASTICSEARCH_URL"`
+	URLFile string `yaml:"url_file" env:"ELASTICSEARCH_URL_FILE" optional:"true"` // Read the URL from this file (e.g. a secret mount) instead.
+
+	SecondaryURL        string `yaml:"secondary_url" env:"ELASTICSEARCH_SECONDARY_URL" optional:"true"` // Mirror writes to this cluster, best-effort; disabled when empty.
+	MaxSecondaryPending int    `yaml:"max_secondary_pending"`                                           // Maximum concurrent writes to the secondary; drop writes beyond this.
+
+	Bulk              bool              `yaml:"bulk" env:"ELASTICSEARCH_BULK" optional:"true"` // Combine concurrent writes into bulk requests.
+	BulkFlushBytes    datasize.ByteSize `yaml:"bulk_flush_bytes" optional:"true"`              // Flush bulk requests at this size; disabled when 0.
+	BulkFlushDocs     int               `yaml:"bulk_flush_docs" optional:"true"`               // Flush bulk requests at this many documents; disabled when 0.
+	BulkFlushInterval time.Duration     `yaml:"bulk_flush_interval" optional:"true"`           // Flush bulk requests this long after their first document.
+
+	Timeout      time.Duration `yaml:"timeout" env:"ELASTICSEARCH_TIMEOUT" optional:"true"` // Timeout per request attempt; disabled when 0.
+	MaxRetries   uint          `yaml:"max_retries" optional:"true"`                         // Retries of requests failing transiently (e.g. 503, connection reset).
+	RetryBackoff time.Duration `yaml:"retry_backoff" optional:"true"`                       // Initial wait between retries, doubling on every retry.
+
+	Sharding          string `yaml:"sharding" optional:"true"`            // Route documents to shards of indexes by ID: none, prefix or hash.
+	ShardPrefixLength int    `yaml:"shard_prefix_length" optional:"true"` // Multihash digest characters in shard names, for prefix sharding.
+	ShardCount        int    `yaml:"shard_count" optional:"true"`         // Number of shards, for hash sharding.
 }
 
 // ElasticSearchDefaults returns the defaults for ElasticSearch.
 func ElasticSearchDefaults() ElasticSearch {
 	return ElasticSearch{
-		URL: "http://localhost:9200",
+		URL:                 "http://localhost:9200",
+		MaxSecondaryPending: 100,
+		BulkFlushBytes:      5 * datasize.MB,
+		BulkFlushDocs:       100,
+		BulkFlushInterval:   500 * time.Millisecond,
+		Timeout:             30 * time.Second,
+		MaxRetries:          3,
+		RetryBackoff:        500 * time.Millisecond,
+		Sharding:            elasticsearch.ShardNone,
+		ShardPrefixLength:   2,
+		ShardCount:          16,
+	}
+}
+
+// ElasticSearchURL returns the URL of the (primary) Elasticsearch cluster, read from URLFile when set.
+func (c *Config) ElasticSearchURL() (string, error) {
+	if c.ElasticSearch.URLFile == "" {
+		return c.ElasticSearch.URL, nil
+	}
+
+	return utils.ReadSecretFile(c.ElasticSearch.URLFile)
+}
+
+// ElasticSearchIndexConfig returns the configuration for the Elasticsearch index name, sharded as configured.
+func (c *Config) ElasticSearchIndexConfig(name string) *elasticsearch.Config {
+	cfg := &elasticsearch.Config{
+		Name:         name,
+		Timeout:      c.ElasticSearch.Timeout,
+		MaxRetries:   c.ElasticSearch.MaxRetries,
+		RetryBackoff: c.ElasticSearch.RetryBackoff,
+	}
+
+	// Cursors are few, hence never sharded.
+	if name != c.Indexes.Cursors.Name {
+		cfg.Sharding = elasticsearch.ShardConfig{
+			Strategy:     c.ElasticSearch.Sharding,
+			PrefixLength: c.ElasticSearch.ShardPrefixLength,
+			Count:        c.ElasticSearch.ShardCount,
+		}
+	}
+
+	return cfg
+}
+
+// ElasticSearchBulkConfig returns the configuration for bulk writes, or nil when writes are not combined.
+func (c *Config) ElasticSearchBulkConfig() *elasticsearch.BulkConfig {
+	if !c.ElasticSearch.Bulk {
+		return nil
+	}
+
+	return &elasticsearch.BulkConfig{
+		FlushBytes:    int(c.ElasticSearch.BulkFlushBytes.Bytes()),
+		FlushDocs:     c.ElasticSearch.BulkFlushDocs,
+		FlushInterval: c.ElasticSearch.BulkFlushInterval,
 	}
 }
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor"
+)
+
+// Extractor is configuration pertaining to running multiple extractors
+type Extractor struct {
+	Parallel bool          `yaml:"parallel" env:"EXTRACTOR_PARALLEL"`
+	Timeout  time.Duration `yaml:"timeout" optional:"true"`
+	Sniff    bool          `yaml:"sniff" env:"EXTRACTOR_SNIFF"`
+}
+
+// ExtractorConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) ExtractorConfig() *extractor.Config {
+	cfg := extractor.Config(c.Extractor)
+	return &cfg
+}
+
+// ExtractorDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func ExtractorDefaults() Extractor {
+	return Extractor(*extractor.DefaultConfig())
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor/font"
+)
+
+// Fonts is configuration pertaining to the font extractor
+type Fonts struct {
+	Enabled        bool              `yaml:"enabled" env:"FONTS_ENABLED"`
+	RequestTimeout time.Duration     `yaml:"timeout"`
+	MaxFileSize    datasize.ByteSize `yaml:"max_file_size"`
+}
+
+// FontsConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) FontsConfig() *font.Config {
+	cfg := font.Config(c.Fonts)
+	return &cfg
+}
+
+// FontsDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func FontsDefaults() Fonts {
+	return Fonts(*font.DefaultConfig())
+}
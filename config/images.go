@@ -0,0 +1,31 @@
+package config
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor/images"
+)
+
+// Images is configuration pertaining to the image extractor
+type Images struct {
+	Enabled        bool              `yaml:"enabled" env:"IMAGES_ENABLED"`
+	RequestTimeout time.Duration     `yaml:"timeout"`
+	MaxDecodeSize  datasize.ByteSize `yaml:"max_decode_size"`
+	MaxPixels      int               `yaml:"max_pixels"`
+	HeaderSize     datasize.ByteSize `yaml:"header_size" optional:"true"`
+	Thumbnails     bool              `yaml:"thumbnails"`
+	ThumbnailSize  int               `yaml:"thumbnail_size"`
+}
+
+// ImagesConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) ImagesConfig() *images.Config {
+	cfg := images.Config(c.Images)
+	return &cfg
+}
+
+// ImagesDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func ImagesDefaults() Images {
+	return Images(*images.DefaultConfig())
+}
@@ -10,6 +10,10 @@ type Indexes struct {
     Files       Index `yaml:"files"`
     Directories Index `yaml:"directories"`
     Invalids    Index `yaml:"invalids"`
+    Cursors     Index `yaml:"cursors"`
+
+    Sink     string `yaml:"sink"`                      // Where to write documents: elasticsearch, ndjson or stdout.
+    SinkFile string `yaml:"sink_file" optional:"true"` // File to append documents to for the ndjson sink.
 }
 
 // IndexesDefaults returns the default indexes.
@@ -24,5 +28,9 @@ func IndexesDefaults() Indexes {
         Invalids: Index{
             Name: "ipfs_invalids",
         },
+        Cursors: Index{
+            Name: "ipfs_cursors",
+        },
+        Sink: "elasticsearch",
     }
 }
@@ -8,6 +8,7 @@ import (
 type Instr struct {
 	SamplingRatio  float64 `yaml:"sampling_ratio" env:"OTEL_TRACE_SAMPLER_ARG"`         // Parent-based sampling ratio (fraction of sniffed hashes traced). Defaults to `0.01` (1%). For some reason, setting this as an environment option fails.
 	JaegerEndpoint string  `yaml:"jaeger_endpoint" env:"OTEL_EXPORTER_JAEGER_ENDPOINT"` // Send spans to Jaeger HTTP endpoint, for example `http://jaeger:14268/api/traces`.
+	MaxLabelValues uint    `yaml:"max_label_values" optional:"true"`                    // Maximum number of distinct values per limited metric label, beyond which values are reported as `other`; unlimited when 0.
 }
 
 // InstrConfig returns component-specific configuration from the canonical central configuration.
@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/c2h5oh/datasize"
 	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"time"
 )
 
 // IPFS specifies the configuration for the IPFS protocol.
@@ -10,6 +11,11 @@ type IPFS struct {
 	APIURL      string            `yaml:"api_url" env:"IPFS_API_URL"`
 	GatewayURL  string            `yaml:"gateway_url" env:"IPFS_GATEWAY_URL"`
 	PartialSize datasize.ByteSize `yaml:"partial_size"`
+
+	APITimeout time.Duration `yaml:"api_timeout" env:"IPFS_API_TIMEOUT" optional:"true"`
+	APIPrefix  string        `yaml:"api_prefix" env:"IPFS_API_PREFIX" optional:"true"`
+
+	PathPrefixes map[string]string `yaml:"path_prefixes" optional:"true"`
 }
 
 // IPFSConfig returns component-specific configuration from the canonical central configuration.
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/logging"
+)
+
+// Logging specifies the configuration for logging.
+type Logging struct {
+	Level      string            `yaml:"level" env:"LOG_LEVEL"`      // Minimum level of logged messages: debug, info, warn or error.
+	Components map[string]string `yaml:"components" optional:"true"` // Levels overriding Level for components (e.g. crawler, amqp, extractor or indexer).
+}
+
+// LoggingConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) LoggingConfig() *logging.Config {
+	cfg := logging.Config(c.Logging)
+	return &cfg
+}
+
+// LoggingDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func LoggingDefaults() Logging {
+	return Logging(*logging.DefaultConfig())
+}
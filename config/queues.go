@@ -10,6 +10,7 @@ type Queues struct {
 	Files       Queue `yaml:"files"`       // Resources known to be files.
 	Directories Queue `yaml:"directories"` // Resources known to be directories.
 	Hashes      Queue `yaml:"hashes"`      // Resources with unknown type.
+	Invalids    Queue `yaml:"invalids"`    // Resources to be indexed as invalid, when InvalidWorkers is set.
 }
 
 // QueuesDefaults returns the default queues.
@@ -24,5 +25,8 @@ func QueuesDefaults() Queues {
 		Hashes: Queue{
 			Name: "hashes",
 		},
+		Invalids: Queue{
+			Name: "invalids",
+		},
 	}
 }
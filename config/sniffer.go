@@ -11,6 +11,8 @@ type Sniffer struct {
 	LastSeenPruneLen   int           `yaml:"lastseen_prunelen" env:"SNIFFER_LASTSEEN_PRUNELEN"`
 	LoggerTimeout      time.Duration `yaml:"logger_timeout"`
 	BufferSize         uint          `yaml:"buffer_size" env:"SNIFFER_BUFFER_SIZE"`
+	FirstCrawlDelay    time.Duration `yaml:"first_crawl_delay" env:"SNIFFER_FIRST_CRAWL_DELAY" optional:"true"`
+	FirstCrawlJitter   time.Duration `yaml:"first_crawl_jitter" env:"SNIFFER_FIRST_CRAWL_JITTER" optional:"true"`
 }
 
 // SnifferConfig returns component-specific configuration from the canonical central configuration.
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor/structureddata"
+)
+
+// StructuredData is configuration pertaining to the structured data extractor
+type StructuredData struct {
+	Enabled        bool              `yaml:"enabled" env:"STRUCTURED_DATA_ENABLED"`
+	RequestTimeout time.Duration     `yaml:"timeout"`
+	MaxFileSize    datasize.ByteSize `yaml:"max_file_size"`
+	MaxBlocks      int               `yaml:"max_blocks"`
+	MaxBlockSize   datasize.ByteSize `yaml:"max_block_size"`
+}
+
+// StructuredDataConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) StructuredDataConfig() *structureddata.Config {
+	cfg := structureddata.Config(c.StructuredData)
+	return &cfg
+}
+
+// StructuredDataDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func StructuredDataDefaults() StructuredData {
+	return StructuredData(*structureddata.DefaultConfig())
+}
@@ -10,9 +10,22 @@ import (
 
 // Tika is configuration pertaining to the sniffer
 type Tika struct {
-	TikaExtractorURL string            `yaml:"url" env:"TIKA_EXTRACTOR"`
-	RequestTimeout   time.Duration     `yaml:"timeout"`
-	MaxFileSize      datasize.ByteSize `yaml:"max_file_size"`
+	TikaExtractorURL string                   `yaml:"url" env:"TIKA_EXTRACTOR" optional:"true"`
+	RequestTimeout   time.Duration            `yaml:"timeout"`
+	MimeTimeouts     map[string]time.Duration `yaml:"mime_timeouts" optional:"true"`
+	MinFileSize      datasize.ByteSize        `yaml:"min_file_size" optional:"true"`
+	MaxFileSize      datasize.ByteSize        `yaml:"max_file_size"`
+	AcceptType       string                   `yaml:"accept"`
+	MaxResponseSize  datasize.ByteSize        `yaml:"max_response_size"`
+	Headers          map[string]string        `yaml:"headers" optional:"true"`
+	HeaderFiles      map[string]string        `yaml:"header_files" optional:"true"`
+	MaxConcurrency   uint                     `yaml:"max_concurrency" env:"TIKA_MAX_CONCURRENCY" optional:"true"`
+	DeniedMetadata   []string                 `yaml:"denied_metadata" optional:"true"`
+
+	FallbackGatewayURL string        `yaml:"fallback_gateway_url" env:"TIKA_FALLBACK_GATEWAY" optional:"true"`
+	FallbackTimeout    time.Duration `yaml:"fallback_timeout"`
+	FallbackRateLimit  float64       `yaml:"fallback_rate_limit" optional:"true"`
+	FallbackValidators bool          `yaml:"fallback_validators" optional:"true"`
 }
 
 // TikaConfig returns component-specific configuration from the canonical central configuration.
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+
+	"github.com/ipfs-search/ipfs-search/components/extractor/toc"
+)
+
+// TOC is configuration pertaining to the table of contents extractor
+type TOC struct {
+	Enabled        bool              `yaml:"enabled" env:"TOC_ENABLED"`
+	RequestTimeout time.Duration     `yaml:"timeout"`
+	MaxFileSize    datasize.ByteSize `yaml:"max_file_size"`
+	MaxEntries     int               `yaml:"max_entries"`
+}
+
+// TOCConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) TOCConfig() *toc.Config {
+	cfg := toc.Config(c.TOC)
+	return &cfg
+}
+
+// TOCDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func TOCDefaults() TOC {
+	return TOC(*toc.DefaultConfig())
+}
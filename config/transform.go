@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/transform"
+)
+
+// Transform is configuration pertaining to transforms of extracted metadata, applied before indexing
+type Transform struct {
+	Steps []transform.Spec `yaml:"steps" optional:"true"`
+}
+
+// TransformConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) TransformConfig() *transform.Config {
+	cfg := transform.Config(c.Transform)
+	return &cfg
+}
+
+// TransformDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func TransformDefaults() Transform {
+	return Transform(*transform.DefaultConfig())
+}
@@ -6,6 +6,7 @@ import (
 )
 
 // findZeroElements returns a slice of all (nested) struct fields with a zero value.
+// Fields tagged with `optional:"true"` as well as booleans are never considered missing.
 func findZeroElements(s interface{}) []string {
 	var output []string
 
@@ -16,7 +17,14 @@ func findZeroElements(s interface{}) []string {
 		f := v.Field(i)
 		name := v.Type().Field(i).Tag.Get("yaml")
 
+		if v.Type().Field(i).Tag.Get("optional") == "true" {
+			continue
+		}
+
 		switch f.Kind() {
+		case reflect.Bool:
+			// False is a valid value for booleans.
+			continue
 		case reflect.Struct:
 			// It's a struct - recurse!
 			for _, newE := range findZeroElements(f.Interface()) {
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/verifier"
+)
+
+// Verifier is configuration pertaining to the verifier, checking indexed content is still retrievable
+type Verifier struct {
+	SampleRate float64       `yaml:"sample_rate" env:"VERIFIER_SAMPLE_RATE"`
+	RateLimit  float64       `yaml:"rate_limit" optional:"true"`
+	Timeout    time.Duration `yaml:"timeout"`
+}
+
+// VerifierConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) VerifierConfig() *verifier.Config {
+	cfg := verifier.Config(c.Verifier)
+	return &cfg
+}
+
+// VerifierDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func VerifierDefaults() Verifier {
+	return Verifier(*verifier.DefaultConfig())
+}
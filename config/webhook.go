@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/webhook"
+)
+
+// Webhook is configuration pertaining to the webhook notifier
+type Webhook struct {
+	URL          string        `yaml:"url" env:"WEBHOOK_URL" optional:"true"`
+	Fields       []string      `yaml:"fields" optional:"true"`
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxRetries   uint          `yaml:"max_retries" optional:"true"`
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	QueueSize    uint          `yaml:"queue_size" optional:"true"`
+	Workers      uint          `yaml:"workers"`
+}
+
+// WebhookConfig returns component-specific configuration from the canonical central configuration.
+func (c *Config) WebhookConfig() *webhook.Config {
+	cfg := webhook.Config(c.Webhook)
+	return &cfg
+}
+
+// WebhookDefaults returns the defaults for component configuration, based on the component-specific configuration.
+func WebhookDefaults() Webhook {
+	return Webhook(*webhook.DefaultConfig())
+}
@@ -1,5 +1,18 @@
 package config
 
+import (
+	"time"
+
+	"github.com/c2h5oh/datasize"
+)
+
+// Behaviour on duplicate tasks, i.e. messages seen before within duplicate_ttl.
+const (
+	DuplicatesProcess          = "process"           // Process anyway, in case earlier attempts failed.
+	DuplicatesDrop             = "drop"              // Acknowledge and drop.
+	DuplicatesDropRedeliveries = "drop_redeliveries" // Drop duplicates within duplicate_window only, processing re-discoveries.
+)
+
 /*
 Workers contains the configuration for the worker pool.
 
@@ -9,13 +22,54 @@ type Workers struct {
 	HashWorkers      int `yaml:"hash_workers" env:"HASH_WORKERS"`
 	FileWorkers      int `yaml:"file_workers" env:"FILE_WORKERS"`
 	DirectoryWorkers int `yaml:"directory_workers" env:"DIRECTORY_WORKERS"`
+
+	InvalidWorkers int `yaml:"invalid_workers" env:"INVALID_WORKERS" optional:"true"` // Index invalid resources through the invalids queue; inline when 0.
+
+	CursorInterval  time.Duration     `yaml:"cursor_interval"`                   // Interval for persisting crawl progress.
+	MaxInflightSize datasize.ByteSize `yaml:"max_inflight_size" optional:"true"` // Hold back resources exceeding this combined size with those in progress; unlimited when 0.
+	MaxInflight     uint              `yaml:"max_inflight" optional:"true"`      // Maximum deliveries processed concurrently, across queues; unlimited when 0.
+	MaxCrawlRate    float64           `yaml:"max_crawl_rate" optional:"true"`    // Maximum crawls per minute, across queues; unlimited when 0.
+	CrawlBurst      uint              `yaml:"crawl_burst" optional:"true"`       // Crawls allowed in a burst after idling, with MaxCrawlRate.
+
+	AckBatchSize     int           `yaml:"ack_batch_size"`     // Acknowledge up to this many messages at once; 1 acknowledges every message.
+	AckFlushInterval time.Duration `yaml:"ack_flush_interval"` // Maximum time to wait before acknowledging partial batches.
+
+	MaxAttempts   uint          `yaml:"max_attempts" optional:"true"`    // Dead-letter messages after this many attempts; retried by the broker indefinitely when 0.
+	RetryDelay    time.Duration `yaml:"retry_delay" optional:"true"`     // Wait before retrying messages failing with temporary errors; immediately when 0.
+	MaxRetryDelay time.Duration `yaml:"max_retry_delay" optional:"true"` // With MaxAttempts, RetryDelay doubles with every attempt up to this; unbounded when 0.
+
+	ProcessingTimeout time.Duration `yaml:"processing_timeout" optional:"true"` // Cancel and retry messages taking longer than this to process; disabled when 0.
+	ProcessingGrace   time.Duration `yaml:"processing_grace" optional:"true"`   // Wait this long for cancelled processing to stop, then abandon it and requeue its message.
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" optional:"true"`   // Grace period for messages in progress at shutdown, after which they're cancelled and requeued.
+
+	StartupTimeout time.Duration `yaml:"startup_timeout" env:"STARTUP_TIMEOUT" optional:"true"` // Retry connecting to IPFS, Elasticsearch and AMQP at startup for this long; a single attempt when 0.
+	StartupBackoff time.Duration `yaml:"startup_backoff"`                                       // Initial wait between startup attempts, doubling on every attempt.
+
+	Duplicates         string        `yaml:"duplicates"`                           // Behaviour on duplicate tasks: process, drop or drop_redeliveries.
+	DuplicateTTL       time.Duration `yaml:"duplicate_ttl" optional:"true"`        // Remember tasks for this long to detect duplicates; disabled when 0.
+	DuplicateWindow    time.Duration `yaml:"duplicate_window" optional:"true"`     // Duplicates within this are likely redeliveries, later ones re-discoveries.
+	DuplicateCacheSize int           `yaml:"duplicate_cache_size" optional:"true"` // Maximum number of tasks remembered.
 }
 
 // WorkersDefaults returns the default configuration for the workerpool.
 func WorkersDefaults() Workers {
 	return Workers{
-		HashWorkers:      70,
-		FileWorkers:      120,
-		DirectoryWorkers: 70,
+		HashWorkers:        70,
+		FileWorkers:        120,
+		DirectoryWorkers:   70,
+		CursorInterval:     time.Minute,
+		CrawlBurst:         10,
+		AckBatchSize:       1,
+		AckFlushInterval:   time.Second,
+		RetryDelay:         5 * time.Second,
+		MaxRetryDelay:      10 * time.Minute,
+		StartupTimeout:     5 * time.Minute,
+		StartupBackoff:     time.Second,
+		ProcessingGrace:    10 * time.Second,
+		ShutdownTimeout:    30 * time.Second,
+		Duplicates:         DuplicatesProcess,
+		DuplicateTTL:       time.Hour,
+		DuplicateWindow:    time.Minute,
+		DuplicateCacheSize: 100000,
 	}
 }
@@ -4,6 +4,7 @@ package instr
 type Config struct {
 	SamplingRatio  float64 // Parent-based sampling ratio (fraction of sniffed hashes traced).
 	JaegerEndpoint string  // Send spans to Jaeger HTTP endpoint.
+	MaxLabelValues uint    // Maximum number of distinct values per limited metric label; unlimited when 0.
 }
 
 // DefaultConfig returns the default configuration for the instrumentation.
@@ -11,5 +12,6 @@ func DefaultConfig() *Config {
 	return &Config{
 		SamplingRatio:  0.01,
 		JaegerEndpoint: "http://localhost:14268/api/traces",
+		MaxLabelValues: 100,
 	}
 }
@@ -2,7 +2,7 @@
 package instr
 
 import (
-	"log"
+	"sync/atomic"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/api/global"
@@ -11,21 +11,27 @@ import (
 	"go.opentelemetry.io/otel/exporters/trace/jaeger"
 	"go.opentelemetry.io/otel/propagators"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/ipfs-search/ipfs-search/logging"
 )
 
 const (
 	name = "github.com/ipfs-search"
 )
 
+var logger = logging.New("instr")
+
 // Instrumentation provides a canonical representation of instrumentation.
 type Instrumentation struct {
 	Tracer trace.Tracer
 	Meter  metric.Meter
 }
 
-// Install configures and installs a Jaeger tracing pipeline. The first returned argument is a flusher, which should be called on program exit.
+// Install configures and installs a Jaeger tracing pipeline and the limit of label values. The first returned argument is a flusher, which should be called on program exit.
 func Install(config *Config, serviceName string) (func(), error) {
-	log.Printf("Creating Jaeger pipeline for service '%s' at ratio %f to endpoint %s", serviceName, config.SamplingRatio, config.JaegerEndpoint)
+	logger.Infof("Creating Jaeger pipeline for service '%s' at ratio %f to endpoint %s", serviceName, config.SamplingRatio, config.JaegerEndpoint)
+
+	atomic.StoreInt64(&maxLabelValues, int64(config.MaxLabelValues))
 
 	// Configure context propagation
 	global.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
@@ -0,0 +1,87 @@
+package instr
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+// OverflowValue is reported for label values beyond the maximum number of distinct values.
+const OverflowValue = "other"
+
+// maxLabelValues is the maximum number of distinct values per limited label, as installed; unlimited when 0.
+var maxLabelValues = int64(DefaultConfig().MaxLabelValues)
+
+// LabelLimit bounds the number of distinct values of a metric label. Every distinct set of labels is a separate time
+// series, kept in memory for as long as the process runs, so labels with values not known in advance (e.g. names
+// from configuration) should be limited. Values beyond the maximum are reported as OverflowValue.
+//
+// Never label metrics by values which are unbounded by nature, e.g. CIDs, peer IDs, file names or error strings; add
+// those to spans instead.
+//
+// A LabelLimit is safe for concurrent use.
+type LabelLimit struct {
+	key string
+
+	mu         sync.RWMutex
+	values     map[string]struct{}
+	overflowed bool
+}
+
+// NewLabelLimit returns a LabelLimit for key, allowing up to the number of distinct values configured with Install.
+func NewLabelLimit(key string) *LabelLimit {
+	return &LabelLimit{
+		key:    key,
+		values: make(map[string]struct{}),
+	}
+}
+
+// seen returns whether value has been seen before and whether the maximum number of values has been exceeded.
+func (l *LabelLimit) seen(value string) (known bool, full bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, known = l.values[value]
+	return known, l.overflowed
+}
+
+// allow returns true when value has been seen before or can be added without exceeding max.
+func (l *LabelLimit) allow(value string, max int) bool {
+	if max == 0 {
+		return true
+	}
+
+	// Most values have been seen before; avoid contention on the write lock.
+	if known, full := l.seen(value); known || full {
+		return known
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.values[value]; ok {
+		return true
+	}
+
+	if len(l.values) >= max {
+		if !l.overflowed {
+			logger.Warnf("Metric label '%s' exceeds %d distinct values, reporting further values as '%s'", l.key, max, OverflowValue)
+			l.overflowed = true
+		}
+
+		return false
+	}
+
+	l.values[value] = struct{}{}
+	return true
+}
+
+// String returns a label for value, or OverflowValue when the maximum number of distinct values has been reached.
+func (l *LabelLimit) String(value string) label.KeyValue {
+	if !l.allow(value, int(atomic.LoadInt64(&maxLabelValues))) {
+		value = OverflowValue
+	}
+
+	return label.String(l.key, value)
+}
@@ -0,0 +1,46 @@
+package instr
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/label"
+)
+
+// withMaxLabelValues runs f with the maximum number of label values set to max.
+func withMaxLabelValues(max int64, f func()) {
+	old := atomic.SwapInt64(&maxLabelValues, max)
+	defer atomic.StoreInt64(&maxLabelValues, old)
+
+	f()
+}
+
+func TestLabelLimitExceeded(t *testing.T) {
+	withMaxLabelValues(2, func() {
+		l := NewLabelLimit("mimetype")
+
+		assert.Equal(t, label.String("mimetype", "text/plain"), l.String("text/plain"))
+		assert.Equal(t, label.String("mimetype", "image/png"), l.String("image/png"))
+
+		// Values beyond the maximum overflow.
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, label.String("mimetype", OverflowValue), l.String(fmt.Sprintf("application/x-%d", i)))
+		}
+
+		// Values seen before are still reported.
+		assert.Equal(t, label.String("mimetype", "text/plain"), l.String("text/plain"))
+	})
+}
+
+func TestLabelLimitUnlimited(t *testing.T) {
+	withMaxLabelValues(0, func() {
+		l := NewLabelLimit("mimetype")
+
+		for i := 0; i < 1000; i++ {
+			value := fmt.Sprintf("application/x-%d", i)
+			assert.Equal(t, label.String("mimetype", value), l.String(value))
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"fmt"
+)
+
+// Config specifies the configuration for logging.
+type Config struct {
+	Level      string            // Minimum level of logged messages: debug, info, warn or error.
+	Components map[string]string // Levels overriding Level for components (e.g. crawler, amqp, extractor or indexer).
+}
+
+// DefaultConfig returns the default configuration for logging.
+func DefaultConfig() *Config {
+	return &Config{
+		Level:      "info",
+		Components: map[string]string{},
+	}
+}
+
+// Validate returns an error when Level or any of the component levels are unknown.
+func (c *Config) Validate() error {
+	if _, err := ParseLevel(c.Level); err != nil {
+		return err
+	}
+
+	for component, level := range c.Components {
+		if _, err := ParseLevel(level); err != nil {
+			return fmt.Errorf("component '%s': %w", component, err)
+		}
+	}
+
+	return nil
+}
+
+// Configure sets the levels of all loggers from c.
+func Configure(c *Config) error {
+	level, err := ParseLevel(c.Level)
+	if err != nil {
+		return err
+	}
+
+	components := make(map[string]Level, len(c.Components))
+	for component, l := range c.Components {
+		if components[component], err = ParseLevel(l); err != nil {
+			return fmt.Errorf("component '%s': %w", component, err)
+		}
+	}
+
+	SetLevels(level, components)
+
+	return nil
+}
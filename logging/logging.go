@@ -0,0 +1,119 @@
+// Package logging provides leveled logging with per-component levels, on top of the standard logger.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Level represents the severity of log messages.
+type Level int
+
+// Levels in increasing order of severity.
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+}
+
+// ErrUnknownLevel is returned when parsing an unknown level.
+var ErrUnknownLevel = errors.New("unknown log level")
+
+// String returns the name of the level.
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel returns the Level with (case-insensitive) name s.
+func ParseLevel(s string) (Level, error) {
+	for l, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return l, nil
+		}
+	}
+
+	return InfoLevel, fmt.Errorf("%w: '%s'", ErrUnknownLevel, s)
+}
+
+var (
+	mu              sync.RWMutex
+	defaultLevel    = InfoLevel
+	componentLevels = map[string]Level{}
+)
+
+// SetLevels sets the minimum level of logged messages, overriding it for components.
+func SetLevels(level Level, components map[string]Level) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	defaultLevel = level
+	componentLevels = components
+}
+
+// levelFor returns the minimum level for component.
+func levelFor(component string) Level {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if l, ok := componentLevels[component]; ok {
+		return l
+	}
+
+	return defaultLevel
+}
+
+// Logger logs messages for a component. Levels are looked up for every message, so Loggers can be created before
+// logging is configured, e.g. as package variables.
+type Logger struct {
+	component string
+}
+
+// New returns a Logger for component.
+func New(component string) *Logger {
+	return &Logger{component}
+}
+
+// Enabled returns true when messages at level are logged, e.g. to avoid expensive formatting.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= levelFor(l.component)
+}
+
+func (l *Logger) output(level Level, format string, v ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	// Calldepth 3 reports the caller of Debugf etc. with log.Lshortfile.
+	log.Output(3, fmt.Sprintf("%-5s %s: %s", strings.ToUpper(level.String()), l.component, fmt.Sprintf(format, v...)))
+}
+
+// Debugf logs detailed messages, e.g. for every processed item.
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.output(DebugLevel, format, v...)
+}
+
+// Infof logs messages about normal operation.
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.output(InfoLevel, format, v...)
+}
+
+// Warnf logs recoverable problems.
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.output(WarnLevel, format, v...)
+}
+
+// Errorf logs errors requiring attention.
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.output(ErrorLevel, format, v...)
+}
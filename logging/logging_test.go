@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggingTestSuite struct {
+	suite.Suite
+
+	buf *bytes.Buffer
+}
+
+func (s *LoggingTestSuite) SetupTest() {
+	s.buf = new(bytes.Buffer)
+
+	log.SetOutput(s.buf)
+	log.SetFlags(0)
+}
+
+func (s *LoggingTestSuite) TearDownTest() {
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
+
+	SetLevels(InfoLevel, map[string]Level{})
+}
+
+func (s *LoggingTestSuite) TestParseLevel() {
+	l, err := ParseLevel("WARN")
+	s.NoError(err)
+	s.Equal(WarnLevel, l)
+
+	_, err = ParseLevel("verbose")
+	s.True(errors.Is(err, ErrUnknownLevel))
+}
+
+func (s *LoggingTestSuite) TestDefaultLevel() {
+	l := New("crawler")
+
+	l.Debugf("debug %d", 1)
+	l.Infof("info %d", 2)
+
+	s.Equal("INFO  crawler: info 2\n", s.buf.String())
+}
+
+func (s *LoggingTestSuite) TestComponentLevels() {
+	s.Require().NoError(Configure(&Config{
+		Level: "error",
+		Components: map[string]string{
+			"amqp": "debug",
+		},
+	}))
+
+	New("crawler").Warnf("crawler")
+	New("amqp").Debugf("amqp")
+
+	s.Equal("DEBUG amqp: amqp\n", s.buf.String())
+}
+
+func (s *LoggingTestSuite) TestValidate() {
+	cfg := DefaultConfig()
+	s.NoError(cfg.Validate())
+
+	cfg.Components["amqp"] = "verbose"
+	s.True(errors.Is(cfg.Validate(), ErrUnknownLevel))
+}
+
+func TestLoggingTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggingTestSuite))
+}
@@ -3,14 +3,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ipfs-search/ipfs-search/commands"
+	"github.com/ipfs-search/ipfs-search/components/crawler/worker"
+	"github.com/ipfs-search/ipfs-search/components/index/elasticsearch"
+	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
+	"github.com/ipfs-search/ipfs-search/components/seed"
 	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/logging"
 	"gopkg.in/urfave/cli.v1"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -28,14 +36,117 @@ func main() {
 		{
 			Name:    "add",
 			Aliases: []string{"a"},
-			Usage:   "add `HASH` to crawler queue",
+			Usage:   "add `HASH` or /ipns/<name> (including DNSLink domains) to crawler queue",
 			Action:  add,
 		},
+		{
+			Name:      "import-car",
+			Usage:     "import CAR archive `FILE` into IPFS and add its root(s) to crawler queue",
+			ArgsUsage: "FILE",
+			Action:    importCAR,
+		},
+		{
+			Name:      "import-cids",
+			Usage:     "add CIDs listed in `FILE` (or stdin) to crawler queue, one per line or as JSON objects with cid, name and parent",
+			ArgsUsage: "[FILE]",
+			Action:    importCIDs,
+			Flags: []cli.Flag{
+				cli.Float64Flag{
+					Name:  "rate",
+					Usage: "queue at most `N` CIDs per second; unlimited when 0",
+				},
+				cli.DurationFlag{
+					Name:  "progress-interval",
+					Usage: "report progress every `DURATION`",
+					Value: 10 * time.Second,
+				},
+			},
+		},
 		{
 			Name:    "crawl",
 			Aliases: []string{"c"},
 			Usage:   "start crawler",
 			Action:  crawl,
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "max-messages",
+					Usage: "batch mode: exit after processing `N` messages",
+				},
+				cli.DurationFlag{
+					Name:  "idle-timeout",
+					Usage: "batch mode: exit when no messages have been received for `DURATION`",
+				},
+			},
+		},
+		{
+			Name:      "replay",
+			Usage:     "replay dead-lettered messages for `QUEUE`",
+			ArgsUsage: "QUEUE",
+			Action:    replay,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "reason",
+					Usage: "only replay messages dead-lettered for `REASON` (e.g. rejected, expired)",
+				},
+				cli.IntFlag{
+					Name:  "max",
+					Usage: "replay at most `N` messages",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "list messages without replaying them",
+				},
+			},
+		},
+		{
+			Name:      "reindex",
+			Usage:     "copy documents from `SOURCE` to `DESTINATION` index, e.g. for mapping changes",
+			ArgsUsage: "SOURCE DESTINATION",
+			Action:    reindex,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "batch-size",
+					Usage: "copy `N` documents per request",
+					Value: 500,
+				},
+				cli.DurationFlag{
+					Name:  "keep-alive",
+					Usage: "keep scroll alive for `DURATION` between requests; resuming must happen within this time",
+					Value: 10 * time.Minute,
+				},
+				cli.StringFlag{
+					Name:  "state",
+					Usage: "persist progress to `FILE`, resuming from it when it exists",
+				},
+				cli.StringSliceFlag{
+					Name:  "rename",
+					Usage: "rename top-level field `OLD=NEW`; may be repeated",
+				},
+				cli.UintFlag{
+					Name:  "max-retries",
+					Usage: "retry documents failing with a retryable status (429, 503) up to `N` times",
+					Value: 3,
+				},
+				cli.DurationFlag{
+					Name:  "retry-backoff",
+					Usage: "wait `DURATION` before retrying failed documents, doubling on every retry",
+					Value: time.Second,
+				},
+				cli.IntFlag{
+					Name:  "workers",
+					Usage: "write `N` pages concurrently",
+					Value: 1,
+				},
+				cli.Float64Flag{
+					Name:  "rate-limit",
+					Usage: "send at most `N` bulk requests per second across workers, 0 for unlimited",
+				},
+			},
+		},
+		{
+			Name:   "verify",
+			Usage:  "check whether a sample of indexed content is still reachable, marking documents accordingly",
+			Action: verify,
 		},
 		{
 			Name:    "config",
@@ -86,6 +197,10 @@ func getConfig(c *cli.Context) (*config.Config, error) {
 		return nil, err
 	}
 
+	if err = logging.Configure(cfg.LoggingConfig()); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
@@ -147,6 +262,117 @@ func add(c *cli.Context) error {
 	return nil
 }
 
+func importCAR(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	if c.NArg() != 1 {
+		return cli.NewExitError("Please supply one CAR file as argument.", 1)
+	}
+	file := c.Args().Get(0)
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	fmt.Printf("Importing CAR file '%s'\n", file)
+
+	err = commands.ImportCAR(ctx, cfg, file)
+
+	switch {
+	case errors.Is(err, commands.ErrImport):
+		return cli.NewExitError(fmt.Sprintf("Error importing CAR file: %s", err), 2)
+	case errors.Is(err, commands.ErrCrawl):
+		return cli.NewExitError(fmt.Sprintf("Error queueing imported roots for crawling: %s", err), 3)
+	case err != nil:
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func importCIDs(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	if c.NArg() > 1 {
+		return cli.NewExitError("Please supply at most one file as argument.", 1)
+	}
+	file := c.Args().Get(0)
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	opts := seed.Options{
+		Rate:             c.Float64("rate"),
+		ProgressInterval: c.Duration("progress-interval"),
+		Priority:         9, // Highest priority, as for add
+	}
+
+	err = commands.ImportCIDs(ctx, cfg, file, opts)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func verify(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	err = commands.Verify(ctx, cfg)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func replay(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	if c.NArg() != 1 {
+		return cli.NewExitError("Please supply one queue as argument.", 1)
+	}
+	queue := c.Args().Get(0)
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	opts := amqp.ReplayOptions{
+		Reason:   c.String("reason"),
+		MaxCount: c.Int("max"),
+		DryRun:   c.Bool("dry-run"),
+	}
+
+	err = commands.Replay(ctx, cfg, queue, opts)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
 // onSigTerm calls f() when SIGTERM (control-C) is received
 func onSigTerm(f func()) {
 	sigChan := make(chan os.Signal, 2)
@@ -182,7 +408,61 @@ func crawl(c *cli.Context) error {
 		return cli.NewExitError(err.Error(), 1)
 	}
 
-	err = commands.Crawl(ctx, cfg)
+	batchOpts := worker.BatchOptions{
+		MaxMessages: c.Uint("max-messages"),
+		IdleTimeout: c.Duration("idle-timeout"),
+	}
+
+	err = commands.Crawl(ctx, cfg, batchOpts)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func reindex(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Allow SIGTERM / Control-C quit through context
+	onSigTerm(cancel)
+
+	if c.NArg() != 2 {
+		return cli.NewExitError("Please supply source and destination index as arguments.", 1)
+	}
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	opts := &elasticsearch.ReindexOptions{
+		Source:      c.Args().Get(0),
+		Destination: c.Args().Get(1),
+		BatchSize:   c.Int("batch-size"),
+		KeepAlive:   c.Duration("keep-alive"),
+		StateFile:   c.String("state"),
+		Workers:     c.Int("workers"),
+		RateLimit:   c.Float64("rate-limit"),
+		Retry: elasticsearch.BulkRetry{
+			MaxRetries: c.Uint("max-retries"),
+			Backoff:    c.Duration("retry-backoff"),
+		},
+	}
+
+	if renames := c.StringSlice("rename"); len(renames) > 0 {
+		names := make(map[string]string, len(renames))
+		for _, r := range renames {
+			parts := strings.SplitN(r, "=", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return cli.NewExitError(fmt.Sprintf("Invalid rename '%s', expected OLD=NEW.", r), 1)
+			}
+			names[parts[0]] = parts[1]
+		}
+		opts.Transform = elasticsearch.RenameFields(names)
+	}
+
+	err = commands.Reindex(ctx, cfg, opts)
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
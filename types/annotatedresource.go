@@ -9,6 +9,10 @@ type AnnotatedResource struct {
 	*Resource
 	Reference `json:",omitempty"`
 	Stat      `json:",omitempty"`
+	IPNSName  string `json:",omitempty"` // IPNS name (e.g. /ipns/ipfs.io) the Resource was resolved from; also its ID until resolved.
+	Attempts  uint   `json:",omitempty"` // Amount of failed attempts at crawling the Resource.
+	MimeType  string `json:",omitempty"` // MIME type sniffed from the content, if any.
+	Error     string `json:",omitempty"` // Error rendering the Resource invalid, for resources queued as invalid.
 }
 
 // String returns the first reference or the URI.
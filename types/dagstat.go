@@ -0,0 +1,9 @@
+package types
+
+// DagStat represents structural statistics of the DAG of a Resource.
+type DagStat struct {
+	Blocks    uint64 // Number of unique blocks, including the root.
+	Size      uint64 // Cumulative size of the DAG in bytes.
+	Depth     uint   // Maximum depth of links from the root.
+	Truncated bool   // Whether traversal stopped at the maximum depth or block count.
+}
@@ -24,6 +24,12 @@ var (
 	// ErrInvalidResource is returned when a resource is unsupported or invalid.
 	ErrInvalidResource = errors.New("resource invalid")
 
+	// ErrUnresolvable is returned when a name could not be resolved (at this time); resolution may be retried.
+	ErrUnresolvable = errors.New("name resolution failed")
+
+	// ErrDenied is returned when a resource is on the denylist.
+	ErrDenied = errors.New("resource denied")
+
 	// ErrUnsupportedType is returned when the type of a resource is currently unsupported.
 	ErrUnsupportedType = WrappedError{ErrInvalidResource, "unsupported type"}
 )
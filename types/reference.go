@@ -4,6 +4,9 @@ package types
 type Reference struct {
 	Parent *Resource
 	Name   string
+	Path   string `json:",omitempty"` // Full path from a known root, e.g. /ipfs/<root>/docs/readme.md.
+
+	WebsiteRoot string `json:",omitempty"` // ID of the nearest ancestor directory with an index page, if any.
 }
 
 // String shows the name
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := NewRateLimiter(0, 1)
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, l.Allow())
+	}
+
+	assert.NoError(t, l.Wait(context.Background()))
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(1, 3)
+
+	assert.True(t, l.Allow())
+	assert.True(t, l.Allow())
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+}
+
+func TestRateLimiterMinimumBurst(t *testing.T) {
+	l := NewRateLimiter(1, 0)
+
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+	now := l.last
+
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+
+	// Refilled at 10 per second, up to the burst.
+	assert.Equal(t, time.Duration(0), l.reserve(now.Add(100*time.Millisecond)))
+	l.refill(now.Add(time.Hour))
+	assert.Equal(t, 2.0, l.tokens)
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := NewRateLimiter(10, 1)
+	now := l.last
+
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+
+	// Every waiting event takes a token, driving tokens negative, and waits for its own.
+	assert.Equal(t, 100*time.Millisecond, l.reserve(now))
+	assert.Equal(t, 200*time.Millisecond, l.reserve(now))
+	assert.Equal(t, -2.0, l.tokens)
+}
+
+func TestRateLimiterNegativeTokens(t *testing.T) {
+	l := NewRateLimiter(10, 1)
+	now := l.last
+
+	l.reserve(now)
+	l.reserve(now)
+	l.reserve(now)
+
+	// Reported while waiting.
+	assert.Less(t, l.Tokens(), 0.0)
+
+	// Not allowed until the waiting events have had their tokens.
+	assert.False(t, l.Allow())
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(100, 1)
+
+	assert.NoError(t, l.Wait(context.Background()))
+
+	start := time.Now()
+	assert.NoError(t, l.Wait(context.Background()))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(5*time.Millisecond))
+}
+
+func TestRateLimiterWaitRefund(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	assert.True(t, l.Allow())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, l.Wait(ctx))
+
+	// The token reserved by the cancelled wait was returned, so the next event waits about a second, not two.
+	assert.InDelta(t, 0, l.Tokens(), 0.1)
+	assert.InDelta(t, float64(time.Second), float64(l.reserve(time.Now())), float64(100*time.Millisecond))
+}
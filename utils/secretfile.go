@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// ReadSecretFile returns the (trimmed) contents of a secret file, e.g. a Docker or Kubernetes secret mount, returning
+// an error when it can't be read or is empty.
+func ReadSecretFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	secret := strings.TrimSpace(string(b))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+
+	return secret, nil
+}
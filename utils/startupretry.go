@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// maxStartupBackoff bounds the wait between startup attempts.
+const maxStartupBackoff = 30 * time.Second
+
+// RetryStartup calls connect until it succeeds, waiting backoff after the first failure and doubling the wait after
+// every next one, so that services wait for their dependencies to come up rather than crash. Every failed attempt is
+// logged. No more attempts are started after timeout has passed; connect is called once when timeout is 0.
+func RetryStartup(ctx context.Context, name string, timeout time.Duration, backoff time.Duration, connect func(context.Context) error) error {
+	var (
+		deadline = time.Now().Add(timeout)
+		wait     = backoff
+		err      error
+	)
+
+	for attempt := 1; ; attempt++ {
+		if err = connect(ctx); err == nil {
+			return nil
+		}
+
+		if time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("%s unavailable after %d attempt(s): %w", name, attempt, err)
+		}
+
+		log.Printf("Connecting to %s failed (attempt %d): %v, retrying in %s", name, attempt, err, wait)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		if wait *= 2; wait > maxStartupBackoff {
+			wait = maxStartupBackoff
+		}
+	}
+}
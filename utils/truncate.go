@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"unicode/utf8"
+)
+
+// Truncate returns s truncated to at most maxSize bytes, on a rune boundary.
+func Truncate(s string, maxSize int) string {
+	if len(s) <= maxSize {
+		return s
+	}
+
+	for maxSize > 0 && !utf8.RuneStart(s[maxSize]) {
+		maxSize--
+	}
+
+	return s[:maxSize]
+}
@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 10))
+	assert.Equal(t, "caf", Truncate("café", 4)) // é is 2 bytes.
+	assert.Equal(t, "café", Truncate("café", 5))
+	assert.Equal(t, "", Truncate("é", 1))
+}